@@ -96,7 +96,14 @@ func (abi ABI) getArguments(name string, data []byte) (Arguments, error) {
 		args = event.Inputs
 	}
 	if args == nil {
-		return nil, fmt.Errorf("abi: could not locate named method or event: %s", name)
+		// Errors are looked up last, as their name may clash with the name
+		// of a method or event.
+		if e, ok := abi.Errors[name]; ok {
+			args = e.Inputs
+		}
+	}
+	if args == nil {
+		return nil, fmt.Errorf("abi: could not locate named method, event or error: %s", name)
 	}
 	return args, nil
 }
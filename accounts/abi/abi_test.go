@@ -317,6 +317,26 @@ func TestCustomErrors(t *testing.T) {
 	check("MyError", "MyError(uint256)")
 }
 
+func TestUnpackCustomError(t *testing.T) {
+	t.Parallel()
+	json := `[{"inputs":[{"name":"available","type":"uint256"},{"name":"required","type":"uint256"}],"name":"InsufficientBalance","type":"error"}]`
+	abi, err := JSON(strings.NewReader(json))
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := append(common.LeftPadBytes([]byte{1}, 32), common.LeftPadBytes([]byte{2}, 32)...)
+	var out struct {
+		Available *big.Int
+		Required  *big.Int
+	}
+	if err := abi.UnpackIntoInterface(&out, "InsufficientBalance", data); err != nil {
+		t.Fatal(err)
+	}
+	if out.Available.Uint64() != 1 || out.Required.Uint64() != 2 {
+		t.Fatalf("wrong error fields: %+v", out)
+	}
+}
+
 func TestMultiPack(t *testing.T) {
 	t.Parallel()
 	abi, err := JSON(strings.NewReader(jsondata))
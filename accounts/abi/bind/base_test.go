@@ -17,8 +17,10 @@
 package bind_test
 
 import (
+	"bytes"
 	"context"
 	"errors"
+	"fmt"
 	"math/big"
 	"reflect"
 	"strings"
@@ -587,3 +589,28 @@ func TestCrashers(t *testing.T) {
 	abi.JSON(strings.NewReader(`[{"inputs":[{"type":"tuple[]","components":[{"type":"bool","name":"----"}]}]}]`))
 	abi.JSON(strings.NewReader(`[{"inputs":[{"type":"tuple[]","components":[{"type":"bool","name":"foo.Bar"}]}]}]`))
 }
+
+type dataError struct{ data interface{} }
+
+func (e *dataError) Error() string          { return "execution reverted" }
+func (e *dataError) ErrorData() interface{} { return e.data }
+
+func TestRevertData(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		err  error
+		data []byte
+		ok   bool
+	}{
+		{&dataError{"0x01020304"}, []byte{1, 2, 3, 4}, true},
+		{fmt.Errorf("wrapped: %w", &dataError{[]byte{5}}), []byte{5}, true},
+		{&dataError{"transaction indexing is in progress"}, nil, false},
+		{errors.New("execution reverted"), nil, false},
+	}
+	for i, test := range tests {
+		data, ok := bind.RevertData(test.err)
+		if ok != test.ok || !bytes.Equal(data, test.data) {
+			t.Errorf("test %d: have %x/%v, want %x/%v", i, data, ok, test.data, test.ok)
+		}
+	}
+}
@@ -0,0 +1,353 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package bind
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// ErrBatchNotExecuted is returned by the results of a batch which has not been
+// executed yet.
+var ErrBatchNotExecuted = errors.New("batch not executed")
+
+// BatchCall is a contract call of a batch. The output, or the error the call
+// failed with, is set by the BatchExecutor.
+type BatchCall struct {
+	To   common.Address
+	Data []byte
+
+	Output []byte
+	Err    error
+	done   bool
+}
+
+// BatchExecutor executes the calls of a batch. Errors of individual calls are
+// reported in the calls, the returned error is a failure of the whole batch.
+type BatchExecutor interface {
+	ExecuteBatch(ctx context.Context, opts *CallOpts, calls []*BatchCall) error
+}
+
+// Batch collects contract calls, to be executed together in a single request.
+//
+// Calls are added with AddCall or AddMethod, which return a handle to retrieve
+// the typed result once the batch has been executed:
+//
+//	batch := new(bind.Batch)
+//	name := bind.AddCall(batch, token, packedName, unpackName)
+//	supply := bind.AddCall(batch, token, packedSupply, unpackSupply)
+//	if err := batch.Execute(nil, bind.NewMulticallExecutor(client)); err != nil {
+//		...
+//	}
+//	n, err := name.Result()
+type Batch struct {
+	calls []*BatchCall
+}
+
+// Add adds a call with the raw calldata to the batch.
+func (b *Batch) Add(to common.Address, calldata []byte) *BatchCall {
+	call := &BatchCall{To: to, Data: calldata}
+	b.calls = append(b.calls, call)
+	return call
+}
+
+// Len returns the number of calls in the batch.
+func (b *Batch) Len() int {
+	return len(b.calls)
+}
+
+// Execute executes the calls added to the batch since the last execution, and
+// resets it.
+func (b *Batch) Execute(opts *CallOpts, executor BatchExecutor) error {
+	if opts == nil {
+		opts = new(CallOpts)
+	}
+	calls := b.calls
+	b.calls = nil
+	if len(calls) == 0 {
+		return nil
+	}
+	if err := executor.ExecuteBatch(ensureContext(opts.Context), opts, calls); err != nil {
+		for _, call := range calls {
+			call.Err = err
+		}
+		return err
+	}
+	for _, call := range calls {
+		call.done = true
+	}
+	return nil
+}
+
+// BatchResult is the typed result of a call added to a batch.
+type BatchResult[T any] struct {
+	call   *BatchCall
+	unpack func([]byte) (T, error)
+}
+
+// Result returns the unpacked output of the call, once the batch is executed.
+func (r *BatchResult[T]) Result() (T, error) {
+	var zero T
+	if r.call.Err != nil {
+		return zero, r.call.Err
+	}
+	if !r.call.done {
+		return zero, ErrBatchNotExecuted
+	}
+	return r.unpack(r.call.Output)
+}
+
+// AddCall adds a call of the contract with the packed calldata to the batch, the
+// result of which is unpacked with the given function.
+func AddCall[T any](b *Batch, c *BoundContract, calldata []byte, unpack func([]byte) (T, error)) *BatchResult[T] {
+	return &BatchResult[T]{call: b.Add(c.address, calldata), unpack: unpack}
+}
+
+// AddMethod adds a call of the contract method to the batch, packing the input
+// and unpacking the output with the contract ABI like BoundContract.Call.
+func (b *Batch) AddMethod(c *BoundContract, method string, params ...interface{}) (*BatchResult[[]interface{}], error) {
+	input, err := c.abi.Pack(method, params...)
+	if err != nil {
+		return nil, err
+	}
+	unpack := func(output []byte) ([]interface{}, error) {
+		return c.abi.Unpack(method, output)
+	}
+	return AddCall(b, c, input, unpack), nil
+}
+
+// DefaultBatchSize is the default number of calls sent in a single request by
+// the batch executors.
+const DefaultBatchSize = 100
+
+// NewRPCBatchExecutor creates an executor sending the calls of a batch as a
+// JSON-RPC batch of eth_call requests.
+func NewRPCBatchExecutor(client *rpc.Client) *RPCBatchExecutor {
+	return &RPCBatchExecutor{client: client, BatchSize: DefaultBatchSize}
+}
+
+// RPCBatchExecutor sends the calls of a batch as JSON-RPC batches of eth_call
+// requests, of at most BatchSize calls each.
+type RPCBatchExecutor struct {
+	client    *rpc.Client
+	BatchSize int
+}
+
+// ExecuteBatch implements BatchExecutor.
+func (e *RPCBatchExecutor) ExecuteBatch(ctx context.Context, opts *CallOpts, calls []*BatchCall) error {
+	for len(calls) > 0 {
+		chunk := calls[:min(len(calls), max(e.BatchSize, 1))]
+		calls = calls[len(chunk):]
+
+		reqs := make([]rpc.BatchElem, len(chunk))
+		outputs := make([]hexutil.Bytes, len(chunk))
+		for i, call := range chunk {
+			reqs[i] = rpc.BatchElem{
+				Method: "eth_call",
+				Args:   []interface{}{callArg(opts.From, call.To, call.Data), blockArg(opts)},
+				Result: &outputs[i],
+			}
+		}
+		if err := e.client.BatchCallContext(ctx, reqs); err != nil {
+			return err
+		}
+		// Make sure there are contracts to operate on for the empty outputs,
+		// like BoundContract.Call.
+		var (
+			empty []*BatchCall
+			codes []rpc.BatchElem
+		)
+		for i, call := range chunk {
+			call.Output, call.Err = outputs[i], reqs[i].Error
+			if call.Err == nil && len(call.Output) == 0 {
+				empty = append(empty, call)
+				codes = append(codes, rpc.BatchElem{
+					Method: "eth_getCode",
+					Args:   []interface{}{call.To, blockArg(opts)},
+					Result: new(hexutil.Bytes),
+				})
+			}
+		}
+		if len(codes) == 0 {
+			continue
+		}
+		if err := e.client.BatchCallContext(ctx, codes); err != nil {
+			return err
+		}
+		for i, call := range empty {
+			if codes[i].Error != nil {
+				call.Err = codes[i].Error
+			} else if len(*codes[i].Result.(*hexutil.Bytes)) == 0 {
+				call.Err = ErrNoCode
+			}
+		}
+	}
+	return nil
+}
+
+// DefaultMulticallAddress is the address the multicall contract is placed at
+// with a state override by default.
+var DefaultMulticallAddress = common.HexToAddress("0x00000000000000000000000000000000000ca11b")
+
+// multicallCode is the runtime code of the multicall contract. The calldata is
+// the concatenation of the calls, each encoded as
+//
+//	target (20 bytes) || len(data) (4 bytes) || data
+//
+// The calls are made in order, and their results are returned concatenated,
+// each encoded as
+//
+//	status (1 byte) || len(output) (4 bytes) || output
+//
+// The lowest bit of the status is set if the call succeeded, and the second one
+// if the target has no code.
+//
+//	   PUSH1 0                         ; out = 0
+//	   PUSH1 0                         ; ptr = 0
+//	loop:
+//	   JUMPDEST                        ; [ptr, out]
+//	   CALLDATASIZE DUP2 LT ISZERO PUSH2 @end JUMPI
+//	   DUP1 CALLDATALOAD PUSH1 96 SHR  ; target
+//	   DUP2 PUSH1 20 ADD CALLDATALOAD PUSH1 224 SHR ; len
+//	   DUP1 DUP4 PUSH1 24 ADD DUP6 PUSH1 5 ADD CALLDATACOPY ; data to out+5
+//	   PUSH1 0 PUSH1 0 DUP3 DUP7 PUSH1 5 ADD PUSH1 0 DUP7 GAS CALL
+//	   DUP3 EXTCODESIZE ISZERO PUSH1 1 SHL OR ; status
+//	   DUP5 MSTORE8
+//	   RETURNDATASIZE PUSH1 224 SHL DUP5 PUSH1 1 ADD MSTORE
+//	   RETURNDATASIZE PUSH1 0 DUP6 PUSH1 5 ADD RETURNDATACOPY
+//	   DUP3 PUSH1 24 ADD ADD SWAP2 POP POP ; ptr += 24 + len
+//	   SWAP1 RETURNDATASIZE ADD PUSH1 5 ADD SWAP1 ; out += 5 + len(output)
+//	   PUSH2 @loop JUMP
+//	end:
+//	   JUMPDEST POP PUSH1 0 RETURN
+var multicallCode = hexutil.MustDecode("0x600060005b3681101561005f57803560601c816014013560e01c808360180185600501376000600082866005016000865af1823b1560011b1784533d60e01b84600101523d6000856005013e8260180101915050903d0160050190610004565b506000f3")
+
+// NewMulticallExecutor creates an executor making the calls of a batch from a
+// multicall contract, in a single eth_call. The contract is placed at
+// DefaultMulticallAddress with a state override, so it does not need to be
+// deployed, but the node must support state overrides.
+//
+// Note the calls are made by the multicall contract: the From field of the call
+// options is the sender of the eth_call only, and not the caller of the
+// contracts.
+func NewMulticallExecutor(client *rpc.Client) *MulticallExecutor {
+	return &MulticallExecutor{client: client, Address: DefaultMulticallAddress, BatchSize: DefaultBatchSize}
+}
+
+// MulticallExecutor makes the calls of a batch from a multicall contract, in
+// eth_call requests of at most BatchSize calls each.
+type MulticallExecutor struct {
+	client    *rpc.Client
+	Address   common.Address
+	BatchSize int
+}
+
+// ExecuteBatch implements BatchExecutor.
+func (e *MulticallExecutor) ExecuteBatch(ctx context.Context, opts *CallOpts, calls []*BatchCall) error {
+	overrides := map[common.Address]interface{}{
+		e.Address: map[string]interface{}{"code": hexutil.Bytes(multicallCode)},
+	}
+	for len(calls) > 0 {
+		chunk := calls[:min(len(calls), max(e.BatchSize, 1))]
+		calls = calls[len(chunk):]
+
+		var input []byte
+		for _, call := range chunk {
+			input = append(input, call.To.Bytes()...)
+			input = binary.BigEndian.AppendUint32(input, uint32(len(call.Data)))
+			input = append(input, call.Data...)
+		}
+		var output hexutil.Bytes
+		if err := e.client.CallContext(ctx, &output, "eth_call", callArg(opts.From, e.Address, input), blockArg(opts), overrides); err != nil {
+			return err
+		}
+		if err := decodeMulticall(chunk, output); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// decodeMulticall sets the results of the calls from the multicall output.
+func decodeMulticall(calls []*BatchCall, output []byte) error {
+	for _, call := range calls {
+		if len(output) < 5 {
+			return fmt.Errorf("multicall output too short")
+		}
+		status, size := output[0], binary.BigEndian.Uint32(output[1:5])
+		if uint64(len(output)-5) < uint64(size) {
+			return fmt.Errorf("multicall output too short")
+		}
+		call.Output, output = output[5:5+size], output[5+size:]
+		switch {
+		case status&1 == 0:
+			call.Err = &revertError{data: call.Output}
+		case status&2 != 0 && len(call.Output) == 0:
+			call.Err = ErrNoCode
+		}
+	}
+	if len(output) != 0 {
+		return fmt.Errorf("multicall output too long")
+	}
+	return nil
+}
+
+// revertError is the error of a call reverted in a multicall. Like the errors
+// returned by the node, it carries the revert data, see RevertData.
+type revertError struct {
+	data []byte
+}
+
+func (e *revertError) Error() string {
+	return "execution reverted"
+}
+
+func (e *revertError) ErrorData() interface{} {
+	return hexutil.Encode(e.data)
+}
+
+// callArg encodes the arguments of an eth_call.
+func callArg(from common.Address, to common.Address, input []byte) interface{} {
+	return map[string]interface{}{
+		"from":  from,
+		"to":    to,
+		"input": hexutil.Bytes(input),
+	}
+}
+
+// blockArg encodes the block an eth_call is made at, according to the options.
+func blockArg(opts *CallOpts) interface{} {
+	switch {
+	case opts.BlockHash != (common.Hash{}):
+		return map[string]interface{}{"blockHash": opts.BlockHash}
+	case opts.Pending:
+		return "pending"
+	case opts.BlockNumber == nil:
+		return "latest"
+	case opts.BlockNumber.Sign() < 0 && opts.BlockNumber.IsInt64():
+		return rpc.BlockNumber(opts.BlockNumber.Int64()).String()
+	default:
+		return hexutil.EncodeBig(new(big.Int).Set(opts.BlockNumber))
+	}
+}
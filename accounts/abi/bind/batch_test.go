@@ -0,0 +1,144 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package bind_test
+
+import (
+	"bytes"
+	"errors"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/eth/ethconfig"
+	"github.com/ethereum/go-ethereum/ethclient/simulated"
+	"github.com/ethereum/go-ethereum/node"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// batchTestCode returns the calldata without its selector, and reverts with the
+// calldata if it starts with 0xff.
+var batchTestCode = hexutil.MustDecode("0x600436036004600037600035" + "60f81c60ff14601c57600436036000f3" + "5b366000600037366000fd")
+
+const batchTestABI = `[{"type":"function","name":"echo","stateMutability":"view","inputs":[{"name":"x","type":"uint256"}],"outputs":[{"name":"","type":"uint256"}]}]`
+
+func TestBatch(t *testing.T) {
+	var (
+		contract = common.HexToAddress("0x1000")
+		codeless = common.HexToAddress("0x2000")
+	)
+	// The executors need the raw RPC client, which the simulated backend does
+	// not expose: reach it over IPC instead.
+	dir, err := os.MkdirTemp("", "batch")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	ipc := filepath.Join(dir, "sim.ipc")
+	sim := simulated.NewBackend(types.GenesisAlloc{contract: {Code: batchTestCode}}, func(nodeConf *node.Config, ethConf *ethconfig.Config) {
+		nodeConf.IPCPath = ipc
+	})
+	defer sim.Close()
+	client, err := rpc.Dial(ipc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	parsed, err := abi.JSON(strings.NewReader(batchTestABI))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var (
+		echo     = bind.NewBoundContract(contract, parsed, sim.Client(), nil, nil)
+		missing  = bind.NewBoundContract(codeless, parsed, sim.Client(), nil, nil)
+		rpcBatch = bind.NewRPCBatchExecutor(client)
+		chunked  = bind.NewRPCBatchExecutor(client)
+		multi    = bind.NewMulticallExecutor(client)
+		chunkedM = bind.NewMulticallExecutor(client)
+	)
+	chunked.BatchSize, chunkedM.BatchSize = 2, 2
+
+	for name, executor := range map[string]bind.BatchExecutor{
+		"rpc":               rpcBatch,
+		"rpc-chunked":       chunked,
+		"multicall":         multi,
+		"multicall-chunked": chunkedM,
+	} {
+		t.Run(name, func(t *testing.T) {
+			batch := new(bind.Batch)
+			var results []*bind.BatchResult[[]interface{}]
+			for i := 0; i < 5; i++ {
+				res, err := batch.AddMethod(echo, "echo", big.NewInt(int64(i)))
+				if err != nil {
+					t.Fatal(err)
+				}
+				results = append(results, res)
+			}
+			typed := bind.AddCall(batch, echo, append([]byte{1, 2, 3, 4}, 5, 6), func(out []byte) (string, error) {
+				return hexutil.Encode(out), nil
+			})
+			reverted := batch.Add(contract, []byte{0xff, 1, 2, 3})
+			nocode, err := batch.AddMethod(missing, "echo", big.NewInt(1))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := typed.Result(); !errors.Is(err, bind.ErrBatchNotExecuted) {
+				t.Fatalf("unexpected error before execution: %v", err)
+			}
+			if err := batch.Execute(nil, executor); err != nil {
+				t.Fatal(err)
+			}
+			if batch.Len() != 0 {
+				t.Fatalf("batch not reset after execution: %d calls", batch.Len())
+			}
+			for i, res := range results {
+				out, err := res.Result()
+				if err != nil {
+					t.Fatalf("call %d failed: %v", i, err)
+				}
+				if out[0].(*big.Int).Int64() != int64(i) {
+					t.Fatalf("call %d: output mismatch: have %v", i, out[0])
+				}
+			}
+			if out, err := typed.Result(); err != nil || out != "0x0506" {
+				t.Fatalf("typed call: have %q, %v, want %q", out, err, "0x0506")
+			}
+			data, ok := bind.RevertData(reverted.Err)
+			if !ok || !bytes.Equal(data, []byte{0xff, 1, 2, 3}) {
+				t.Fatalf("reverted call: have data %x (%v), error %v", data, ok, reverted.Err)
+			}
+			if _, err := nocode.Result(); !errors.Is(err, bind.ErrNoCode) {
+				t.Fatalf("call to codeless account: have error %v, want %v", err, bind.ErrNoCode)
+			}
+			// The results must match the unbatched calls.
+			direct, err := echo.CallRaw(nil, append([]byte{1, 2, 3, 4}, 5, 6))
+			if err != nil || !bytes.Equal(direct, []byte{5, 6}) {
+				t.Fatalf("direct call: have %x, %v", direct, err)
+			}
+			if _, err := missing.CallRaw(nil, []byte{1, 2, 3, 4}); !errors.Is(err, bind.ErrNoCode) {
+				t.Fatalf("direct call to codeless account: have error %v", err)
+			}
+		})
+	}
+}
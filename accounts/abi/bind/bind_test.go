@@ -1902,11 +1902,25 @@ var bindTests = []struct {
 			if err != nil {
 				t.Error(err)
 			}
-			if err := contract.Error(new(bind.CallOpts)); err == nil {
+			err = contract.Error(new(bind.CallOpts))
+			if err == nil {
 				t.Fatalf("expected contract to throw error")
 			}
-			// TODO (MariusVanDerWijden unpack error using abigen
-			// once that is implemented
+			raw, ok := bind.RevertData(err)
+			if !ok {
+				t.Fatalf("no revert data in error: %v", err)
+			}
+			unpacked, err := UnpackNewErrorsError(raw)
+			if err != nil {
+				t.Fatalf("failed to unpack error: %v", err)
+			}
+			myErr, ok := unpacked.(*NewErrorsMyError3)
+			if !ok || myErr.A.Int64() != 1 || myErr.B.Int64() != 2 || myErr.C.Int64() != 3 {
+				t.Fatalf("wrong error unpacked: %v", unpacked)
+			}
+			if myErr.Error() != "MyError3(1, 2, 3)" {
+				t.Fatalf("wrong error message: %v", myErr)
+			}
 	   `,
 		nil,
 		nil,
@@ -0,0 +1,92 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package bind
+
+import (
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+)
+
+// tmplContractV2 extends the contract template data with the normalization
+// specific to the v2 bindings.
+type tmplContractV2 struct {
+	*tmplContract
+	ID      string                 // Library placeholder of the contract, or its type if unknown
+	Methods map[string]*tmplMethod // Calls and transacts, which share a namespace
+}
+
+// tmplDataV2 is the data structure required to fill the v2 binding template.
+type tmplDataV2 struct {
+	Package   string                     // Name of the package to place the generated file in
+	Contracts map[string]*tmplContractV2 // List of contracts to generate into this file
+	Structs   map[string]*tmplStruct     // Contract struct type definitions
+}
+
+// BindV2 generates Go bindings for the v2 binding runtime, in the bind/v2
+// package. Contrary to Bind, the generated code does not interact with the
+// chain: it only packs calldata and unpacks return values, events and errors,
+// which are then used with the generic helpers of the runtime.
+func BindV2(types []string, abis []string, bytecodes []string, pkg string, libs map[string]string, aliases map[string]string) (string, error) {
+	contracts, structs, err := parseContracts(types, abis, bytecodes, nil, LangGo, libs, aliases)
+	if err != nil {
+		return "", err
+	}
+	data := &tmplDataV2{
+		Package:   pkg,
+		Contracts: make(map[string]*tmplContractV2),
+		Structs:   structs,
+	}
+	for name, contract := range contracts {
+		methods := make(map[string]*tmplMethod)
+		identifiers := make(map[string]string)
+		for _, group := range []map[string]*tmplMethod{contract.Calls, contract.Transacts} {
+			for original, method := range group {
+				if other, ok := identifiers[method.Normalized.Name]; ok {
+					return "", fmt.Errorf("duplicated identifier \"%s\"(normalized \"%s\") of method \"%s\", use --alias for renaming", original, method.Normalized.Name, other)
+				}
+				identifiers[method.Normalized.Name] = original
+				methods[original] = method
+			}
+		}
+		// The constructor arguments become parameters of the packing function.
+		contract.Constructor.Inputs = normalizeArgs(contract.Constructor.Inputs)
+		// The library placeholders of all contracts are known when binding a
+		// combined-json output, they identify the contracts on deployment.
+		id := name
+		for pattern, lib := range libs {
+			if lib == name {
+				id = pattern
+			}
+		}
+		data.Contracts[name] = &tmplContractV2{tmplContract: contract, ID: id, Methods: methods}
+	}
+	return render(data, LangGo, tmplSourceGoV2)
+}
+
+// normalizeArgs names the anonymous arguments, and the ones named after a Go
+// keyword, after their position.
+func normalizeArgs(args abi.Arguments) abi.Arguments {
+	normalized := make(abi.Arguments, len(args))
+	copy(normalized, args)
+	for i, arg := range normalized {
+		if arg.Name == "" || isKeyWord(arg.Name) {
+			normalized[i].Name = fmt.Sprintf("arg%d", i)
+		}
+	}
+	return normalized
+}
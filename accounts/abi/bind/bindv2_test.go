@@ -0,0 +1,471 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package bind
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// bindV2Tests reuse the contracts of bindTests, exercising them through the v2
+// bindings.
+var bindV2Tests = []struct {
+	name    string
+	imports string
+	tester  string
+}{
+	{
+		`Interactor`,
+		`
+			"github.com/ethereum/go-ethereum/accounts/abi/bind/v2"
+		`,
+		`
+			sim, auth := newSimulation(t)
+			c := NewInteractor()
+
+			// Deploy with a constructor argument, transact and call
+			addr, _, err := bind.DeployContract(auth, common.FromHex(InteractorMetaData.Bin), sim.Client(), c.PackConstructor("Deploy string"))
+			if err != nil {
+				t.Fatalf("Failed to deploy interactor contract: %v", err)
+			}
+			sim.Commit()
+			instance := c.Instance(sim.Client(), addr)
+			if _, err := bind.Transact(instance, auth, c.PackTransact("Transact string")); err != nil {
+				t.Fatalf("Failed to transact with interactor contract: %v", err)
+			}
+			sim.Commit()
+
+			if str, err := bind.Call(instance, nil, c.PackDeployString(), c.UnpackDeployString); err != nil {
+				t.Fatalf("Failed to retrieve deploy string: %v", err)
+			} else if str != "Deploy string" {
+				t.Fatalf("Deploy string mismatch: have '%s', want 'Deploy string'", str)
+			}
+			if str, err := bind.Call(instance, nil, c.PackTransactString(), c.UnpackTransactString); err != nil {
+				t.Fatalf("Failed to retrieve transact string: %v", err)
+			} else if str != "Transact string" {
+				t.Fatalf("Transact string mismatch: have '%s', want 'Transact string'", str)
+			}
+		`,
+	},
+	{
+		`Getter`,
+		`
+			"math/big"
+
+			"github.com/ethereum/go-ethereum/accounts/abi/bind/v2"
+		`,
+		`
+			sim, auth := newSimulation(t)
+			c := NewGetter()
+
+			addr, _, err := bind.DeployContract(auth, common.FromHex(GetterMetaData.Bin), sim.Client(), nil)
+			if err != nil {
+				t.Fatalf("Failed to deploy getter contract: %v", err)
+			}
+			sim.Commit()
+
+			out, err := bind.Call(c.Instance(sim.Client(), addr), nil, c.PackGetter(), c.UnpackGetter)
+			if err != nil {
+				t.Fatalf("Failed to call anonymous field retriever: %v", err)
+			}
+			if out.Arg0 != "Hi" || out.Arg1.Cmp(big.NewInt(1)) != 0 {
+				t.Fatalf("Retrieved value mismatch: have %v/%v, want %v/%v", out.Arg0, out.Arg1, "Hi", 1)
+			}
+		`,
+	},
+	{
+		`Eventer`,
+		`
+			"math/big"
+			"time"
+
+			"github.com/ethereum/go-ethereum/accounts/abi/bind/v2"
+		`,
+		`
+			sim, auth := newSimulation(t)
+			c := NewEventer()
+
+			addr, _, err := bind.DeployContract(auth, common.FromHex(EventerMetaData.Bin), sim.Client(), nil)
+			if err != nil {
+				t.Fatalf("Failed to deploy eventer contract: %v", err)
+			}
+			sim.Commit()
+			instance := c.Instance(sim.Client(), addr)
+
+			// Subscribe to the future events and raise a few of them
+			sink := make(chan *EventerSimpleEvent, 16)
+			sub, err := bind.WatchEvents(instance, nil, c.UnpackSimpleEventEvent, sink, []any{common.Address{2}})
+			if err != nil {
+				t.Fatalf("Failed to watch simple events: %v", err)
+			}
+			defer sub.Unsubscribe()
+
+			for i := 1; i <= 3; i++ {
+				if _, err := bind.Transact(instance, auth, c.PackRaiseSimpleEvent(common.Address{byte(i)}, [32]byte{byte(i)}, true, big.NewInt(int64(10+i)))); err != nil {
+					t.Fatalf("event %d: raise failed: %v", i, err)
+				}
+			}
+			sim.Commit()
+
+			// Filter the past events on indexed arguments
+			it, err := bind.FilterEvents(instance, nil, c.UnpackSimpleEventEvent, []any{common.Address{1}, common.Address{3}})
+			if err != nil {
+				t.Fatalf("Failed to filter simple events: %v", err)
+			}
+			defer it.Close()
+
+			var values []uint64
+			for it.Next() {
+				if it.Value().Raw == nil || !it.Value().Flag {
+					t.Errorf("simple log content mismatch: %+v", it.Value())
+				}
+				values = append(values, it.Value().Value.Uint64())
+			}
+			if it.Error() != nil {
+				t.Fatalf("Failed to iterate simple events: %v", it.Error())
+			}
+			if len(values) != 2 || values[0] != 11 || values[1] != 13 {
+				t.Fatalf("Filtered events mismatch: have %v, want [11 13]", values)
+			}
+			select {
+			case ev := <-sink:
+				if ev.Addr != (common.Address{2}) || ev.Id != [32]byte{2} || ev.Value.Uint64() != 12 {
+					t.Fatalf("Watched event mismatch: %+v", ev)
+				}
+			case err := <-sub.Err():
+				t.Fatalf("Watch failed: %v", err)
+			case <-time.After(5 * time.Second):
+				t.Fatalf("Watched event not delivered")
+			}
+			// Logs of other events are rejected
+			if _, err := c.UnpackNodataEventEvent(it.Value().Raw); err == nil {
+				t.Fatalf("Unpacked log of another event")
+			}
+		`,
+	},
+	{
+		`NewErrors`,
+		`
+			"errors"
+
+			"github.com/ethereum/go-ethereum/accounts/abi/bind/v2"
+		`,
+		`
+			sim, auth := newSimulation(t)
+			c := NewNewErrors()
+
+			addr, _, err := bind.DeployContract(auth, common.FromHex(NewErrorsMetaData.Bin), sim.Client(), nil)
+			if err != nil {
+				t.Fatalf("Failed to deploy errors contract: %v", err)
+			}
+			sim.Commit()
+
+			_, err = bind.Call(c.Instance(sim.Client(), addr), nil, c.PackError(), func([]byte) (struct{}, error) { return struct{}{}, nil })
+			raw, ok := bind.RevertData(err)
+			if !ok {
+				t.Fatalf("Expected revert with data, have %v", err)
+			}
+			unpacked, err := c.UnpackError(raw)
+			if err != nil {
+				t.Fatalf("Failed to unpack error: %v", err)
+			}
+			var e *NewErrorsMyError3
+			if !errors.As(unpacked.(error), &e) || e.A.Uint64() != 1 || e.B.Uint64() != 2 || e.C.Uint64() != 3 {
+				t.Fatalf("Unpacked error mismatch: %#v", unpacked)
+			}
+			if common.BytesToHash(raw[:4]) != common.BytesToHash(NewErrorsMyError3ErrorID().Bytes()[:4]) {
+				t.Fatalf("Error selector mismatch")
+			}
+			if _, err := c.UnpackError([]byte{1, 2, 3, 4}); err == nil {
+				t.Fatalf("Unpacked unknown error")
+			}
+		`,
+	},
+	{
+		`Overload`,
+		`
+			"math/big"
+
+			"github.com/ethereum/go-ethereum/accounts/abi/bind/v2"
+		`,
+		`
+			sim, auth := newSimulation(t)
+			c := NewOverload()
+
+			addr, _, err := bind.DeployContract(auth, common.FromHex(OverloadMetaData.Bin), sim.Client(), nil)
+			if err != nil {
+				t.Fatalf("Failed to deploy contract: %v", err)
+			}
+			sim.Commit()
+			instance := c.Instance(sim.Client(), addr)
+
+			// The overloads of foo and bar are told apart by their position in the ABI
+			if _, err := bind.Transact(instance, auth, c.PackFoo(big.NewInt(1), big.NewInt(2))); err != nil {
+				t.Fatalf("Failed to transact: %v", err)
+			}
+			if _, err := bind.Transact(instance, auth, c.PackFoo0(big.NewInt(3))); err != nil {
+				t.Fatalf("Failed to transact: %v", err)
+			}
+			sim.Commit()
+
+			bars, err := bind.FilterEvents(instance, nil, c.UnpackBarEvent)
+			if err != nil {
+				t.Fatalf("Failed to filter bar events: %v", err)
+			}
+			if !bars.Next() || bars.Value().I.Uint64() != 3 || bars.Next() {
+				t.Fatalf("Wrong bar events: %v", bars.Error())
+			}
+			bar0s, err := bind.FilterEvents(instance, nil, c.UnpackBar0Event)
+			if err != nil {
+				t.Fatalf("Failed to filter bar0 events: %v", err)
+			}
+			if !bar0s.Next() || bar0s.Value().I.Uint64() != 1 || bar0s.Value().J.Uint64() != 2 || bar0s.Next() {
+				t.Fatalf("Wrong bar0 events: %v", bar0s.Error())
+			}
+			// A log of an overload can't be unpacked as the other one
+			if _, err := c.UnpackBarEvent(bar0s.Value().Raw); err == nil {
+				t.Fatalf("Unpacked log of another overload")
+			}
+		`,
+	},
+	{
+		`UseLibrary`,
+		`
+			"math/big"
+
+			"github.com/ethereum/go-ethereum/accounts/abi/bind/v2"
+		`,
+		`
+			sim, auth := newSimulation(t)
+
+			// The library is deployed first and linked into the contract
+			res, err := bind.LinkAndDeploy(&bind.DeploymentParams{
+				Contracts: []*bind.MetaData{UseLibraryMetaData},
+			}, bind.DefaultDeployer(auth, sim.Client()))
+			if err != nil {
+				t.Fatalf("Failed to deploy contracts: %v", err)
+			}
+			if len(res.Addresses) != 2 {
+				t.Fatalf("Deployed %d contracts, want 2", len(res.Addresses))
+			}
+			sim.Commit()
+
+			addr := res.Addresses[UseLibraryMetaData.ID]
+			if addr == (common.Address{}) {
+				t.Fatalf("Contract address not reported")
+			}
+			c := NewUseLibrary()
+			instance := c.Instance(sim.Client(), addr)
+			sum, err := bind.Call(instance, nil, c.PackAdd(big.NewInt(1), big.NewInt(2)), c.UnpackAdd)
+			if err != nil {
+				t.Fatalf("Failed to call linked contract: %v", err)
+			}
+			if sum.Cmp(big.NewInt(3)) != 0 {
+				t.Fatalf("Add did not return the correct result: %d != %d", sum, 3)
+			}
+		`,
+	},
+}
+
+// bindV2Helpers is shared by the tests of the v2 bindings.
+const bindV2Helpers = `
+package bindtest
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind/v2"
+	bind1 "github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient/simulated"
+)
+
+func newSimulation(t *testing.T) (*simulated.Backend, *bind.TransactOpts) {
+	key, _ := crypto.GenerateKey()
+	auth, _ := bind1.NewKeyedTransactorWithChainID(key, big.NewInt(1337))
+
+	sim := simulated.NewBackend(types.GenesisAlloc{auth.From: {Balance: big.NewInt(1000000000000000000)}})
+	t.Cleanup(func() { sim.Close() })
+	return sim, auth
+}
+`
+
+func TestGolangBindingsV2(t *testing.T) {
+	t.Parallel()
+	// Skip the test if no Go command can be found
+	gocmd := runtime.GOROOT() + "/bin/go"
+	if !common.FileExist(gocmd) {
+		t.Skip("go sdk not found for testing")
+	}
+	// Create a temporary workspace for the test suite
+	ws := t.TempDir()
+
+	pkg := filepath.Join(ws, "bindtest")
+	if err := os.MkdirAll(pkg, 0700); err != nil {
+		t.Fatalf("failed to create package: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(pkg, "helpers_test.go"), []byte(bindV2Helpers), 0600); err != nil {
+		t.Fatalf("failed to write test helpers: %v", err)
+	}
+	// Generate the test suite for all the contracts
+	for i, tt := range bindV2Tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				types         = []string{tt.name}
+				abi, bytecode []string
+				libs          map[string]string
+			)
+			for _, bt := range bindTests {
+				if bt.name == tt.name {
+					abi, bytecode, libs = bt.abi, bt.bytecode, bt.libs
+					if bt.types != nil {
+						types = bt.types
+					}
+				}
+			}
+			if abi == nil {
+				t.Fatalf("test %d: contract %s not found", i, tt.name)
+			}
+			// Generate the binding and create a Go source file in the workspace
+			bind, err := BindV2(types, abi, bytecode, "bindtest", libs, nil)
+			if err != nil {
+				t.Fatalf("test %d: failed to generate binding: %v", i, err)
+			}
+			if err = os.WriteFile(filepath.Join(pkg, strings.ToLower(tt.name)+".go"), []byte(bind), 0600); err != nil {
+				t.Fatalf("test %d: failed to write binding: %v", i, err)
+			}
+			// Generate the test file with the injected test code
+			code := fmt.Sprintf(`
+			package bindtest
+
+			import (
+				"testing"
+
+				"github.com/ethereum/go-ethereum/common"
+				%s
+			)
+
+			func Test%s(t *testing.T) {
+				%s
+			}
+		`, tt.imports, tt.name, tt.tester)
+			if err := os.WriteFile(filepath.Join(pkg, strings.ToLower(tt.name)+"_test.go"), []byte(code), 0600); err != nil {
+				t.Fatalf("test %d: failed to write tests: %v", i, err)
+			}
+		})
+	}
+	// Convert the package to go modules and use the current source for go-ethereum
+	moder := exec.Command(gocmd, "mod", "init", "bindtest")
+	moder.Dir = pkg
+	if out, err := moder.CombinedOutput(); err != nil {
+		t.Fatalf("failed to convert binding test to modules: %v\n%s", err, out)
+	}
+	pwd, _ := os.Getwd()
+	replacer := exec.Command(gocmd, "mod", "edit", "-x", "-require", "github.com/ethereum/go-ethereum@v0.0.0", "-replace", "github.com/ethereum/go-ethereum="+filepath.Join(pwd, "..", "..", "..")) // Repo root
+	replacer.Dir = pkg
+	if out, err := replacer.CombinedOutput(); err != nil {
+		t.Fatalf("failed to replace binding test dependency to current source tree: %v\n%s", err, out)
+	}
+	tidier := exec.Command(gocmd, "mod", "tidy")
+	tidier.Dir = pkg
+	if out, err := tidier.CombinedOutput(); err != nil {
+		t.Fatalf("failed to tidy Go module file: %v\n%s", err, out)
+	}
+	// Test the entire package and report any failures
+	cmd := exec.Command(gocmd, "test", "-v", "-count", "1")
+	cmd.Dir = pkg
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("failed to run binding test: %v\n%s", err, out)
+	}
+}
+
+func TestBindV2DuplicatedIdentifier(t *testing.T) {
+	t.Parallel()
+	// A call and a transaction normalizing to the same name would both produce
+	// PackFoo in the v2 bindings.
+	abi := `[
+		{"constant":true,"inputs":[],"name":"foo","outputs":[],"type":"function"},
+		{"constant":false,"inputs":[{"name":"a","type":"uint256"}],"name":"Foo","outputs":[],"type":"function"}
+	]`
+	if _, err := Bind([]string{"Dup"}, []string{abi}, []string{""}, nil, "bindtest", LangGo, nil, nil); err != nil {
+		t.Fatalf("v1 binding failed: %v", err)
+	}
+	if _, err := BindV2([]string{"Dup"}, []string{abi}, []string{""}, "bindtest", nil, nil); err == nil {
+		t.Fatal("duplicated identifier not detected")
+	}
+	if _, err := BindV2([]string{"Dup"}, []string{abi}, []string{""}, "bindtest", nil, map[string]string{"foo": "bar"}); err != nil {
+		t.Fatalf("aliased binding failed: %v", err)
+	}
+}
+
+func TestBindSignatureAlias(t *testing.T) {
+	t.Parallel()
+	abi := `[
+		{"anonymous":false,"inputs":[{"indexed":false,"name":"i","type":"uint256"}],"name":"bar","type":"event"},
+		{"anonymous":false,"inputs":[{"indexed":false,"name":"i","type":"uint256"},{"indexed":false,"name":"j","type":"uint256"}],"name":"bar","type":"event"}
+	]`
+	aliases := map[string]string{"bar(uint256,uint256)": "barPair"}
+	for _, bind := range []func() (string, error){
+		func() (string, error) {
+			return Bind([]string{"Overload"}, []string{abi}, []string{""}, nil, "bindtest", LangGo, nil, aliases)
+		},
+		func() (string, error) {
+			return BindV2([]string{"Overload"}, []string{abi}, []string{""}, "bindtest", nil, aliases)
+		},
+	} {
+		code, err := bind()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(code, "type OverloadBarPair struct") || !strings.Contains(code, "type OverloadBar struct") {
+			t.Fatalf("overloaded event not aliased by signature:\n%s", code)
+		}
+	}
+}
+
+func TestBindErrorEventClash(t *testing.T) {
+	t.Parallel()
+	abi := `[
+		{"anonymous":false,"inputs":[{"indexed":false,"name":"i","type":"uint256"}],"name":"Transfer","type":"event"},
+		{"inputs":[{"name":"i","type":"uint256"}],"name":"Transfer","type":"error"}
+	]`
+	for _, bind := range []func() (string, error){
+		func() (string, error) {
+			return Bind([]string{"Clash"}, []string{abi}, []string{""}, nil, "bindtest", LangGo, nil, nil)
+		},
+		func() (string, error) {
+			return BindV2([]string{"Clash"}, []string{abi}, []string{""}, "bindtest", nil, nil)
+		},
+	} {
+		code, err := bind()
+		if err != nil {
+			t.Fatalf("binding failed: %v", err)
+		}
+		if !strings.Contains(code, "type ClashTransferError struct") || !strings.Contains(code, "type ClashTransfer struct") {
+			t.Fatalf("error type not renamed:\n%s", code)
+		}
+	}
+}
@@ -4,9 +4,11 @@
 package {{.Package}}
 
 import (
+	"bytes"
+	"errors"
+	"fmt"
 	"math/big"
 	"strings"
-	"errors"
 
 	ethereum "github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/accounts/abi"
@@ -18,7 +20,9 @@ import (
 
 // Reference imports to suppress errors if they are not otherwise used.
 var (
+	_ = bytes.Equal
 	_ = errors.New
+	_ = fmt.Sprintf
 	_ = big.NewInt
 	_ = strings.NewReader
 	_ = ethereum.NotFound
@@ -484,4 +488,52 @@ var (
 		}
 
  	{{end}}
-{{end}}
\ No newline at end of file
+	{{range .Errors}}
+		// {{$contract.Type}}{{.Normalized.Name}} represents a {{.Original.Name}} error raised by the {{$contract.Type}} contract.
+		type {{$contract.Type}}{{.Normalized.Name}} struct {
+			{{range .Normalized.Inputs}}{{capitalise .Name}} {{bindtype .Type $structs}}; {{end}}
+		}
+
+		// Error implements the error interface.
+		func (e *{{$contract.Type}}{{.Normalized.Name}}) Error() string {
+			return fmt.Sprintf("{{.Original.Name}}({{range $i, $_ := .Normalized.Inputs}}{{if $i}}, {{end}}%v{{end}})"{{range .Normalized.Inputs}}, e.{{capitalise .Name}}{{end}})
+		}
+
+		// {{$contract.Type}}{{.Normalized.Name}}ErrorID returns the hash of the canonical
+		// representation of the error's signature.
+		//
+		// Solidity: {{.Original.String}}
+		func {{$contract.Type}}{{.Normalized.Name}}ErrorID() common.Hash {
+			return common.HexToHash("{{.Original.ID.Hex}}")
+		}
+	{{end}}
+
+	{{if .Errors}}
+		// Unpack{{.Type}}Error decodes the revert data of a call to the contract, as
+		// returned by bind.RevertData, into the matching custom error.
+		func Unpack{{.Type}}Error(raw []byte) (any, error) {
+			if len(raw) < 4 {
+				return nil, errors.New("invalid error data")
+			}
+			{{range .Errors}}
+			if bytes.Equal(raw[:4], {{$contract.Type}}{{.Normalized.Name}}ErrorID().Bytes()[:4]) {
+				out := new({{$contract.Type}}{{.Normalized.Name}})
+				{{if .Normalized.Inputs}}
+				parsed, err := {{$contract.Type}}MetaData.GetAbi()
+				if err != nil {
+					return nil, err
+				}
+				values, err := parsed.Errors["{{.Original.Name}}"].Inputs.Unpack(raw[4:])
+				if err != nil {
+					return nil, err
+				}
+				{{range $i, $t := .Normalized.Inputs}}
+				out.{{capitalise .Name}} = *abi.ConvertType(values[{{$i}}], new({{bindtype .Type $structs}})).(*{{bindtype .Type $structs}}){{end}}
+				{{end}}
+				return out, nil
+			}
+			{{end}}
+			return nil, errors.New("unknown error")
+		}
+	{{end}}
+{{end}}
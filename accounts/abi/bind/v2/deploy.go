@@ -0,0 +1,115 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package bind
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// DeploymentParams are the contracts to deploy with LinkAndDeploy.
+type DeploymentParams struct {
+	Contracts []*MetaData
+
+	// Inputs are the packed constructor inputs of the contracts, by ID.
+	Inputs map[string][]byte
+
+	// Overrides are the addresses of already deployed libraries, by ID. They
+	// are linked against instead of being deployed again.
+	Overrides map[string]common.Address
+}
+
+// DeploymentResult contains the transactions and addresses of the contracts and
+// libraries deployed by LinkAndDeploy, by ID.
+type DeploymentResult struct {
+	Txs       map[string]*types.Transaction
+	Addresses map[string]common.Address
+}
+
+// DeployFn deploys a contract given its constructor input and linked bytecode.
+type DeployFn func(input, bytecode []byte) (common.Address, *types.Transaction, error)
+
+// DefaultDeployer returns a DeployFn sending the deployment transactions with the
+// given options through the backend.
+func DefaultDeployer(opts *TransactOpts, backend ContractBackend) DeployFn {
+	return func(input, bytecode []byte) (common.Address, *types.Transaction, error) {
+		return DeployContract(opts, bytecode, backend, input)
+	}
+}
+
+// LinkAndDeploy deploys the contracts, after the libraries they link against.
+// The library placeholders in the bytecode are replaced by the addresses of the
+// libraries, which are deployed once even if several contracts depend on them.
+// On failure, the contracts deployed so far are returned along with the error.
+func LinkAndDeploy(params *DeploymentParams, deploy DeployFn) (*DeploymentResult, error) {
+	d := &linker{
+		params:    params,
+		deploy:    deploy,
+		addresses: make(map[string]common.Address),
+		result: &DeploymentResult{
+			Txs:       make(map[string]*types.Transaction),
+			Addresses: make(map[string]common.Address),
+		},
+	}
+	for id, addr := range params.Overrides {
+		d.addresses[id] = addr
+	}
+	for _, contract := range params.Contracts {
+		if _, err := d.link(contract); err != nil {
+			return d.result, err
+		}
+	}
+	return d.result, nil
+}
+
+// linker deploys a tree of contracts depending on libraries.
+type linker struct {
+	params    *DeploymentParams
+	deploy    DeployFn
+	addresses map[string]common.Address // deployed and overridden contracts
+	result    *DeploymentResult
+}
+
+// link deploys the dependencies of the contract, then the contract itself with
+// their addresses linked into its bytecode.
+func (d *linker) link(contract *MetaData) (common.Address, error) {
+	if addr, ok := d.addresses[contract.ID]; ok {
+		return addr, nil
+	}
+	bin := strings.TrimPrefix(contract.Bin, "0x")
+	for _, dep := range contract.Deps {
+		addr, err := d.link(dep)
+		if err != nil {
+			return common.Address{}, err
+		}
+		bin = strings.ReplaceAll(bin, "__$"+dep.ID+"$__", addr.Hex()[2:])
+	}
+	if strings.Contains(bin, "__$") {
+		return common.Address{}, fmt.Errorf("contract %s has unlinked library references", contract.ID)
+	}
+	addr, tx, err := d.deploy(d.params.Inputs[contract.ID], common.FromHex(bin))
+	if err != nil {
+		return common.Address{}, fmt.Errorf("failed to deploy contract %s: %w", contract.ID, err)
+	}
+	d.addresses[contract.ID] = addr
+	d.result.Addresses[contract.ID] = addr
+	d.result.Txs[contract.ID] = tx
+	return addr, nil
+}
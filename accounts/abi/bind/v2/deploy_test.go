@@ -0,0 +1,92 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package bind
+
+import (
+	"bytes"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+func TestLinkAndDeploy(t *testing.T) {
+	var (
+		// c links against l1 and l2, l2 links against l1 and l3.
+		l1 = &MetaData{ID: "l1", Bin: "0x01"}
+		l3 = &MetaData{ID: "l3", Bin: "0x03"}
+		l2 = &MetaData{ID: "l2", Bin: "0x02__$l1$__00__$l3$__", Deps: []*MetaData{l1, l3}}
+		c  = &MetaData{ID: "c", Bin: "0xff__$l2$____$l1$__", Deps: []*MetaData{l2, l1}}
+
+		override = common.Address{0x33}
+		deployed []string
+		codes    = make(map[string][]byte)
+	)
+	deploy := func(input, bytecode []byte) (common.Address, *types.Transaction, error) {
+		id := string(rune('a' + len(deployed)))
+		deployed = append(deployed, id)
+		codes[id] = append(bytecode, input...)
+		return common.Address{byte(len(deployed))}, new(types.Transaction), nil
+	}
+	res, err := LinkAndDeploy(&DeploymentParams{
+		Contracts: []*MetaData{c, l2},
+		Inputs:    map[string][]byte{"c": {0xaa}},
+		Overrides: map[string]common.Address{"l3": override},
+	}, deploy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// l1, l2 and c are deployed once, in this order.
+	if len(deployed) != 3 {
+		t.Fatalf("deployed %d contracts, want 3", len(deployed))
+	}
+	want := map[string]common.Address{"l1": {1}, "l2": {2}, "c": {3}}
+	for id, addr := range want {
+		if res.Addresses[id] != addr || res.Txs[id] == nil {
+			t.Errorf("contract %s: have address %x, want %x", id, res.Addresses[id], addr)
+		}
+	}
+	if _, ok := res.Addresses["l3"]; ok {
+		t.Error("overridden library reported as deployed")
+	}
+	wantL2 := slices.Concat([]byte{0x02}, common.Address{1}.Bytes(), []byte{0}, override.Bytes())
+	if !bytes.Equal(codes["b"], wantL2) {
+		t.Errorf("wrong l2 code: have %x, want %x", codes["b"], wantL2)
+	}
+	wantC := slices.Concat([]byte{0xff}, common.Address{2}.Bytes(), common.Address{1}.Bytes(), []byte{0xaa})
+	if !bytes.Equal(codes["c"], wantC) {
+		t.Errorf("wrong c code: have %x, want %x", codes["c"], wantC)
+	}
+}
+
+func TestLinkAndDeployErrors(t *testing.T) {
+	fail := errors.New("deployment failed")
+	deploy := func(input, bytecode []byte) (common.Address, *types.Transaction, error) {
+		return common.Address{}, nil, fail
+	}
+	lib := &MetaData{ID: "lib", Bin: "0x01"}
+	if _, err := LinkAndDeploy(&DeploymentParams{Contracts: []*MetaData{{ID: "c", Deps: []*MetaData{lib}}}}, deploy); !errors.Is(err, fail) {
+		t.Fatalf("wrong error: %v", err)
+	}
+	// Placeholders of libraries missing from the dependencies are rejected.
+	unlinked := &MetaData{ID: "c", Bin: "0x__$lib$__"}
+	if _, err := LinkAndDeploy(&DeploymentParams{Contracts: []*MetaData{unlinked}}, deploy); err == nil || errors.Is(err, fail) {
+		t.Fatalf("wrong error: %v", err)
+	}
+}
@@ -0,0 +1,261 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package bind is the runtime of the contract bindings generated by abigen --v2.
+//
+// Contrary to the original bindings, the generated code only packs and unpacks
+// calldata, return values, events and errors. Interacting with a contract is
+// done through the generic helpers of this package, which accept the packed
+// calldata and an unpacking function:
+//
+//	c := mypkg.NewToken()
+//	instance := c.Instance(backend, address)
+//	balance, err := bind.Call(instance, nil, c.PackBalanceOf(holder), c.UnpackBalanceOf)
+package bind
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	bind1 "github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/event"
+)
+
+var (
+	errNoEventSignature       = errors.New("no event signature")
+	errEventSignatureMismatch = errors.New("event signature mismatch")
+)
+
+// The options, backends and contract handle are shared with the original
+// bindings, so that both can be used side by side.
+type (
+	CallOpts        = bind1.CallOpts
+	TransactOpts    = bind1.TransactOpts
+	FilterOpts      = bind1.FilterOpts
+	WatchOpts       = bind1.WatchOpts
+	MetaData        = bind1.MetaData
+	BoundContract   = bind1.BoundContract
+	ContractBackend = bind1.ContractBackend
+	DeployBackend   = bind1.DeployBackend
+	Batch           = bind1.Batch
+	BatchExecutor   = bind1.BatchExecutor
+)
+
+// ContractEvent is implemented by the event types of the generated bindings.
+type ContractEvent interface {
+	ContractEventName() string
+}
+
+// NewBoundContract creates a handle to the contract deployed at the given
+// address, interacting with it through the backend.
+func NewBoundContract(address common.Address, abi abi.ABI, backend ContractBackend) *BoundContract {
+	return bind1.NewBoundContract(address, abi, backend, backend, backend)
+}
+
+// Call executes a call with the packed calldata and unpacks the result with the
+// given function.
+func Call[T any](c *BoundContract, opts *CallOpts, calldata []byte, unpack func([]byte) (T, error)) (T, error) {
+	var zero T
+	output, err := c.CallRaw(opts, calldata)
+	if err != nil {
+		return zero, err
+	}
+	res, err := unpack(output)
+	if err != nil {
+		return zero, err
+	}
+	return res, nil
+}
+
+// AddCall adds a call with the packed calldata to the batch, the result of which
+// is unpacked with the given function once the batch is executed.
+func AddCall[T any](b *Batch, c *BoundContract, calldata []byte, unpack func([]byte) (T, error)) *bind1.BatchResult[T] {
+	return bind1.AddCall(b, c, calldata, unpack)
+}
+
+// Transact sends a transaction with the packed calldata to the contract.
+func Transact(c *BoundContract, opts *TransactOpts, calldata []byte) (*types.Transaction, error) {
+	return c.RawTransact(opts, calldata)
+}
+
+// DeployContract sends a transaction deploying the bytecode, followed by the
+// packed constructor input, and returns the address the contract will have.
+func DeployContract(opts *TransactOpts, bytecode []byte, backend ContractBackend, constructorInput []byte) (common.Address, *types.Transaction, error) {
+	c := bind1.NewBoundContract(common.Address{}, abi.ABI{}, backend, backend, backend)
+	tx, err := c.RawCreationTransact(opts, append(common.CopyBytes(bytecode), constructorInput...))
+	if err != nil {
+		return common.Address{}, nil, err
+	}
+	return crypto.CreateAddress(opts.From, tx.Nonce()), tx, nil
+}
+
+// FilterEvents returns an iterator over the past events of type T emitted by
+// the contract, matching the given indexed argument values.
+func FilterEvents[T ContractEvent](c *BoundContract, opts *FilterOpts, unpack func(*types.Log) (*T, error), topics ...[]any) (*EventIterator[T], error) {
+	var ev T
+	logs, sub, err := c.FilterLogs(opts, ev.ContractEventName(), topics...)
+	if err != nil {
+		return nil, err
+	}
+	return &EventIterator[T]{unpack: unpack, logs: logs, sub: sub}, nil
+}
+
+// WatchEvents subscribes to the future events of type T emitted by the contract,
+// matching the given indexed argument values, and sends them to the sink. Logs
+// which cannot be unpacked end the subscription with an error.
+func WatchEvents[T ContractEvent](c *BoundContract, opts *WatchOpts, unpack func(*types.Log) (*T, error), sink chan<- *T, topics ...[]any) (event.Subscription, error) {
+	var ev T
+	logs, sub, err := c.WatchLogs(opts, ev.ContractEventName(), topics...)
+	if err != nil {
+		return nil, err
+	}
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case log := <-logs:
+				ev, err := unpack(&log)
+				if err != nil {
+					return err
+				}
+				select {
+				case sink <- ev:
+				case err := <-sub.Err():
+					return err
+				case <-quit:
+					return nil
+				}
+			case err := <-sub.Err():
+				return err
+			case <-quit:
+				return nil
+			}
+		}
+	}), nil
+}
+
+// EventIterator iterates over the events returned by FilterEvents.
+type EventIterator[T any] struct {
+	current *T
+	unpack  func(*types.Log) (*T, error)
+	logs    chan types.Log
+	sub     event.Subscription
+	done    bool
+	fail    error
+}
+
+// Next advances the iterator to the next event, returning whether there is one.
+// In case of a retrieval or unpacking error, false is returned and Error() can
+// be queried for the exact failure.
+func (it *EventIterator[T]) Next() bool {
+	if it.fail != nil {
+		return false
+	}
+	// If the iterator completed, deliver directly whatever's available
+	if it.done {
+		select {
+		case log := <-it.logs:
+			return it.next(&log)
+		default:
+			return false
+		}
+	}
+	// Iterator still in progress, wait for either a data or an error event
+	select {
+	case log := <-it.logs:
+		return it.next(&log)
+	case err := <-it.sub.Err():
+		it.done = true
+		it.fail = err
+		return it.Next()
+	}
+}
+
+func (it *EventIterator[T]) next(log *types.Log) bool {
+	ev, err := it.unpack(log)
+	if err != nil {
+		it.fail = err
+		return false
+	}
+	it.current = ev
+	return true
+}
+
+// Value returns the current event.
+func (it *EventIterator[T]) Value() *T {
+	return it.current
+}
+
+// Error returns any retrieval or unpacking error that occurred during iteration.
+func (it *EventIterator[T]) Error() error {
+	return it.fail
+}
+
+// Close terminates the iteration and frees up any resources.
+func (it *EventIterator[T]) Close() error {
+	it.sub.Unsubscribe()
+	return nil
+}
+
+// WaitMined waits for the transaction to be mined and returns its receipt.
+func WaitMined(ctx context.Context, b DeployBackend, tx *types.Transaction) (*types.Receipt, error) {
+	return bind1.WaitMined(ctx, b, tx)
+}
+
+// WaitDeployed waits for the contract creation transaction to be mined and
+// returns the address of the deployed contract.
+func WaitDeployed(ctx context.Context, b DeployBackend, tx *types.Transaction) (common.Address, error) {
+	return bind1.WaitDeployed(ctx, b, tx)
+}
+
+// RevertData extracts the revert data from the error returned by a reverted call
+// or gas estimation, to be decoded with the UnpackError method of the bindings.
+func RevertData(err error) ([]byte, bool) {
+	return bind1.RevertData(err)
+}
+
+// UnpackLog unpacks a log of the given event into the output structure, which
+// must have a field for every argument of the event. It is used by the event
+// decoders of the generated bindings.
+func UnpackLog(a *abi.ABI, out any, event string, log *types.Log) error {
+	ev, ok := a.Events[event]
+	if !ok {
+		return fmt.Errorf("event '%s' not found", event)
+	}
+	if len(log.Topics) == 0 {
+		return errNoEventSignature
+	}
+	if log.Topics[0] != ev.ID {
+		return errEventSignatureMismatch
+	}
+	if len(log.Data) > 0 {
+		if err := a.UnpackIntoInterface(out, event, log.Data); err != nil {
+			return err
+		}
+	}
+	var indexed abi.Arguments
+	for _, arg := range ev.Inputs {
+		if arg.Indexed {
+			indexed = append(indexed, arg)
+		}
+	}
+	return abi.ParseTopics(out, indexed, log.Topics[1:])
+}
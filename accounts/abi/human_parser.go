@@ -0,0 +1,486 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package abi
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// fragmentMarshaling is the JSON ABI representation of a human-readable fragment.
+type fragmentMarshaling struct {
+	Type            string               `json:"type"`
+	Name            string               `json:"name,omitempty"`
+	Inputs          []argumentMarshaling `json:"inputs"`
+	Outputs         []argumentMarshaling `json:"outputs,omitempty"`
+	StateMutability string               `json:"stateMutability,omitempty"`
+	Anonymous       bool                 `json:"anonymous,omitempty"`
+}
+
+// argumentMarshaling is the JSON ABI representation of an argument of a
+// human-readable fragment.
+type argumentMarshaling struct {
+	Name         string               `json:"name"`
+	Type         string               `json:"type"`
+	InternalType string               `json:"internalType,omitempty"`
+	Components   []argumentMarshaling `json:"components,omitempty"`
+	Indexed      bool                 `json:"indexed,omitempty"`
+}
+
+// ParseHumanReadable parses a human-readable ABI, made of Solidity-like
+// declarations of the contract interface, one per fragment:
+//
+//	function transfer(address to, uint256 amount) returns (bool)
+//	function balanceOf(address) view returns (uint256)
+//	event Transfer(address indexed from, address indexed to, uint256 value)
+//	error InsufficientBalance(uint256 available, uint256 required)
+//	constructor(string name, string symbol)
+//	struct Point { uint256 x; uint256 y; }
+//	function area(Point[2] corners) pure returns (uint256)
+//
+// Functions may also be given as bare signatures, without the function keyword.
+// Struct declarations can be referenced by name in all other fragments.
+func ParseHumanReadable(fragments []string) (ABI, error) {
+	blob, err := HumanReadableJSON(fragments)
+	if err != nil {
+		return ABI{}, err
+	}
+	return JSON(bytes.NewReader(blob))
+}
+
+// HumanReadableJSON converts a human-readable ABI into its JSON representation.
+// See ParseHumanReadable for the accepted format.
+func HumanReadableJSON(fragments []string) ([]byte, error) {
+	p := &humanParser{
+		structs:  make(map[string][]string),
+		resolved: make(map[string][]argumentMarshaling),
+	}
+	// Collect the struct declarations first, they may be used before they
+	// are declared.
+	var decls [][]string
+	for _, fragment := range fragments {
+		tokens, err := tokenize(fragment)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse fragment '%s': %v", fragment, err)
+		}
+		if len(tokens) == 0 {
+			continue
+		}
+		if tokens[0] != "struct" {
+			decls = append(decls, tokens)
+			continue
+		}
+		if len(tokens) < 2 || !isIdentifier(tokens[1]) {
+			return nil, fmt.Errorf("failed to parse fragment '%s': missing struct name", fragment)
+		}
+		if _, ok := p.structs[tokens[1]]; ok {
+			return nil, fmt.Errorf("duplicate struct %s", tokens[1])
+		}
+		p.structs[tokens[1]] = tokens[2:]
+	}
+	for name := range p.structs {
+		if _, err := p.resolveStruct(name); err != nil {
+			return nil, err
+		}
+	}
+	out := make([]fragmentMarshaling, 0, len(decls))
+	for _, tokens := range decls {
+		fragment, err := p.parseFragment(tokens)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse fragment '%s': %v", strings.Join(tokens, " "), err)
+		}
+		out = append(out, fragment)
+	}
+	return json.Marshal(out)
+}
+
+// ConvertHumanReadable returns the JSON ABI of the input, which is either a JSON
+// ABI, returned as is, a JSON array of human-readable fragments, or the
+// human-readable fragments one per line. Empty lines and lines starting with //
+// are ignored in the latter.
+func ConvertHumanReadable(input []byte) ([]byte, error) {
+	trimmed := bytes.TrimSpace(input)
+	if len(trimmed) > 0 && trimmed[0] == '[' {
+		var fragments []string
+		if err := json.Unmarshal(trimmed, &fragments); err != nil {
+			return input, nil
+		}
+		return HumanReadableJSON(fragments)
+	}
+	var fragments []string
+	for _, line := range strings.Split(string(trimmed), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "//") {
+			continue
+		}
+		fragments = append(fragments, line)
+	}
+	return HumanReadableJSON(fragments)
+}
+
+// tokenize splits a fragment into identifiers, numbers and punctuation.
+func tokenize(fragment string) ([]string, error) {
+	var tokens []string
+	for i := 0; i < len(fragment); {
+		c := fragment[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case strings.IndexByte("()[],{};", c) >= 0:
+			tokens = append(tokens, string(c))
+			i++
+		case isAlpha(c) || isDigit(c) || isIdentifierSymbol(c):
+			start := i
+			for i < len(fragment) && (isAlpha(fragment[i]) || isDigit(fragment[i]) || isIdentifierSymbol(fragment[i])) {
+				i++
+			}
+			tokens = append(tokens, fragment[start:i])
+		default:
+			return nil, fmt.Errorf("unexpected character '%c'", c)
+		}
+	}
+	return tokens, nil
+}
+
+// isIdentifier returns whether the token is a valid identifier.
+func isIdentifier(token string) bool {
+	return token != "" && (isAlpha(token[0]) || isIdentifierSymbol(token[0]))
+}
+
+// humanParser parses the tokens of human-readable fragments.
+type humanParser struct {
+	tokens []string
+
+	structs   map[string][]string             // Tokens of the struct declarations
+	resolved  map[string][]argumentMarshaling // Fields of the parsed structs
+	resolving []string                        // Structs being parsed, to detect cycles
+}
+
+func (p *humanParser) peek() string {
+	if len(p.tokens) == 0 {
+		return ""
+	}
+	return p.tokens[0]
+}
+
+func (p *humanParser) next() string {
+	token := p.peek()
+	if len(p.tokens) > 0 {
+		p.tokens = p.tokens[1:]
+	}
+	return token
+}
+
+func (p *humanParser) expect(token string) error {
+	if next := p.next(); next != token {
+		if next == "" {
+			return fmt.Errorf("expected '%s', got end of fragment", token)
+		}
+		return fmt.Errorf("expected '%s', got '%s'", token, next)
+	}
+	return nil
+}
+
+func (p *humanParser) identifier() (string, error) {
+	token := p.next()
+	if !isIdentifier(token) {
+		return "", fmt.Errorf("expected identifier, got '%s'", token)
+	}
+	return token, nil
+}
+
+// resolveStruct parses the fields of the named struct.
+func (p *humanParser) resolveStruct(name string) ([]argumentMarshaling, error) {
+	if fields, ok := p.resolved[name]; ok {
+		return fields, nil
+	}
+	for _, other := range p.resolving {
+		if other == name {
+			return nil, fmt.Errorf("recursive struct %s", name)
+		}
+	}
+	p.resolving = append(p.resolving, name)
+	defer func() { p.resolving = p.resolving[:len(p.resolving)-1] }()
+
+	// Parse the struct body with a fresh token stream, restoring the one of
+	// the declaration referencing it afterwards.
+	saved := p.tokens
+	p.tokens = p.structs[name]
+	defer func() { p.tokens = saved }()
+
+	if err := p.expect("{"); err != nil {
+		return nil, fmt.Errorf("invalid struct %s: %v", name, err)
+	}
+	var fields []argumentMarshaling
+	for p.peek() != "}" {
+		field, err := p.parseParam(false)
+		if err != nil {
+			return nil, fmt.Errorf("invalid struct %s: %v", name, err)
+		}
+		if err := p.expect(";"); err != nil {
+			return nil, fmt.Errorf("invalid struct %s: %v", name, err)
+		}
+		fields = append(fields, field)
+	}
+	p.next()
+	if p.peek() == ";" {
+		p.next()
+	}
+	if len(p.tokens) != 0 {
+		return nil, fmt.Errorf("invalid struct %s: unexpected '%s'", name, p.peek())
+	}
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("invalid struct %s: no fields", name)
+	}
+	p.resolved[name] = fields
+	return fields, nil
+}
+
+// parseFragment parses a function, constructor, fallback, receive, event or
+// error declaration.
+func (p *humanParser) parseFragment(tokens []string) (fragmentMarshaling, error) {
+	p.tokens = tokens
+
+	var (
+		fragment fragmentMarshaling
+		err      error
+	)
+	switch kind := p.peek(); kind {
+	case "event":
+		p.next()
+		fragment.Type = kind
+		if fragment.Name, err = p.identifier(); err != nil {
+			return fragment, err
+		}
+		if fragment.Inputs, err = p.parseParams(true); err != nil {
+			return fragment, err
+		}
+		if p.peek() == "anonymous" {
+			p.next()
+			fragment.Anonymous = true
+		}
+	case "error":
+		p.next()
+		fragment.Type = kind
+		if fragment.Name, err = p.identifier(); err != nil {
+			return fragment, err
+		}
+		if fragment.Inputs, err = p.parseParams(false); err != nil {
+			return fragment, err
+		}
+	case "constructor":
+		p.next()
+		fragment.Type = kind
+		if fragment.Inputs, err = p.parseParams(false); err != nil {
+			return fragment, err
+		}
+		if fragment.StateMutability, err = p.parseModifiers(); err != nil {
+			return fragment, err
+		}
+	case "fallback", "receive":
+		p.next()
+		fragment.Type = kind
+		if err := p.expect("("); err != nil {
+			return fragment, err
+		}
+		if err := p.expect(")"); err != nil {
+			return fragment, err
+		}
+		if fragment.StateMutability, err = p.parseModifiers(); err != nil {
+			return fragment, err
+		}
+		fragment.Inputs = []argumentMarshaling{}
+	default:
+		if kind == "function" {
+			p.next()
+		}
+		fragment.Type = "function"
+		if fragment.Name, err = p.identifier(); err != nil {
+			return fragment, err
+		}
+		if fragment.Inputs, err = p.parseParams(false); err != nil {
+			return fragment, err
+		}
+		if fragment.StateMutability, err = p.parseModifiers(); err != nil {
+			return fragment, err
+		}
+		if p.peek() == "returns" {
+			p.next()
+			if fragment.Outputs, err = p.parseParams(false); err != nil {
+				return fragment, err
+			}
+			if fragment.Outputs == nil {
+				fragment.Outputs = []argumentMarshaling{}
+			}
+		}
+	}
+	if p.peek() == ";" {
+		p.next()
+	}
+	if len(p.tokens) != 0 {
+		return fragment, fmt.Errorf("unexpected '%s'", p.peek())
+	}
+	return fragment, nil
+}
+
+// parseModifiers parses the visibility and state mutability of a function,
+// returning the latter.
+func (p *humanParser) parseModifiers() (string, error) {
+	mutability := "nonpayable"
+	for {
+		switch token := p.peek(); token {
+		case "external", "public", "virtual", "override":
+		case "view", "pure", "payable", "nonpayable":
+			mutability = token
+		case "constant":
+			mutability = "view"
+		case "internal", "private":
+			return "", fmt.Errorf("%s functions are not part of the ABI", token)
+		default:
+			return mutability, nil
+		}
+		p.next()
+	}
+}
+
+// parseParams parses a parenthesized list of parameters.
+func (p *humanParser) parseParams(indexable bool) ([]argumentMarshaling, error) {
+	if err := p.expect("("); err != nil {
+		return nil, err
+	}
+	params := []argumentMarshaling{}
+	if p.peek() == ")" {
+		p.next()
+		return params, nil
+	}
+	for {
+		param, err := p.parseParam(indexable)
+		if err != nil {
+			return nil, err
+		}
+		params = append(params, param)
+
+		switch token := p.next(); token {
+		case ",":
+		case ")":
+			return params, nil
+		default:
+			return nil, fmt.Errorf("expected ',' or ')', got '%s'", token)
+		}
+	}
+}
+
+// parseParam parses a parameter type, followed by its optional modifiers and
+// name.
+func (p *humanParser) parseParam(indexable bool) (argumentMarshaling, error) {
+	param, err := p.parseType()
+	if err != nil {
+		return param, err
+	}
+	for {
+		switch token := p.peek(); token {
+		case "indexed":
+			if !indexable {
+				return param, errors.New("unexpected indexed parameter")
+			}
+			param.Indexed = true
+		case "memory", "calldata", "storage":
+		default:
+			if isIdentifier(token) {
+				p.next()
+				param.Name = token
+			}
+			return param, nil
+		}
+		p.next()
+	}
+}
+
+// parseType parses an elementary, tuple or struct type, with its array
+// dimensions.
+func (p *humanParser) parseType() (argumentMarshaling, error) {
+	var arg argumentMarshaling
+	switch token := p.peek(); {
+	case token == "(" || token == "tuple":
+		if token == "tuple" {
+			p.next()
+		}
+		components, err := p.parseParams(false)
+		if err != nil {
+			return arg, err
+		}
+		if len(components) == 0 {
+			return arg, errors.New("empty tuple")
+		}
+		// Tuple components must be named to be unpacked into structs, name
+		// them like the selector parser does.
+		for i := range components {
+			if components[i].Name == "" {
+				components[i].Name = fmt.Sprintf("name%d", i)
+			}
+		}
+		arg.Type, arg.Components = "tuple", components
+	case isIdentifier(token):
+		p.next()
+		if _, ok := p.structs[token]; ok {
+			components, err := p.resolveStruct(token)
+			if err != nil {
+				return arg, err
+			}
+			arg.Type, arg.InternalType, arg.Components = "tuple", "struct "+token, components
+			break
+		}
+		switch token {
+		case "uint":
+			token = "uint256"
+		case "int":
+			token = "int256"
+		case "byte":
+			token = "bytes1"
+		}
+		if _, err := NewType(token, "", nil); err != nil {
+			return arg, err
+		}
+		arg.Type = token
+	default:
+		return arg, fmt.Errorf("expected type, got '%s'", token)
+	}
+	for p.peek() == "[" {
+		p.next()
+		suffix := "[]"
+		if token := p.peek(); token != "" && isDigit(token[0]) {
+			p.next()
+			for i := 0; i < len(token); i++ {
+				if !isDigit(token[i]) {
+					return arg, fmt.Errorf("invalid array size '%s'", token)
+				}
+			}
+			suffix = "[" + token + "]"
+		}
+		if err := p.expect("]"); err != nil {
+			return arg, err
+		}
+		arg.Type += suffix
+		if arg.InternalType != "" {
+			arg.InternalType += suffix
+		}
+	}
+	return arg, nil
+}
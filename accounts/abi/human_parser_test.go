@@ -0,0 +1,172 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package abi
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseHumanReadable(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		fragments []string
+		want      string
+	}{
+		{
+			[]string{"function transfer(address to, uint256 amount) returns (bool)"},
+			`[{"type":"function","name":"transfer","stateMutability":"nonpayable","inputs":[{"name":"to","type":"address"},{"name":"amount","type":"uint256"}],"outputs":[{"name":"","type":"bool"}]}]`,
+		},
+		{
+			[]string{"function balanceOf(address) external view returns (uint)"},
+			`[{"type":"function","name":"balanceOf","stateMutability":"view","inputs":[{"name":"","type":"address"}],"outputs":[{"name":"","type":"uint256"}]}]`,
+		},
+		{
+			[]string{"transfer(address,uint256)"},
+			`[{"type":"function","name":"transfer","stateMutability":"nonpayable","inputs":[{"name":"","type":"address"},{"name":"","type":"uint256"}]}]`,
+		},
+		{
+			[]string{"event Transfer(address indexed from, address indexed to, uint256 value)", "event Anon(bytes32 indexed) anonymous"},
+			`[
+				{"type":"event","name":"Transfer","inputs":[{"name":"from","type":"address","indexed":true},{"name":"to","type":"address","indexed":true},{"name":"value","type":"uint256"}]},
+				{"type":"event","name":"Anon","anonymous":true,"inputs":[{"name":"","type":"bytes32","indexed":true}]}
+			]`,
+		},
+		{
+			[]string{"error InsufficientBalance(uint256 available, uint256 required);"},
+			`[{"type":"error","name":"InsufficientBalance","inputs":[{"name":"available","type":"uint256"},{"name":"required","type":"uint256"}]}]`,
+		},
+		{
+			[]string{"constructor(string memory name) payable", "receive() external payable", "fallback() external"},
+			`[
+				{"type":"constructor","stateMutability":"payable","inputs":[{"name":"name","type":"string"}]},
+				{"type":"receive","stateMutability":"payable"},
+				{"type":"fallback","stateMutability":"nonpayable"}
+			]`,
+		},
+		{
+			[]string{"function swap((address token, uint256 amount)[] calldata legs, tuple(bytes32,uint8[2][]) extra) payable"},
+			`[{"type":"function","name":"swap","stateMutability":"payable","inputs":[
+				{"name":"legs","type":"tuple[]","components":[{"name":"token","type":"address"},{"name":"amount","type":"uint256"}]},
+				{"name":"extra","type":"tuple","components":[{"name":"name0","type":"bytes32"},{"name":"name1","type":"uint8[2][]"}]}
+			]}]`,
+		},
+		{
+			[]string{
+				"function area(Rect[2] rects) pure returns (uint256)",
+				"struct Rect { Point min; Point max; }",
+				"struct Point { uint256 x; uint256 y; }",
+			},
+			`[{"type":"function","name":"area","stateMutability":"pure","inputs":[
+				{"name":"rects","type":"tuple[2]","internalType":"struct Rect[2]","components":[
+					{"name":"min","type":"tuple","internalType":"struct Point","components":[{"name":"x","type":"uint256"},{"name":"y","type":"uint256"}]},
+					{"name":"max","type":"tuple","internalType":"struct Point","components":[{"name":"x","type":"uint256"},{"name":"y","type":"uint256"}]}
+				]}
+			],"outputs":[{"name":"","type":"uint256"}]}]`,
+		},
+	}
+	for i, test := range tests {
+		have, err := ParseHumanReadable(test.fragments)
+		if err != nil {
+			t.Errorf("test %d: failed to parse: %v", i, err)
+			continue
+		}
+		want, err := JSON(strings.NewReader(test.want))
+		if err != nil {
+			t.Fatalf("test %d: invalid expected ABI: %v", i, err)
+		}
+		if !reflect.DeepEqual(have, want) {
+			t.Errorf("test %d: ABI mismatch:\nhave %+v\nwant %+v", i, have, want)
+		}
+	}
+}
+
+func TestParseHumanReadableSelectors(t *testing.T) {
+	t.Parallel()
+	// Bare selectors must produce the same method as the selector parser.
+	for _, selector := range []string{
+		"noargs()",
+		"withArray(uint256[],address[2],uint8[4][][5])",
+		"multiNest(address,(uint256[],uint256),((address,bytes32),uint256))",
+		"multiArrayNest((uint256,uint256)[],(uint256,uint256)[])",
+	} {
+		parsed, err := ParseHumanReadable([]string{selector})
+		if err != nil {
+			t.Fatalf("failed to parse %s: %v", selector, err)
+		}
+		method, ok := parsed.Methods[strings.Split(selector, "(")[0]]
+		if !ok {
+			t.Fatalf("method of %s not found", selector)
+		}
+		if method.Sig != selector {
+			t.Errorf("signature mismatch: have %s, want %s", method.Sig, selector)
+		}
+	}
+}
+
+func TestParseHumanReadableErrors(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		fragments []string
+		err       string
+	}{
+		{[]string{"function foo(uint256"}, "expected ',' or ')', got ''"},
+		{[]string{"function foo(uint256[x])"}, "expected ']', got 'x'"},
+		{[]string{"function foo(Missing)"}, "unsupported arg type: Missing"},
+		{[]string{"function foo(uint256 indexed x)"}, "unexpected indexed parameter"},
+		{[]string{"function foo() internal"}, "internal functions are not part of the ABI"},
+		{[]string{"function foo() returns (bool) extra"}, "unexpected 'extra'"},
+		{[]string{"event Foo(uint256 #)"}, "unexpected character '#'"},
+		{[]string{"struct A { B b; }", "struct B { A a; }", "function foo(A)"}, "recursive struct"},
+		{[]string{"struct A { uint256 a; }", "struct A { uint256 b; }"}, "duplicate struct A"},
+		{[]string{"receive() external"}, "the statemutability of receive can only be payable"},
+	}
+	for i, test := range tests {
+		_, err := ParseHumanReadable(test.fragments)
+		if err == nil {
+			t.Errorf("test %d: expected error containing %q", i, test.err)
+			continue
+		}
+		if !strings.Contains(err.Error(), test.err) {
+			t.Errorf("test %d: error mismatch: have %q, want %q", i, err, test.err)
+		}
+	}
+}
+
+func TestConvertHumanReadable(t *testing.T) {
+	t.Parallel()
+	want := `[{"type":"function","name":"foo","inputs":[{"name":"a","type":"uint256"}],"stateMutability":"view"},{"type":"event","name":"Bar","inputs":[]}]`
+	for i, input := range []string{
+		"// Foo contract\nfunction foo(uint256 a) view\n\nevent Bar()\n",
+		`["function foo(uint256 a) view", "event Bar()"]`,
+		want,
+	} {
+		blob, err := ConvertHumanReadable([]byte(input))
+		if err != nil {
+			t.Fatalf("test %d: failed to convert: %v", i, err)
+		}
+		have, err := JSON(strings.NewReader(string(blob)))
+		if err != nil {
+			t.Fatalf("test %d: invalid JSON ABI: %v", i, err)
+		}
+		expected, _ := JSON(strings.NewReader(want))
+		if !reflect.DeepEqual(have, expected) {
+			t.Errorf("test %d: ABI mismatch:\nhave %+v\nwant %+v", i, have, expected)
+		}
+	}
+}
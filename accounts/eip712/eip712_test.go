@@ -0,0 +1,246 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package eip712_test
+
+import (
+	"bytes"
+	"math/big"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/accounts/eip712"
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+type Person struct {
+	Name   string         `eip712:"name"`
+	Wallet common.Address `eip712:"wallet"`
+}
+
+type Mail struct {
+	From     Person `eip712:"from"`
+	To       Person `eip712:"to"`
+	Contents string `eip712:"contents"`
+}
+
+var mailDomain = eip712.TypedDataDomain{
+	Name:              "Ether Mail",
+	Version:           "1",
+	ChainId:           math.NewHexOrDecimal256(1),
+	VerifyingContract: "0xCcCCccccCCCCcCCCCCCcCcCccCcCCCcCcccccccC",
+}
+
+var mail = Mail{
+	From:     Person{Name: "Cow", Wallet: common.HexToAddress("0xCD2a3d9F938E13CD947Ec05AbC7FE734Df8DD826")},
+	To:       Person{Name: "Bob", Wallet: common.HexToAddress("0xbBbBBBBbbBBBbbbBbbBbbbbBBbBbbbbBbBbbBBbB")},
+	Contents: "Hello, Bob!",
+}
+
+// Tests the example of the specification, built from Go structs.
+func TestNewTypedData(t *testing.T) {
+	t.Parallel()
+	typedData, err := eip712.NewTypedData(mailDomain, mail)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantTypes := eip712.Types{
+		"EIP712Domain": {{Name: "name", Type: "string"}, {Name: "version", Type: "string"}, {Name: "chainId", Type: "uint256"}, {Name: "verifyingContract", Type: "address"}},
+		"Mail":         {{Name: "from", Type: "Person"}, {Name: "to", Type: "Person"}, {Name: "contents", Type: "string"}},
+		"Person":       {{Name: "name", Type: "string"}, {Name: "wallet", Type: "address"}},
+	}
+	if !reflect.DeepEqual(typedData.Types, wantTypes) {
+		t.Errorf("types mismatch: have %v, want %v", typedData.Types, wantTypes)
+	}
+	if typedData.PrimaryType != "Mail" {
+		t.Errorf("primary type mismatch: have %s, want Mail", typedData.PrimaryType)
+	}
+	separator, err := mailDomain.Separator()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := common.HexToHash("0xf2cee375fa42b42143804025fc449deafd50cc031ca257e0b194a650a912090f"); separator != want {
+		t.Errorf("domain separator mismatch: have %x, want %x", separator, want)
+	}
+	hash, err := typedData.Hash()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := common.HexToHash("0xbe609aee343fb3c4b28e1df9e632fca64fcfaede20f02e86244efddf30957bd2"); hash != want {
+		t.Errorf("hash mismatch: have %x, want %x", hash, want)
+	}
+	key := crypto.ToECDSAUnsafe(crypto.Keccak256([]byte("cow")))
+	sig, err := eip712.SignWithKey(key, typedData)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := hexutil.MustDecode("0x4355c47d63924e8a72e509b65029052eb6c299d53a04e167c5775fd466751c9d07299936d304c153f6443dfa05f40ff007d72911b6f72307f996231605b915621c")
+	if !bytes.Equal(sig, want) {
+		t.Errorf("signature mismatch: have %x, want %x", sig, want)
+	}
+	signer, err := eip712.Recover(typedData, sig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if signer != mail.From.Wallet {
+		t.Errorf("recovered signer mismatch: have %v, want %v", signer, mail.From.Wallet)
+	}
+}
+
+type Grid struct {
+	Cells  [][2]uint16 `eip712:"cells"`
+	Owners []*Owner    `eip712:"owners,Holder[]"`
+	Total  *big.Int    `eip712:"total,uint128"`
+	Salt   [4]byte     `eip712:"salt"`
+	Note   string      `eip712:"-"`
+}
+
+type Owner struct {
+	Account common.Address
+	Shares  []uint16
+}
+
+// Tests the encoding of nested and fixed-size arrays, and the type overrides.
+func TestNestedArrays(t *testing.T) {
+	t.Parallel()
+	grid := Grid{
+		Cells:  [][2]uint16{{1, 2}, {3, 4}},
+		Owners: []*Owner{{Account: common.Address{0x01}, Shares: []uint16{10, 20}}},
+		Total:  big.NewInt(30),
+		Salt:   [4]byte{0xde, 0xad, 0xbe, 0xef},
+	}
+	primary, types, err := eip712.TypesOf(&grid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantTypes := eip712.Types{
+		"Grid":   {{Name: "cells", Type: "uint16[2][]"}, {Name: "owners", Type: "Holder[]"}, {Name: "total", Type: "uint128"}, {Name: "salt", Type: "bytes4"}},
+		"Holder": {{Name: "Account", Type: "address"}, {Name: "Shares", Type: "uint16[]"}},
+	}
+	if primary != "Grid" || !reflect.DeepEqual(types, wantTypes) {
+		t.Fatalf("types mismatch: have %s %v, want Grid %v", primary, types, wantTypes)
+	}
+	typedData, err := eip712.NewTypedData(eip712.TypedDataDomain{Name: "Grid"}, grid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	have, err := typedData.HashStruct("Grid", typedData.Message)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Encode the struct by hand, following the specification.
+	word := func(v int64) []byte { return math.U256Bytes(big.NewInt(v)) }
+	concat := func(items ...[]byte) []byte { return bytes.Join(items, nil) }
+
+	holderType := "Holder(address Account,uint16[] Shares)"
+	holder := crypto.Keccak256(
+		crypto.Keccak256([]byte(holderType)),
+		common.LeftPadBytes(common.Address{0x01}.Bytes(), 32),
+		crypto.Keccak256(word(10), word(20)),
+	)
+	want := crypto.Keccak256(
+		crypto.Keccak256([]byte("Grid(uint16[2][] cells,Holder[] owners,uint128 total,bytes4 salt)"+holderType)),
+		crypto.Keccak256(crypto.Keccak256(concat(word(1), word(2))), crypto.Keccak256(concat(word(3), word(4)))),
+		crypto.Keccak256(holder),
+		word(30),
+		common.RightPadBytes([]byte{0xde, 0xad, 0xbe, 0xef}, 32),
+	)
+	if !bytes.Equal(have, want) {
+		t.Errorf("struct hash mismatch: have %x, want %x", []byte(have), want)
+	}
+	// Fixed-size arrays must have the declared length.
+	typedData.Message["cells"] = []interface{}{[]interface{}{1.0, 2.0, 3.0}}
+	if _, err := typedData.Hash(); err == nil || !strings.Contains(err.Error(), "doesn't match type 'uint16[2]'") {
+		t.Errorf("unexpected error for array length mismatch: %v", err)
+	}
+}
+
+func TestTypesOfErrors(t *testing.T) {
+	t.Parallel()
+	type Conflict struct {
+		A Person
+		B Owner `eip712:"b,Person"`
+	}
+	type Unsupported struct {
+		M map[string]string
+	}
+	type Anonymous struct {
+		S struct{ X uint64 }
+	}
+	tests := []struct {
+		value interface{}
+		err   string
+	}{
+		{42, "message must be a struct"},
+		{Conflict{}, "conflicting definitions of Person"},
+		{Unsupported{}, "field M of Unsupported: eip712: unsupported type map[string]string"},
+		{Anonymous{}, "needs a type override"},
+	}
+	for i, test := range tests {
+		_, _, err := eip712.TypesOf(test.value)
+		if err == nil || !strings.Contains(err.Error(), test.err) {
+			t.Errorf("test %d: error mismatch: have %v, want %q", i, err, test.err)
+		}
+	}
+}
+
+// Tests signing typed data with the accounts of a keystore.
+func TestSignWithKeystore(t *testing.T) {
+	t.Parallel()
+	ks := keystore.NewKeyStore(t.TempDir(), keystore.LightScryptN, keystore.LightScryptP)
+	account, err := ks.NewAccount("password")
+	if err != nil {
+		t.Fatal(err)
+	}
+	wallet := ks.Wallets()[0]
+
+	message := mail
+	message.From.Wallet = account.Address
+	typedData, err := eip712.NewTypedData(mailDomain, message)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := eip712.Sign(wallet, account, typedData); err != keystore.ErrLocked {
+		t.Fatalf("signing with locked account: have error %v, want %v", err, keystore.ErrLocked)
+	}
+	sig, err := eip712.SignWithPassphrase(wallet, account, "password", typedData)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ks.Unlock(account, "password"); err != nil {
+		t.Fatal(err)
+	}
+	unlocked, err := eip712.Sign(wallet, accounts.Account{Address: account.Address}, typedData)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(sig, unlocked) {
+		t.Errorf("signature mismatch: %x != %x", sig, unlocked)
+	}
+	signer, err := eip712.Recover(typedData, sig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if signer != account.Address {
+		t.Errorf("recovered signer mismatch: have %v, want %v", signer, account.Address)
+	}
+}
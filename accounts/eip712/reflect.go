@@ -0,0 +1,292 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package eip712
+
+import (
+	"fmt"
+	"math/big"
+	"reflect"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/common/math"
+)
+
+var (
+	addressT = reflect.TypeOf(common.Address{})
+	bigT     = reflect.TypeOf((*big.Int)(nil))
+)
+
+// NewTypedData creates the typed data of a message given as a Go struct, the
+// types of which are derived by TypesOf. The typed data is validated like clef
+// does before signing it.
+func NewTypedData(domain TypedDataDomain, message interface{}) (*TypedData, error) {
+	primaryType, types, err := TypesOf(message)
+	if err != nil {
+		return nil, err
+	}
+	types["EIP712Domain"] = domain.Types()
+
+	value, err := messageValue(reflect.ValueOf(message))
+	if err != nil {
+		return nil, err
+	}
+	typedData := &TypedData{
+		Types:       types,
+		PrimaryType: primaryType,
+		Domain:      domain,
+		Message:     value.(map[string]interface{}),
+	}
+	if err := typedData.Validate(); err != nil {
+		return nil, err
+	}
+	return typedData, nil
+}
+
+// TypesOf derives the EIP-712 types of a Go struct, returning the name of its
+// type and the definitions of all the struct types it references.
+//
+// Struct types are named after their Go type. The fields are named after the Go
+// fields, and typed after their Go type: common.Address is an address,
+// *big.Int and the Go integers are uint256/int256 or their sized variant,
+// []byte is bytes, [N]byte is bytesN, and slices and arrays of other types are
+// dynamic and fixed-size arrays. The "eip712" field tag overrides the name of
+// a field, and optionally its type, or skips it:
+//
+//	type Mail struct {
+//		From     Person   `eip712:"from"`
+//		To       []Person `eip712:"to"`
+//		Amount   *big.Int `eip712:"amount,uint128"`
+//		Contents string   `eip712:"contents"`
+//		Internal int      `eip712:"-"`
+//	}
+//
+// If the type of a struct field is overridden, the struct is defined with the
+// overriding name.
+func TypesOf(v interface{}) (string, Types, error) {
+	t := reflect.TypeOf(v)
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return "", nil, fmt.Errorf("eip712: message must be a struct, got %v", t)
+	}
+	d := &typeDeriver{types: make(Types), defs: make(map[string]reflect.Type)}
+	name, err := d.derive(t, "")
+	if err != nil {
+		return "", nil, err
+	}
+	return name, d.types, nil
+}
+
+// structField is a field of a Go struct included in its EIP-712 type.
+type structField struct {
+	index int
+	name  string
+	typ   string // Overridden type, if any
+}
+
+// structFields returns the fields of the Go struct which are part of its
+// EIP-712 type.
+func structFields(t reflect.Type) []structField {
+	var fields []structField
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		field := structField{index: i, name: f.Name}
+		if tag, ok := f.Tag.Lookup("eip712"); ok {
+			if tag == "-" {
+				continue
+			}
+			name, typ, _ := strings.Cut(tag, ",")
+			if name != "" {
+				field.name = name
+			}
+			field.typ = typ
+		}
+		fields = append(fields, field)
+	}
+	return fields
+}
+
+// typeDeriver collects the struct types referenced by a message.
+type typeDeriver struct {
+	types Types
+	defs  map[string]reflect.Type // Go types of the structs, to detect conflicts
+}
+
+// derive returns the EIP-712 type of the Go type, defining the structs it
+// references. If the type is overridden, it is returned as is, the structs
+// being defined with the overriding name.
+func (d *typeDeriver) derive(t reflect.Type, override string) (string, error) {
+	if override != "" {
+		if elem := structElem(t); elem != nil {
+			if err := d.define(elem, (&Type{Type: override}).typeName()); err != nil {
+				return "", err
+			}
+		}
+		return override, nil
+	}
+	switch {
+	case t == addressT:
+		return "address", nil
+	case t == bigT:
+		return "uint256", nil
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		return "bool", nil
+	case reflect.String:
+		return "string", nil
+	case reflect.Int:
+		return "int256", nil
+	case reflect.Uint:
+		return "uint256", nil
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return fmt.Sprintf("int%d", t.Bits()), nil
+	case reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return fmt.Sprintf("uint%d", t.Bits()), nil
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return "bytes", nil
+		}
+		elem, err := d.derive(t.Elem(), "")
+		if err != nil {
+			return "", err
+		}
+		return elem + "[]", nil
+	case reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			if t.Len() == 0 || t.Len() > 32 {
+				return "", fmt.Errorf("eip712: unsupported byte array length %d", t.Len())
+			}
+			return fmt.Sprintf("bytes%d", t.Len()), nil
+		}
+		elem, err := d.derive(t.Elem(), "")
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("%s[%d]", elem, t.Len()), nil
+	case reflect.Pointer:
+		if t.Elem().Kind() == reflect.Struct {
+			return d.derive(t.Elem(), "")
+		}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return "", fmt.Errorf("eip712: anonymous struct %v needs a type override", t)
+		}
+		return t.Name(), d.define(t, t.Name())
+	}
+	return "", fmt.Errorf("eip712: unsupported type %v", t)
+}
+
+// define defines the EIP-712 struct type with the given name from the Go struct.
+func (d *typeDeriver) define(t reflect.Type, name string) error {
+	if prev, ok := d.defs[name]; ok {
+		if prev != t {
+			return fmt.Errorf("eip712: conflicting definitions of %s: %v and %v", name, prev, t)
+		}
+		return nil
+	}
+	// Register the type before its fields, which may reference it.
+	d.defs[name] = t
+
+	var fields []Type
+	for _, field := range structFields(t) {
+		typ, err := d.derive(t.Field(field.index).Type, field.typ)
+		if err != nil {
+			return fmt.Errorf("field %s of %s: %w", field.name, name, err)
+		}
+		fields = append(fields, Type{Name: field.name, Type: typ})
+	}
+	d.types[name] = fields
+	return nil
+}
+
+// structElem returns the struct type contained in the Go type through pointers,
+// slices and arrays, if any.
+func structElem(t reflect.Type) reflect.Type {
+	for t != bigT {
+		switch t.Kind() {
+		case reflect.Pointer, reflect.Slice, reflect.Array:
+			t = t.Elem()
+		case reflect.Struct:
+			return t
+		default:
+			return nil
+		}
+	}
+	return nil
+}
+
+// messageValue converts the Go value into its representation in the message of
+// typed data, as it would be decoded from JSON.
+func messageValue(v reflect.Value) (interface{}, error) {
+	switch {
+	case v.Type() == addressT:
+		return v.Interface().(common.Address).Hex(), nil
+	case v.Type() == bigT:
+		if v.IsNil() {
+			return nil, fmt.Errorf("eip712: nil integer")
+		}
+		return (*math.HexOrDecimal256)(v.Interface().(*big.Int)), nil
+	}
+	switch v.Kind() {
+	case reflect.Bool:
+		return v.Bool(), nil
+	case reflect.String:
+		return v.String(), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return (*math.HexOrDecimal256)(big.NewInt(v.Int())), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return (*math.HexOrDecimal256)(new(big.Int).SetUint64(v.Uint())), nil
+	case reflect.Slice, reflect.Array:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			b := make([]byte, v.Len())
+			reflect.Copy(reflect.ValueOf(b), v)
+			return hexutil.Bytes(b), nil
+		}
+		values := make([]interface{}, v.Len())
+		for i := range values {
+			value, err := messageValue(v.Index(i))
+			if err != nil {
+				return nil, err
+			}
+			values[i] = value
+		}
+		return values, nil
+	case reflect.Pointer:
+		if v.IsNil() {
+			return nil, fmt.Errorf("eip712: nil %v", v.Type())
+		}
+		return messageValue(v.Elem())
+	case reflect.Struct:
+		message := make(map[string]interface{})
+		for _, field := range structFields(v.Type()) {
+			value, err := messageValue(v.Field(field.index))
+			if err != nil {
+				return nil, fmt.Errorf("field %s of %v: %w", field.name, v.Type(), err)
+			}
+			message[field.name] = value
+		}
+		return message, nil
+	}
+	return nil, fmt.Errorf("eip712: unsupported type %v", v.Type())
+}
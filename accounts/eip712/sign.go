@@ -0,0 +1,131 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package eip712
+
+import (
+	"crypto/ecdsa"
+	"errors"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// Validate checks that the typed data is well-formed and can be hashed, which
+// is what clef verifies before signing it.
+func (typedData *TypedData) Validate() error {
+	if _, err := typedData.Format(); err != nil {
+		return err
+	}
+	_, _, err := TypedDataAndHash(*typedData)
+	return err
+}
+
+// Hash returns the hash of the typed data to be signed.
+func (typedData *TypedData) Hash() (common.Hash, error) {
+	sighash, _, err := TypedDataAndHash(*typedData)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return common.BytesToHash(sighash), nil
+}
+
+// Sign signs the typed data with an unlocked account of the wallet, after
+// validating it. The V value of the signature is 27 or 28, like in the
+// signatures produced by clef.
+func Sign(wallet accounts.Wallet, account accounts.Account, typedData *TypedData) ([]byte, error) {
+	rawData, err := signingData(typedData)
+	if err != nil {
+		return nil, err
+	}
+	signature, err := wallet.SignData(account, accounts.MimetypeTypedData, rawData)
+	if err != nil {
+		return nil, err
+	}
+	return legacyV(signature), nil
+}
+
+// SignWithPassphrase signs the typed data like Sign, unlocking the account of
+// the wallet with the passphrase for this signature only.
+func SignWithPassphrase(wallet accounts.Wallet, account accounts.Account, passphrase string, typedData *TypedData) ([]byte, error) {
+	rawData, err := signingData(typedData)
+	if err != nil {
+		return nil, err
+	}
+	signature, err := wallet.SignDataWithPassphrase(account, passphrase, accounts.MimetypeTypedData, rawData)
+	if err != nil {
+		return nil, err
+	}
+	return legacyV(signature), nil
+}
+
+// SignWithKey signs the typed data like Sign, with the private key.
+func SignWithKey(key *ecdsa.PrivateKey, typedData *TypedData) ([]byte, error) {
+	rawData, err := signingData(typedData)
+	if err != nil {
+		return nil, err
+	}
+	signature, err := crypto.Sign(crypto.Keccak256(rawData), key)
+	if err != nil {
+		return nil, err
+	}
+	return legacyV(signature), nil
+}
+
+// Recover returns the address of the account which signed the typed data. The
+// V value of the signature must be 27 or 28.
+func Recover(typedData *TypedData, signature []byte) (common.Address, error) {
+	if len(signature) != crypto.SignatureLength {
+		return common.Address{}, errors.New("signature must be 65 bytes long")
+	}
+	if signature[64] != 27 && signature[64] != 28 {
+		return common.Address{}, errors.New("invalid Ethereum signature (V is not 27 or 28)")
+	}
+	sighash, err := typedData.Hash()
+	if err != nil {
+		return common.Address{}, err
+	}
+	sig := common.CopyBytes(signature)
+	sig[64] -= 27 // Transform yellow paper V from 27/28 to 0/1
+	pubkey, err := crypto.SigToPub(sighash[:], sig)
+	if err != nil {
+		return common.Address{}, err
+	}
+	return crypto.PubkeyToAddress(*pubkey), nil
+}
+
+// signingData validates the typed data, and returns the data to sign, the hash
+// of which is the hash of the typed data.
+func signingData(typedData *TypedData) ([]byte, error) {
+	if _, err := typedData.Format(); err != nil {
+		return nil, err
+	}
+	_, rawData, err := TypedDataAndHash(*typedData)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(rawData), nil
+}
+
+// legacyV transforms the V value of the signature from 0/1 to 27/28, if the
+// wallet did not already do it.
+func legacyV(signature []byte) []byte {
+	if signature[64] < 27 {
+		signature[64] += 27
+	}
+	return signature
+}
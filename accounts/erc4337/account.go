@@ -0,0 +1,128 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package erc4337
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// Account is a smart contract account, which executes the calls of the user
+// operations it signs.
+type Account interface {
+	// Address returns the address of the account contract.
+	Address() common.Address
+
+	// Factory returns the factory deploying the account and the data of the
+	// call deploying it, or nil if the account cannot be deployed by a user
+	// operation.
+	Factory() (*common.Address, []byte)
+
+	// EncodeCallData returns the call data of a user operation making the
+	// account call the given contract.
+	EncodeCallData(to common.Address, value *big.Int, data []byte) ([]byte, error)
+
+	// DummySignature returns a signature which fails the validation of the
+	// account without reverting, and costs as much gas as a valid signature.
+	// It is used in place of the signature to estimate the gas of operations.
+	DummySignature() []byte
+
+	// SignUserOperation signs the hash of the user operation.
+	SignUserOperation(op *UserOperation, hash common.Hash) ([]byte, error)
+}
+
+// simpleAccountABI contains the methods of the SimpleAccount and
+// SimpleAccountFactory contracts of the reference implementation.
+var simpleAccountABI = mustParse(
+	"function execute(address dest, uint256 value, bytes func)",
+	"function createAccount(address owner, uint256 salt) returns (address)",
+)
+
+// simpleAccountDummySignature is a signature of valid format which doesn't
+// recover to the owner of any account.
+var simpleAccountDummySignature = hexutil.MustDecode("0xfffffffffffffffffffffffffffffff0000000000000000000000000000000007aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa1c")
+
+// SimpleAccount is an account of the SimpleAccount reference implementation,
+// owned by an account of a wallet which signs the operations.
+type SimpleAccount struct {
+	address     common.Address
+	wallet      accounts.Wallet
+	owner       accounts.Account
+	factory     *common.Address
+	factoryData []byte
+}
+
+// NewSimpleAccount creates a SimpleAccount at the given address, owned by the
+// account of the wallet.
+func NewSimpleAccount(address common.Address, wallet accounts.Wallet, owner accounts.Account) *SimpleAccount {
+	return &SimpleAccount{address: address, wallet: wallet, owner: owner}
+}
+
+// SetFactory sets the SimpleAccountFactory deploying the account with the given
+// salt, for operations sent before it is deployed.
+func (a *SimpleAccount) SetFactory(factory common.Address, salt *big.Int) error {
+	data, err := simpleAccountABI.Pack("createAccount", a.owner.Address, bigOrZero(salt))
+	if err != nil {
+		return err
+	}
+	a.factory, a.factoryData = &factory, data
+	return nil
+}
+
+// Address implements Account, returning the address of the account contract.
+func (a *SimpleAccount) Address() common.Address {
+	return a.address
+}
+
+// Factory implements Account, returning the factory set by SetFactory.
+func (a *SimpleAccount) Factory() (*common.Address, []byte) {
+	return a.factory, a.factoryData
+}
+
+// EncodeCallData implements Account, encoding a call to the execute method.
+func (a *SimpleAccount) EncodeCallData(to common.Address, value *big.Int, data []byte) ([]byte, error) {
+	return simpleAccountABI.Pack("execute", to, bigOrZero(value), data)
+}
+
+// DummySignature implements Account.
+func (a *SimpleAccount) DummySignature() []byte {
+	return common.CopyBytes(simpleAccountDummySignature)
+}
+
+// SignUserOperation implements Account, signing the hash as a text message with
+// the owner account, as the account contract verifies it.
+func (a *SimpleAccount) SignUserOperation(op *UserOperation, hash common.Hash) ([]byte, error) {
+	sig, err := a.wallet.SignText(a.owner, hash[:])
+	if err != nil {
+		return nil, err
+	}
+	sig[64] += 27 // Transform V from 0/1 to 27/28, as ecrecover expects it
+	return sig, nil
+}
+
+// mustParse parses the human-readable ABI fragments, panicking on error.
+func mustParse(fragments ...string) abi.ABI {
+	parsed, err := abi.ParseHumanReadable(fragments)
+	if err != nil {
+		panic(err)
+	}
+	return parsed
+}
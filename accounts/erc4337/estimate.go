@@ -0,0 +1,186 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package erc4337
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// The overheads of a bundle transaction, which are charged to its operations
+// through their pre-verification gas.
+const (
+	bundleOverheadGas = 21000 // Intrinsic gas of the bundle transaction
+	opOverheadGas     = 18300 // Gas spent by the entry point on each operation
+	opWordGas         = 4     // Gas spent by the entry point on each word of an operation
+	zeroByteGas       = 4     // Calldata cost of a zero byte
+	nonZeroByteGas    = 16    // Calldata cost of a non-zero byte
+)
+
+// entryPointABI contains the methods of the accounts called by the entry point.
+var entryPointABI = mustParse(
+	"struct PackedUserOperation { address sender; uint256 nonce; bytes initCode; bytes callData; bytes32 accountGasLimits; uint256 preVerificationGas; bytes32 gasFees; bytes paymasterAndData; bytes signature; }",
+	"function validateUserOp(PackedUserOperation userOp, bytes32 userOpHash, uint256 missingAccountFunds) returns (uint256 validationData)",
+	"function getNonce(address sender, uint192 key) view returns (uint256 nonce)",
+)
+
+// GasEstimate contains the estimated gas limits of a user operation.
+type GasEstimate struct {
+	PreVerificationGas   uint64
+	VerificationGasLimit uint64
+	CallGasLimit         uint64
+}
+
+// SimulationError is returned by EstimateGas if a simulated call fails. Like the
+// errors returned by the node, it carries the revert data.
+type SimulationError struct {
+	Call    string // Simulated call: deployment, validation or execution
+	Message string
+	Data    []byte
+}
+
+func (e *SimulationError) Error() string {
+	return fmt.Sprintf("erc4337: %s of the user operation failed: %s", e.Call, e.Message)
+}
+
+func (e *SimulationError) ErrorData() interface{} {
+	return hexutil.Encode(e.Data)
+}
+
+// PreVerificationGas returns the gas to pay the bundler for including the
+// operation in a bundle of its own: the calldata and the overhead of the
+// operation. The gas limits are counted at a high value, so that the returned
+// gas covers the operation once they are estimated.
+func PreVerificationGas(op *UserOperation) (uint64, error) {
+	op = op.Copy()
+	op.CallGasLimit, op.VerificationGasLimit, op.PreVerificationGas = math.MaxUint32, math.MaxUint32, math.MaxUint32
+
+	data, err := entryPointABI.Methods["validateUserOp"].Inputs[:1].Pack(op.Pack())
+	if err != nil {
+		return 0, err
+	}
+	gas := uint64(bundleOverheadGas + opOverheadGas + opWordGas*((len(data)+31)/32))
+	for _, b := range data {
+		if b == 0 {
+			gas += zeroByteGas
+		} else {
+			gas += nonZeroByteGas
+		}
+	}
+	return gas, nil
+}
+
+// EstimateGas estimates the gas limits of the operation, simulating its
+// execution by the entry point with eth_simulateV1 on the node: the deployment
+// of the account if the operation has a factory, its validation, and the
+// execution of its call data. The estimated limits are the gas used by the
+// calls, including the intrinsic gas of a transaction which covers the overhead
+// of the entry point.
+//
+// The operation must be signed, for example with the dummy signature of the
+// account. Paymasters are not simulated, their gas limits must be set.
+func EstimateGas(ctx context.Context, client *rpc.Client, entryPoint common.Address, op *UserOperation, chainID *big.Int) (*GasEstimate, error) {
+	preVerificationGas, err := PreVerificationGas(op)
+	if err != nil {
+		return nil, err
+	}
+	validate, err := entryPointABI.Pack("validateUserOp", op.Pack(), op.Hash(entryPoint, chainID), new(big.Int))
+	if err != nil {
+		return nil, err
+	}
+	var (
+		names = []string{"validation", "execution"}
+		calls = []interface{}{
+			callArgs(entryPoint, op.Sender, validate),
+			callArgs(entryPoint, op.Sender, op.CallData),
+		}
+	)
+	if op.Factory != nil {
+		names = append([]string{"deployment"}, names...)
+		calls = append([]interface{}{callArgs(entryPoint, *op.Factory, op.FactoryData)}, calls...)
+	}
+	type simCallResult struct {
+		GasUsed hexutil.Uint64 `json:"gasUsed"`
+		Status  hexutil.Uint64 `json:"status"`
+		Error   *struct {
+			Message string        `json:"message"`
+			Data    hexutil.Bytes `json:"data"`
+		} `json:"error"`
+	}
+	var blocks []struct {
+		Calls []simCallResult `json:"calls"`
+	}
+	opts := map[string]interface{}{
+		"blockStateCalls": []interface{}{map[string]interface{}{"calls": calls}},
+	}
+	if err := client.CallContext(ctx, &blocks, "eth_simulateV1", opts, "latest"); err != nil {
+		return nil, err
+	}
+	if len(blocks) != 1 || len(blocks[0].Calls) != len(calls) {
+		return nil, fmt.Errorf("erc4337: unexpected simulation result")
+	}
+	estimate := &GasEstimate{PreVerificationGas: preVerificationGas}
+	for i, res := range blocks[0].Calls {
+		if res.Status != 1 {
+			err := &SimulationError{Call: names[i], Message: "execution failed"}
+			if res.Error != nil {
+				err.Message, err.Data = res.Error.Message, res.Error.Data
+			}
+			return nil, err
+		}
+		if names[i] == "execution" {
+			estimate.CallGasLimit = uint64(res.GasUsed)
+		} else {
+			estimate.VerificationGasLimit += uint64(res.GasUsed)
+		}
+	}
+	return estimate, nil
+}
+
+// GetNonce returns the nonce of the next operation of the account with the given
+// key, which selects one of its parallel sequences of nonces.
+func GetNonce(ctx context.Context, caller ethereum.ContractCaller, entryPoint common.Address, sender common.Address, key *big.Int) (*big.Int, error) {
+	input, err := entryPointABI.Pack("getNonce", sender, bigOrZero(key))
+	if err != nil {
+		return nil, err
+	}
+	output, err := caller.CallContract(ctx, ethereum.CallMsg{To: &entryPoint, Data: input}, nil)
+	if err != nil {
+		return nil, err
+	}
+	values, err := entryPointABI.Unpack("getNonce", output)
+	if err != nil {
+		return nil, err
+	}
+	return values[0].(*big.Int), nil
+}
+
+// callArgs encodes the arguments of a simulated call.
+func callArgs(from common.Address, to common.Address, input []byte) interface{} {
+	return map[string]interface{}{
+		"from":  from,
+		"to":    to,
+		"input": hexutil.Bytes(input),
+	}
+}
@@ -0,0 +1,136 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package erc4337
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/eth/ethconfig"
+	"github.com/ethereum/go-ethereum/ethclient/simulated"
+	"github.com/ethereum/go-ethereum/node"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+func TestPreVerificationGas(t *testing.T) {
+	t.Parallel()
+	gas, err := PreVerificationGas(testOp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The gas grows with the calldata, but not with the gas limits.
+	op := testOp.Copy()
+	op.CallData = append(op.CallData, 1, 2, 3, 4)
+	op.CallGasLimit, op.PreVerificationGas = 1, gas
+	more, err := PreVerificationGas(op)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := gas + 4*(nonZeroByteGas-zeroByteGas); more != want {
+		t.Errorf("gas mismatch: have %d, want %d", more, want)
+	}
+	if gas < bundleOverheadGas+opOverheadGas {
+		t.Errorf("gas %d below the overhead", gas)
+	}
+}
+
+func TestEstimateGas(t *testing.T) {
+	var (
+		sender   = common.HexToAddress("0x5e4de7")
+		reverter = common.HexToAddress("0xdead")
+		factory  = common.HexToAddress("0xfac7")
+	)
+	// The simulation needs the raw RPC client, which the simulated backend
+	// does not expose: reach it over IPC instead.
+	dir, err := os.MkdirTemp("", "erc4337")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	ipc := filepath.Join(dir, "sim.ipc")
+	sim := simulated.NewBackend(types.GenesisAlloc{
+		sender:       {Code: []byte{0x5b, 0x00}},                           // JUMPDEST, STOP
+		reverter:     {Code: hexutil.MustDecode("0x60ff60005360016000fd")}, // revert(0xff)
+		factory:      {Code: hexutil.MustDecode("0x600160015560206000f3")}, // store 1, return 32 bytes
+		EntryPoint07: {Code: hexutil.MustDecode("0x600560005260206000f3")}, // getNonce returns 5
+	}, func(nodeConf *node.Config, ethConf *ethconfig.Config) {
+		nodeConf.IPCPath = ipc
+	})
+	defer sim.Close()
+	client, err := rpc.Dial(ipc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	ctx := context.Background()
+	nonce, err := GetNonce(ctx, sim.Client(), EntryPoint07, sender, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if nonce.Int64() != 5 {
+		t.Errorf("nonce mismatch: have %v, want 5", nonce)
+	}
+	op := &UserOperation{
+		Sender:               sender,
+		Nonce:                nonce,
+		CallData:             make([]byte, 100),
+		MaxFeePerGas:         big.NewInt(1e9),
+		MaxPriorityFeePerGas: big.NewInt(1e9),
+		Signature:            simpleAccountDummySignature,
+	}
+	estimate, err := EstimateGas(ctx, client, EntryPoint07, op, big.NewInt(1337))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if estimate.CallGasLimit != 21000+100*4+1 {
+		t.Errorf("call gas limit mismatch: have %d, want %d", estimate.CallGasLimit, 21000+100*4+1)
+	}
+	if estimate.VerificationGasLimit <= 21000 {
+		t.Errorf("verification gas limit too low: %d", estimate.VerificationGasLimit)
+	}
+	if want, _ := PreVerificationGas(op); estimate.PreVerificationGas != want {
+		t.Errorf("pre-verification gas mismatch: have %d, want %d", estimate.PreVerificationGas, want)
+	}
+	// The deployment is included in the verification gas.
+	op.Factory = &factory
+	deployed, err := EstimateGas(ctx, client, EntryPoint07, op, big.NewInt(1337))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if deployed.VerificationGasLimit <= estimate.VerificationGasLimit+20000 {
+		t.Errorf("deployment not estimated: have %d, without factory %d", deployed.VerificationGasLimit, estimate.VerificationGasLimit)
+	}
+	// Reverting calls are reported with their revert data.
+	op.Factory, op.Sender = nil, reverter
+	_, err = EstimateGas(ctx, client, EntryPoint07, op, big.NewInt(1337))
+	var simErr *SimulationError
+	if !errors.As(err, &simErr) {
+		t.Fatalf("expected simulation error, got %v", err)
+	}
+	if simErr.Call != "validation" || !bytes.Equal(simErr.Data, []byte{0xff}) {
+		t.Errorf("unexpected simulation error %q with data %x", simErr.Error(), simErr.Data)
+	}
+}
@@ -0,0 +1,205 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package erc4337 implements the user operations of ERC-4337 smart contract
+// accounts, as defined for version 0.7 of the entry point contract.
+package erc4337
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+//go:generate go run github.com/fjl/gencodec -type UserOperation -field-override userOperationMarshaling -out gen_userop_json.go
+
+// EntryPoint07 is the address of the version 0.7 entry point contract, which
+// is deployed at the same address on all chains.
+var EntryPoint07 = common.HexToAddress("0x0000000071727De22E5E9d8BAf0edAc6f37da032")
+
+// UserOperation is an operation of a smart contract account, in the format used
+// by the JSON-RPC API of bundlers.
+type UserOperation struct {
+	Sender               common.Address  `json:"sender" gencodec:"required"`
+	Nonce                *big.Int        `json:"nonce" gencodec:"required"`
+	Factory              *common.Address `json:"factory,omitempty"`
+	FactoryData          []byte          `json:"factoryData,omitempty"`
+	CallData             []byte          `json:"callData" gencodec:"required"`
+	CallGasLimit         uint64          `json:"callGasLimit" gencodec:"required"`
+	VerificationGasLimit uint64          `json:"verificationGasLimit" gencodec:"required"`
+	PreVerificationGas   uint64          `json:"preVerificationGas" gencodec:"required"`
+	MaxFeePerGas         *big.Int        `json:"maxFeePerGas" gencodec:"required"`
+	MaxPriorityFeePerGas *big.Int        `json:"maxPriorityFeePerGas" gencodec:"required"`
+
+	Paymaster                     *common.Address `json:"paymaster,omitempty"`
+	PaymasterVerificationGasLimit uint64          `json:"paymasterVerificationGasLimit,omitempty"`
+	PaymasterPostOpGasLimit       uint64          `json:"paymasterPostOpGasLimit,omitempty"`
+	PaymasterData                 []byte          `json:"paymasterData,omitempty"`
+
+	Signature []byte `json:"signature" gencodec:"required"`
+}
+
+type userOperationMarshaling struct {
+	Nonce                         *hexutil.Big
+	FactoryData                   hexutil.Bytes
+	CallData                      hexutil.Bytes
+	CallGasLimit                  hexutil.Uint64
+	VerificationGasLimit          hexutil.Uint64
+	PreVerificationGas            hexutil.Uint64
+	MaxFeePerGas                  *hexutil.Big
+	MaxPriorityFeePerGas          *hexutil.Big
+	PaymasterVerificationGasLimit hexutil.Uint64
+	PaymasterPostOpGasLimit       hexutil.Uint64
+	PaymasterData                 hexutil.Bytes
+	Signature                     hexutil.Bytes
+}
+
+// PackedUserOperation is a user operation in the format of the entry point
+// contract, with the gas limits and fees packed in pairs.
+type PackedUserOperation struct {
+	Sender             common.Address
+	Nonce              *big.Int
+	InitCode           []byte
+	CallData           []byte
+	AccountGasLimits   [32]byte
+	PreVerificationGas *big.Int
+	GasFees            [32]byte
+	PaymasterAndData   []byte
+	Signature          []byte
+}
+
+// Copy returns a deep copy of the user operation.
+func (op *UserOperation) Copy() *UserOperation {
+	cpy := *op
+	if op.Nonce != nil {
+		cpy.Nonce = new(big.Int).Set(op.Nonce)
+	}
+	if op.Factory != nil {
+		factory := *op.Factory
+		cpy.Factory = &factory
+	}
+	if op.MaxFeePerGas != nil {
+		cpy.MaxFeePerGas = new(big.Int).Set(op.MaxFeePerGas)
+	}
+	if op.MaxPriorityFeePerGas != nil {
+		cpy.MaxPriorityFeePerGas = new(big.Int).Set(op.MaxPriorityFeePerGas)
+	}
+	if op.Paymaster != nil {
+		paymaster := *op.Paymaster
+		cpy.Paymaster = &paymaster
+	}
+	cpy.FactoryData = common.CopyBytes(op.FactoryData)
+	cpy.CallData = common.CopyBytes(op.CallData)
+	cpy.PaymasterData = common.CopyBytes(op.PaymasterData)
+	cpy.Signature = common.CopyBytes(op.Signature)
+	return &cpy
+}
+
+// InitCode returns the factory address followed by the factory data, or nil if
+// the operation has no factory.
+func (op *UserOperation) InitCode() []byte {
+	if op.Factory == nil {
+		return nil
+	}
+	return append(op.Factory.Bytes(), op.FactoryData...)
+}
+
+// PaymasterAndData returns the paymaster address followed by its gas limits and
+// data, or nil if the operation has no paymaster.
+func (op *UserOperation) PaymasterAndData() []byte {
+	if op.Paymaster == nil {
+		return nil
+	}
+	data := op.Paymaster.Bytes()
+	data = append(data, uint128(new(big.Int).SetUint64(op.PaymasterVerificationGasLimit))...)
+	data = append(data, uint128(new(big.Int).SetUint64(op.PaymasterPostOpGasLimit))...)
+	return append(data, op.PaymasterData...)
+}
+
+// AccountGasLimits returns the verification and call gas limits packed in a word.
+func (op *UserOperation) AccountGasLimits() [32]byte {
+	return packUints(new(big.Int).SetUint64(op.VerificationGasLimit), new(big.Int).SetUint64(op.CallGasLimit))
+}
+
+// GasFees returns the priority fee and the maximum fee packed in a word.
+func (op *UserOperation) GasFees() [32]byte {
+	return packUints(op.MaxPriorityFeePerGas, op.MaxFeePerGas)
+}
+
+// Pack returns the operation in the format of the entry point contract.
+func (op *UserOperation) Pack() PackedUserOperation {
+	return PackedUserOperation{
+		Sender:             op.Sender,
+		Nonce:              bigOrZero(op.Nonce),
+		InitCode:           op.InitCode(),
+		CallData:           op.CallData,
+		AccountGasLimits:   op.AccountGasLimits(),
+		PreVerificationGas: new(big.Int).SetUint64(op.PreVerificationGas),
+		GasFees:            op.GasFees(),
+		PaymasterAndData:   op.PaymasterAndData(),
+		Signature:          op.Signature,
+	}
+}
+
+// Hash returns the hash of the operation signed by the account, which covers
+// all its fields except the signature, together with the entry point and the
+// chain ID.
+func (op *UserOperation) Hash(entryPoint common.Address, chainID *big.Int) common.Hash {
+	var (
+		limits = op.AccountGasLimits()
+		fees   = op.GasFees()
+	)
+	inner := crypto.Keccak256(
+		common.LeftPadBytes(op.Sender.Bytes(), 32),
+		word(op.Nonce),
+		crypto.Keccak256(op.InitCode()),
+		crypto.Keccak256(op.CallData),
+		limits[:],
+		word(new(big.Int).SetUint64(op.PreVerificationGas)),
+		fees[:],
+		crypto.Keccak256(op.PaymasterAndData()),
+	)
+	return crypto.Keccak256Hash(inner, common.LeftPadBytes(entryPoint.Bytes(), 32), word(chainID))
+}
+
+// bigOrZero returns the integer, or zero if it is nil.
+func bigOrZero(v *big.Int) *big.Int {
+	if v == nil {
+		return new(big.Int)
+	}
+	return v
+}
+
+// word encodes the integer as a 256 bit word.
+func word(v *big.Int) []byte {
+	return common.LeftPadBytes(bigOrZero(v).Bytes(), 32)
+}
+
+// uint128 encodes the integer on 16 bytes, truncating it if it's too large.
+func uint128(v *big.Int) []byte {
+	b := word(v)
+	return b[16:]
+}
+
+// packUints packs two 128 bit integers in a word, the first in the high bits.
+func packUints(high, low *big.Int) [32]byte {
+	var packed [32]byte
+	copy(packed[:16], uint128(high))
+	copy(packed[16:], uint128(low))
+	return packed
+}
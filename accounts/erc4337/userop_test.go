@@ -0,0 +1,197 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package erc4337
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+var (
+	testFactory   = common.HexToAddress("0xfac7")
+	testPaymaster = common.HexToAddress("0x9a7")
+
+	testOp = &UserOperation{
+		Sender:                        common.HexToAddress("0x5e4de7"),
+		Nonce:                         big.NewInt(7),
+		Factory:                       &testFactory,
+		FactoryData:                   []byte{0x01, 0x02},
+		CallData:                      []byte{0xca, 0x11},
+		CallGasLimit:                  100000,
+		VerificationGasLimit:          200000,
+		PreVerificationGas:            50000,
+		MaxFeePerGas:                  big.NewInt(2e9),
+		MaxPriorityFeePerGas:          big.NewInt(1e9),
+		Paymaster:                     &testPaymaster,
+		PaymasterVerificationGasLimit: 30000,
+		PaymasterPostOpGasLimit:       10000,
+		PaymasterData:                 []byte{0xda, 0x7a},
+		Signature:                     []byte{0x51, 0x9e},
+	}
+)
+
+// Tests the hash of user operations against the ABI encoding of the entry point.
+func TestUserOperationHash(t *testing.T) {
+	t.Parallel()
+	typ := func(name string) abi.Type {
+		t, err := abi.NewType(name, "", nil)
+		if err != nil {
+			panic(err)
+		}
+		return t
+	}
+	inner := abi.Arguments{
+		{Type: typ("address")}, {Type: typ("uint256")}, {Type: typ("bytes32")}, {Type: typ("bytes32")},
+		{Type: typ("bytes32")}, {Type: typ("uint256")}, {Type: typ("bytes32")}, {Type: typ("bytes32")},
+	}
+	outer := abi.Arguments{{Type: typ("bytes32")}, {Type: typ("address")}, {Type: typ("uint256")}}
+
+	packed := testOp.Pack()
+	encoded, err := inner.Pack(
+		packed.Sender, packed.Nonce, crypto.Keccak256Hash(packed.InitCode), crypto.Keccak256Hash(packed.CallData),
+		packed.AccountGasLimits, packed.PreVerificationGas, packed.GasFees, crypto.Keccak256Hash(packed.PaymasterAndData),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	chainID := big.NewInt(1337)
+	encoded, err = outer.Pack(crypto.Keccak256Hash(encoded), EntryPoint07, chainID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if have, want := testOp.Hash(EntryPoint07, chainID), crypto.Keccak256Hash(encoded); have != want {
+		t.Errorf("hash mismatch: have %x, want %x", have, want)
+	}
+	// The packed fields follow the layout of the entry point.
+	if want := append(testFactory.Bytes(), 0x01, 0x02); !bytes.Equal(packed.InitCode, want) {
+		t.Errorf("init code mismatch: have %x, want %x", packed.InitCode, want)
+	}
+	wantLimits := common.HexToHash("0x00000000000000000000000000030d40000000000000000000000000000186a0")
+	if packed.AccountGasLimits != wantLimits {
+		t.Errorf("gas limits mismatch: have %x, want %x", packed.AccountGasLimits, wantLimits)
+	}
+	wantFees := common.HexToHash("0x0000000000000000000000003b9aca0000000000000000000000000077359400")
+	if packed.GasFees != wantFees {
+		t.Errorf("gas fees mismatch: have %x, want %x", packed.GasFees, wantFees)
+	}
+	wantPaymaster := hexutil.MustDecode("0x00000000000000000000000000000000000009a7" + "00000000000000000000000000007530" + "00000000000000000000000000002710" + "da7a")
+	if !bytes.Equal(packed.PaymasterAndData, wantPaymaster) {
+		t.Errorf("paymaster and data mismatch: have %x, want %x", packed.PaymasterAndData, wantPaymaster)
+	}
+	// The signature is not part of the hash.
+	op := testOp.Copy()
+	op.Signature = nil
+	if op.Hash(EntryPoint07, chainID) != testOp.Hash(EntryPoint07, chainID) {
+		t.Error("hash depends on the signature")
+	}
+}
+
+func TestUserOperationJSON(t *testing.T) {
+	t.Parallel()
+	blob, err := json.Marshal(testOp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"sender":"0x00000000000000000000000000000000005e4de7","nonce":"0x7","factory":"0x000000000000000000000000000000000000fac7","factoryData":"0x0102","callData":"0xca11","callGasLimit":"0x186a0","verificationGasLimit":"0x30d40","preVerificationGas":"0xc350","maxFeePerGas":"0x77359400","maxPriorityFeePerGas":"0x3b9aca00","paymaster":"0x00000000000000000000000000000000000009a7","paymasterVerificationGasLimit":"0x7530","paymasterPostOpGasLimit":"0x2710","paymasterData":"0xda7a","signature":"0x519e"}`
+	if string(blob) != want {
+		t.Errorf("JSON mismatch:\nhave %s\nwant %s", blob, want)
+	}
+	var dec UserOperation
+	if err := json.Unmarshal(blob, &dec); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(&dec, testOp) {
+		t.Errorf("decoded operation mismatch: have %+v, want %+v", dec, testOp)
+	}
+	if err := json.Unmarshal([]byte(`{"sender":"0x00000000000000000000000000000000005e4de7"}`), &dec); err == nil {
+		t.Error("expected error for missing fields")
+	}
+}
+
+func TestSimpleAccount(t *testing.T) {
+	t.Parallel()
+	ks := keystore.NewKeyStore(t.TempDir(), keystore.LightScryptN, keystore.LightScryptP)
+	owner, err := ks.NewAccount("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ks.Unlock(owner, ""); err != nil {
+		t.Fatal(err)
+	}
+	account := NewSimpleAccount(common.HexToAddress("0x5e4de7"), ks.Wallets()[0], owner)
+	if factory, data := account.Factory(); factory != nil || data != nil {
+		t.Fatalf("unexpected factory %v %x", factory, data)
+	}
+	if err := account.SetFactory(testFactory, big.NewInt(1)); err != nil {
+		t.Fatal(err)
+	}
+	factory, data := account.Factory()
+	wantData := hexutil.MustDecode("0x5fbfb9cf" + common.Bytes2Hex(common.LeftPadBytes(owner.Address.Bytes(), 32)) + "0000000000000000000000000000000000000000000000000000000000000001")
+	if *factory != testFactory || !bytes.Equal(data, wantData) {
+		t.Errorf("factory mismatch: have %v %x, want %v %x", factory, data, testFactory, wantData)
+	}
+	callData, err := account.EncodeCallData(common.HexToAddress("0x70"), big.NewInt(5), []byte{0xab})
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantCall := hexutil.MustDecode("0xb61d27f6" +
+		"0000000000000000000000000000000000000000000000000000000000000070" +
+		"0000000000000000000000000000000000000000000000000000000000000005" +
+		"0000000000000000000000000000000000000000000000000000000000000060" +
+		"0000000000000000000000000000000000000000000000000000000000000001" +
+		"ab00000000000000000000000000000000000000000000000000000000000000")
+	if !bytes.Equal(callData, wantCall) {
+		t.Errorf("call data mismatch:\nhave %x\nwant %x", callData, wantCall)
+	}
+	hash := testOp.Hash(EntryPoint07, big.NewInt(1))
+	sig, err := account.SignUserOperation(testOp, hash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sig) != 65 || sig[64] < 27 {
+		t.Fatalf("invalid signature %x", sig)
+	}
+	recovered := common.CopyBytes(sig)
+	recovered[64] -= 27
+	pub, err := crypto.SigToPub(accounts.TextHash(hash[:]), recovered)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if signer := crypto.PubkeyToAddress(*pub); signer != owner.Address {
+		t.Errorf("signer mismatch: have %v, want %v", signer, owner.Address)
+	}
+	// The dummy signature must recover to some address, as the account reverts
+	// on invalid signatures.
+	dummy := account.DummySignature()
+	if len(dummy) != len(sig) {
+		t.Fatalf("dummy signature length mismatch: have %d, want %d", len(dummy), len(sig))
+	}
+	dummy[64] -= 27
+	if _, err := crypto.SigToPub(accounts.TextHash(hash[:]), dummy); err != nil {
+		t.Errorf("dummy signature doesn't recover: %v", err)
+	}
+}
@@ -279,6 +279,20 @@ func (ks *KeyStore) SignTx(a accounts.Account, tx *types.Transaction, chainID *b
 	return types.SignTx(tx, signer, unlockedKey.PrivateKey)
 }
 
+// SignAuthorization signs the given EIP-7702 authorization with the requested
+// account.
+func (ks *KeyStore) SignAuthorization(a accounts.Account, auth types.SetCodeAuthorization) (types.SetCodeAuthorization, error) {
+	// Look up the key to sign with and abort if it cannot be found
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+
+	unlockedKey, found := ks.unlocked[a.Address]
+	if !found {
+		return types.SetCodeAuthorization{}, ErrLocked
+	}
+	return types.SignSetCode(unlockedKey.PrivateKey, auth)
+}
+
 // SignHashWithPassphrase signs hash if the private key matching the given address
 // can be decrypted with the given passphrase. The produced signature is in the
 // [R || S || V] format where V is 0 or 1.
@@ -304,6 +318,18 @@ func (ks *KeyStore) SignTxWithPassphrase(a accounts.Account, passphrase string,
 	return types.SignTx(tx, signer, key.PrivateKey)
 }
 
+// SignAuthorizationWithPassphrase signs the EIP-7702 authorization if the
+// private key matching the given address can be decrypted with the given
+// passphrase.
+func (ks *KeyStore) SignAuthorizationWithPassphrase(a accounts.Account, passphrase string, auth types.SetCodeAuthorization) (types.SetCodeAuthorization, error) {
+	_, key, err := ks.getDecryptedKey(a, passphrase)
+	if err != nil {
+		return types.SetCodeAuthorization{}, err
+	}
+	defer zeroKey(key.PrivateKey)
+	return types.SignSetCode(key.PrivateKey, auth)
+}
+
 // Unlock unlocks the given account indefinitely.
 func (ks *KeyStore) Unlock(a accounts.Account, passphrase string) error {
 	return ks.TimedUnlock(a, passphrase, 0)
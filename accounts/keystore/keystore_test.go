@@ -29,8 +29,10 @@ import (
 
 	"github.com/ethereum/go-ethereum/accounts"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/event"
+	"github.com/holiman/uint256"
 )
 
 var testSigData = make([]byte, 32)
@@ -115,6 +117,45 @@ func TestSignWithPassphrase(t *testing.T) {
 	}
 }
 
+func TestSignAuthorization(t *testing.T) {
+	t.Parallel()
+	_, ks := tmpKeyStore(t)
+
+	pass := "passwd"
+	acc, err := ks.NewAccount(pass)
+	if err != nil {
+		t.Fatal(err)
+	}
+	auth := types.SetCodeAuthorization{
+		ChainID: *uint256.NewInt(1),
+		Address: common.HexToAddress("0x42"),
+		Nonce:   3,
+	}
+	if _, err := ks.SignAuthorization(acc, auth); err != ErrLocked {
+		t.Fatalf("expected ErrLocked, got %v", err)
+	}
+	if _, err := ks.SignAuthorizationWithPassphrase(acc, "invalid passwd", auth); err == nil {
+		t.Fatal("expected SignAuthorizationWithPassphrase to fail with invalid password")
+	}
+	signed, err := ks.SignAuthorizationWithPassphrase(acc, pass, auth)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if authority, err := signed.Authority(); err != nil || authority != acc.Address {
+		t.Fatalf("wrong authority %v: %v", authority, err)
+	}
+	if err := ks.Unlock(acc, pass); err != nil {
+		t.Fatal(err)
+	}
+	signed, err = ks.SignAuthorization(acc, auth)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if authority, err := signed.Authority(); err != nil || authority != acc.Address {
+		t.Fatalf("wrong authority %v: %v", authority, err)
+	}
+}
+
 func TestTimedUnlock(t *testing.T) {
 	t.Parallel()
 	_, ks := tmpKeyStore(t)
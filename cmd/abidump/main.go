@@ -17,22 +17,27 @@
 package main
 
 import (
+	"bytes"
 	"encoding/hex"
 	"flag"
 	"fmt"
 	"os"
 	"strings"
 
+	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/signer/core/apitypes"
 	"github.com/ethereum/go-ethereum/signer/fourbyte"
 )
 
+var abiFlag = flag.String("abi", "", "Path to the JSON or human-readable ABI to decode the data with, instead of the fourbyte database")
+
 func init() {
 	flag.Usage = func() {
-		fmt.Fprintln(os.Stderr, "Usage:", os.Args[0], "<hexdata>")
+		fmt.Fprintln(os.Stderr, "Usage:", os.Args[0], "[--abi <file>] <hexdata>")
 		flag.PrintDefaults()
 		fmt.Fprintln(os.Stderr, `
-Parses the given ABI data and tries to interpret it from the fourbyte database.`)
+Parses the given ABI data and tries to interpret it from the fourbyte database,
+or from the given contract ABI.`)
 	}
 }
 
@@ -48,6 +53,39 @@ func parse(data []byte) {
 	}
 }
 
+func parseWithABI(path string, data []byte) {
+	blob, err := os.ReadFile(path)
+	if err != nil {
+		die(err)
+	}
+	if blob, err = abi.ConvertHumanReadable(blob); err != nil {
+		die(err)
+	}
+	parsed, err := abi.JSON(bytes.NewReader(blob))
+	if err != nil {
+		die(err)
+	}
+	if len(data) < 4 {
+		die("invalid call data, incomplete method signature")
+	}
+	method, err := parsed.MethodById(data[:4])
+	if err != nil {
+		die(err)
+	}
+	values, err := method.Inputs.Unpack(data[4:])
+	if err != nil {
+		die(err)
+	}
+	fmt.Println(method.Sig)
+	for i, arg := range method.Inputs {
+		name := arg.Name
+		if name == "" {
+			name = fmt.Sprintf("arg%d", i)
+		}
+		fmt.Printf("  %s %s: %v\n", arg.Type, name, values[i])
+	}
+}
+
 // Example
 // ./abidump a9059cbb000000000000000000000000ea0e2dc7d65a50e77fc7e84bff3fd2a9e781ff5c0000000000000000000000000000000000000000000000015af1d78b58c40000
 func main() {
@@ -60,7 +98,11 @@ func main() {
 		if err != nil {
 			die(err)
 		}
-		parse(data)
+		if *abiFlag != "" {
+			parseWithABI(*abiFlag, data)
+		} else {
+			parse(data)
+		}
 	default:
 		fmt.Fprintln(os.Stderr, "Error: one argument needed")
 		flag.Usage()
@@ -24,6 +24,7 @@ import (
 	"regexp"
 	"strings"
 
+	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/cmd/utils"
 	"github.com/ethereum/go-ethereum/common/compiler"
@@ -37,7 +38,7 @@ var (
 	// Flags needed by abigen
 	abiFlag = &cli.StringFlag{
 		Name:  "abi",
-		Usage: "Path to the Ethereum contract ABI json to bind, - for STDIN",
+		Usage: "Path to the Ethereum contract ABI json or human-readable ABI to bind, - for STDIN",
 	}
 	binFlag = &cli.StringFlag{
 		Name:  "bin",
@@ -70,7 +71,11 @@ var (
 	}
 	aliasFlag = &cli.StringFlag{
 		Name:  "alias",
-		Usage: "Comma separated aliases for function and event renaming, e.g. original1=alias1, original2=alias2",
+		Usage: "Comma separated aliases for function, event and error renaming, e.g. original1=alias1, original2(uint256)=alias2",
+	}
+	v2Flag = &cli.BoolFlag{
+		Name:  "v2",
+		Usage: "Generate v2 bindings, packing and unpacking data for the generic helpers of bind/v2",
 	}
 )
 
@@ -88,6 +93,7 @@ func init() {
 		outFlag,
 		langFlag,
 		aliasFlag,
+		v2Flag,
 	}
 	app.Action = abigen
 }
@@ -117,19 +123,22 @@ func abigen(c *cli.Context) error {
 	if c.String(abiFlag.Name) != "" {
 		// Load up the ABI, optional bytecode and type name from the parameters
 		var (
-			abi []byte
-			err error
+			data []byte
+			err  error
 		)
 		input := c.String(abiFlag.Name)
 		if input == "-" {
-			abi, err = io.ReadAll(os.Stdin)
+			data, err = io.ReadAll(os.Stdin)
 		} else {
-			abi, err = os.ReadFile(input)
+			data, err = os.ReadFile(input)
 		}
 		if err != nil {
 			utils.Fatalf("Failed to read input ABI: %v", err)
 		}
-		abis = append(abis, string(abi))
+		if data, err = abi.ConvertHumanReadable(data); err != nil {
+			utils.Fatalf("Failed to parse input ABI: %v", err)
+		}
+		abis = append(abis, string(data))
 
 		var bin []byte
 		if binFile := c.String(binFlag.Name); binFile != "" {
@@ -205,18 +214,18 @@ func abigen(c *cli.Context) error {
 	}
 	// Extract all aliases from the flags
 	if c.IsSet(aliasFlag.Name) {
-		// We support multi-versions for aliasing
-		// e.g.
-		//      foo=bar,foo2=bar2
-		//      foo:bar,foo2:bar2
-		re := regexp.MustCompile(`(?:(\w+)[:=](\w+))`)
-		submatches := re.FindAllStringSubmatch(c.String(aliasFlag.Name), -1)
-		for _, match := range submatches {
-			aliases[match[1]] = match[2]
-		}
+		aliases = parseAliases(c.String(aliasFlag.Name))
 	}
 	// Generate the contract binding
-	code, err := bind.Bind(types, abis, bins, sigs, c.String(pkgFlag.Name), lang, libs, aliases)
+	var (
+		code string
+		err  error
+	)
+	if c.Bool(v2Flag.Name) {
+		code, err = bind.BindV2(types, abis, bins, c.String(pkgFlag.Name), libs, aliases)
+	} else {
+		code, err = bind.Bind(types, abis, bins, sigs, c.String(pkgFlag.Name), lang, libs, aliases)
+	}
 	if err != nil {
 		utils.Fatalf("Failed to generate ABI binding: %v", err)
 	}
@@ -231,6 +240,23 @@ func abigen(c *cli.Context) error {
 	return nil
 }
 
+// aliasPattern matches the aliases of the --alias flag. We support multi-versions
+// for aliasing, and overloads can be renamed by their signature, e.g.
+//
+//	foo=bar,foo2=bar2
+//	foo:bar,foo2:bar2
+//	foo(uint256,address)=bar
+var aliasPattern = regexp.MustCompile(`(?:(\w+(?:\([\w,()\[\]]*\))?)[:=](\w+))`)
+
+// parseAliases parses the value of the --alias flag.
+func parseAliases(value string) map[string]string {
+	aliases := make(map[string]string)
+	for _, match := range aliasPattern.FindAllStringSubmatch(value, -1) {
+		aliases[match[1]] = match[2]
+	}
+	return aliases
+}
+
 func main() {
 	log.SetDefault(log.NewLogger(log.NewTerminalHandlerWithLevel(os.Stderr, log.LevelInfo, true)))
 
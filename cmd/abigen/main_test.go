@@ -0,0 +1,38 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseAliases(t *testing.T) {
+	t.Parallel()
+	for _, tt := range []struct {
+		value string
+		want  map[string]string
+	}{
+		{"foo=bar,foo2=bar2", map[string]string{"foo": "bar", "foo2": "bar2"}},
+		{"foo:bar, foo2:bar2", map[string]string{"foo": "bar", "foo2": "bar2"}},
+		{"foo(uint256,address)=bar,foo=baz", map[string]string{"foo(uint256,address)": "bar", "foo": "baz"}},
+		{"foo((uint256,bytes32)[],uint8)=bar", map[string]string{"foo((uint256,bytes32)[],uint8)": "bar"}},
+	} {
+		assert.Equal(t, tt.want, parseAliases(tt.value), tt.value)
+	}
+}
@@ -21,6 +21,8 @@ import (
 	"context"
 	"crypto/rand"
 	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
 	"encoding/hex"
 	"encoding/json"
 	"errors"
@@ -28,6 +30,7 @@ import (
 	"io"
 	"math/big"
 	"net"
+	"net/http"
 	"os"
 	"os/signal"
 	"path/filepath"
@@ -122,9 +125,50 @@ var (
 		Usage: "File used to emit audit logs. Set to \"\" to disable",
 		Value: "audit.log",
 	}
+	tlsAddrFlag = &cli.StringFlag{
+		Name:  "tls.addr",
+		Usage: "Listening address (host:port) of the mutually authenticated TLS endpoint of the external API, disabled if empty",
+	}
+	tlsCertFlag = &cli.StringFlag{
+		Name:  "tls.cert",
+		Usage: "PEM encoded server certificate of the TLS endpoint",
+	}
+	tlsKeyFlag = &cli.StringFlag{
+		Name:  "tls.key",
+		Usage: "PEM encoded server private key of the TLS endpoint",
+	}
+	tlsClientCAFlag = &cli.StringFlag{
+		Name:  "tls.clientca",
+		Usage: "PEM encoded certificate authorities client certificates of the TLS endpoint are verified with",
+	}
+	tlsClientsFlag = &cli.StringFlag{
+		Name:  "tls.clients",
+		Usage: "JSON file mapping client certificates of the TLS endpoint to their permitted methods and accounts",
+	}
+	approvalThresholdFlag = &cli.IntFlag{
+		Name:  "approvals.threshold",
+		Usage: "Number of approvers which need to approve signing requests, disabled if zero",
+	}
+	approvalApproversFlag = &cli.StringFlag{
+		Name:  "approvals.approvers",
+		Usage: "Comma separated names of the approvers, i.e. the approver API client names and/or 'ui' for the UI",
+	}
+	approvalAccountsFlag = &cli.StringFlag{
+		Name:  "approvals.accounts",
+		Usage: "Comma separated accounts whose signing requests need approvals, all if empty",
+	}
+	approvalExpiryFlag = &cli.DurationFlag{
+		Name:  "approvals.expiry",
+		Usage: "Time after which signing requests without enough approvals are rejected",
+		Value: 10 * time.Minute,
+	}
+	approvalAddrFlag = &cli.StringFlag{
+		Name:  "approvals.addr",
+		Usage: "Listening address (host:port) of the approver API, served over mutual TLS with the tls.* settings, disabled if empty",
+	}
 	ruleFlag = &cli.StringFlag{
 		Name:  "rules",
-		Usage: "Path to the rule file to auto-authorize requests with",
+		Usage: "Path to the rule file to auto-authorize requests with (javascript, or a YAML/JSON policy)",
 	}
 	stdiouiFlag = &cli.BoolFlag{
 		Name: "stdio-ui",
@@ -277,6 +321,16 @@ func init() {
 		utils.IPCPathFlag,
 		utils.HTTPEnabledFlag,
 		rpcPortFlag,
+		tlsAddrFlag,
+		tlsCertFlag,
+		tlsKeyFlag,
+		tlsClientCAFlag,
+		tlsClientsFlag,
+		approvalThresholdFlag,
+		approvalApproversFlag,
+		approvalAccountsFlag,
+		approvalExpiryFlag,
+		approvalAddrFlag,
 		signerSecretFlag,
 		customDBFlag,
 		auditLogFlag,
@@ -601,6 +655,55 @@ Make sure to backup keystore and passwords in a safe location.`,
 	return nil
 }
 
+// startTLSEndpoint serves the given APIs over TLS, requiring clients to
+// authenticate with a certificate configured in the clients file.
+func startTLSEndpoint(c *cli.Context, endpoint string, apis []rpc.API, timeouts rpc.HTTPTimeouts) (*http.Server, net.Addr, error) {
+	clients, err := core.LoadClients(c.String(tlsClientsFlag.Name))
+	if err != nil {
+		return nil, nil, err
+	}
+	cert, err := tls.LoadX509KeyPair(c.String(tlsCertFlag.Name), c.String(tlsKeyFlag.Name))
+	if err != nil {
+		return nil, nil, err
+	}
+	caPEM, err := os.ReadFile(c.String(tlsClientCAFlag.Name))
+	if err != nil {
+		return nil, nil, err
+	}
+	clientCAs := x509.NewCertPool()
+	if !clientCAs.AppendCertsFromPEM(caPEM) {
+		return nil, nil, errors.New("no certificates in client CA file")
+	}
+	modules := make([]string, 0, len(apis))
+	for _, api := range apis {
+		modules = append(modules, api.Namespace)
+	}
+	srv := rpc.NewServer()
+	srv.SetBatchLimits(node.DefaultConfig.BatchRequestLimit, node.DefaultConfig.BatchResponseMaxSize)
+	if err := node.RegisterApis(apis, modules, srv); err != nil {
+		return nil, nil, err
+	}
+	listener, err := tls.Listen("tcp", endpoint, &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		ClientCAs:    clientCAs,
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		MinVersion:   tls.VersionTLS12,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	node.CheckTimeouts(&timeouts)
+	httpSrv := &http.Server{
+		Handler:           core.NewClientAuthHandler(clients, srv),
+		ReadTimeout:       timeouts.ReadTimeout,
+		ReadHeaderTimeout: timeouts.ReadHeaderTimeout,
+		WriteTimeout:      timeouts.WriteTimeout,
+		IdleTimeout:       timeouts.IdleTimeout,
+	}
+	go httpSrv.Serve(listener)
+	return httpSrv, listener.Addr(), nil
+}
+
 // ipcEndpoint resolves an IPC endpoint based on a configured value, taking into
 // account the set data folders as well as the designated platform we're currently
 // running on.
@@ -681,18 +784,56 @@ func signer(c *cli.Context) error {
 				if storedShasum != foundShaSum {
 					log.Warn("Rule hash not attested, disabling", "hash", foundShaSum, "attested", storedShasum)
 				} else {
-					// Initialize rules
-					ruleEngine, err := rules.NewRuleEvaluator(ui, jsStorage)
-					if err != nil {
-						utils.Fatalf(err.Error())
+					// Initialize rules, declarative policies are YAML or JSON files
+					switch strings.ToLower(filepath.Ext(ruleFile)) {
+					case ".json", ".yaml", ".yml":
+						policy, err := rules.ParsePolicy(ruleJS)
+						if err != nil {
+							utils.Fatalf("Failed to load policy: %v", err)
+						}
+						policyEngine, err := rules.NewPolicyEvaluator(ui, policy, jsStorage)
+						if err != nil {
+							utils.Fatalf(err.Error())
+						}
+						ui = policyEngine
+						log.Info("Policy engine configured", "file", ruleFile, "rules", len(policy.Rules), "dryrun", policy.DryRun)
+					default:
+						ruleEngine, err := rules.NewRuleEvaluator(ui, jsStorage)
+						if err != nil {
+							utils.Fatalf(err.Error())
+						}
+						ruleEngine.Init(string(ruleJS))
+						ui = ruleEngine
+						log.Info("Rule engine configured", "file", c.String(ruleFlag.Name))
 					}
-					ruleEngine.Init(string(ruleJS))
-					ui = ruleEngine
-					log.Info("Rule engine configured", "file", c.String(ruleFlag.Name))
 				}
 			}
 		}
 	}
+	// Hold signing requests until enough approvers approve them. This wraps the
+	// rule engine, so rules cannot approve these requests on their own, while
+	// approved requests still need to pass the rules.
+	var approvals *core.ApprovalQueue
+	if threshold := c.Int(approvalThresholdFlag.Name); threshold > 0 {
+		var accounts []common.Address
+		for _, account := range utils.SplitAndTrim(c.String(approvalAccountsFlag.Name)) {
+			if !common.IsHexAddress(account) {
+				utils.Fatalf("Invalid approval account %q", account)
+			}
+			accounts = append(accounts, common.HexToAddress(account))
+		}
+		approvals, err = core.NewApprovalQueue(ui, core.ApprovalConfig{
+			Threshold: threshold,
+			Approvers: utils.SplitAndTrim(c.String(approvalApproversFlag.Name)),
+			Accounts:  accounts,
+			Expiry:    c.Duration(approvalExpiryFlag.Name),
+		})
+		if err != nil {
+			utils.Fatalf("Failed to configure approvals: %v", err)
+		}
+		ui = approvals
+		log.Info("Multi-approval configured", "threshold", threshold, "approvers", c.String(approvalApproversFlag.Name), "accounts", len(accounts))
+	}
 	var (
 		chainId  = c.Int64(chainIdFlag.Name)
 		ksLoc    = c.String(keystoreFlag.Name)
@@ -712,18 +853,29 @@ func signer(c *cli.Context) error {
 	ui.RegisterUIServer(core.NewUIServerAPI(apiImpl))
 	api = apiImpl
 
+	// Enforce the permissions of the clients of the TLS endpoint. This happens
+	// before audit logging, so rejected requests are logged too.
+	if c.String(tlsAddrFlag.Name) != "" {
+		api = core.NewClientFilter(api)
+	}
 	// Audit logging
 	if logfile := c.String(auditLogFlag.Name); logfile != "" {
-		api, err = core.NewAuditLogger(logfile, api)
+		auditLogger, err := core.NewAuditLogger(logfile, api)
 		if err != nil {
 			utils.Fatalf(err.Error())
 		}
+		api = auditLogger
+		if approvals != nil {
+			approvals.SetAuditLogger(auditLogger)
+		}
 		log.Info("Audit logs configured", "file", logfile)
 	}
 	// register signer API with server
 	var (
 		extapiURL = "n/a"
+		tlsapiURL = "n/a"
 		ipcapiURL = "n/a"
+		approvURL = "n/a"
 	)
 	rpcAPI := []rpc.API{
 		{
@@ -731,6 +883,12 @@ func signer(c *cli.Context) error {
 			Service:   api,
 		},
 	}
+	// Signing requests block while pending approvals, so the response must not
+	// time out before the request expires.
+	timeouts := rpc.DefaultHTTPTimeouts
+	if approvals != nil {
+		timeouts.WriteTimeout += c.Duration(approvalExpiryFlag.Name)
+	}
 	if c.Bool(utils.HTTPEnabledFlag.Name) {
 		vhosts := utils.SplitAndTrim(c.String(utils.HTTPVirtualHostsFlag.Name))
 		cors := utils.SplitAndTrim(c.String(utils.HTTPCORSDomainFlag.Name))
@@ -748,7 +906,7 @@ func signer(c *cli.Context) error {
 
 		// start http server
 		httpEndpoint := net.JoinHostPort(c.String(utils.HTTPListenAddrFlag.Name), fmt.Sprintf("%d", port))
-		httpServer, addr, err := node.StartHTTPEndpoint(httpEndpoint, rpc.DefaultHTTPTimeouts, handler)
+		httpServer, addr, err := node.StartHTTPEndpoint(httpEndpoint, timeouts, handler)
 		if err != nil {
 			utils.Fatalf("Could not start RPC api: %v", err)
 		}
@@ -761,6 +919,41 @@ func signer(c *cli.Context) error {
 			log.Info("HTTP endpoint closed", "url", extapiURL)
 		}()
 	}
+	if endpoint := c.String(tlsAddrFlag.Name); endpoint != "" {
+		tlsServer, addr, err := startTLSEndpoint(c, endpoint, rpcAPI, timeouts)
+		if err != nil {
+			utils.Fatalf("Could not start TLS api: %v", err)
+		}
+		tlsapiURL = fmt.Sprintf("https://%v/", addr)
+		log.Info("TLS endpoint opened", "url", tlsapiURL)
+
+		defer func() {
+			tlsServer.Shutdown(context.Background())
+			log.Info("TLS endpoint closed", "url", tlsapiURL)
+		}()
+	}
+	if endpoint := c.String(approvalAddrFlag.Name); endpoint != "" {
+		if approvals == nil {
+			utils.Fatalf("The approver API requires --%s", approvalThresholdFlag.Name)
+		}
+		approverAPI := []rpc.API{
+			{
+				Namespace: "approver",
+				Service:   core.NewApproverAPI(approvals),
+			},
+		}
+		approverServer, addr, err := startTLSEndpoint(c, endpoint, approverAPI, rpc.DefaultHTTPTimeouts)
+		if err != nil {
+			utils.Fatalf("Could not start approver api: %v", err)
+		}
+		approvURL = fmt.Sprintf("https://%v/", addr)
+		log.Info("Approver endpoint opened", "url", approvURL)
+
+		defer func() {
+			approverServer.Shutdown(context.Background())
+			log.Info("Approver endpoint closed", "url", approvURL)
+		}()
+	}
 	if !c.Bool(utils.IPCDisabledFlag.Name) {
 		givenPath := c.String(utils.IPCPathFlag.Name)
 		ipcapiURL = ipcEndpoint(filepath.Join(givenPath, "clef.ipc"), configDir)
@@ -783,7 +976,9 @@ func signer(c *cli.Context) error {
 			"intapi_version": core.InternalAPIVersion,
 			"extapi_version": core.ExternalAPIVersion,
 			"extapi_http":    extapiURL,
+			"extapi_https":   tlsapiURL,
 			"extapi_ipc":     ipcapiURL,
+			"approver_https": approvURL,
 		}})
 
 	abortChan := make(chan os.Signal, 1)
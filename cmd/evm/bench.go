@@ -0,0 +1,295 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/cmd/evm/internal/bench"
+	"github.com/ethereum/go-ethereum/cmd/utils"
+	"github.com/ethereum/go-ethereum/consensus/beacon"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/tracing"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/internal/flags"
+	"github.com/ethereum/go-ethereum/node"
+	"github.com/ethereum/go-ethereum/tests"
+	"github.com/ethereum/go-ethereum/triedb"
+	"github.com/urfave/cli/v2"
+)
+
+var (
+	BenchDataDirFlag = &cli.StringFlag{
+		Name:     "datadir",
+		Usage:    "Data directory of a geth node to execute blocks from",
+		Category: flags.VMCategory,
+	}
+	BenchBlocksFlag = &cli.StringFlag{
+		Name:     "blocks",
+		Usage:    "Range of blocks to execute from the data directory, as <first>-<last> or a single number",
+		Category: flags.VMCategory,
+	}
+	BenchRunsFlag = &cli.IntFlag{
+		Name:     "runs",
+		Usage:    "Number of times to execute the tests or blocks",
+		Value:    1,
+		Category: flags.VMCategory,
+	}
+	BenchOutputFlag = &cli.StringFlag{
+		Name:     "out",
+		Usage:    "File to write the JSON report to, for use as a later baseline",
+		Category: flags.VMCategory,
+	}
+	BenchBaselineFlag = &cli.StringFlag{
+		Name:     "baseline",
+		Usage:    "JSON report of a previous run to compare against",
+		Category: flags.VMCategory,
+	}
+	BenchThresholdFlag = &cli.Float64Flag{
+		Name:     "threshold",
+		Usage:    "Fail if the ns/gas ratio of an opcode or precompile regressed by more than this percentage against the baseline (0 = never fail)",
+		Category: flags.VMCategory,
+	}
+	BenchMinCountFlag = &cli.Uint64Flag{
+		Name:     "mincount",
+		Usage:    "Minimum number of executions of an opcode or precompile for it to be compared against the baseline",
+		Value:    100,
+		Category: flags.VMCategory,
+	}
+)
+
+var benchCommand = &cli.Command{
+	Action:    benchCmd,
+	Name:      "bench",
+	Usage:     "Measures the execution time per gas of opcodes and precompiles",
+	ArgsUsage: "[<statetest file or directory> ...]",
+	Description: `The bench command executes a suite of state tests (e.g. tests/evm-benchmarks),
+or a range of blocks from the data directory of a node, and reports the time and gas
+spent in every opcode and precompile. The state of the parent of every executed
+block must be available, so only recent blocks can be executed on a non-archive node.
+
+With --baseline, the ns/gas ratios are compared against the report of a previous
+run, written with --out. Measurements include the overhead of tracing, so only
+reports taken on the same machine are comparable.`,
+	Flags: []cli.Flag{
+		BenchDataDirFlag,
+		BenchBlocksFlag,
+		BenchRunsFlag,
+		BenchOutputFlag,
+		BenchBaselineFlag,
+		BenchThresholdFlag,
+		BenchMinCountFlag,
+	},
+}
+
+func benchCmd(ctx *cli.Context) error {
+	var (
+		timer    = bench.NewTimer()
+		runs     = ctx.Int(BenchRunsFlag.Name)
+		baseline *bench.Report
+		err      error
+	)
+	if path := ctx.String(BenchBaselineFlag.Name); path != "" {
+		if baseline, err = bench.LoadReport(path); err != nil {
+			return err
+		}
+	}
+	switch {
+	case ctx.IsSet(BenchDataDirFlag.Name):
+		if ctx.Args().Len() > 0 {
+			return errors.New("state tests and --datadir can't be used together")
+		}
+		first, last, err := parseBlockRange(ctx.String(BenchBlocksFlag.Name))
+		if err != nil {
+			return err
+		}
+		stack, err := node.New(&node.Config{DataDir: ctx.String(BenchDataDirFlag.Name)})
+		if err != nil {
+			return err
+		}
+		defer stack.Close()
+		db, err := stack.OpenDatabaseWithFreezer("chaindata", 512, 256, "", "", true)
+		if err != nil {
+			return err
+		}
+		defer db.Close()
+		tdb := utils.MakeTrieDatabase(ctx, db, false, true, false)
+		defer tdb.Close()
+		if err := benchBlocks(db, tdb, first, last, runs, timer.Hooks()); err != nil {
+			return err
+		}
+	case ctx.Args().Len() > 0:
+		files, err := benchTestFiles(ctx.Args().Slice())
+		if err != nil {
+			return err
+		}
+		for i := 0; i < runs; i++ {
+			for _, file := range files {
+				if err := benchStateTests(file, timer.Hooks()); err != nil {
+					return err
+				}
+			}
+		}
+	default:
+		return errors.New("no state tests or --datadir given")
+	}
+	report := timer.Report()
+	if path := ctx.String(BenchOutputFlag.Name); path != "" {
+		blob, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(path, blob, 0644); err != nil {
+			return err
+		}
+	}
+	if err := report.WriteTable(os.Stdout, baseline); err != nil {
+		return err
+	}
+	if baseline == nil || ctx.Float64(BenchThresholdFlag.Name) <= 0 {
+		return nil
+	}
+	var regressions []string
+	for _, c := range report.Compare(baseline, ctx.Uint64(BenchMinCountFlag.Name)) {
+		if c.Delta() > ctx.Float64(BenchThresholdFlag.Name) {
+			regressions = append(regressions, fmt.Sprintf("%s %s: %.2f -> %.2f ns/gas (%+.1f%%)", c.Kind, c.Name, c.Baseline, c.Current, c.Delta()))
+		}
+	}
+	if len(regressions) > 0 {
+		return fmt.Errorf("%d regressions above %v%%:\n%s", len(regressions), ctx.Float64(BenchThresholdFlag.Name), strings.Join(regressions, "\n"))
+	}
+	return nil
+}
+
+// parseBlockRange parses a block range given as <first>-<last> or a single number.
+func parseBlockRange(s string) (first, last uint64, err error) {
+	if s == "" {
+		return 0, 0, errors.New("--blocks is required with --datadir")
+	}
+	firstStr, lastStr, isRange := strings.Cut(s, "-")
+	if first, err = strconv.ParseUint(firstStr, 10, 64); err != nil {
+		return 0, 0, fmt.Errorf("invalid block range %q", s)
+	}
+	last = first
+	if isRange {
+		if last, err = strconv.ParseUint(lastStr, 10, 64); err != nil {
+			return 0, 0, fmt.Errorf("invalid block range %q", s)
+		}
+	}
+	if first == 0 || last < first {
+		return 0, 0, fmt.Errorf("invalid block range %q", s)
+	}
+	return first, last, nil
+}
+
+// benchTestFiles collects the JSON files in the given files and directories.
+func benchTestFiles(paths []string) ([]string, error) {
+	var files []string
+	for _, path := range paths {
+		err := filepath.WalkDir(path, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if !d.IsDir() && filepath.Ext(path) == ".json" {
+				files = append(files, path)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return files, nil
+}
+
+// benchStateTests executes all subtests of a state test file. Failing subtests are
+// measured nonetheless, and subtests of unsupported forks are skipped.
+func benchStateTests(file string, tracer *tracing.Hooks) error {
+	src, err := os.ReadFile(file)
+	if err != nil {
+		return err
+	}
+	var testsByName map[string]tests.StateTest
+	if err := json.Unmarshal(src, &testsByName); err != nil {
+		return fmt.Errorf("invalid state test %s: %v", file, err)
+	}
+	for _, test := range testsByName {
+		for _, st := range test.Subtests() {
+			config, _, err := tests.GetChainConfig(st.Fork)
+			if err != nil {
+				continue
+			}
+			tracer.OnBlockchainInit(config)
+			tstate, _, _ := test.RunNoVerify(st, vm.Config{Tracer: tracer}, false, rawdb.HashScheme)
+			tstate.Close()
+		}
+	}
+	return nil
+}
+
+// benchBlocks executes the given range of canonical blocks of a chain database,
+// each on the state of its parent.
+func benchBlocks(db ethdb.Database, tdb *triedb.Database, first, last uint64, runs int, tracer *tracing.Hooks) error {
+	config := rawdb.ReadChainConfig(db, rawdb.ReadCanonicalHash(db, 0))
+	if config == nil {
+		return errors.New("chain config not found in database")
+	}
+	hc, err := core.NewHeaderChain(db, config, beacon.New(ethash.NewFaker()), func() bool { return false })
+	if err != nil {
+		return err
+	}
+	var (
+		processor = core.NewStateProcessor(config, hc)
+		sdb       = state.NewDatabase(tdb, nil)
+	)
+	tracer.OnBlockchainInit(config)
+	for i := 0; i < runs; i++ {
+		for number := first; number <= last; number++ {
+			block := rawdb.ReadBlock(db, rawdb.ReadCanonicalHash(db, number), number)
+			if block == nil {
+				return fmt.Errorf("block %d not found", number)
+			}
+			parent := hc.GetHeader(block.ParentHash(), number-1)
+			if parent == nil {
+				return fmt.Errorf("parent of block %d not found", number)
+			}
+			statedb, err := state.New(parent.Root, sdb)
+			if err != nil {
+				return fmt.Errorf("state of block %d not available: %v", number-1, err)
+			}
+			result, err := processor.Process(block, statedb, vm.Config{Tracer: tracer})
+			if err != nil {
+				return fmt.Errorf("block %d: %v", number, err)
+			}
+			if result.GasUsed != block.GasUsed() {
+				return fmt.Errorf("block %d: gas used %d, expected %d", number, result.GasUsed, block.GasUsed())
+			}
+		}
+	}
+	return nil
+}
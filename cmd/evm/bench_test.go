@@ -0,0 +1,95 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/cmd/evm/internal/bench"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+func TestBenchBlocks(t *testing.T) {
+	// The contract calls the identity precompile.
+	contract := common.HexToAddress("0xc0de")
+	genesis := &core.Genesis{
+		Config: params.TestChainConfig,
+		Alloc: types.GenesisAlloc{
+			testAddr: {Balance: big.NewInt(params.Ether)},
+			contract: {Code: common.FromHex("60006000600060006004" + "5afa00")},
+		},
+	}
+	signer := types.LatestSigner(genesis.Config)
+	_, blocks, _ := core.GenerateChainWithGenesis(genesis, ethash.NewFaker(), 3, func(i int, gen *core.BlockGen) {
+		gen.AddTx(types.MustSignNewTx(testKey, signer, &types.LegacyTx{
+			Nonce:    gen.TxNonce(testAddr),
+			To:       &contract,
+			Gas:      100_000,
+			GasPrice: gen.BaseFee(),
+		}))
+	})
+	db := rawdb.NewMemoryDatabase()
+	cacheConfig := core.DefaultCacheConfigWithScheme(rawdb.HashScheme)
+	cacheConfig.TrieDirtyDisabled = true // keep the state of all blocks
+	chain, err := core.NewBlockChain(db, cacheConfig, genesis, nil, ethash.NewFaker(), vm.Config{}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer chain.Stop()
+	if _, err := chain.InsertChain(blocks); err != nil {
+		t.Fatal(err)
+	}
+
+	timer := bench.NewTimer()
+	if err := benchBlocks(db, chain.TrieDB(), 2, 3, 2, timer.Hooks()); err != nil {
+		t.Fatal(err)
+	}
+	report := timer.Report()
+	if e := report.Precompiles["IDENTITY"]; e == nil || e.Count != 4 || e.Gas != 4*15 {
+		t.Fatalf("wrong identity entry: %+v", e)
+	}
+	if e := report.Opcodes["STATICCALL"]; e == nil || e.Count != 4 {
+		t.Fatalf("wrong STATICCALL entry: %+v", e)
+	}
+	if err := benchBlocks(db, chain.TrieDB(), 4, 4, 1, timer.Hooks()); err == nil {
+		t.Fatal("missing block executed")
+	}
+}
+
+func TestParseBlockRange(t *testing.T) {
+	for _, tt := range []struct {
+		in          string
+		first, last uint64
+	}{{"5", 5, 5}, {"1-10", 1, 10}} {
+		first, last, err := parseBlockRange(tt.in)
+		if err != nil || first != tt.first || last != tt.last {
+			t.Errorf("%q: got %d-%d (err %v), want %d-%d", tt.in, first, last, err, tt.first, tt.last)
+		}
+	}
+	for _, in := range []string{"", "0", "5-4", "a-b", "1-"} {
+		if _, _, err := parseBlockRange(in); err == nil {
+			t.Errorf("%q: invalid range accepted", in)
+		}
+	}
+}
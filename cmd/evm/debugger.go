@@ -0,0 +1,44 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/ethereum/go-ethereum/cmd/evm/internal/debugger"
+	"github.com/ethereum/go-ethereum/common/compiler"
+	"github.com/ethereum/go-ethereum/core/tracing"
+	"github.com/urfave/cli/v2"
+)
+
+// newDebugger creates the interactive debugger if requested by the --debugger flag.
+// Commands are read from stdin. It returns nil if the debugger is not enabled.
+func newDebugger(ctx *cli.Context) (*tracing.Hooks, error) {
+	if !ctx.Bool(DebuggerFlag.Name) {
+		return nil, nil
+	}
+	var info *compiler.DebugInfo
+	if path := ctx.String(DebuggerSolcFlag.Name); path != "" {
+		var err error
+		if info, err = compiler.LoadDebugInfo(path); err != nil {
+			return nil, fmt.Errorf("failed to load source maps: %v", err)
+		}
+	}
+	fmt.Fprintln(os.Stdout, "Interactive EVM debugger, type 'help' for a list of commands.")
+	return debugger.New(os.Stdin, os.Stdout, info).Hooks(), nil
+}
@@ -0,0 +1,172 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/cmd/evm/internal/fuzzdiff"
+	"github.com/ethereum/go-ethereum/internal/flags"
+	"github.com/urfave/cli/v2"
+)
+
+var (
+	FuzzExternalFlag = &cli.StringFlag{
+		Name:     "external",
+		Usage:    "Command line of the t8n to compare against, e.g. \"evmone-t8n\" or \"ethereum-spec-evm t8n\"",
+		Category: flags.VMCategory,
+	}
+	FuzzSeedFlag = &cli.Int64Flag{
+		Name:     "seed",
+		Usage:    "Seed of the first generated case, consecutive cases use consecutive seeds (default: current time)",
+		Category: flags.VMCategory,
+	}
+	FuzzIterationsFlag = &cli.IntFlag{
+		Name:     "iterations",
+		Usage:    "Number of cases to generate (0 = unlimited)",
+		Value:    1000,
+		Category: flags.VMCategory,
+	}
+	FuzzForksFlag = &cli.StringFlag{
+		Name:     "forks",
+		Usage:    "Comma separated list of forks to generate cases for",
+		Value:    strings.Join(fuzzdiff.DefaultForks, ","),
+		Category: flags.VMCategory,
+	}
+	FuzzWorkDirFlag = &cli.StringFlag{
+		Name:     "workdir",
+		Usage:    "Directory to place the failing cases into (default: a new temporary directory)",
+		Category: flags.VMCategory,
+	}
+	FuzzTraceFlag = &cli.BoolFlag{
+		Name:     "trace",
+		Usage:    "Compare the opcode traces of the transactions, the external t8n must support --trace",
+		Category: flags.VMCategory,
+	}
+	FuzzNoMinimizeFlag = &cli.BoolFlag{
+		Name:     "nominimize",
+		Usage:    "Don't minimize the failing cases",
+		Category: flags.VMCategory,
+	}
+)
+
+var fuzzDiffCommand = &cli.Command{
+	Action: fuzzDiffCmd,
+	Name:   "fuzz-diff",
+	Usage:  "Compares the t8n tool against another t8n implementation on random state transitions",
+	Description: `The fuzz-diff command generates random state transitions (prestate, environment,
+transactions and fork) and executes them with the built-in t8n tool and an external
+t8n command, comparing the rejected transactions, receipts, state root and, with
+--trace, the opcode traces.
+
+The inputs and outputs of every case for which the t8n tools differ are kept in the
+work directory, along with a minimized version of the case. A case is reproduced
+by running fuzz-diff with its seed and --iterations 1. The command fails if any
+differences were found.`,
+	Flags: []cli.Flag{
+		FuzzExternalFlag,
+		FuzzSeedFlag,
+		FuzzIterationsFlag,
+		FuzzForksFlag,
+		FuzzWorkDirFlag,
+		FuzzTraceFlag,
+		FuzzNoMinimizeFlag,
+	},
+}
+
+func fuzzDiffCmd(ctx *cli.Context) error {
+	external := ctx.String(FuzzExternalFlag.Name)
+	if strings.TrimSpace(external) == "" {
+		return errors.New("--external is required")
+	}
+	var (
+		forks      = strings.Split(ctx.String(FuzzForksFlag.Name), ",")
+		iterations = ctx.Int(FuzzIterationsFlag.Name)
+		seed       = time.Now().UnixNano()
+		workdir    = ctx.String(FuzzWorkDirFlag.Name)
+		err        error
+	)
+	if ctx.IsSet(FuzzSeedFlag.Name) {
+		seed = ctx.Int64(FuzzSeedFlag.Name)
+	}
+	if workdir == "" {
+		if workdir, err = os.MkdirTemp("", "fuzz-diff-"); err != nil {
+			return err
+		}
+	} else if err := os.MkdirAll(workdir, 0755); err != nil {
+		return err
+	}
+	differ := &fuzzdiff.Differ{
+		Internal: fuzzdiff.Internal,
+		External: fuzzdiff.Command(external),
+		Trace:    ctx.Bool(FuzzTraceFlag.Name),
+	}
+	fmt.Printf("Fuzzing from seed %d, failing cases are placed into %s\n", seed, workdir)
+
+	var executed, failed int
+	for ; iterations == 0 || executed < iterations; executed++ {
+		caseSeed := seed + int64(executed)
+		c, err := fuzzdiff.Generate(caseSeed, forks)
+		if err != nil {
+			return err
+		}
+		dir := filepath.Join(workdir, fmt.Sprintf("case-%d", caseSeed))
+		diffs, err := differ.Run(dir, c)
+		if err != nil {
+			return err
+		}
+		if len(diffs) == 0 {
+			os.RemoveAll(dir)
+			continue
+		}
+		failed++
+		printCase(fmt.Sprintf("Seed %d", caseSeed), external, dir, c, diffs, differ.Trace)
+		if ctx.Bool(FuzzNoMinimizeFlag.Name) {
+			continue
+		}
+		min, err := differ.Minimize(workdir, c, diffs[0].Kind)
+		if err != nil {
+			return err
+		}
+		dir += "-min"
+		if diffs, err = differ.Run(dir, min); err != nil {
+			return err
+		}
+		printCase("Minimized", external, dir, min, diffs, differ.Trace)
+	}
+	fmt.Printf("Executed %d cases, %d with differences\n", executed, failed)
+	if failed > 0 {
+		return fmt.Errorf("t8n tools differ in %d cases", failed)
+	}
+	return nil
+}
+
+// printCase prints the differences of a case and how to execute it with the
+// external t8n.
+func printCase(title, external, dir string, c *fuzzdiff.Case, diffs []fuzzdiff.Diff, trace bool) {
+	fmt.Printf("%s (%v) differs:\n", title, c)
+	for _, diff := range diffs {
+		fmt.Printf("  %v\n", diff)
+	}
+	args := c.Args(dir, filepath.Join(dir, "external"), trace)
+	fmt.Printf("  reproduce with: %s %s\n", external, strings.Join(args, " "))
+}
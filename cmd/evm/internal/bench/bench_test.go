@@ -0,0 +1,108 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package bench
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm/runtime"
+)
+
+func TestTimer(t *testing.T) {
+	// PUSH1 1 PUSH1 2 ADD POP, then STATICCALL the identity precompile without input.
+	code := common.FromHex("6001600201" + "50" + "60006000600060006004" + "5afa" + "00")
+	addr := common.HexToAddress("0xaaaa")
+	statedb, _ := state.New(types.EmptyRootHash, state.NewDatabaseForTesting())
+	statedb.SetCode(addr, code)
+
+	timer := NewTimer()
+	cfg := &runtime.Config{State: statedb}
+	cfg.EVMConfig.Tracer = timer.Hooks()
+	for i := 0; i < 2; i++ {
+		if _, _, err := runtime.Call(addr, nil, cfg); err != nil {
+			t.Fatal(err)
+		}
+	}
+	report := timer.Report()
+	for name, want := range map[string]Entry{
+		"PUSH1":      {Count: 14, Gas: 42},
+		"ADD":        {Count: 2, Gas: 6},
+		"GAS":        {Count: 2, Gas: 4},
+		"STATICCALL": {Count: 2, Gas: 200}, // warm access, without the callee's gas
+		"STOP":       {Count: 2},
+	} {
+		e := report.Opcodes[name]
+		if e == nil || e.Count != want.Count || e.Gas != want.Gas {
+			t.Errorf("%s: wrong entry %+v, want count %d, gas %d", name, e, want.Count, want.Gas)
+		}
+	}
+	if e := report.Precompiles["IDENTITY"]; e == nil || e.Count != 2 || e.Gas != 30 {
+		t.Errorf("wrong identity entry: %+v", e)
+	}
+	if total := report.Total(); total.Gas != 42+6+4+200+30+2*2 {
+		t.Errorf("wrong total gas %d", total.Gas)
+	}
+}
+
+func TestCompare(t *testing.T) {
+	baseline := &Report{
+		Opcodes: map[string]*Entry{
+			"ADD":    {Count: 1000, Gas: 3000, Nanos: 3000},
+			"MUL":    {Count: 1000, Gas: 5000, Nanos: 10000},
+			"SSTORE": {Count: 10, Gas: 200000, Nanos: 1000},
+		},
+		Precompiles: map[string]*Entry{
+			"ECRECOVER": {Count: 100, Gas: 300000, Nanos: 3000000},
+		},
+	}
+	current := &Report{
+		Opcodes: map[string]*Entry{
+			"ADD":    {Count: 1000, Gas: 3000, Nanos: 4500},
+			"MUL":    {Count: 1000, Gas: 5000, Nanos: 9000},
+			"SSTORE": {Count: 10, Gas: 200000, Nanos: 9000},
+			"SUB":    {Count: 1000, Gas: 3000, Nanos: 3000},
+		},
+		Precompiles: map[string]*Entry{
+			"ECRECOVER": {Count: 100, Gas: 300000, Nanos: 3300000},
+		},
+	}
+	// SSTORE is executed too rarely, SUB is missing from the baseline.
+	changes := current.Compare(baseline, 100)
+	var have []string
+	for _, c := range changes {
+		have = append(have, c.Kind+" "+c.Name)
+	}
+	want := "opcode ADD,precompile ECRECOVER,opcode MUL"
+	if strings.Join(have, ",") != want {
+		t.Fatalf("wrong changes: %v, want %v", have, want)
+	}
+	if d := changes[0].Delta(); d < 49.9 || d > 50.1 {
+		t.Errorf("wrong delta %v", d)
+	}
+
+	var out strings.Builder
+	if err := current.WriteTable(&out, baseline); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "+50.0%") || !strings.Contains(out.String(), "total") {
+		t.Errorf("wrong table:\n%s", out.String())
+	}
+}
@@ -0,0 +1,185 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package bench
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"text/tabwriter"
+	"time"
+)
+
+// Entry holds the measurements of an opcode or precompile.
+type Entry struct {
+	Count    uint64  `json:"count"`
+	Gas      uint64  `json:"gas"`
+	Nanos    uint64  `json:"ns"`
+	NsPerGas float64 `json:"nsPerGas"`
+}
+
+func (e *Entry) add(gas uint64, elapsed time.Duration) {
+	e.Count++
+	e.Gas += gas
+	if elapsed > 0 {
+		e.Nanos += uint64(elapsed)
+	}
+}
+
+// finalize returns a copy of the entry with the derived fields filled in.
+func (e *Entry) finalize() *Entry {
+	cpy := *e
+	if cpy.Gas > 0 {
+		cpy.NsPerGas = float64(cpy.Nanos) / float64(cpy.Gas)
+	}
+	return &cpy
+}
+
+// Report holds the measurements of a benchmark run, by opcode and precompile name.
+type Report struct {
+	Opcodes     map[string]*Entry `json:"opcodes"`
+	Precompiles map[string]*Entry `json:"precompiles"`
+}
+
+// LoadReport reads a report from a JSON file.
+func LoadReport(path string) (*Report, error) {
+	blob, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var r Report
+	if err := json.Unmarshal(blob, &r); err != nil {
+		return nil, fmt.Errorf("invalid report %s: %v", path, err)
+	}
+	return &r, nil
+}
+
+// Total returns the sum of all entries of the report.
+func (r *Report) Total() *Entry {
+	var total Entry
+	for _, m := range []map[string]*Entry{r.Opcodes, r.Precompiles} {
+		for _, e := range m {
+			total.Count += e.Count
+			total.Gas += e.Gas
+			total.Nanos += e.Nanos
+		}
+	}
+	return total.finalize()
+}
+
+// Change is the difference of an entry between a baseline and the current report.
+type Change struct {
+	Kind     string  // "opcode" or "precompile"
+	Name     string  // opcode or precompile name
+	Baseline float64 // ns per gas in the baseline
+	Current  float64 // ns per gas in the current report
+}
+
+// Delta returns the relative change of the ns per gas ratio, in percent.
+func (c Change) Delta() float64 {
+	return (c.Current/c.Baseline - 1) * 100
+}
+
+// Compare returns the changes of the entries present in both reports, ordered by
+// descending delta. Entries executed less than minCount times in either report are
+// skipped, as their measurements are too noisy to be compared.
+func (r *Report) Compare(baseline *Report, minCount uint64) []Change {
+	var changes []Change
+	compare := func(kind string, current, base map[string]*Entry) {
+		for name, e := range current {
+			b, ok := base[name]
+			if !ok || e.Count < minCount || b.Count < minCount || e.Gas == 0 || b.Gas == 0 || b.Nanos == 0 {
+				continue
+			}
+			changes = append(changes, Change{
+				Kind:     kind,
+				Name:     name,
+				Baseline: float64(b.Nanos) / float64(b.Gas),
+				Current:  float64(e.Nanos) / float64(e.Gas),
+			})
+		}
+	}
+	compare("opcode", r.Opcodes, baseline.Opcodes)
+	compare("precompile", r.Precompiles, baseline.Precompiles)
+
+	sort.Slice(changes, func(i, j int) bool {
+		if di, dj := changes[i].Delta(), changes[j].Delta(); di != dj {
+			return di > dj
+		}
+		return changes[i].Name < changes[j].Name
+	})
+	return changes
+}
+
+// WriteTable writes the report as a table, ordered by descending time. If a
+// baseline is given, the change of the ns per gas ratio is added as a column.
+func (r *Report) WriteTable(w io.Writer, baseline *Report) error {
+	type row struct {
+		kind, name string
+		e, base    *Entry
+	}
+	var rows []row
+	add := func(kind string, current, base map[string]*Entry) {
+		for name, e := range current {
+			rows = append(rows, row{kind, name, e, base[name]})
+		}
+	}
+	var baseOpcodes, basePrecompiles map[string]*Entry
+	if baseline != nil {
+		baseOpcodes, basePrecompiles = baseline.Opcodes, baseline.Precompiles
+	}
+	add("opcode", r.Opcodes, baseOpcodes)
+	add("precompile", r.Precompiles, basePrecompiles)
+
+	sort.Slice(rows, func(i, j int) bool {
+		if rows[i].e.Nanos != rows[j].e.Nanos {
+			return rows[i].e.Nanos > rows[j].e.Nanos
+		}
+		return rows[i].name < rows[j].name
+	})
+
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', tabwriter.AlignRight)
+	header := "KIND\tNAME\tCOUNT\tGAS\tTIME\tNS/GAS\t"
+	if baseline != nil {
+		header += "BASELINE\tDELTA\t"
+	}
+	fmt.Fprintln(tw, header)
+	for _, row := range rows {
+		fmt.Fprintf(tw, "%s\t%s\t%d\t%d\t%v\t%s\t", row.kind, row.name, row.e.Count, row.e.Gas, time.Duration(row.e.Nanos), nsPerGas(row.e))
+		if baseline != nil {
+			if row.base == nil || row.base.Gas == 0 || row.base.Nanos == 0 || row.e.Gas == 0 {
+				fmt.Fprint(tw, "-\t-\t")
+			} else {
+				c := Change{Baseline: float64(row.base.Nanos) / float64(row.base.Gas), Current: float64(row.e.Nanos) / float64(row.e.Gas)}
+				fmt.Fprintf(tw, "%.2f\t%+.1f%%\t", c.Baseline, c.Delta())
+			}
+		}
+		fmt.Fprintln(tw)
+	}
+	total := r.Total()
+	fmt.Fprintf(tw, "\ttotal\t%d\t%d\t%v\t%s\t\n", total.Count, total.Gas, time.Duration(total.Nanos), nsPerGas(total))
+	return tw.Flush()
+}
+
+func nsPerGas(e *Entry) string {
+	if e.Gas == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%.2f", float64(e.Nanos)/float64(e.Gas))
+}
@@ -0,0 +1,198 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+// Package bench measures the execution time of EVM opcodes and precompiles.
+package bench
+
+import (
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/tracing"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// precompileNames are the names under which precompiles are reported.
+var precompileNames = map[common.Address]string{
+	common.BytesToAddress([]byte{0x01}): "ECRECOVER",
+	common.BytesToAddress([]byte{0x02}): "SHA256",
+	common.BytesToAddress([]byte{0x03}): "RIPEMD160",
+	common.BytesToAddress([]byte{0x04}): "IDENTITY",
+	common.BytesToAddress([]byte{0x05}): "MODEXP",
+	common.BytesToAddress([]byte{0x06}): "BN254_ADD",
+	common.BytesToAddress([]byte{0x07}): "BN254_MUL",
+	common.BytesToAddress([]byte{0x08}): "BN254_PAIRING",
+	common.BytesToAddress([]byte{0x09}): "BLAKE2F",
+	common.BytesToAddress([]byte{0x0a}): "KZG_POINT_EVALUATION",
+	common.BytesToAddress([]byte{0x0b}): "BLS12_G1ADD",
+	common.BytesToAddress([]byte{0x0c}): "BLS12_G1MUL",
+	common.BytesToAddress([]byte{0x0d}): "BLS12_G1MSM",
+	common.BytesToAddress([]byte{0x0e}): "BLS12_G2ADD",
+	common.BytesToAddress([]byte{0x0f}): "BLS12_G2MUL",
+	common.BytesToAddress([]byte{0x10}): "BLS12_G2MSM",
+	common.BytesToAddress([]byte{0x11}): "BLS12_PAIRING_CHECK",
+	common.BytesToAddress([]byte{0x12}): "BLS12_MAP_FP_TO_G1",
+	common.BytesToAddress([]byte{0x13}): "BLS12_MAP_FP2_TO_G2",
+}
+
+// PrecompileName returns the name under which a precompile is reported.
+func PrecompileName(addr common.Address) string {
+	if name, ok := precompileNames[addr]; ok {
+		return name
+	}
+	return addr.Hex()
+}
+
+// Timer is a tracer measuring the time and gas spent in each opcode and precompile.
+//
+// The time of an opcode is measured from its start until the next event of its call
+// frame, excluding the time spent in child frames. Its gas is the gas it consumed
+// itself, so the gas forwarded by calls is attributed to the callee. Note that the
+// measurements include the overhead of tracing, which is significant for cheap
+// opcodes. Only measurements taken on the same machine are comparable.
+type Timer struct {
+	config      *params.ChainConfig
+	precompiles map[common.Address]bool
+	frames      []*timerFrame
+	opcodes     map[vm.OpCode]*Entry
+	calls       map[common.Address]*Entry // precompile calls
+}
+
+type timerFrame struct {
+	to       common.Address
+	start    time.Time
+	startGas uint64
+	executed bool // whether any opcode was executed
+
+	// The pending opcode is settled at the next event of the frame.
+	pending   bool
+	op        vm.OpCode
+	opStart   time.Time
+	opGas     uint64        // gas available before the opcode
+	childGas  uint64        // gas used by child frames of the opcode
+	childTime time.Duration // time spent in child frames of the opcode
+}
+
+// NewTimer creates a timer.
+func NewTimer() *Timer {
+	return &Timer{
+		opcodes: make(map[vm.OpCode]*Entry),
+		calls:   make(map[common.Address]*Entry),
+	}
+}
+
+// Hooks returns the tracing hooks of the timer. The OnBlockchainInit hook should
+// be invoked with the chain config before tracing, to determine the precompiles
+// active at each transaction. Otherwise, all known precompiles are assumed to be
+// active.
+func (t *Timer) Hooks() *tracing.Hooks {
+	return &tracing.Hooks{
+		OnBlockchainInit: func(config *params.ChainConfig) { t.config = config },
+		OnTxStart:        t.onTxStart,
+		OnEnter:          t.onEnter,
+		OnExit:           t.onExit,
+		OnOpcode:         t.onOpcode,
+	}
+}
+
+func (t *Timer) onTxStart(env *tracing.VMContext, tx *types.Transaction, from common.Address) {
+	addrs := vm.PrecompiledAddressesPrague
+	if t.config != nil {
+		addrs = vm.ActivePrecompiles(t.config.Rules(env.BlockNumber, env.Random != nil, env.Time))
+	}
+	t.precompiles = make(map[common.Address]bool, len(addrs))
+	for _, addr := range addrs {
+		t.precompiles[addr] = true
+	}
+	t.frames = t.frames[:0]
+}
+
+func (t *Timer) onEnter(depth int, typ byte, from common.Address, to common.Address, input []byte, gas uint64, value *big.Int) {
+	t.frames = append(t.frames, &timerFrame{to: to, start: time.Now(), startGas: gas})
+}
+
+func (t *Timer) onExit(depth int, output []byte, gasUsed uint64, err error, reverted bool) {
+	now := time.Now()
+	if len(t.frames) == 0 {
+		return
+	}
+	f := t.frames[len(t.frames)-1]
+	t.frames = t.frames[:len(t.frames)-1]
+
+	if f.pending {
+		t.settle(f, f.startGas-min(gasUsed, f.startGas), now)
+	}
+	elapsed := now.Sub(f.start)
+	if !f.executed && t.precompiles[f.to] {
+		e := t.calls[f.to]
+		if e == nil {
+			e = new(Entry)
+			t.calls[f.to] = e
+		}
+		e.add(gasUsed, elapsed)
+	}
+	if len(t.frames) > 0 {
+		parent := t.frames[len(t.frames)-1]
+		parent.childGas += gasUsed
+		parent.childTime += elapsed
+	}
+}
+
+func (t *Timer) onOpcode(pc uint64, op byte, gas, cost uint64, scope tracing.OpContext, rData []byte, depth int, err error) {
+	now := time.Now()
+	if len(t.frames) == 0 {
+		return
+	}
+	f := t.frames[len(t.frames)-1]
+	if f.pending {
+		t.settle(f, gas, now)
+	}
+	f.executed, f.pending = true, true
+	f.op, f.opStart, f.opGas, f.childGas, f.childTime = vm.OpCode(op), now, gas, 0, 0
+}
+
+// settle records the pending opcode of a frame, given the gas remaining after it.
+func (t *Timer) settle(f *timerFrame, remaining uint64, now time.Time) {
+	var gas uint64
+	if used := f.opGas - min(remaining, f.opGas); used > f.childGas {
+		gas = used - f.childGas
+	}
+	e := t.opcodes[f.op]
+	if e == nil {
+		e = new(Entry)
+		t.opcodes[f.op] = e
+	}
+	e.add(gas, now.Sub(f.opStart)-f.childTime)
+	f.pending = false
+}
+
+// Report returns the measurements collected so far.
+func (t *Timer) Report() *Report {
+	r := &Report{
+		Opcodes:     make(map[string]*Entry, len(t.opcodes)),
+		Precompiles: make(map[string]*Entry, len(t.calls)),
+	}
+	for op, e := range t.opcodes {
+		r.Opcodes[op.String()] = e.finalize()
+	}
+	for addr, e := range t.calls {
+		r.Precompiles[PrecompileName(addr)] = e.finalize()
+	}
+	return r
+}
@@ -0,0 +1,172 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package debugger
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/core/vm"
+)
+
+type breakpointKind string
+
+const (
+	bpPC     breakpointKind = "pc"
+	bpOp     breakpointKind = "op"
+	bpDepth  breakpointKind = "depth"
+	bpSstore breakpointKind = "sstore"
+	bpLine   breakpointKind = "line"
+)
+
+// breakpoint is a condition suspending the execution.
+type breakpoint struct {
+	id   int
+	kind breakpointKind
+
+	pc    uint64
+	addr  *common.Address // contract of a pc breakpoint, any if nil
+	op    vm.OpCode
+	depth int
+	slot  *common.Hash // slot of a sstore breakpoint, any if nil
+	file  string
+	line  int
+}
+
+// parseBreakpoint parses the arguments of the break command.
+func parseBreakpoint(args []string) (*breakpoint, error) {
+	if len(args) == 0 {
+		return nil, errors.New("missing breakpoint kind")
+	}
+	var (
+		b  = &breakpoint{kind: breakpointKind(args[0])}
+		ok bool
+	)
+	args = args[1:]
+	switch b.kind {
+	case bpPC:
+		if len(args) < 1 || len(args) > 2 {
+			return nil, errors.New("usage: break pc <pc> [address]")
+		}
+		if b.pc, ok = math.ParseUint64(args[0]); !ok {
+			return nil, fmt.Errorf("invalid pc %q", args[0])
+		}
+		if len(args) == 2 {
+			if !common.IsHexAddress(args[1]) {
+				return nil, fmt.Errorf("invalid address %q", args[1])
+			}
+			addr := common.HexToAddress(args[1])
+			b.addr = &addr
+		}
+	case bpOp:
+		if len(args) != 1 {
+			return nil, errors.New("usage: break op <opcode>")
+		}
+		name := strings.ToUpper(args[0])
+		b.op = vm.StringToOp(name)
+		if b.op.String() != name {
+			return nil, fmt.Errorf("unknown opcode %q", args[0])
+		}
+	case bpDepth:
+		if len(args) != 1 {
+			return nil, errors.New("usage: break depth <depth>")
+		}
+		depth, err := strconv.Atoi(args[0])
+		if err != nil || depth < 1 {
+			return nil, fmt.Errorf("invalid depth %q", args[0])
+		}
+		b.depth = depth
+	case bpSstore:
+		if len(args) > 1 {
+			return nil, errors.New("usage: break sstore [slot]")
+		}
+		if len(args) == 1 {
+			slot, ok := parseHash(args[0])
+			if !ok {
+				return nil, fmt.Errorf("invalid slot %q", args[0])
+			}
+			b.slot = &slot
+		}
+	case bpLine:
+		if len(args) != 1 {
+			return nil, errors.New("usage: break line <file>:<line>")
+		}
+		i := strings.LastIndexByte(args[0], ':')
+		if i <= 0 {
+			return nil, fmt.Errorf("invalid source line %q", args[0])
+		}
+		line, err := strconv.Atoi(args[0][i+1:])
+		if err != nil || line < 1 {
+			return nil, fmt.Errorf("invalid source line %q", args[0])
+		}
+		b.file, b.line = args[0][:i], line
+	default:
+		return nil, fmt.Errorf("unknown breakpoint kind %q", b.kind)
+	}
+	return b, nil
+}
+
+// matches reports whether the breakpoint is hit by the step s in frame f.
+func (b *breakpoint) matches(d *Debugger, f *frame, s *step) bool {
+	switch b.kind {
+	case bpPC:
+		return s.pc == b.pc && (b.addr == nil || *b.addr == s.scope.Address())
+	case bpOp:
+		return s.op == b.op
+	case bpDepth:
+		return s.depth == b.depth && f != nil && f.fresh
+	case bpSstore:
+		if s.op != vm.SSTORE {
+			return false
+		}
+		stack := s.scope.StackData()
+		return b.slot == nil || (len(stack) > 0 && common.Hash(stack[len(stack)-1].Bytes32()) == *b.slot)
+	case bpLine:
+		file, line := d.sourceLine(f, s.pc)
+		if file == nil || line != b.line || line == f.lastLine {
+			return false
+		}
+		return file.Name == b.file || strings.HasSuffix(file.Name, "/"+b.file)
+	}
+	return false
+}
+
+func (b *breakpoint) String() string {
+	switch b.kind {
+	case bpPC:
+		if b.addr != nil {
+			return fmt.Sprintf("pc %d in %v", b.pc, *b.addr)
+		}
+		return fmt.Sprintf("pc %d", b.pc)
+	case bpOp:
+		return fmt.Sprintf("opcode %v", b.op)
+	case bpDepth:
+		return fmt.Sprintf("call depth %d", b.depth)
+	case bpSstore:
+		if b.slot != nil {
+			return fmt.Sprintf("storage write to %v", *b.slot)
+		}
+		return "storage write"
+	case bpLine:
+		return fmt.Sprintf("line %s:%d", b.file, b.line)
+	}
+	return string(b.kind)
+}
@@ -0,0 +1,522 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+// Package debugger implements an interactive, command line driven EVM debugger.
+//
+// The debugger is a tracer: it is attached to the EVM through tracing.Hooks and
+// suspends execution inside the opcode hook while it waits for commands. It can
+// therefore be used with anything that accepts a tracer, like core/vm/runtime or
+// state test execution.
+package debugger
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math/big"
+	"sort"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/compiler"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/core/tracing"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+)
+
+// mode defines when execution is suspended next.
+type mode int
+
+const (
+	modeStep     mode = iota // stop at the next opcode
+	modeNext                 // stop at the next opcode in the same or an outer frame
+	modeOut                  // stop at the next opcode in an outer frame
+	modeContinue             // stop at breakpoints only
+	modeDetached             // never stop again
+)
+
+// frame is a call frame of the execution.
+type frame struct {
+	typ      vm.OpCode
+	from, to common.Address
+	fresh    bool // set until the first opcode of the frame is executed
+
+	// Source map of the frame's code, resolved at the first opcode.
+	resolved bool
+	contract *compiler.ContractDebugInfo
+	srcmap   *compiler.SourceMap
+	lastLine int // source line of the previous opcode, for line breakpoints
+}
+
+// step holds the arguments of the opcode hook where execution is suspended.
+type step struct {
+	pc    uint64
+	op    vm.OpCode
+	gas   uint64
+	cost  uint64
+	scope tracing.OpContext
+	rData []byte
+	depth int
+}
+
+// Debugger is an interactive EVM debugger.
+type Debugger struct {
+	in   *bufio.Scanner
+	out  io.Writer
+	info *compiler.DebugInfo
+
+	mode      mode
+	modeDepth int    // reference depth of modeNext and modeOut
+	lastCmd   string // repeated on empty input
+
+	breakpoints []*breakpoint
+	nextID      int
+
+	state   tracing.StateDB
+	frames  []*frame
+	written map[common.Address]map[common.Hash]struct{} // storage slots written by SSTORE in the transaction
+}
+
+// New creates a debugger reading commands from in and writing to out. The debug
+// info of the executed contracts is optional, if provided, source locations are
+// displayed and line breakpoints can be set.
+func New(in io.Reader, out io.Writer, info *compiler.DebugInfo) *Debugger {
+	return &Debugger{
+		in:      bufio.NewScanner(in),
+		out:     out,
+		info:    info,
+		nextID:  1,
+		written: make(map[common.Address]map[common.Hash]struct{}),
+	}
+}
+
+// Hooks returns the tracing hooks of the debugger.
+func (d *Debugger) Hooks() *tracing.Hooks {
+	return &tracing.Hooks{
+		OnTxStart: d.onTxStart,
+		OnTxEnd:   d.onTxEnd,
+		OnEnter:   d.onEnter,
+		OnExit:    d.onExit,
+		OnOpcode:  d.onOpcode,
+		OnFault:   d.onFault,
+	}
+}
+
+func (d *Debugger) printf(format string, args ...interface{}) {
+	fmt.Fprintf(d.out, format, args...)
+}
+
+func (d *Debugger) onTxStart(env *tracing.VMContext, tx *types.Transaction, from common.Address) {
+	d.state = env.StateDB
+	d.frames = d.frames[:0]
+	clear(d.written)
+	if d.mode != modeDetached {
+		d.printf("Transaction started, block %v, sender %v\n", env.BlockNumber, from)
+	}
+}
+
+func (d *Debugger) onTxEnd(receipt *types.Receipt, err error) {
+	if d.mode == modeDetached {
+		return
+	}
+	switch {
+	case err != nil:
+		d.printf("Transaction failed: %v\n", err)
+	case receipt != nil:
+		d.printf("Transaction finished, gas used %d\n", receipt.GasUsed)
+	default:
+		d.printf("Transaction finished\n")
+	}
+}
+
+func (d *Debugger) onEnter(depth int, typ byte, from common.Address, to common.Address, input []byte, gas uint64, value *big.Int) {
+	d.frames = append(d.frames, &frame{typ: vm.OpCode(typ), from: from, to: to, fresh: true})
+	if depth > 0 && d.verbose(depth) {
+		d.printf("Entering %v frame at depth %d: %v -> %v, gas %d\n", vm.OpCode(typ), depth+1, from, to, gas)
+	}
+}
+
+func (d *Debugger) onExit(depth int, output []byte, gasUsed uint64, err error, reverted bool) {
+	if len(d.frames) > 0 {
+		d.frames = d.frames[:len(d.frames)-1]
+	}
+	if d.mode == modeDetached || (depth > 0 && !d.verbose(depth)) {
+		return
+	}
+	status := "returned"
+	if reverted {
+		status = "reverted"
+	}
+	d.printf("Frame at depth %d %s, gas used %d, output %v", depth+1, status, gasUsed, hexutil.Bytes(output))
+	if err != nil {
+		d.printf(", error: %v", err)
+	}
+	d.printf("\n")
+}
+
+func (d *Debugger) onFault(pc uint64, op byte, gas, cost uint64, scope tracing.OpContext, depth int, err error) {
+	if d.mode != modeDetached {
+		d.printf("Fault at pc %d (%v), depth %d: %v\n", pc, vm.OpCode(op), depth, err)
+	}
+}
+
+// recordWrite remembers the storage slot written by a SSTORE.
+func (d *Debugger) recordWrite(s *step) {
+	stack := s.scope.StackData()
+	if len(stack) == 0 {
+		return
+	}
+	addr := s.scope.Address()
+	if d.written[addr] == nil {
+		d.written[addr] = make(map[common.Hash]struct{})
+	}
+	d.written[addr][common.Hash(stack[len(stack)-1].Bytes32())] = struct{}{}
+}
+
+// verbose reports whether frame changes at the given call depth (0-based, as
+// reported by the enter and exit hooks) are printed.
+func (d *Debugger) verbose(depth int) bool {
+	switch d.mode {
+	case modeStep:
+		return true
+	case modeNext, modeOut:
+		return depth < d.modeDepth
+	}
+	return false
+}
+
+func (d *Debugger) onOpcode(pc uint64, op byte, gas, cost uint64, scope tracing.OpContext, rData []byte, depth int, err error) {
+	if d.mode == modeDetached {
+		return
+	}
+	s := &step{pc: pc, op: vm.OpCode(op), gas: gas, cost: cost, scope: scope, rData: rData, depth: depth}
+	f := d.frame()
+	if f != nil && !f.resolved {
+		f.resolved = true
+		f.contract, f.srcmap = d.info.Find(scope.ContractCode())
+	}
+	var hits []*breakpoint
+	for _, b := range d.breakpoints {
+		if b.matches(d, f, s) {
+			hits = append(hits, b)
+		}
+	}
+	if f != nil {
+		f.fresh = false
+		if file, line := d.sourceLine(f, pc); file != nil {
+			f.lastLine = line
+		}
+	}
+	stop := len(hits) > 0
+	switch d.mode {
+	case modeStep:
+		stop = true
+	case modeNext:
+		stop = stop || depth <= d.modeDepth
+	case modeOut:
+		stop = stop || depth < d.modeDepth
+	}
+	if stop {
+		for _, b := range hits {
+			d.printf("Breakpoint %d hit: %v\n", b.id, b)
+		}
+		d.printStep(s)
+		d.prompt(s)
+	}
+	if s.op == vm.SSTORE {
+		d.recordWrite(s)
+	}
+}
+
+// frame returns the innermost call frame.
+func (d *Debugger) frame() *frame {
+	if len(d.frames) == 0 {
+		return nil
+	}
+	return d.frames[len(d.frames)-1]
+}
+
+// location resolves the source location of pc in the given frame.
+func (d *Debugger) location(f *frame, pc uint64) (*compiler.SourceFile, compiler.SourceLocation, bool) {
+	if f == nil || f.srcmap == nil {
+		return nil, compiler.SourceLocation{}, false
+	}
+	loc, ok := f.srcmap.Lookup(pc)
+	if !ok {
+		return nil, loc, false
+	}
+	file := d.info.File(loc)
+	return file, loc, file != nil
+}
+
+// sourceLine returns the source file and line of pc in the given frame.
+func (d *Debugger) sourceLine(f *frame, pc uint64) (*compiler.SourceFile, int) {
+	file, loc, ok := d.location(f, pc)
+	if !ok {
+		return nil, 0
+	}
+	line, _ := file.Position(loc.Start)
+	return file, line
+}
+
+func (d *Debugger) printStep(s *step) {
+	d.printf("[%d] %v pc=%d %v gas=%d cost=%d\n", s.depth, s.scope.Address(), s.pc, s.op, s.gas, s.cost)
+	f := d.frame()
+	if file, loc, ok := d.location(f, s.pc); ok {
+		line, col := file.Position(loc.Start)
+		d.printf("    at %s:%d:%d (%s)\n", file.Name, line, col, f.contract.Name)
+		if src := strings.TrimSpace(file.Line(line)); src != "" {
+			d.printf("    %s\n", src)
+		}
+	}
+}
+
+// prompt reads and executes commands until execution is resumed.
+func (d *Debugger) prompt(s *step) {
+	for {
+		d.printf("(evm) ")
+		if !d.in.Scan() {
+			// End of input, let the execution run to completion.
+			d.printf("\n")
+			d.mode = modeDetached
+			return
+		}
+		line := strings.TrimSpace(d.in.Text())
+		if line == "" {
+			line = d.lastCmd
+		}
+		d.lastCmd = line
+		if resume := d.execute(s, strings.Fields(line)); resume {
+			return
+		}
+	}
+}
+
+const helpText = `Commands:
+  step, s                    execute the next opcode
+  next, n                    execute the next opcode, stepping over calls
+  out, o                     run until the current frame returns
+  continue, c                run until a breakpoint is hit
+  break, b <kind> [args]     set a breakpoint, kinds are:
+       pc <pc> [address]         program counter, optionally in a contract
+       op <opcode>               opcode name
+       depth <depth>             entering a call frame at the given depth
+       sstore [slot]             storage write, optionally to a slot
+       line <file>:<line>        source line (requires source maps)
+  delete, d <id>             delete a breakpoint
+  breakpoints, bl            list breakpoints
+  stack, st                  show the stack
+  memory, m [offset [size]]  show memory
+  storage, sl [slot]         show a storage slot or all written slots
+  returndata, rd             show the return data of the last call
+  where, bt                  show the call frames
+  list, l                    show the source code around the current location
+  quit, q                    stop debugging, the execution runs to completion
+`
+
+// execute runs a command. It reports whether execution should be resumed.
+func (d *Debugger) execute(s *step, args []string) bool {
+	if len(args) == 0 {
+		return false
+	}
+	switch cmd, args := args[0], args[1:]; cmd {
+	case "step", "s":
+		d.mode = modeStep
+		return true
+	case "next", "n":
+		d.mode, d.modeDepth = modeNext, s.depth
+		return true
+	case "out", "o":
+		d.mode, d.modeDepth = modeOut, s.depth
+		return true
+	case "continue", "c":
+		d.mode = modeContinue
+		return true
+	case "quit", "q":
+		d.mode = modeDetached
+		return true
+	case "break", "b":
+		b, err := parseBreakpoint(args)
+		if err != nil {
+			d.printf("Invalid breakpoint: %v\n", err)
+			return false
+		}
+		if b.kind == bpLine && d.info == nil {
+			d.printf("Line breakpoints require source maps\n")
+			return false
+		}
+		b.id = d.nextID
+		d.nextID++
+		d.breakpoints = append(d.breakpoints, b)
+		d.printf("Breakpoint %d: %v\n", b.id, b)
+	case "delete", "d":
+		d.deleteBreakpoint(args)
+	case "breakpoints", "bl":
+		if len(d.breakpoints) == 0 {
+			d.printf("No breakpoints\n")
+		}
+		for _, b := range d.breakpoints {
+			d.printf("%d: %v\n", b.id, b)
+		}
+	case "stack", "st":
+		d.printStack(s)
+	case "memory", "m":
+		d.printMemory(s, args)
+	case "storage", "sl":
+		d.printStorage(s, args)
+	case "returndata", "rd":
+		d.printf("%v\n", hexutil.Bytes(s.rData))
+	case "where", "bt":
+		d.printFrames()
+	case "list", "l":
+		d.printSource(s)
+	case "help", "h":
+		fmt.Fprint(d.out, helpText)
+	default:
+		d.printf("Unknown command %q, type 'help' for a list of commands\n", cmd)
+	}
+	return false
+}
+
+func (d *Debugger) deleteBreakpoint(args []string) {
+	if len(args) != 1 {
+		d.printf("Usage: delete <id>\n")
+		return
+	}
+	id, ok := math.ParseUint64(args[0])
+	if ok {
+		for i, b := range d.breakpoints {
+			if uint64(b.id) == id {
+				d.breakpoints = append(d.breakpoints[:i], d.breakpoints[i+1:]...)
+				d.printf("Deleted breakpoint %d\n", id)
+				return
+			}
+		}
+	}
+	d.printf("No breakpoint %s\n", args[0])
+}
+
+func (d *Debugger) printStack(s *step) {
+	stack := s.scope.StackData()
+	if len(stack) == 0 {
+		d.printf("Stack is empty\n")
+	}
+	for i := len(stack) - 1; i >= 0; i-- {
+		d.printf("%4d: %#x\n", len(stack)-1-i, stack[i].Bytes32())
+	}
+}
+
+func (d *Debugger) printMemory(s *step, args []string) {
+	var (
+		mem          = s.scope.MemoryData()
+		offset, size = uint64(0), uint64(len(mem))
+		ok           = true
+	)
+	if len(args) > 0 {
+		offset, ok = math.ParseUint64(args[0])
+		size = 32
+	}
+	if len(args) > 1 && ok {
+		size, ok = math.ParseUint64(args[1])
+	}
+	if !ok {
+		d.printf("Usage: memory [offset [size]]\n")
+		return
+	}
+	if len(mem) == 0 {
+		d.printf("Memory is empty\n")
+		return
+	}
+	if offset >= uint64(len(mem)) {
+		d.printf("Offset out of range, memory size is %d\n", len(mem))
+		return
+	}
+	end := min(offset+size, uint64(len(mem)))
+	for pos := offset; pos < end; pos += 32 {
+		d.printf("%#06x: %x\n", pos, mem[pos:min(pos+32, end)])
+	}
+}
+
+func (d *Debugger) printStorage(s *step, args []string) {
+	if d.state == nil {
+		d.printf("Storage is not available\n")
+		return
+	}
+	addr := s.scope.Address()
+	if len(args) > 0 {
+		slot, ok := parseHash(args[0])
+		if !ok {
+			d.printf("Usage: storage [slot]\n")
+			return
+		}
+		d.printf("%v: %v\n", slot, d.state.GetState(addr, slot))
+		return
+	}
+	var slots []common.Hash
+	for slot := range d.written[addr] {
+		slots = append(slots, slot)
+	}
+	if len(slots) == 0 {
+		d.printf("No storage written by %v\n", addr)
+		return
+	}
+	sort.Slice(slots, func(i, j int) bool { return slots[i].Cmp(slots[j]) < 0 })
+	for _, slot := range slots {
+		d.printf("%v: %v\n", slot, d.state.GetState(addr, slot))
+	}
+}
+
+func (d *Debugger) printFrames() {
+	for i := len(d.frames) - 1; i >= 0; i-- {
+		f := d.frames[i]
+		d.printf("#%d %v %v -> %v", i+1, f.typ, f.from, f.to)
+		if f.contract != nil {
+			d.printf(" (%s)", f.contract.Name)
+		}
+		d.printf("\n")
+	}
+}
+
+// sourceContext is the number of lines shown around the current line.
+const sourceContext = 3
+
+func (d *Debugger) printSource(s *step) {
+	file, loc, ok := d.location(d.frame(), s.pc)
+	if !ok || file.Content == nil {
+		d.printf("No source available\n")
+		return
+	}
+	line, _ := file.Position(loc.Start)
+	endLine, _ := file.Position(loc.Start + max(loc.Length-1, 0))
+	for n := max(line-sourceContext, 1); n <= min(endLine+sourceContext, file.Lines()); n++ {
+		marker := " "
+		if n >= line && n <= endLine {
+			marker = ">"
+		}
+		d.printf("%s %4d  %s\n", marker, n, file.Line(n))
+	}
+}
+
+// parseHash parses a storage slot given as a decimal or hex number.
+func parseHash(s string) (common.Hash, bool) {
+	v, ok := math.ParseBig256(s)
+	if !ok {
+		return common.Hash{}, false
+	}
+	return common.BigToHash(v), true
+}
@@ -0,0 +1,149 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package debugger
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/compiler"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm/runtime"
+)
+
+var (
+	outerAddr = common.HexToAddress("0xaaaa")
+	innerAddr = common.HexToAddress("0xbbbb")
+
+	// outerCode stores 42 in slot 1 and calls innerCode.
+	outerCode = common.FromHex("602a600155" + "60006000600060006000" + "73" + innerAddr.Hex()[2:] + "5af15000")
+	// innerCode stores 1 in slot 0.
+	innerCode = common.FromHex("600160005500")
+)
+
+func runScript(t *testing.T, script string, info *compiler.DebugInfo) string {
+	t.Helper()
+	statedb, _ := state.New(types.EmptyRootHash, state.NewDatabaseForTesting())
+	statedb.SetCode(outerAddr, outerCode)
+	statedb.SetCode(innerAddr, innerCode)
+
+	var out bytes.Buffer
+	dbg := New(strings.NewReader(script), &out, info)
+	cfg := &runtime.Config{State: statedb}
+	cfg.EVMConfig.Tracer = dbg.Hooks()
+	if _, _, err := runtime.Call(outerAddr, nil, cfg); err != nil {
+		t.Fatal("execution failed:", err)
+	}
+	return out.String()
+}
+
+func checkOutput(t *testing.T, out string, want ...string) {
+	t.Helper()
+	for _, w := range want {
+		i := strings.Index(out, w)
+		if i < 0 {
+			t.Fatalf("output doesn't contain %q:\n%s", w, out)
+		}
+		out = out[i+len(w):]
+	}
+}
+
+func TestBreakpoints(t *testing.T) {
+	script := `break sstore 1
+break depth 2
+continue
+stack
+storage 1
+continue
+where
+out
+storage
+quit
+`
+	out := runScript(t, script, nil)
+	checkOutput(t, out,
+		"[1] "+outerAddr.Hex()+" pc=0 PUSH1",
+		"Breakpoint 1: storage write to 0x0000000000000000000000000000000000000000000000000000000000000001",
+		"Breakpoint 2: call depth 2",
+		// SSTORE breakpoint, the slot is not written yet.
+		"Breakpoint 1 hit", "pc=4 SSTORE",
+		"   0: 0x0000000000000000000000000000000000000000000000000000000000000001",
+		"   1: 0x000000000000000000000000000000000000000000000000000000000000002a",
+		"0x0000000000000000000000000000000000000000000000000000000000000001: 0x0000000000000000000000000000000000000000000000000000000000000000",
+		// Entering the inner call.
+		"Breakpoint 2 hit", "[2] "+innerAddr.Hex()+" pc=0 PUSH1",
+		"#2 CALL "+outerAddr.Hex()+" -> "+innerAddr.Hex(),
+		"#1 CALL",
+		// Returned to the outer frame, the slot is written now.
+		"Frame at depth 2 returned",
+		"[1] "+outerAddr.Hex()+" pc=38 POP",
+		"0x0000000000000000000000000000000000000000000000000000000000000001: 0x000000000000000000000000000000000000000000000000000000000000002a",
+	)
+	if strings.Contains(out, "Transaction finished") {
+		t.Fatal("output printed after quit")
+	}
+}
+
+func TestStepping(t *testing.T) {
+	// Step into the call, then step over the remaining inner opcodes with next
+	// until the outer frame is reached again.
+	script := "break pc 37\nc\ns\nbl\nd 1\nbl\nn\n\n\n\nn\nmemory\nrd\nc\n"
+	out := runScript(t, script, nil)
+	checkOutput(t, out,
+		"Breakpoint 1 hit", "pc=37 CALL",
+		"Entering CALL frame at depth 2",
+		"[2] "+innerAddr.Hex()+" pc=0 PUSH1",
+		"1: pc 37",
+		"Deleted breakpoint 1",
+		"No breakpoints",
+		"[2] "+innerAddr.Hex()+" pc=2 PUSH1",
+		"[2] "+innerAddr.Hex()+" pc=4 SSTORE",
+		"[2] "+innerAddr.Hex()+" pc=5 STOP",
+		"Frame at depth 2 returned",
+		"[1] "+outerAddr.Hex()+" pc=38 POP",
+		"[1] "+outerAddr.Hex()+" pc=39 STOP",
+		"Memory is empty",
+		"0x\n",
+		"Frame at depth 1 returned",
+		"Transaction finished",
+	)
+}
+
+func TestSourceMap(t *testing.T) {
+	// The source maps assign a line to each opcode of the inner code.
+	const src = "a = 1;\nstop;\n"
+	combinedJSON := `{
+		"contracts": {"test.sol:Inner": {"bin": "00", "bin-runtime": "600160005500", "srcmap": "", "srcmap-runtime": "0:6:0:-:0;;;7:5;"}},
+		"sourceList": ["test.sol"]
+	}`
+	info, err := compiler.ParseDebugInfo([]byte(combinedJSON), func(string) ([]byte, error) { return []byte(src), nil })
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := runScript(t, "break line test.sol:2\nc\nl\nq\n", info)
+	checkOutput(t, out,
+		"Breakpoint 1: line test.sol:2",
+		"Breakpoint 1 hit", "[2] "+innerAddr.Hex()+" pc=5 STOP",
+		"at test.sol:2:1 (test.sol:Inner)",
+		"stop;",
+		"     1  a = 1;",
+		">    2  stop;",
+	)
+}
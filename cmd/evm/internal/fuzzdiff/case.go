@@ -0,0 +1,159 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+// Package fuzzdiff implements differential fuzzing of t8n tools: random state
+// transitions are executed by two t8n implementations and their results compared.
+package fuzzdiff
+
+import (
+	"crypto/ecdsa"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// Env is the block environment of a state transition, in the t8n env format.
+type Env struct {
+	Coinbase              common.Address                      `json:"currentCoinbase"`
+	Difficulty            *math.HexOrDecimal256               `json:"currentDifficulty,omitempty"`
+	Random                *math.HexOrDecimal256               `json:"currentRandom,omitempty"`
+	GasLimit              math.HexOrDecimal64                 `json:"currentGasLimit"`
+	Number                math.HexOrDecimal64                 `json:"currentNumber"`
+	Timestamp             math.HexOrDecimal64                 `json:"currentTimestamp"`
+	BlockHashes           map[math.HexOrDecimal64]common.Hash `json:"blockHashes"`
+	Withdrawals           []*types.Withdrawal                 `json:"withdrawals"`
+	BaseFee               *math.HexOrDecimal256               `json:"currentBaseFee,omitempty"`
+	ExcessBlobGas         *math.HexOrDecimal64                `json:"currentExcessBlobGas,omitempty"`
+	ParentBeaconBlockRoot *common.Hash                        `json:"parentBeaconBlockRoot,omitempty"`
+}
+
+// Tx is an unsigned transaction of a case, along with the key signing it.
+type Tx struct {
+	Key  *ecdsa.PrivateKey
+	Data types.TxData
+}
+
+// Case is a state transition to be executed by the t8n tools.
+type Case struct {
+	Fork    string
+	ChainID *big.Int
+	Alloc   types.GenesisAlloc
+	Env     Env
+	Txs     []*Tx
+}
+
+// Write stores the inputs of the case as alloc.json, env.json and txs.rlp in the
+// given directory.
+func (c *Case) Write(dir string) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	signer := types.LatestSignerForChainID(c.ChainID)
+	txs := make(types.Transactions, len(c.Txs))
+	for i, tx := range c.Txs {
+		signed, err := types.SignNewTx(tx.Key, signer, tx.Data)
+		if err != nil {
+			return err
+		}
+		txs[i] = signed
+	}
+	blob, err := rlp.EncodeToBytes(txs)
+	if err != nil {
+		return err
+	}
+	for name, data := range map[string]interface{}{
+		"alloc.json": c.Alloc,
+		"env.json":   c.Env,
+		"txs.rlp":    hexutil.Bytes(blob),
+	} {
+		blob, err := json.MarshalIndent(data, "", "  ")
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(filepath.Join(dir, name), blob, 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Args returns the t8n command line arguments executing the case written to dir,
+// placing the outputs into outdir.
+func (c *Case) Args(dir, outdir string, trace bool) []string {
+	args := []string{
+		"--input.alloc", filepath.Join(dir, "alloc.json"),
+		"--input.env", filepath.Join(dir, "env.json"),
+		"--input.txs", filepath.Join(dir, "txs.rlp"),
+		"--state.fork", c.Fork,
+		"--state.chainid", c.ChainID.String(),
+		"--state.reward", "-1",
+		"--output.basedir", outdir,
+		"--output.result", "result.json",
+		"--output.alloc", "alloc.json",
+	}
+	if trace {
+		args = append(args, "--trace")
+	}
+	return args
+}
+
+// copy returns a copy of the case which can be modified without affecting the
+// original. The code, storage and transactions are shared, so they must be
+// replaced instead of modified.
+func (c *Case) copy() *Case {
+	cpy := *c
+	cpy.Alloc = make(types.GenesisAlloc, len(c.Alloc))
+	for addr, account := range c.Alloc {
+		cpy.Alloc[addr] = account
+	}
+	cpy.Env.Withdrawals = slices.Clone(c.Env.Withdrawals)
+	cpy.Txs = slices.Clone(c.Txs)
+	return &cpy
+}
+
+// String returns a short description of the case.
+func (c *Case) String() string {
+	return fmt.Sprintf("%s, %d accounts, %d transactions", c.Fork, len(c.Alloc), len(c.Txs))
+}
+
+// withTxFields returns a copy of the transaction data with the given nonce and input.
+func withTxFields(data types.TxData, nonce uint64, input []byte) types.TxData {
+	switch tx := data.(type) {
+	case *types.LegacyTx:
+		cpy := *tx
+		cpy.Nonce, cpy.Data = nonce, input
+		return &cpy
+	case *types.AccessListTx:
+		cpy := *tx
+		cpy.Nonce, cpy.Data = nonce, input
+		return &cpy
+	case *types.DynamicFeeTx:
+		cpy := *tx
+		cpy.Nonce, cpy.Data = nonce, input
+		return &cpy
+	default:
+		panic("unsupported transaction type")
+	}
+}
@@ -0,0 +1,365 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package fuzzdiff
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"math/big"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/cmd/evm/internal/t8ntool"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/urfave/cli/v2"
+)
+
+// T8n executes a state transition given the t8n command line arguments.
+type T8n func(args []string) error
+
+// Command returns a T8n running an external command, e.g. "evmone-t8n" or
+// "ethereum-spec-evm t8n". The t8n arguments are appended to the command.
+func Command(command string) T8n {
+	fields := strings.Fields(command)
+	return func(args []string) error {
+		cmd := exec.Command(fields[0], append(fields[1:], args...)...)
+		var stderr bytes.Buffer
+		cmd.Stderr = &stderr
+		if err := cmd.Run(); err != nil {
+			return fmt.Errorf("%v: %s", err, bytes.TrimSpace(stderr.Bytes()))
+		}
+		return nil
+	}
+}
+
+// internalFlags are the t8ntool flags used by the fuzzer.
+var internalFlags = []cli.Flag{
+	t8ntool.TraceFlag,
+	t8ntool.OutputBasedir,
+	t8ntool.OutputAllocFlag,
+	t8ntool.OutputResultFlag,
+	t8ntool.OutputBodyFlag,
+	t8ntool.InputAllocFlag,
+	t8ntool.InputEnvFlag,
+	t8ntool.InputTxsFlag,
+	t8ntool.ForknameFlag,
+	t8ntool.ChainIDFlag,
+	t8ntool.RewardFlag,
+}
+
+// Internal executes a state transition with the t8ntool of this binary, in process.
+// Panics are reported as errors.
+func Internal(args []string) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	set := flag.NewFlagSet("t8n", flag.ContinueOnError)
+	for _, f := range internalFlags {
+		if err := f.Apply(set); err != nil {
+			return err
+		}
+	}
+	if err := set.Parse(args); err != nil {
+		return err
+	}
+	return t8ntool.Transition(cli.NewContext(cli.NewApp(), set, nil))
+}
+
+// Step is an opcode execution of a trace.
+type Step struct {
+	Pc    uint64                  `json:"pc"`
+	Op    *vm.OpCode              `json:"op"`
+	Gas   math.HexOrDecimal64     `json:"gas"`
+	Depth int                     `json:"depth"`
+	Stack []*math.HexOrDecimal256 `json:"stack"`
+}
+
+func (s *Step) String() string {
+	return fmt.Sprintf("pc %d, op %v, gas %d, depth %d, stack %d items", s.Pc, *s.Op, s.Gas, s.Depth, len(s.Stack))
+}
+
+// Result is the outcome of a state transition reported by a t8n.
+type Result struct {
+	Err error `json:"-"` // set if the t8n failed, then all other fields are empty
+
+	StateRoot    common.Hash         `json:"stateRoot"`
+	ReceiptsRoot common.Hash         `json:"receiptsRoot"`
+	LogsHash     common.Hash         `json:"logsHash"`
+	GasUsed      math.HexOrDecimal64 `json:"gasUsed"`
+	Receipts     []struct {
+		Status  math.HexOrDecimal64 `json:"status"`
+		GasUsed math.HexOrDecimal64 `json:"gasUsed"`
+	} `json:"receipts"`
+	Rejected []struct {
+		Index int `json:"index"`
+	} `json:"rejected"`
+
+	Alloc  types.GenesisAlloc `json:"-"`
+	Traces map[string][]*Step `json:"-"` // trace files, by name
+}
+
+// loadResult reads the outputs of a t8n from its output directory.
+func loadResult(dir string) (*Result, error) {
+	var r Result
+	for name, v := range map[string]interface{}{"result.json": &r, "alloc.json": &r.Alloc} {
+		blob, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(blob, v); err != nil {
+			return nil, fmt.Errorf("invalid %s: %v", name, err)
+		}
+	}
+	files, err := filepath.Glob(filepath.Join(dir, "trace-*.jsonl"))
+	if err != nil {
+		return nil, err
+	}
+	r.Traces = make(map[string][]*Step)
+	for _, file := range files {
+		steps, err := loadTrace(file)
+		if err != nil {
+			return nil, err
+		}
+		r.Traces[filepath.Base(file)] = steps
+	}
+	return &r, nil
+}
+
+// loadTrace reads the opcode steps of a JSON trace, skipping other lines like the
+// summary at the end.
+func loadTrace(file string) ([]*Step, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var (
+		steps   []*Step
+		scanner = bufio.NewScanner(f)
+	)
+	scanner.Buffer(nil, 64*1024*1024)
+	for scanner.Scan() {
+		var step Step
+		if err := json.Unmarshal(scanner.Bytes(), &step); err != nil {
+			return nil, fmt.Errorf("invalid trace %s: %v", filepath.Base(file), err)
+		}
+		if step.Op != nil {
+			steps = append(steps, &step)
+		}
+	}
+	return steps, scanner.Err()
+}
+
+// Diff is a difference between the results of two t8ns.
+type Diff struct {
+	Kind   string // what differs: error, rejected, receipt, gasUsed, receiptsRoot, logsHash, stateRoot or trace
+	Detail string
+}
+
+func (d Diff) String() string {
+	return d.Kind + ": " + d.Detail
+}
+
+// Compare returns the differences between two results, most fundamental first.
+// Results of t8ns which both failed are considered equal, as the error messages
+// and exit codes of implementations differ.
+func Compare(a, b *Result) []Diff {
+	switch {
+	case a.Err != nil && b.Err != nil:
+		return nil
+	case a.Err != nil || b.Err != nil:
+		return []Diff{{"error", fmt.Sprintf("%v vs %v", errString(a.Err), errString(b.Err))}}
+	}
+	var diffs []Diff
+	rejected := func(r *Result) []int {
+		var indices []int
+		for _, rej := range r.Rejected {
+			indices = append(indices, rej.Index)
+		}
+		slices.Sort(indices)
+		return indices
+	}
+	if ia, ib := rejected(a), rejected(b); !slices.Equal(ia, ib) {
+		diffs = append(diffs, Diff{"rejected", fmt.Sprintf("transactions %v vs %v", ia, ib)})
+	}
+	for i := 0; i < min(len(a.Receipts), len(b.Receipts)); i++ {
+		if a.Receipts[i] != b.Receipts[i] {
+			diffs = append(diffs, Diff{"receipt", fmt.Sprintf("%d: status %d, gas used %d vs status %d, gas used %d",
+				i, a.Receipts[i].Status, a.Receipts[i].GasUsed, b.Receipts[i].Status, b.Receipts[i].GasUsed)})
+		}
+	}
+	if a.GasUsed != b.GasUsed {
+		diffs = append(diffs, Diff{"gasUsed", fmt.Sprintf("%d vs %d", a.GasUsed, b.GasUsed)})
+	}
+	if a.ReceiptsRoot != b.ReceiptsRoot {
+		diffs = append(diffs, Diff{"receiptsRoot", fmt.Sprintf("%v vs %v", a.ReceiptsRoot, b.ReceiptsRoot)})
+	}
+	if a.LogsHash != b.LogsHash {
+		diffs = append(diffs, Diff{"logsHash", fmt.Sprintf("%v vs %v", a.LogsHash, b.LogsHash)})
+	}
+	if a.StateRoot != b.StateRoot {
+		diffs = append(diffs, Diff{"stateRoot", fmt.Sprintf("%v vs %v, %s", a.StateRoot, b.StateRoot, compareAlloc(a.Alloc, b.Alloc))})
+	}
+	return append(diffs, compareTraces(a.Traces, b.Traces)...)
+}
+
+func errString(err error) string {
+	if err == nil {
+		return "success"
+	}
+	return err.Error()
+}
+
+// compareAlloc describes the first difference between two post-states.
+func compareAlloc(a, b types.GenesisAlloc) string {
+	var addrs []common.Address
+	for addr := range a {
+		addrs = append(addrs, addr)
+	}
+	for addr := range b {
+		if _, ok := a[addr]; !ok {
+			addrs = append(addrs, addr)
+		}
+	}
+	slices.SortFunc(addrs, common.Address.Cmp)
+	for _, addr := range addrs {
+		aa, aok := a[addr]
+		ab, bok := b[addr]
+		switch {
+		case !aok || !bok:
+			return fmt.Sprintf("account %v exists: %v vs %v", addr, aok, bok)
+		case aa.Nonce != ab.Nonce:
+			return fmt.Sprintf("account %v nonce: %d vs %d", addr, aa.Nonce, ab.Nonce)
+		case aa.Balance.Cmp(ab.Balance) != 0:
+			return fmt.Sprintf("account %v balance: %v vs %v", addr, aa.Balance, ab.Balance)
+		case !bytes.Equal(aa.Code, ab.Code):
+			return fmt.Sprintf("account %v code: %#x vs %#x", addr, aa.Code, ab.Code)
+		}
+		for slot, value := range aa.Storage {
+			if ab.Storage[slot] != value {
+				return fmt.Sprintf("account %v slot %v: %v vs %v", addr, slot, value, ab.Storage[slot])
+			}
+		}
+		for slot, value := range ab.Storage {
+			if aa.Storage[slot] != value {
+				return fmt.Sprintf("account %v slot %v: %v vs %v", addr, slot, aa.Storage[slot], value)
+			}
+		}
+	}
+	return "no difference in the post-state"
+}
+
+// compareTraces returns the first differing step of every transaction trace.
+func compareTraces(a, b map[string][]*Step) []Diff {
+	var names []string
+	for name := range a {
+		names = append(names, name)
+	}
+	for name := range b {
+		if _, ok := a[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+
+	var diffs []Diff
+	for _, name := range names {
+		ta, aok := a[name]
+		tb, bok := b[name]
+		if !aok || !bok {
+			diffs = append(diffs, Diff{"trace", fmt.Sprintf("%s exists: %v vs %v", name, aok, bok)})
+			continue
+		}
+		for i := 0; i < max(len(ta), len(tb)); i++ {
+			if i >= len(ta) || i >= len(tb) {
+				diffs = append(diffs, Diff{"trace", fmt.Sprintf("%s: %d vs %d steps", name, len(ta), len(tb))})
+				break
+			}
+			if !ta[i].equal(tb[i]) {
+				diffs = append(diffs, Diff{"trace", fmt.Sprintf("%s step %d: %v vs %v", name, i, ta[i], tb[i])})
+				break
+			}
+		}
+	}
+	return diffs
+}
+
+// equal reports whether two steps are the same. The stack is only compared if
+// both traces include it.
+func (s *Step) equal(o *Step) bool {
+	if s.Pc != o.Pc || *s.Op != *o.Op || s.Gas != o.Gas || s.Depth != o.Depth {
+		return false
+	}
+	if s.Stack == nil || o.Stack == nil {
+		return true
+	}
+	return slices.EqualFunc(s.Stack, o.Stack, func(a, b *math.HexOrDecimal256) bool {
+		return (*big.Int)(a).Cmp((*big.Int)(b)) == 0
+	})
+}
+
+// Differ executes cases with two t8ns and compares the results.
+type Differ struct {
+	Internal T8n
+	External T8n
+	Trace    bool // compare opcode traces, requires --trace support by both t8ns
+}
+
+// Run writes the case into dir and executes it with both t8ns, placing their
+// outputs into the internal and external subdirectories.
+func (d *Differ) Run(dir string, c *Case) ([]Diff, error) {
+	if err := c.Write(dir); err != nil {
+		return nil, err
+	}
+	a, err := d.run(d.Internal, dir, "internal", c)
+	if err != nil {
+		return nil, err
+	}
+	b, err := d.run(d.External, dir, "external", c)
+	if err != nil {
+		return nil, err
+	}
+	return Compare(a, b), nil
+}
+
+func (d *Differ) run(t8n T8n, dir, name string, c *Case) (*Result, error) {
+	outdir := filepath.Join(dir, name)
+	if err := os.RemoveAll(outdir); err != nil { // drop traces of earlier runs
+		return nil, err
+	}
+	if err := t8n(c.Args(dir, outdir, d.Trace)); err != nil {
+		return &Result{Err: err}, nil
+	}
+	r, err := loadResult(outdir)
+	if err != nil {
+		// Missing or invalid outputs are a failure of the t8n.
+		return &Result{Err: err}, nil
+	}
+	return r, nil
+}
@@ -0,0 +1,154 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package fuzzdiff
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+func TestGenerate(t *testing.T) {
+	a, err := Generate(1, DefaultForks)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, _ := Generate(1, DefaultForks)
+	dirA, dirB := t.TempDir(), t.TempDir()
+	if err := a.Write(dirA); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.Write(dirB); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"alloc.json", "env.json", "txs.rlp"} {
+		blobA, _ := os.ReadFile(filepath.Join(dirA, name))
+		blobB, _ := os.ReadFile(filepath.Join(dirB, name))
+		if !bytes.Equal(blobA, blobB) {
+			t.Errorf("%s differs for the same seed", name)
+		}
+	}
+	if _, err := Generate(1, []string{"Unknown"}); err == nil {
+		t.Error("unknown fork accepted")
+	}
+}
+
+func TestDiffSelf(t *testing.T) {
+	d := &Differ{Internal: Internal, External: Internal, Trace: true}
+	var receipts int
+	for seed := int64(0); seed < 20; seed++ {
+		c, err := Generate(seed, DefaultForks)
+		if err != nil {
+			t.Fatal(err)
+		}
+		dir := t.TempDir()
+		diffs, err := d.Run(dir, c)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(diffs) > 0 {
+			t.Fatalf("seed %d: differences %v", seed, diffs)
+		}
+		r, err := loadResult(filepath.Join(dir, "internal"))
+		if err != nil {
+			t.Fatalf("seed %d: %v", seed, err)
+		}
+		if len(r.Traces) != len(c.Txs) {
+			t.Fatalf("seed %d: %d traces for %d transactions", seed, len(r.Traces), len(c.Txs))
+		}
+		receipts += len(r.Receipts)
+	}
+	if receipts == 0 {
+		t.Fatal("no transactions executed")
+	}
+}
+
+// storageBug is a t8n which reports a wrong state root if any account of the
+// prestate has storage.
+func storageBug(args []string) error {
+	if err := Internal(args); err != nil {
+		return err
+	}
+	var alloc types.GenesisAlloc
+	blob, _ := os.ReadFile(flagValue(args, "--input.alloc"))
+	if err := json.Unmarshal(blob, &alloc); err != nil {
+		return err
+	}
+	for _, account := range alloc {
+		if len(account.Storage) > 0 {
+			result := filepath.Join(flagValue(args, "--output.basedir"), "result.json")
+			blob, _ := os.ReadFile(result)
+			var r map[string]interface{}
+			json.Unmarshal(blob, &r)
+			r["stateRoot"] = "0x0000000000000000000000000000000000000000000000000000000000000000"
+			blob, _ = json.Marshal(r)
+			return os.WriteFile(result, blob, 0644)
+		}
+	}
+	return nil
+}
+
+func flagValue(args []string, name string) string {
+	return args[slices.Index(args, name)+1]
+}
+
+func TestMinimize(t *testing.T) {
+	if !reflect.DeepEqual(chunks([]byte{1, 2, 3}), [][]byte{{3}, {1, 2}, {2, 3}, {1, 3}, {1, 2}}) {
+		t.Fatalf("wrong chunks: %v", chunks([]byte{1, 2, 3}))
+	}
+	d := &Differ{Internal: Internal, External: storageBug}
+	for seed := int64(0); ; seed++ {
+		c, err := Generate(seed, DefaultForks)
+		if err != nil {
+			t.Fatal(err)
+		}
+		diffs, err := d.Run(t.TempDir(), c)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(diffs) == 0 {
+			continue
+		}
+		if diffs[0].Kind != "stateRoot" {
+			t.Fatalf("wrong difference: %v", diffs[0])
+		}
+		min, err := d.Minimize(t.TempDir(), c, diffs[0].Kind)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// Only a single account with a single slot is needed to trigger the bug.
+		if len(min.Txs) != 0 || len(min.Env.Withdrawals) != 0 || len(min.Alloc) != 1 {
+			t.Fatalf("case not minimized: %v", min)
+		}
+		for _, account := range min.Alloc {
+			if len(account.Storage) != 1 || len(account.Code) != 0 {
+				t.Fatalf("account not minimized: %d slots, code %x", len(account.Storage), account.Code)
+			}
+		}
+		// The original case must not be modified.
+		if len(c.Txs) == 0 || len(c.Alloc) < 2 {
+			t.Fatalf("original case modified: %v", c)
+		}
+		return
+	}
+}
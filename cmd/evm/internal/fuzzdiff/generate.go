@@ -0,0 +1,341 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package fuzzdiff
+
+import (
+	"crypto/ecdsa"
+	"math/big"
+	"math/rand"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/tests"
+)
+
+// DefaultForks are the forks cases are generated for by default.
+var DefaultForks = []string{"Berlin", "London", "Paris", "Shanghai", "Cancun"}
+
+// opcodes are all opcodes defined in any fork.
+var opcodes []vm.OpCode
+
+func init() {
+	for i := 0; i < 256; i++ {
+		if op := vm.OpCode(i); !strings.HasPrefix(op.String(), "opcode ") {
+			opcodes = append(opcodes, op)
+		}
+	}
+}
+
+// generator produces the random contents of a case.
+type generator struct {
+	rand   *rand.Rand
+	config *params.ChainConfig
+	keys   []*ecdsa.PrivateKey
+	addrs  []common.Address // senders, contracts and precompiles to use in calls
+}
+
+// Generate creates a random case for one of the given forks, derived from the seed.
+// The transactions are mostly valid, with a few of them rejected due to wrong
+// nonces, insufficient gas or fees.
+func Generate(seed int64, forks []string) (*Case, error) {
+	g := &generator{rand: rand.New(rand.NewSource(seed))}
+	fork := forks[g.rand.Intn(len(forks))]
+	config, _, err := tests.GetChainConfig(fork)
+	if err != nil {
+		return nil, err
+	}
+	g.config = config
+	c := &Case{
+		Fork:    fork,
+		ChainID: big.NewInt(1),
+		Alloc:   make(types.GenesisAlloc),
+	}
+	// Create the accounts. Senders are funded well enough to pay for all
+	// transactions most of the time.
+	senders := 1 + g.rand.Intn(3)
+	for i := 0; i < senders; i++ {
+		key := g.key()
+		addr := crypto.PubkeyToAddress(key.PublicKey)
+		balance := big.NewInt(params.Ether)
+		if g.rand.Intn(10) == 0 {
+			balance = big.NewInt(g.rand.Int63n(params.GWei))
+		}
+		g.keys = append(g.keys, key)
+		g.addrs = append(g.addrs, addr)
+		c.Alloc[addr] = types.Account{Balance: balance, Nonce: uint64(g.rand.Intn(3))}
+	}
+	contracts := make([]common.Address, 1+g.rand.Intn(4))
+	for i := range contracts {
+		contracts[i] = g.address()
+		g.addrs = append(g.addrs, contracts[i])
+	}
+	for i := 1; i <= 10; i++ {
+		g.addrs = append(g.addrs, common.BytesToAddress([]byte{byte(i)}))
+	}
+	for _, addr := range contracts {
+		account := types.Account{
+			Code:    g.code(1 + g.rand.Intn(200)),
+			Balance: big.NewInt(g.rand.Int63n(1000)),
+		}
+		if n := g.rand.Intn(4); n > 0 {
+			account.Storage = make(map[common.Hash]common.Hash)
+			for i := 0; i < n; i++ {
+				account.Storage[common.BigToHash(big.NewInt(g.rand.Int63n(4)))] = g.word()
+			}
+		}
+		c.Alloc[addr] = account
+	}
+	c.Env = g.env()
+	c.Txs = g.txs(c)
+	return c, nil
+}
+
+// key returns a random private key.
+func (g *generator) key() *ecdsa.PrivateKey {
+	for {
+		seed := make([]byte, 32)
+		g.rand.Read(seed)
+		if key, err := crypto.ToECDSA(seed); err == nil {
+			return key
+		}
+	}
+}
+
+// address returns a random address.
+func (g *generator) address() common.Address {
+	var addr common.Address
+	g.rand.Read(addr[:])
+	return addr
+}
+
+// word returns a random 32 byte word, mostly small values.
+func (g *generator) word() common.Hash {
+	var word common.Hash
+	switch g.rand.Intn(3) {
+	case 0:
+		word[31] = byte(g.rand.Intn(256))
+	case 1:
+		g.rand.Read(word[32-1-g.rand.Intn(32):])
+	default:
+		g.rand.Read(word[:])
+	}
+	return word
+}
+
+// push appends a PUSH instruction of the value to the code.
+func push(code []byte, value []byte) []byte {
+	if len(value) == 0 {
+		value = []byte{0}
+	}
+	code = append(code, byte(vm.PUSH1)+byte(len(value)-1))
+	return append(code, value...)
+}
+
+// code returns random code of about the given size. Besides random instructions,
+// it contains small values, known addresses and calls to keep executions going
+// past the first few instructions.
+func (g *generator) code(size int) []byte {
+	var code []byte
+	for len(code) < size {
+		switch n := g.rand.Intn(20); {
+		case n < 6:
+			code = push(code, []byte{byte(g.rand.Intn(33))})
+		case n < 8:
+			code = push(code, g.addrs[g.rand.Intn(len(g.addrs))].Bytes())
+		case n < 9:
+			word := g.word()
+			code = push(code, word[32-1-g.rand.Intn(32):])
+		case n < 10:
+			code = g.call(code)
+		case n < 11:
+			code = append(code, byte(g.rand.Intn(256)))
+		default:
+			code = append(code, byte(opcodes[g.rand.Intn(len(opcodes))]))
+		}
+	}
+	return code
+}
+
+// call appends a call of a known address to the code.
+func (g *generator) call(code []byte) []byte {
+	op := []vm.OpCode{vm.CALL, vm.CALLCODE, vm.DELEGATECALL, vm.STATICCALL}[g.rand.Intn(4)]
+	for i := 0; i < 4; i++ { // return size and offset, input size and offset
+		code = push(code, []byte{byte(g.rand.Intn(64))})
+	}
+	if op == vm.CALL || op == vm.CALLCODE {
+		code = push(code, []byte{byte(g.rand.Intn(4))})
+	}
+	code = push(code, g.addrs[g.rand.Intn(len(g.addrs))].Bytes())
+	if g.rand.Intn(2) == 0 {
+		code = append(code, byte(vm.GAS))
+	} else {
+		code = push(code, big.NewInt(g.rand.Int63n(100_000)).Bytes())
+	}
+	return append(code, byte(op))
+}
+
+// env returns a random block environment valid for the fork.
+func (g *generator) env() Env {
+	env := Env{
+		Coinbase:    g.address(),
+		GasLimit:    30_000_000,
+		Number:      math.HexOrDecimal64(1 + g.rand.Intn(32)),
+		BlockHashes: make(map[math.HexOrDecimal64]common.Hash),
+	}
+	env.Timestamp = env.Number*12 + math.HexOrDecimal64(g.rand.Intn(12))
+	if g.rand.Intn(4) == 0 {
+		env.Coinbase = g.addrs[g.rand.Intn(len(g.addrs))]
+	}
+	// All ancestors are available, executing BLOCKHASH can't fail.
+	for i := math.HexOrDecimal64(0); i < env.Number; i++ {
+		env.BlockHashes[i] = g.word()
+	}
+	var (
+		number = new(big.Int).SetUint64(uint64(env.Number))
+		time   = uint64(env.Timestamp)
+	)
+	if g.config.TerminalTotalDifficulty != nil && g.config.TerminalTotalDifficulty.Sign() == 0 {
+		env.Random = (*math.HexOrDecimal256)(g.word().Big())
+	} else {
+		env.Difficulty = (*math.HexOrDecimal256)(big.NewInt(0x20000 + g.rand.Int63n(0x20000)))
+	}
+	if g.config.IsLondon(number) {
+		env.BaseFee = (*math.HexOrDecimal256)(big.NewInt(7 + g.rand.Int63n(1000)))
+	}
+	if g.config.IsShanghai(number, time) {
+		env.Withdrawals = make([]*types.Withdrawal, g.rand.Intn(4))
+		for i := range env.Withdrawals {
+			env.Withdrawals[i] = &types.Withdrawal{
+				Index:     uint64(i),
+				Validator: uint64(g.rand.Intn(1000)),
+				Address:   g.addrs[g.rand.Intn(len(g.addrs))],
+				Amount:    uint64(g.rand.Intn(1000)),
+			}
+		}
+	}
+	if g.config.IsCancun(number, time) {
+		excess := math.HexOrDecimal64(g.rand.Intn(4) * params.BlobTxBlobGasPerBlob)
+		root := g.word()
+		env.ExcessBlobGas, env.ParentBeaconBlockRoot = &excess, &root
+	}
+	return env
+}
+
+// txs returns random transactions of the senders, using the transaction types
+// supported by the fork.
+func (g *generator) txs(c *Case) []*Tx {
+	var (
+		number = new(big.Int).SetUint64(uint64(c.Env.Number))
+		nonces = make(map[*ecdsa.PrivateKey]uint64)
+		txs    []*Tx
+	)
+	baseFee := big.NewInt(1)
+	if c.Env.BaseFee != nil {
+		baseFee = (*big.Int)(c.Env.BaseFee)
+	}
+	for i, n := 0, 1+g.rand.Intn(6); i < n; i++ {
+		key := g.keys[g.rand.Intn(len(g.keys))]
+		nonce, ok := nonces[key]
+		if !ok {
+			nonce = c.Alloc[crypto.PubkeyToAddress(key.PublicKey)].Nonce
+		}
+		var to *common.Address
+		switch n := g.rand.Intn(10); {
+		case n < 7:
+			addr := g.addrs[g.rand.Intn(len(g.addrs))]
+			to = &addr
+		case n < 8:
+			addr := g.address()
+			to = &addr
+		}
+		var data []byte
+		if to == nil {
+			data = g.code(g.rand.Intn(100))
+		} else {
+			data = make([]byte, g.rand.Intn(68))
+			g.rand.Read(data)
+		}
+		var (
+			value    = big.NewInt(g.rand.Int63n(1000) * int64(g.rand.Intn(2)))
+			gas      = 100_000 + uint64(g.rand.Intn(400_000))
+			gasPrice = new(big.Int).Add(baseFee, big.NewInt(g.rand.Int63n(100)))
+		)
+		// Make some transactions invalid. Their nonces are not used, so later
+		// transactions of the sender stay valid.
+		switch g.rand.Intn(20) {
+		case 0:
+			nonce += 1 + uint64(g.rand.Intn(2))
+		case 1:
+			gas = uint64(g.rand.Intn(int(params.TxGas + 5000)))
+		case 2:
+			gasPrice = big.NewInt(g.rand.Int63n(baseFee.Int64()))
+		default:
+			nonces[key] = nonce + 1
+		}
+		var accesses types.AccessList
+		for j, n := 0, g.rand.Intn(3); j < n; j++ {
+			tuple := types.AccessTuple{Address: g.addrs[g.rand.Intn(len(g.addrs))]}
+			for k, n := 0, g.rand.Intn(3); k < n; k++ {
+				tuple.StorageKeys = append(tuple.StorageKeys, common.BigToHash(big.NewInt(g.rand.Int63n(4))))
+			}
+			accesses = append(accesses, tuple)
+		}
+
+		var tx types.TxData
+		switch n := g.rand.Intn(3); {
+		case n == 2 && g.config.IsLondon(number):
+			tx = &types.DynamicFeeTx{
+				ChainID:    c.ChainID,
+				Nonce:      nonce,
+				GasTipCap:  big.NewInt(g.rand.Int63n(gasPrice.Int64() + 1)),
+				GasFeeCap:  gasPrice,
+				Gas:        gas,
+				To:         to,
+				Value:      value,
+				Data:       data,
+				AccessList: accesses,
+			}
+		case n >= 1 && g.config.IsBerlin(number):
+			tx = &types.AccessListTx{
+				ChainID:    c.ChainID,
+				Nonce:      nonce,
+				GasPrice:   gasPrice,
+				Gas:        gas,
+				To:         to,
+				Value:      value,
+				Data:       data,
+				AccessList: accesses,
+			}
+		default:
+			tx = &types.LegacyTx{
+				Nonce:    nonce,
+				GasPrice: gasPrice,
+				Gas:      gas,
+				To:       to,
+				Value:    value,
+				Data:     data,
+			}
+		}
+		txs = append(txs, &Tx{Key: key, Data: tx})
+	}
+	return txs
+}
@@ -0,0 +1,139 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package fuzzdiff
+
+import (
+	"os"
+	"slices"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// Minimize shrinks a case for which the t8ns differ, as long as the first
+// difference stays of the same kind. It repeatedly tries to remove transactions,
+// withdrawals, accounts, storage slots and chunks of code and transaction input,
+// until no removal reproduces the difference any more. Temporary files are
+// placed into dir.
+func (d *Differ) Minimize(dir string, c *Case, kind string) (*Case, error) {
+	for {
+		var reduced bool
+		for _, candidate := range shrink(c) {
+			tmp, err := os.MkdirTemp(dir, "minimize-")
+			if err != nil {
+				return nil, err
+			}
+			diffs, err := d.Run(tmp, candidate)
+			os.RemoveAll(tmp)
+			if err != nil {
+				return nil, err
+			}
+			if len(diffs) > 0 && diffs[0].Kind == kind {
+				c, reduced = candidate, true
+				break
+			}
+		}
+		if !reduced {
+			return c, nil
+		}
+	}
+}
+
+// shrink returns the candidates for reducing a case, coarse reductions first.
+func shrink(c *Case) []*Case {
+	var candidates []*Case
+
+	// Remove transactions, renumbering the later ones of the same sender.
+	for i := len(c.Txs) - 1; i >= 0; i-- {
+		cpy := c.copy()
+		cpy.Txs = slices.Delete(cpy.Txs, i, i+1)
+		for j := i; j < len(cpy.Txs); j++ {
+			if tx := cpy.Txs[j]; tx.Key == c.Txs[i].Key {
+				data := types.NewTx(tx.Data)
+				cpy.Txs[j] = &Tx{Key: tx.Key, Data: withTxFields(tx.Data, data.Nonce()-1, data.Data())}
+			}
+		}
+		candidates = append(candidates, cpy)
+	}
+	for i := range c.Env.Withdrawals {
+		cpy := c.copy()
+		cpy.Env.Withdrawals = slices.Delete(cpy.Env.Withdrawals, i, i+1)
+		candidates = append(candidates, cpy)
+	}
+	// Remove accounts and storage slots.
+	var addrs []common.Address
+	for addr := range c.Alloc {
+		addrs = append(addrs, addr)
+	}
+	slices.SortFunc(addrs, common.Address.Cmp)
+	for _, addr := range addrs {
+		cpy := c.copy()
+		delete(cpy.Alloc, addr)
+		candidates = append(candidates, cpy)
+	}
+	for _, addr := range addrs {
+		var slots []common.Hash
+		for slot := range c.Alloc[addr].Storage {
+			slots = append(slots, slot)
+		}
+		slices.SortFunc(slots, common.Hash.Cmp)
+		for _, slot := range slots {
+			cpy := c.copy()
+			account := cpy.Alloc[addr]
+			account.Storage = make(map[common.Hash]common.Hash)
+			for k, v := range c.Alloc[addr].Storage {
+				if k != slot {
+					account.Storage[k] = v
+				}
+			}
+			cpy.Alloc[addr] = account
+			candidates = append(candidates, cpy)
+		}
+	}
+	// Remove chunks of code and transaction input, halving the chunk size down to
+	// single bytes.
+	for _, addr := range addrs {
+		for _, code := range chunks(c.Alloc[addr].Code) {
+			cpy := c.copy()
+			account := cpy.Alloc[addr]
+			account.Code = code
+			cpy.Alloc[addr] = account
+			candidates = append(candidates, cpy)
+		}
+	}
+	for i, tx := range c.Txs {
+		data := types.NewTx(tx.Data)
+		for _, input := range chunks(data.Data()) {
+			cpy := c.copy()
+			cpy.Txs[i] = &Tx{Key: tx.Key, Data: withTxFields(tx.Data, data.Nonce(), input)}
+			candidates = append(candidates, cpy)
+		}
+	}
+	return candidates
+}
+
+// chunks returns copies of the data with a chunk removed, for chunk sizes of
+// half the data down to a single byte.
+func chunks(data []byte) [][]byte {
+	var reduced [][]byte
+	for size := (len(data) + 1) / 2; size > 0; size /= 2 {
+		for i := 0; i < len(data); i += size {
+			reduced = append(reduced, slices.Concat(data[:i], data[min(i+size, len(data)):]))
+		}
+	}
+	return reduced
+}
@@ -139,6 +139,21 @@ var (
 		Usage:    "enable return data output",
 		Category: flags.VMCategory,
 	}
+	DebuggerFlag = &cli.BoolFlag{
+		Name:     "debugger",
+		Usage:    "run the execution in the interactive debugger",
+		Category: flags.VMCategory,
+	}
+	DebuggerSolcFlag = &cli.StringFlag{
+		Name:     "debugger.solc",
+		Usage:    "solc --combined-json output (bin, bin-runtime, srcmap, srcmap-runtime) providing source maps to the debugger",
+		Category: flags.VMCategory,
+	}
+	ProfileFlag = &cli.StringFlag{
+		Name:     "profile",
+		Usage:    "write a gas profile of the execution to the given file (pprof format for .pprof and .pb.gz files, folded stacks for flamegraph tools otherwise)",
+		Category: flags.VMCategory,
+	}
 	refTestFlag = &cli.StringFlag{
 		Name:  "test",
 		Usage: "Path to EOF validation reference test.",
@@ -250,6 +265,8 @@ var traceFlags = []cli.Flag{
 	DisableStackFlag,
 	DisableStorageFlag,
 	DisableReturnDataFlag,
+	DebuggerFlag,
+	DebuggerSolcFlag,
 }
 
 var app = flags.NewApp("the evm command line interface")
@@ -267,6 +284,10 @@ func init() {
 		blockBuilderCommand,
 		eofParseCommand,
 		eofDumpCommand,
+		replayCommand,
+		benchCommand,
+		t8nCaptureCommand,
+		fuzzDiffCommand,
 	}
 	app.Before = func(ctx *cli.Context) error {
 		flags.MigrateGlobalFlags(ctx)
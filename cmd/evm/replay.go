@@ -0,0 +1,274 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/tracing"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/eth/tracers/logger"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/internal/flags"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/ethereum/go-ethereum/tests"
+	"github.com/urfave/cli/v2"
+)
+
+var (
+	ReplayRPCFlag = &cli.StringFlag{
+		Name:     "rpc",
+		Usage:    "RPC endpoint of the node to capture the transaction from",
+		Category: flags.VMCategory,
+	}
+	ReplayCaptureFlag = &cli.StringFlag{
+		Name:     "capture",
+		Usage:    "File to store the captured transaction in, or to replay it from if --rpc is not set",
+		Category: flags.VMCategory,
+	}
+)
+
+var replayCommand = &cli.Command{
+	Action:    replayCmd,
+	Name:      "replay",
+	Usage:     "Re-executes a historical transaction on its RPC-captured prestate",
+	ArgsUsage: "<txhash>",
+	Description: `The replay command fetches a transaction, its block header and the state it
+accessed (using the prestate tracer of the node's debug API) and executes it locally,
+so it can be traced or stepped through with --debugger. The captured data can be
+stored with --capture, and replayed offline later by omitting --rpc.`,
+	Flags: []cli.Flag{ReplayRPCFlag, ReplayCaptureFlag},
+}
+
+// txCapture holds everything needed to re-execute a transaction.
+type txCapture struct {
+	ChainID     *big.Int               `json:"chainId"`
+	Header      *types.Header          `json:"header"`
+	Tx          *types.Transaction     `json:"transaction"`
+	TxIndex     uint                   `json:"txIndex"`
+	GasUsed     uint64                 `json:"gasUsed"`
+	Prestate    types.GenesisAlloc     `json:"prestate"`
+	BlockHashes map[uint64]common.Hash `json:"blockHashes,omitempty"`
+}
+
+// prestateAccount is an account in the prestate tracer output.
+type prestateAccount struct {
+	Balance *hexutil.Big                `json:"balance"`
+	Code    hexutil.Bytes               `json:"code"`
+	Nonce   uint64                      `json:"nonce"`
+	Storage map[common.Hash]common.Hash `json:"storage"`
+}
+
+// account converts the tracer output to a genesis account.
+func (acc *prestateAccount) account() types.Account {
+	balance := new(big.Int)
+	if acc.Balance != nil {
+		balance = acc.Balance.ToInt()
+	}
+	return types.Account{Balance: balance, Code: acc.Code, Nonce: acc.Nonce, Storage: acc.Storage}
+}
+
+// captureTx fetches a mined transaction and its prestate from an RPC endpoint.
+func captureTx(ctx context.Context, client *rpc.Client, hash common.Hash) (*txCapture, error) {
+	ec := ethclient.NewClient(client)
+	tx, pending, err := ec.TransactionByHash(ctx, hash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch transaction: %v", err)
+	}
+	if pending {
+		return nil, errors.New("transaction is pending")
+	}
+	receipt, err := ec.TransactionReceipt(ctx, hash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch receipt: %v", err)
+	}
+	header, err := ec.HeaderByHash(ctx, receipt.BlockHash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch block header: %v", err)
+	}
+	chainID, err := ec.ChainID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch chain ID: %v", err)
+	}
+	var prestate map[common.Address]*prestateAccount
+	if err := client.CallContext(ctx, &prestate, "debug_traceTransaction", hash, map[string]string{"tracer": "prestateTracer"}); err != nil {
+		return nil, fmt.Errorf("failed to trace transaction: %v", err)
+	}
+	c := &txCapture{
+		ChainID:     chainID,
+		Header:      header,
+		Tx:          tx,
+		TxIndex:     receipt.TransactionIndex,
+		GasUsed:     receipt.GasUsed,
+		Prestate:    make(types.GenesisAlloc, len(prestate)),
+		BlockHashes: make(map[uint64]common.Hash),
+	}
+	for addr, acc := range prestate {
+		c.Prestate[addr] = acc.account()
+	}
+	return c, nil
+}
+
+// chainConfig returns the configuration of the chain the transaction was captured
+// from. Unknown chains need to be configured with a genesis file.
+func (c *txCapture) chainConfig(genesisPath string) (*params.ChainConfig, error) {
+	return lookupChainConfig(c.ChainID, genesisPath)
+}
+
+// lookupChainConfig returns the configuration of a well-known chain, or the one
+// of the genesis file if given.
+func lookupChainConfig(chainID *big.Int, genesisPath string) (*params.ChainConfig, error) {
+	if genesisPath != "" {
+		return readGenesis(genesisPath).Config, nil
+	}
+	for _, config := range []*params.ChainConfig{params.MainnetChainConfig, params.SepoliaChainConfig, params.HoleskyChainConfig, params.AllDevChainProtocolChanges} {
+		if config.ChainID.Cmp(chainID) == 0 {
+			return config, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown chain ID %v, use --%s to provide the chain configuration", chainID, GenesisFlag.Name)
+}
+
+// execute runs the captured transaction. The getHash function resolves block
+// hashes for the BLOCKHASH opcode.
+func (c *txCapture) execute(config *params.ChainConfig, tracer *tracing.Hooks, getHash vm.GetHashFunc) (*core.ExecutionResult, error) {
+	st := tests.MakePreState(rawdb.NewMemoryDatabase(), c.Prestate, false, rawdb.HashScheme)
+	defer st.Close()
+
+	msg, err := core.TransactionToMessage(c.Tx, types.MakeSigner(config, c.Header.Number, c.Header.Time), c.Header.BaseFee)
+	if err != nil {
+		return nil, err
+	}
+	blockCtx := core.NewEVMBlockContext(c.Header, nil, &c.Header.Coinbase)
+	blockCtx.GetHash = getHash
+	evm := vm.NewEVM(blockCtx, core.NewEVMTxContext(msg), st.StateDB, config, vm.Config{Tracer: tracer})
+	st.StateDB.SetTxContext(c.Tx.Hash(), int(c.TxIndex))
+
+	if tracer != nil && tracer.OnTxStart != nil {
+		tracer.OnTxStart(evm.GetVMContext(), c.Tx, msg.From)
+	}
+	result, err := core.ApplyMessage(evm, msg, new(core.GasPool).AddGas(c.Tx.Gas()))
+	if tracer != nil && tracer.OnTxEnd != nil {
+		var receipt *types.Receipt
+		if result != nil {
+			receipt = &types.Receipt{TxHash: c.Tx.Hash(), GasUsed: result.UsedGas}
+		}
+		tracer.OnTxEnd(receipt, err)
+	}
+	return result, err
+}
+
+func replayCmd(ctx *cli.Context) error {
+	var (
+		capture     *txCapture
+		getHash     vm.GetHashFunc
+		capturePath = ctx.String(ReplayCaptureFlag.Name)
+	)
+	if url := ctx.String(ReplayRPCFlag.Name); url != "" {
+		if ctx.Args().Len() != 1 {
+			return errors.New("transaction hash argument required")
+		}
+		hash := common.HexToHash(ctx.Args().First())
+		client, err := rpc.DialContext(ctx.Context, url)
+		if err != nil {
+			return err
+		}
+		defer client.Close()
+		if capture, err = captureTx(ctx.Context, client, hash); err != nil {
+			return err
+		}
+		// Block hashes are fetched on demand and added to the capture.
+		ec := ethclient.NewClient(client)
+		getHash = func(n uint64) common.Hash {
+			header, err := ec.HeaderByNumber(ctx.Context, new(big.Int).SetUint64(n))
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Failed to fetch hash of block %d: %v\n", n, err)
+				return common.Hash{}
+			}
+			capture.BlockHashes[n] = header.Hash()
+			return header.Hash()
+		}
+	} else {
+		if capturePath == "" {
+			return fmt.Errorf("either --%s or --%s is required", ReplayRPCFlag.Name, ReplayCaptureFlag.Name)
+		}
+		blob, err := os.ReadFile(capturePath)
+		if err != nil {
+			return err
+		}
+		if err := json.Unmarshal(blob, &capture); err != nil {
+			return fmt.Errorf("invalid capture file: %v", err)
+		}
+		capturePath = "" // don't overwrite the input
+		getHash = func(n uint64) common.Hash {
+			hash, ok := capture.BlockHashes[n]
+			if !ok {
+				fmt.Fprintf(os.Stderr, "Hash of block %d not captured\n", n)
+			}
+			return hash
+		}
+	}
+	config, err := capture.chainConfig(ctx.String(GenesisFlag.Name))
+	if err != nil {
+		return err
+	}
+	tracer, err := newDebugger(ctx)
+	if err != nil {
+		return err
+	}
+	if tracer == nil && ctx.Bool(MachineFlag.Name) {
+		tracer = logger.NewJSONLogger(&logger.Config{
+			EnableMemory:     !ctx.Bool(DisableMemoryFlag.Name),
+			DisableStack:     ctx.Bool(DisableStackFlag.Name),
+			DisableStorage:   ctx.Bool(DisableStorageFlag.Name),
+			EnableReturnData: !ctx.Bool(DisableReturnDataFlag.Name),
+		}, os.Stderr)
+	}
+	result, err := capture.execute(config, tracer, getHash)
+	if err != nil {
+		return fmt.Errorf("transaction execution failed: %v", err)
+	}
+	fmt.Printf("output: %#x\n", result.ReturnData)
+	if result.Err != nil {
+		fmt.Printf("error: %v\n", result.Err)
+	}
+	fmt.Printf("gas used: %d\n", result.UsedGas)
+	if result.UsedGas != capture.GasUsed {
+		fmt.Fprintf(os.Stderr, "Gas used differs from the receipt (%d), the replay is not faithful\n", capture.GasUsed)
+	}
+	if capturePath != "" {
+		blob, err := json.MarshalIndent(capture, "", "  ")
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(capturePath, blob, 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
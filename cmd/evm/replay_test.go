@@ -0,0 +1,160 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/eth"
+	"github.com/ethereum/go-ethereum/eth/catalyst"
+	"github.com/ethereum/go-ethereum/eth/downloader"
+	"github.com/ethereum/go-ethereum/eth/ethconfig"
+	"github.com/ethereum/go-ethereum/eth/tracers"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/node"
+	"github.com/ethereum/go-ethereum/p2p"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+var (
+	testKey, _ = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+	testAddr   = crypto.PubkeyToAddress(testKey.PublicKey)
+)
+
+// startTestNode starts a dev-mode node serving the debug API. Blocks are sealed
+// by the returned beacon on demand.
+func startTestNode(t *testing.T, alloc types.GenesisAlloc) (*node.Node, *catalyst.SimulatedBeacon) {
+	t.Helper()
+	stack, err := node.New(&node.Config{P2P: p2p.Config{NoDiscovery: true, MaxPeers: 0}})
+	if err != nil {
+		t.Fatal("can't create node:", err)
+	}
+	t.Cleanup(func() { stack.Close() })
+
+	genesis := core.DeveloperGenesisBlock(30_000_000, &testAddr)
+	for addr, account := range alloc {
+		genesis.Alloc[addr] = account
+	}
+	ethConf := ethconfig.Defaults
+	ethConf.Genesis = genesis
+	ethConf.SyncMode = downloader.FullSync
+	ethservice, err := eth.New(stack, &ethConf)
+	if err != nil {
+		t.Fatal("can't create eth service:", err)
+	}
+	stack.RegisterAPIs(tracers.APIs(ethservice.APIBackend))
+	beacon, err := catalyst.NewSimulatedBeacon(0, ethservice)
+	if err != nil {
+		t.Fatal("can't create simulated beacon:", err)
+	}
+	stack.RegisterLifecycle(beacon)
+	if err := stack.Start(); err != nil {
+		t.Fatal("can't start node:", err)
+	}
+	ethservice.SetSynced()
+	return stack, beacon
+}
+
+// sendTx signs and sends a transaction calling the given contract, and seals it
+// into a block.
+func sendTx(t *testing.T, client *ethclient.Client, beacon *catalyst.SimulatedBeacon, to common.Address, nonce uint64) *types.Transaction {
+	t.Helper()
+	tx := types.MustSignNewTx(testKey, types.LatestSignerForChainID(params.AllDevChainProtocolChanges.ChainID), &types.DynamicFeeTx{
+		ChainID:   params.AllDevChainProtocolChanges.ChainID,
+		Nonce:     nonce,
+		To:        &to,
+		Gas:       100_000,
+		GasTipCap: big.NewInt(params.GWei),
+		GasFeeCap: big.NewInt(2 * params.GWei),
+	})
+	if err := client.SendTransaction(context.Background(), tx); err != nil {
+		t.Fatal("can't send transaction:", err)
+	}
+	beacon.Commit()
+	if _, err := client.TransactionReceipt(context.Background(), tx.Hash()); err != nil {
+		t.Fatal("transaction not included:", err)
+	}
+	return tx
+}
+
+func TestReplay(t *testing.T) {
+	// The contract stores the hash of the parent block: NUMBER PUSH1 1 SWAP1 SUB
+	// BLOCKHASH PUSH1 0 SSTORE STOP.
+	contract := common.HexToAddress("0xc0de")
+	stack, beacon := startTestNode(t, types.GenesisAlloc{
+		contract: {Balance: new(big.Int), Code: common.FromHex("436001900340600055" + "00")},
+	})
+	rpcClient := stack.Attach()
+	client := ethclient.NewClient(rpcClient)
+
+	sendTx(t, client, beacon, testAddr, 0) // move past the genesis block
+	tx := sendTx(t, client, beacon, contract, 1)
+
+	capture, err := captureTx(context.Background(), rpcClient, tx.Hash())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := capture.Prestate[contract]; !ok {
+		t.Fatal("contract missing from prestate")
+	}
+	config, err := capture.chainConfig("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	parent, err := client.HeaderByHash(context.Background(), capture.Header.ParentHash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	getHash := func(n uint64) common.Hash {
+		capture.BlockHashes[n] = parent.Hash()
+		return parent.Hash()
+	}
+	result, err := capture.execute(config, nil, getHash)
+	if err != nil {
+		t.Fatal("replay failed:", err)
+	}
+	if result.Failed() || result.UsedGas != capture.GasUsed {
+		t.Fatalf("wrong replay result: err %v, gas used %d, want %d", result.Err, result.UsedGas, capture.GasUsed)
+	}
+	if len(capture.BlockHashes) != 1 {
+		t.Fatalf("wrong number of block hashes requested: %d", len(capture.BlockHashes))
+	}
+
+	// The capture can be stored and replayed offline.
+	blob, err := json.Marshal(capture)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var loaded *txCapture
+	if err := json.Unmarshal(blob, &loaded); err != nil {
+		t.Fatal(err)
+	}
+	if loaded.Tx.Hash() != tx.Hash() || loaded.Header.Hash() != capture.Header.Hash() {
+		t.Fatal("capture changed by encoding")
+	}
+	result, err = loaded.execute(config, nil, func(n uint64) common.Hash { return loaded.BlockHashes[n] })
+	if err != nil || result.UsedGas != capture.GasUsed {
+		t.Fatalf("offline replay failed: err %v, gas used %d", err, result.UsedGas)
+	}
+}
@@ -19,12 +19,14 @@ package main
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"math/big"
 	"os"
 	goruntime "runtime"
 	"slices"
+	"strings"
 	"testing"
 	"time"
 
@@ -37,6 +39,7 @@ import (
 	"github.com/ethereum/go-ethereum/core/tracing"
 	"github.com/ethereum/go-ethereum/core/vm"
 	"github.com/ethereum/go-ethereum/core/vm/runtime"
+	"github.com/ethereum/go-ethereum/eth/tracers"
 	"github.com/ethereum/go-ethereum/eth/tracers/logger"
 	"github.com/ethereum/go-ethereum/internal/flags"
 	"github.com/ethereum/go-ethereum/params"
@@ -51,7 +54,7 @@ var runCommand = &cli.Command{
 	Usage:       "Run arbitrary evm binary",
 	ArgsUsage:   "<code>",
 	Description: `The run command runs arbitrary EVM code.`,
-	Flags:       slices.Concat(vmFlags, traceFlags),
+	Flags:       slices.Concat(vmFlags, traceFlags, []cli.Flag{ProfileFlag}),
 }
 
 // readGenesis will read the given JSON format genesis file and return
@@ -75,6 +78,43 @@ func readGenesis(genesisPath string) *core.Genesis {
 	return genesis
 }
 
+// isPprofPath reports whether a profile file should be written in pprof format.
+func isPprofPath(path string) bool {
+	return strings.HasSuffix(path, ".pprof") || strings.HasSuffix(path, ".pb.gz")
+}
+
+// newProfiler creates a gas profiler for the given output file.
+func newProfiler(path string) (*tracers.Tracer, error) {
+	format := "folded"
+	if isPprofPath(path) {
+		format = "pprof"
+	}
+	cfg, _ := json.Marshal(map[string]string{"format": format})
+	return tracers.DefaultDirectory.New("gasProfiler", new(tracers.Context), cfg, nil)
+}
+
+// writeProfile stores the result of the gas profiler.
+func writeProfile(profiler *tracers.Tracer, path string) error {
+	res, err := profiler.GetResult()
+	if err != nil {
+		return err
+	}
+	// The folded format is returned as a JSON string, pprof as base64 encoded
+	// binary, which is decoded by unmarshalling into a byte slice.
+	var content []byte
+	if isPprofPath(path) {
+		err = json.Unmarshal(res, &content)
+	} else {
+		var folded string
+		err = json.Unmarshal(res, &folded)
+		content = []byte(folded)
+	}
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, content, 0644)
+}
+
 type execStats struct {
 	time           time.Duration // The execution time.
 	allocs         int64         // The number of heap allocations during execution.
@@ -119,6 +159,7 @@ func runCmd(ctx *cli.Context) error {
 
 	var (
 		tracer      *tracing.Hooks
+		profiler    *tracers.Tracer
 		debugLogger *logger.StructLogger
 		statedb     *state.StateDB
 		chainConfig *params.ChainConfig
@@ -128,7 +169,24 @@ func runCmd(ctx *cli.Context) error {
 		blobHashes  []common.Hash  // TODO (MariusVanDerWijden) implement blob hashes in state tests
 		blobBaseFee = new(big.Int) // TODO (MariusVanDerWijden) implement blob fee in state tests
 	)
-	if ctx.Bool(MachineFlag.Name) {
+	dbg, err := newDebugger(ctx)
+	if err != nil {
+		return err
+	}
+	if dbg != nil {
+		if ctx.String(CodeFileFlag.Name) == "-" {
+			return errors.New("--debugger reads commands from stdin, code can't be read from stdin too")
+		}
+		tracer = dbg
+	} else if ctx.IsSet(ProfileFlag.Name) {
+		if ctx.Bool(BenchFlag.Name) {
+			return errors.New("--profile can't be used with --bench")
+		}
+		if profiler, err = newProfiler(ctx.String(ProfileFlag.Name)); err != nil {
+			return err
+		}
+		tracer = profiler.Hooks
+	} else if ctx.Bool(MachineFlag.Name) {
 		tracer = logger.NewJSONLogger(logconfig, os.Stdout)
 	} else if ctx.Bool(DebugFlag.Name) {
 		debugLogger = logger.NewStructLogger(logconfig)
@@ -302,7 +360,12 @@ allocations:     %d
 allocated bytes: %d
 `, initialGas-leftOverGas, stats.time, stats.allocs, stats.bytesAllocated)
 	}
-	if tracer == nil {
+	if profiler != nil {
+		if err := writeProfile(profiler, ctx.String(ProfileFlag.Name)); err != nil {
+			return err
+		}
+	}
+	if tracer == nil || dbg != nil || profiler != nil {
 		fmt.Printf("%#x\n", output)
 		if err != nil {
 			fmt.Printf(" error: %v\n", err)
@@ -19,6 +19,7 @@ package main
 import (
 	"bufio"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 
@@ -58,7 +59,17 @@ func stateTestCmd(ctx *cli.Context) error {
 		EnableReturnData: !ctx.Bool(DisableReturnDataFlag.Name),
 	}
 	var cfg vm.Config
+	dbg, err := newDebugger(ctx)
+	if err != nil {
+		return err
+	}
 	switch {
+	case dbg != nil:
+		if len(ctx.Args().First()) == 0 {
+			return errors.New("--debugger reads commands from stdin, the test file must be given as an argument")
+		}
+		cfg.Tracer = dbg
+
 	case ctx.Bool(MachineFlag.Name):
 		cfg.Tracer = logger.NewJSONLogger(config, os.Stderr)
 
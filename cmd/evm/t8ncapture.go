@@ -0,0 +1,369 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/ethereum/go-ethereum/cmd/evm/internal/t8ntool"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/urfave/cli/v2"
+)
+
+var t8nCaptureCommand = &cli.Command{
+	Action:    t8nCaptureCmd,
+	Name:      "t8n-capture",
+	Usage:     "Captures a block from a node as input files for the state transition tool",
+	ArgsUsage: "<block number>",
+	Description: `The t8n-capture command fetches a block and the state accessed by its
+transactions (using the prestate tracer of the node's debug API) and writes them as
+alloc.json, env.json and txs.rlp into the output directory. The block can then be
+re-executed offline with 'evm t8n', using the command line printed on completion.
+
+The alloc only contains the accounts and storage slots accessed by the block, so
+the state root computed by t8n differs from the one of the block. The receipts
+root and the gas used are reproduced exactly.`,
+	Flags: []cli.Flag{ReplayRPCFlag, GenesisFlag, t8ntool.OutputBasedir},
+}
+
+// t8nEnv is the block environment in the env.json format of t8n.
+type t8nEnv struct {
+	Coinbase              common.Address                      `json:"currentCoinbase"`
+	Difficulty            *math.HexOrDecimal256               `json:"currentDifficulty,omitempty"`
+	Random                *math.HexOrDecimal256               `json:"currentRandom,omitempty"`
+	GasLimit              math.HexOrDecimal64                 `json:"currentGasLimit"`
+	Number                math.HexOrDecimal64                 `json:"currentNumber"`
+	Timestamp             math.HexOrDecimal64                 `json:"currentTimestamp"`
+	ParentTimestamp       math.HexOrDecimal64                 `json:"parentTimestamp"`
+	BlockHashes           map[math.HexOrDecimal64]common.Hash `json:"blockHashes"`
+	Ommers                []t8nOmmer                          `json:"ommers,omitempty"`
+	Withdrawals           []*types.Withdrawal                 `json:"withdrawals"`
+	BaseFee               *math.HexOrDecimal256               `json:"currentBaseFee,omitempty"`
+	ParentUncleHash       common.Hash                         `json:"parentUncleHash"`
+	ExcessBlobGas         *math.HexOrDecimal64                `json:"currentExcessBlobGas,omitempty"`
+	ParentBeaconBlockRoot *common.Hash                        `json:"parentBeaconBlockRoot,omitempty"`
+}
+
+type t8nOmmer struct {
+	Delta   uint64         `json:"delta"`
+	Address common.Address `json:"address"`
+}
+
+// blockCapture holds the t8n input files of a block.
+type blockCapture struct {
+	Header  *types.Header
+	ChainID *big.Int
+	Alloc   types.GenesisAlloc
+	Env     *t8nEnv
+	Txs     types.Transactions
+}
+
+func t8nCaptureCmd(ctx *cli.Context) error {
+	if !ctx.IsSet(ReplayRPCFlag.Name) {
+		return fmt.Errorf("--%s is required", ReplayRPCFlag.Name)
+	}
+	if ctx.Args().Len() != 1 {
+		return errors.New("expected a block number as the only argument")
+	}
+	number, err := strconv.ParseUint(ctx.Args().First(), 0, 64)
+	if err != nil || number == 0 {
+		return fmt.Errorf("invalid block number %q", ctx.Args().First())
+	}
+	client, err := rpc.DialContext(ctx.Context, ctx.String(ReplayRPCFlag.Name))
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	capture, err := captureBlock(ctx.Context, client, number)
+	if err != nil {
+		return err
+	}
+	config, err := lookupChainConfig(capture.ChainID, ctx.String(GenesisFlag.Name))
+	if err != nil {
+		return err
+	}
+	dir := ctx.String(t8ntool.OutputBasedir.Name)
+	if err := capture.write(dir); err != nil {
+		return err
+	}
+	fork, reward := t8nFork(config, capture.Header)
+	fmt.Printf("Captured block %d with %d transactions and %d accounts. Execute it with:\n\n", number, len(capture.Txs), len(capture.Alloc))
+	fmt.Printf("  evm t8n --input.alloc=%s --input.env=%s --input.txs=%s --state.fork=%s --state.chainid=%v --state.reward=%d\n",
+		filepath.Join(dir, "alloc.json"), filepath.Join(dir, "env.json"), filepath.Join(dir, "txs.rlp"), fork, capture.ChainID, reward)
+	fmt.Printf("\nExpected results: gasUsed %#x, receiptsRoot %v\n", capture.Header.GasUsed, capture.Header.ReceiptHash)
+	return nil
+}
+
+// captureBlock fetches a block, its environment and the state it accessed from an
+// RPC endpoint.
+func captureBlock(ctx context.Context, client *rpc.Client, number uint64) (*blockCapture, error) {
+	ec := ethclient.NewClient(client)
+	block, err := ec.BlockByNumber(ctx, new(big.Int).SetUint64(number))
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch block: %v", err)
+	}
+	parent, err := ec.HeaderByHash(ctx, block.ParentHash())
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch parent block: %v", err)
+	}
+	chainID, err := ec.ChainID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch chain ID: %v", err)
+	}
+	c := &blockCapture{
+		Header:  block.Header(),
+		ChainID: chainID,
+		Alloc:   make(types.GenesisAlloc),
+		Env:     newT8nEnv(block, parent),
+		Txs:     block.Transactions(),
+	}
+	if err := c.fetchPrestate(ctx, client, block); err != nil {
+		return nil, err
+	}
+	if err := c.fetchBlockHashes(ctx, client, number); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func newT8nEnv(block *types.Block, parent *types.Header) *t8nEnv {
+	env := &t8nEnv{
+		Coinbase:              block.Coinbase(),
+		GasLimit:              math.HexOrDecimal64(block.GasLimit()),
+		Number:                math.HexOrDecimal64(block.NumberU64()),
+		Timestamp:             math.HexOrDecimal64(block.Time()),
+		ParentTimestamp:       math.HexOrDecimal64(parent.Time),
+		ParentUncleHash:       parent.UncleHash,
+		ParentBeaconBlockRoot: block.BeaconRoot(),
+	}
+	if block.Difficulty().Sign() > 0 {
+		env.Difficulty = (*math.HexOrDecimal256)(block.Difficulty())
+	} else {
+		env.Random = (*math.HexOrDecimal256)(new(big.Int).SetBytes(block.MixDigest().Bytes()))
+	}
+	if block.BaseFee() != nil {
+		env.BaseFee = (*math.HexOrDecimal256)(block.BaseFee())
+	}
+	if block.ExcessBlobGas() != nil {
+		env.ExcessBlobGas = (*math.HexOrDecimal64)(block.ExcessBlobGas())
+	}
+	if block.Header().WithdrawalsHash != nil {
+		env.Withdrawals = make([]*types.Withdrawal, 0, len(block.Withdrawals()))
+		env.Withdrawals = append(env.Withdrawals, block.Withdrawals()...)
+	}
+	for _, uncle := range block.Uncles() {
+		env.Ommers = append(env.Ommers, t8nOmmer{Delta: block.NumberU64() - uncle.Number.Uint64(), Address: uncle.Coinbase})
+	}
+	return env
+}
+
+// fetchPrestate collects the state accessed by the block. The prestate of every
+// transaction is traced, and the first occurrence of an account or storage slot
+// holds its value before the block.
+func (c *blockCapture) fetchPrestate(ctx context.Context, client *rpc.Client, block *types.Block) error {
+	var results []struct {
+		TxHash common.Hash                         `json:"txHash"`
+		Result map[common.Address]*prestateAccount `json:"result"`
+		Error  string                              `json:"error"`
+	}
+	if len(block.Transactions()) > 0 {
+		err := client.CallContext(ctx, &results, "debug_traceBlockByNumber", hexutil.EncodeUint64(block.NumberU64()), map[string]string{"tracer": "prestateTracer"})
+		if err != nil {
+			return fmt.Errorf("failed to trace block: %v", err)
+		}
+	}
+	for _, res := range results {
+		if res.Error != "" {
+			return fmt.Errorf("failed to trace transaction %v: %v", res.TxHash, res.Error)
+		}
+		for addr, acc := range res.Result {
+			existing, ok := c.Alloc[addr]
+			if !ok {
+				c.Alloc[addr] = acc.account()
+				continue
+			}
+			for slot, value := range acc.Storage {
+				if _, ok := existing.Storage[slot]; ok {
+					continue
+				}
+				if existing.Storage == nil {
+					existing.Storage = make(map[common.Hash]common.Hash)
+				}
+				existing.Storage[slot] = value
+			}
+			c.Alloc[addr] = existing
+		}
+	}
+	// Rewards and withdrawals credit accounts outside of transactions.
+	credited := []common.Address{block.Coinbase()}
+	for _, w := range block.Withdrawals() {
+		credited = append(credited, w.Address)
+	}
+	for _, uncle := range block.Uncles() {
+		credited = append(credited, uncle.Coinbase)
+	}
+	parent := new(big.Int).SetUint64(block.NumberU64() - 1)
+	ec := ethclient.NewClient(client)
+	for _, addr := range credited {
+		if _, ok := c.Alloc[addr]; ok {
+			continue
+		}
+		balance, err := ec.BalanceAt(ctx, addr, parent)
+		if err != nil {
+			return fmt.Errorf("failed to fetch balance of %v: %v", addr, err)
+		}
+		nonce, err := ec.NonceAt(ctx, addr, parent)
+		if err != nil {
+			return fmt.Errorf("failed to fetch nonce of %v: %v", addr, err)
+		}
+		code, err := ec.CodeAt(ctx, addr, parent)
+		if err != nil {
+			return fmt.Errorf("failed to fetch code of %v: %v", addr, err)
+		}
+		c.Alloc[addr] = types.Account{Balance: balance, Nonce: nonce, Code: code}
+	}
+	return nil
+}
+
+// fetchBlockHashes retrieves the hashes of the ancestors accessible to BLOCKHASH.
+func (c *blockCapture) fetchBlockHashes(ctx context.Context, client *rpc.Client, number uint64) error {
+	var (
+		first   = number - min(number, 256)
+		batch   []rpc.BatchElem
+		headers = make([]struct {
+			Hash common.Hash `json:"hash"`
+		}, number-first)
+	)
+	for i := range headers {
+		batch = append(batch, rpc.BatchElem{
+			Method: "eth_getBlockByNumber",
+			Args:   []interface{}{hexutil.EncodeUint64(first + uint64(i)), false},
+			Result: &headers[i],
+		})
+	}
+	if err := client.BatchCallContext(ctx, batch); err != nil {
+		return fmt.Errorf("failed to fetch block hashes: %v", err)
+	}
+	c.Env.BlockHashes = make(map[math.HexOrDecimal64]common.Hash, len(headers))
+	for i, elem := range batch {
+		if elem.Error != nil {
+			return fmt.Errorf("failed to fetch block %d: %v", first+uint64(i), elem.Error)
+		}
+		c.Env.BlockHashes[math.HexOrDecimal64(first+uint64(i))] = headers[i].Hash
+	}
+	return nil
+}
+
+// write stores the capture as t8n input files in the given directory.
+func (c *blockCapture) write(dir string) error {
+	if dir != "" {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return err
+		}
+	}
+	txs, err := rlp.EncodeToBytes(c.Txs)
+	if err != nil {
+		return err
+	}
+	for name, data := range map[string]interface{}{
+		"alloc.json": c.Alloc,
+		"env.json":   c.Env,
+		"txs.rlp":    hexutil.Bytes(txs),
+	} {
+		blob, err := json.MarshalIndent(data, "", "  ")
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(filepath.Join(dir, name), blob, 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// t8nFork returns the t8n fork name and mining reward for executing a block.
+func t8nFork(config *params.ChainConfig, header *types.Header) (string, int64) {
+	var (
+		number = header.Number
+		time   = header.Time
+		reward = ethash.FrontierBlockReward
+		fork   string
+	)
+	switch {
+	case config.IsConstantinople(number):
+		reward = ethash.ConstantinopleBlockReward
+	case config.IsByzantium(number):
+		reward = ethash.ByzantiumBlockReward
+	}
+	switch {
+	case config.IsPrague(number, time):
+		fork = "Prague"
+	case config.IsCancun(number, time):
+		fork = "Cancun"
+	case config.IsShanghai(number, time):
+		fork = "Shanghai"
+	case header.Difficulty.Sign() == 0:
+		fork = "Paris"
+	case config.IsGrayGlacier(number):
+		fork = "GrayGlacier"
+	case config.IsArrowGlacier(number):
+		fork = "ArrowGlacier"
+	case config.IsLondon(number):
+		fork = "London"
+	case config.IsBerlin(number):
+		fork = "Berlin"
+	case config.IsMuirGlacier(number):
+		fork = "MuirGlacier"
+	case config.IsIstanbul(number):
+		fork = "Istanbul"
+	case config.IsPetersburg(number):
+		fork = "ConstantinopleFix"
+	case config.IsConstantinople(number):
+		fork = "Constantinople"
+	case config.IsByzantium(number):
+		fork = "Byzantium"
+	case config.IsEIP158(number):
+		fork = "EIP158"
+	case config.IsEIP150(number):
+		fork = "EIP150"
+	case config.IsHomestead(number):
+		fork = "Homestead"
+	default:
+		fork = "Frontier"
+	}
+	// Proof-of-stake blocks don't have a mining reward.
+	if header.Difficulty.Sign() == 0 {
+		return fork, -1
+	}
+	return fork, int64(reward.Uint64())
+}
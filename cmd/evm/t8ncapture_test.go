@@ -0,0 +1,124 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/internal/cmdtest"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+func TestT8nCapture(t *testing.T) {
+	// The contract stores the parent block hash under the caller and increments
+	// a counter in slot 1.
+	contract := common.HexToAddress("0xc0de")
+	stack, beacon := startTestNode(t, types.GenesisAlloc{
+		contract: {
+			Balance: new(big.Int),
+			Code:    common.FromHex("436001900340" + "3355" + "600154600101600155" + "00"),
+			Storage: map[common.Hash]common.Hash{common.BigToHash(big.NewInt(1)): common.BigToHash(big.NewInt(41))},
+		},
+	})
+	rpcClient := stack.Attach()
+	client := ethclient.NewClient(rpcClient)
+
+	sendTx(t, client, beacon, testAddr, 0) // move past the genesis block
+	// Include multiple transactions touching the same slots into one block.
+	signer := types.LatestSignerForChainID(params.AllDevChainProtocolChanges.ChainID)
+	for nonce := uint64(1); nonce <= 3; nonce++ {
+		tx := types.MustSignNewTx(testKey, signer, &types.DynamicFeeTx{
+			ChainID:   params.AllDevChainProtocolChanges.ChainID,
+			Nonce:     nonce,
+			To:        &contract,
+			Value:     big.NewInt(int64(nonce)),
+			Gas:       100_000,
+			GasTipCap: big.NewInt(params.GWei),
+			GasFeeCap: big.NewInt(2 * params.GWei),
+		})
+		if err := client.SendTransaction(context.Background(), tx); err != nil {
+			t.Fatal(err)
+		}
+	}
+	beacon.Commit()
+
+	capture, err := captureBlock(context.Background(), rpcClient, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(capture.Txs) != 3 {
+		t.Fatalf("wrong number of transactions: %d", len(capture.Txs))
+	}
+	// The alloc holds the state before the block, not the one before the last tx.
+	if v := capture.Alloc[contract].Storage[common.BigToHash(big.NewInt(1))]; v != common.BigToHash(big.NewInt(41)) {
+		t.Fatalf("wrong prestate of counter: %v", v)
+	}
+	if n := capture.Alloc[testAddr].Nonce; n != 1 {
+		t.Fatalf("wrong prestate nonce of sender: %d", n)
+	}
+	if len(capture.Env.BlockHashes) != 2 {
+		t.Fatalf("wrong number of block hashes: %d", len(capture.Env.BlockHashes))
+	}
+	dir := t.TempDir()
+	if err := capture.write(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	// Execute the captured block with t8n and check the results.
+	fork, reward := t8nFork(params.AllDevChainProtocolChanges, capture.Header)
+	tt := cmdtest.NewTestCmd(t, nil)
+	tt.Run("evm-test", "t8n",
+		"--input.alloc", filepath.Join(dir, "alloc.json"),
+		"--input.env", filepath.Join(dir, "env.json"),
+		"--input.txs", filepath.Join(dir, "txs.rlp"),
+		"--state.fork", fork,
+		"--state.chainid", capture.ChainID.String(),
+		"--state.reward", fmt.Sprint(reward),
+		"--output.result", "stdout", "--output.alloc", "", "--output.body", "",
+	)
+	output := tt.Output()
+	tt.WaitExit()
+	if status := tt.ExitStatus(); status != 0 {
+		t.Fatalf("t8n failed with exit status %d: %s", status, tt.StderrText())
+	}
+	var out struct {
+		Result struct {
+			ReceiptRoot common.Hash         `json:"receiptsRoot"`
+			GasUsed     math.HexOrDecimal64 `json:"gasUsed"`
+			Rejected    []json.RawMessage   `json:"rejected"`
+		} `json:"result"`
+	}
+	if err := json.Unmarshal(output, &out); err != nil {
+		t.Fatalf("invalid t8n output: %v\n%s", err, output)
+	}
+	if len(out.Result.Rejected) > 0 {
+		t.Fatalf("transactions rejected: %s", out.Result.Rejected)
+	}
+	if uint64(out.Result.GasUsed) != capture.Header.GasUsed || out.Result.ReceiptRoot != capture.Header.ReceiptHash {
+		t.Fatalf("wrong result: gas used %d, receipts root %v; want %d, %v",
+			out.Result.GasUsed, out.Result.ReceiptRoot, capture.Header.GasUsed, capture.Header.ReceiptHash)
+	}
+}
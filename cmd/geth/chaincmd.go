@@ -53,13 +53,18 @@ var (
 			utils.CachePreimagesFlag,
 			utils.OverrideCancun,
 			utils.OverrideVerkle,
+			utils.StateSnapshotFlag,
 		}, utils.DatabaseFlags),
 		Description: `
 The init command initializes a new genesis block and definition for the network.
 This is a destructive action and changes the network in which you will be
 participating.
 
-It expects the genesis file as argument.`,
+It expects the genesis file as argument.
+
+If --state-snapshot is given, the header chain and head state exported by
+'geth snapshot export-state' are imported on top of the genesis block, and
+the node starts up as if snap sync had just completed at the exported head.`,
 	}
 	dumpGenesisCommand = &cli.Command{
 		Action:    dumpGenesis,
@@ -238,6 +243,11 @@ func initGenesis(ctx *cli.Context) error {
 
 	log.Info("Successfully wrote genesis state", "database", "chaindata", "hash", hash)
 
+	if ctx.IsSet(utils.StateSnapshotFlag.Name) {
+		if err := utils.ImportStateSnapshot(chaindb, triedb, ctx.String(utils.StateSnapshotFlag.Name)); err != nil {
+			utils.Fatalf("Failed to import state snapshot: %v", err)
+		}
+	}
 	return nil
 }
 
@@ -148,6 +148,20 @@ as the backend data source, making this command a lot faster.
 
 The argument is interpreted as block number or hash. If none is provided, the latest
 block is used.
+`,
+			},
+			{
+				Action:    snapshotExportState,
+				Name:      "export-state",
+				Usage:     "Export the header chain and head state into a state snapshot file",
+				ArgsUsage: "<dumpfile>",
+				Flags:     slices.Concat(utils.NetworkFlags, utils.DatabaseFlags),
+				Description: `
+The export-state command exports the canonical header chain and the full state
+of the current head block into a single file. The file can be imported into a
+new node with 'geth init --state-snapshot <dumpfile> <genesisPath>', which will
+verify it against the genesis block and state root and bootstrap the node as if
+snap sync had just completed.
 `,
 			},
 			{
@@ -619,6 +633,39 @@ func dumpState(ctx *cli.Context) error {
 	return nil
 }
 
+// snapshotExportState exports the header chain and the head state into a file
+// usable for bootstrapping new nodes.
+func snapshotExportState(ctx *cli.Context) error {
+	if ctx.NArg() != 1 {
+		utils.Fatalf("This command requires an argument.")
+	}
+	stack, _ := makeConfigNode(ctx)
+	defer stack.Close()
+
+	chaindb := utils.MakeChainDatabase(ctx, stack, true)
+	defer chaindb.Close()
+
+	triedb := utils.MakeTrieDatabase(ctx, chaindb, false, true, false)
+	defer triedb.Close()
+
+	headBlock := rawdb.ReadHeadBlock(chaindb)
+	if headBlock == nil {
+		log.Error("Failed to load head block")
+		return errors.New("no head block")
+	}
+	snapConfig := snapshot.Config{
+		CacheSize:  256,
+		Recovery:   false,
+		NoBuild:    true,
+		AsyncBuild: false,
+	}
+	snaptree, err := snapshot.New(snapConfig, chaindb, triedb, headBlock.Root())
+	if err != nil {
+		return err
+	}
+	return utils.ExportStateSnapshot(chaindb, snaptree, headBlock.Header(), ctx.Args().First())
+}
+
 // snapshotExportPreimages dumps the preimage data to a flat file.
 func snapshotExportPreimages(ctx *cli.Context) error {
 	if ctx.NArg() < 1 {
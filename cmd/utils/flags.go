@@ -200,6 +200,10 @@ var (
 		Name:  "nocode",
 		Usage: "Exclude contract code (save db lookups)",
 	}
+	StateSnapshotFlag = &cli.StringFlag{
+		Name:  "state-snapshot",
+		Usage: "Import the header chain and head state from a file created by 'geth snapshot export-state'",
+	}
 	StartKeyFlag = &cli.StringFlag{
 		Name:  "start",
 		Usage: "Start position. Either a hash or address",
@@ -249,6 +253,18 @@ var (
 		Value:    ethconfig.Defaults.SyncMode.String(),
 		Category: flags.StateCategory,
 	}
+	SyncBandwidthFlag = &cli.Uint64Flag{
+		Name:     "syncmode.bandwidth",
+		Usage:    "Maximum network bandwidth used by the chain and state syncers in bytes per second (0 = unlimited)",
+		Value:    ethconfig.Defaults.SyncBandwidth,
+		Category: flags.StateCategory,
+	}
+	SyncIOPSFlag = &cli.Uint64Flag{
+		Name:     "syncmode.iops",
+		Usage:    "Maximum disk write rate of the state syncer in 4KiB operations per second (0 = unlimited)",
+		Value:    ethconfig.Defaults.SyncIOPS,
+		Category: flags.StateCategory,
+	}
 	GCModeFlag = &cli.StringFlag{
 		Name:     "gcmode",
 		Usage:    `Blockchain garbage collection mode, only relevant in state.scheme=hash ("full", "archive")`,
@@ -805,6 +821,11 @@ var (
 		Usage:    "Sets DNS discovery entry points (use \"\" to disable DNS)",
 		Category: flags.NetworkingCategory,
 	}
+	DiscoveryFileFlag = &cli.StringFlag{
+		Name:     "discovery.file",
+		Usage:    "Nodes file (devp2p nodes.json format) to keep static peers in sync with, reloaded on change",
+		Category: flags.NetworkingCategory,
+	}
 	DiscoveryPortFlag = &cli.IntFlag{
 		Name:     "discovery.port",
 		Usage:    "Use a custom UDP port for P2P discovery",
@@ -1666,6 +1687,12 @@ func SetEthConfig(ctx *cli.Context, stack *node.Node, cfg *ethconfig.Config) {
 			Fatalf("invalid --syncmode flag: %v", err)
 		}
 	}
+	if ctx.IsSet(SyncBandwidthFlag.Name) {
+		cfg.SyncBandwidth = ctx.Uint64(SyncBandwidthFlag.Name)
+	}
+	if ctx.IsSet(SyncIOPSFlag.Name) {
+		cfg.SyncIOPS = ctx.Uint64(SyncIOPSFlag.Name)
+	}
 	if ctx.IsSet(NetworkIdFlag.Name) {
 		cfg.NetworkId = ctx.Uint64(NetworkIdFlag.Name)
 	}
@@ -1773,6 +1800,9 @@ func SetEthConfig(ctx *cli.Context, stack *node.Node, cfg *ethconfig.Config) {
 			cfg.EthDiscoveryURLs = SplitAndTrim(urls)
 		}
 	}
+	if ctx.IsSet(DiscoveryFileFlag.Name) {
+		cfg.NodesFile = ctx.String(DiscoveryFileFlag.Name)
+	}
 	// Override any default configs for hard coded networks.
 	switch {
 	case ctx.Bool(MainnetFlag.Name):
@@ -0,0 +1,509 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package utils
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state/snapshot"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/trie"
+	"github.com/ethereum/go-ethereum/triedb"
+)
+
+// stateSnapshotMagic is the leading identifier of state snapshot files.
+const stateSnapshotMagic = "gethstatesnap"
+
+// stateSnapshotHeader is the first element of a state snapshot file. It is
+// followed by the canonical headers from block 1 up to and including the head,
+// the body and receipts of the head block and finally the accounts of the head
+// state sorted by hash, each followed by its storage slots.
+//
+// Whenever a backwards-incompatible change is made, the Version header should
+// be bumped. If the importer sees a higher version, it should reject the import.
+type stateSnapshotHeader struct {
+	Magic    string      // Always set to 'gethstatesnap' for disambiguation
+	Version  uint64      // Version of the file format
+	Genesis  common.Hash // Hash of the genesis block the chain is built on
+	Number   uint64      // Number of the head block the state belongs to
+	Hash     common.Hash // Hash of the head block the state belongs to
+	Root     common.Hash // State root of the head block
+	UnixTime uint64      // Time when the snapshot was taken
+}
+
+// stateSnapshotAccount is a single account entry in a state snapshot file,
+// followed by Slots number of stateSnapshotSlot entries.
+type stateSnapshotAccount struct {
+	Hash    common.Hash // Hash of the account address
+	Account []byte      // Account in slim RLP encoding
+	Code    []byte      // Contract code of the account (nil for EOAs)
+	Slots   uint64      // Number of storage slots following the account
+}
+
+// stateSnapshotSlot is a single storage entry in a state snapshot file.
+type stateSnapshotSlot struct {
+	Hash  common.Hash // Hash of the storage slot key
+	Value []byte      // RLP encoded storage slot value
+}
+
+// ExportStateSnapshot exports the header chain and the state of the given head
+// block into a single file, which can be used to bootstrap a new node without
+// having to sync the state from the network. If the suffix is 'gz', gzip
+// compression is used.
+func ExportStateSnapshot(db ethdb.Database, snaptree *snapshot.Tree, head *types.Header, fn string) error {
+	log.Info("Exporting state snapshot", "file", fn, "number", head.Number, "hash", head.Hash(), "root", head.Root)
+
+	body := rawdb.ReadBody(db, head.Hash(), head.Number.Uint64())
+	if body == nil {
+		return fmt.Errorf("missing body of head block #%d", head.Number)
+	}
+	receipts := rawdb.ReadRawReceipts(db, head.Hash(), head.Number.Uint64())
+	if len(receipts) != len(body.Transactions) {
+		return fmt.Errorf("missing receipts of head block #%d", head.Number)
+	}
+	fh, err := os.OpenFile(fn, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, os.ModePerm)
+	if err != nil {
+		return err
+	}
+	defer fh.Close()
+
+	var (
+		writer io.Writer = fh
+		gz     *gzip.Writer
+	)
+	if strings.HasSuffix(fn, ".gz") {
+		gz = gzip.NewWriter(writer)
+		writer = gz
+	}
+	buf := bufio.NewWriter(writer)
+	if err := exportStateSnapshot(buf, db, snaptree, head, body, receipts); err != nil {
+		return err
+	}
+	// Flush and close everything explicitly, a failure here means a truncated file
+	if err := buf.Flush(); err != nil {
+		return err
+	}
+	if gz != nil {
+		if err := gz.Close(); err != nil {
+			return err
+		}
+	}
+	if err := fh.Close(); err != nil {
+		return err
+	}
+	log.Info("Exported state snapshot", "file", fn)
+	return nil
+}
+
+// exportStateSnapshot writes the contents of a state snapshot file.
+func exportStateSnapshot(writer io.Writer, db ethdb.Database, snaptree *snapshot.Tree, head *types.Header, body *types.Body, receipts types.Receipts) error {
+	// Write the file header and the canonical header chain
+	meta := stateSnapshotHeader{
+		Magic:    stateSnapshotMagic,
+		Version:  0,
+		Genesis:  rawdb.ReadCanonicalHash(db, 0),
+		Number:   head.Number.Uint64(),
+		Hash:     head.Hash(),
+		Root:     head.Root,
+		UnixTime: uint64(time.Now().Unix()),
+	}
+	if err := rlp.Encode(writer, &meta); err != nil {
+		return err
+	}
+	var (
+		start  = time.Now()
+		logged = time.Now()
+	)
+	for number := uint64(1); number <= meta.Number; number++ {
+		hash := rawdb.ReadCanonicalHash(db, number)
+		header := rawdb.ReadHeader(db, hash, number)
+		if header == nil {
+			return fmt.Errorf("missing canonical header #%d", number)
+		}
+		if err := rlp.Encode(writer, header); err != nil {
+			return err
+		}
+		if time.Since(logged) > 8*time.Second {
+			log.Info("Exporting header chain", "number", number, "elapsed", common.PrettyDuration(time.Since(start)))
+			logged = time.Now()
+		}
+	}
+	if err := rlp.Encode(writer, body); err != nil {
+		return err
+	}
+	storageReceipts := make([]*types.ReceiptForStorage, len(receipts))
+	for i, receipt := range receipts {
+		storageReceipts[i] = (*types.ReceiptForStorage)(receipt)
+	}
+	if err := rlp.Encode(writer, storageReceipts); err != nil {
+		return err
+	}
+	// Iterate over the state snapshot and write out all accounts with their
+	// code and storage slots
+	accIt, err := snaptree.AccountIterator(head.Root, common.Hash{})
+	if err != nil {
+		return err
+	}
+	defer accIt.Release()
+
+	var accounts, slots uint64
+	for accIt.Next() {
+		account, err := types.FullAccount(accIt.Account())
+		if err != nil {
+			return err
+		}
+		entry := stateSnapshotAccount{
+			Hash:    accIt.Hash(),
+			Account: accIt.Account(),
+		}
+		if codeHash := common.BytesToHash(account.CodeHash); codeHash != types.EmptyCodeHash {
+			if entry.Code = rawdb.ReadCode(db, codeHash); len(entry.Code) == 0 {
+				return fmt.Errorf("missing code %x of account %x", codeHash, entry.Hash)
+			}
+		}
+		var storage []stateSnapshotSlot
+		if account.Root != types.EmptyRootHash {
+			stIt, err := snaptree.StorageIterator(head.Root, entry.Hash, common.Hash{})
+			if err != nil {
+				return err
+			}
+			for stIt.Next() {
+				storage = append(storage, stateSnapshotSlot{Hash: stIt.Hash(), Value: common.CopyBytes(stIt.Slot())})
+			}
+			err = stIt.Error()
+			stIt.Release()
+			if err != nil {
+				return err
+			}
+		}
+		entry.Slots = uint64(len(storage))
+		if err := rlp.Encode(writer, &entry); err != nil {
+			return err
+		}
+		for i := range storage {
+			if err := rlp.Encode(writer, &storage[i]); err != nil {
+				return err
+			}
+		}
+		accounts++
+		slots += entry.Slots
+
+		if time.Since(logged) > 8*time.Second {
+			log.Info("Exporting state snapshot", "accounts", accounts, "slots", slots, "elapsed", common.PrettyDuration(time.Since(start)))
+			logged = time.Now()
+		}
+	}
+	if err := accIt.Error(); err != nil {
+		return err
+	}
+	log.Info("Exported state", "accounts", accounts, "slots", slots, "elapsed", common.PrettyDuration(time.Since(start)))
+	return nil
+}
+
+// ImportStateSnapshot imports a header chain and head state previously exported
+// with ExportStateSnapshot into a database containing only the genesis block.
+// The header chain is verified to link up with the local genesis, the state is
+// verified against the head header's state root and the trie nodes are rebuilt
+// locally. On success, the database is left in the same state as if snap sync
+// had just completed at the exported head block.
+func ImportStateSnapshot(db ethdb.Database, tdb *triedb.Database, fn string) error {
+	log.Info("Importing state snapshot", "file", fn)
+
+	fh, err := os.Open(fn)
+	if err != nil {
+		return err
+	}
+	defer fh.Close()
+
+	var reader io.Reader = bufio.NewReader(fh)
+	if strings.HasSuffix(fn, ".gz") {
+		if reader, err = gzip.NewReader(reader); err != nil {
+			return err
+		}
+	}
+	stream := rlp.NewStream(reader, 0)
+
+	// Read the file header and ensure it's compatible with the local chain
+	var meta stateSnapshotHeader
+	if err := stream.Decode(&meta); err != nil {
+		return fmt.Errorf("could not decode header: %v", err)
+	}
+	if meta.Magic != stateSnapshotMagic {
+		return errors.New("incompatible data, wrong magic")
+	}
+	if meta.Version != 0 {
+		return fmt.Errorf("incompatible version %d, (support only 0)", meta.Version)
+	}
+	genesis := rawdb.ReadCanonicalHash(db, 0)
+	if genesis == (common.Hash{}) {
+		return errors.New("database not initialized with a genesis block")
+	}
+	if genesis != meta.Genesis {
+		return fmt.Errorf("genesis mismatch: local %x, snapshot %x", genesis, meta.Genesis)
+	}
+	if head := rawdb.ReadHeadHeaderHash(db); head != genesis {
+		return errors.New("database already contains blocks beyond genesis")
+	}
+	log.Info("Importing header chain", "number", meta.Number, "hash", meta.Hash, "age",
+		common.PrettyDuration(time.Since(time.Unix(int64(meta.UnixTime), 0))))
+
+	// Import and verify the header chain, linking it to the local genesis
+	var (
+		start  = time.Now()
+		logged = time.Now()
+		batch  = db.NewBatch()
+		parent = rawdb.ReadHeader(db, genesis, 0)
+		td     = rawdb.ReadTd(db, genesis, 0)
+	)
+	if parent == nil || td == nil {
+		return errors.New("missing genesis header")
+	}
+	for number := uint64(1); number <= meta.Number; number++ {
+		header := new(types.Header)
+		if err := stream.Decode(header); err != nil {
+			return fmt.Errorf("header #%d: failed to parse: %v", number, err)
+		}
+		if header.Number.Uint64() != number {
+			return fmt.Errorf("header #%d: number mismatch: have %d", number, header.Number)
+		}
+		if header.ParentHash != parent.Hash() {
+			return fmt.Errorf("header #%d: non contiguous chain: parent %x, want %x", number, header.ParentHash, parent.Hash())
+		}
+		hash := header.Hash()
+		td = new(big.Int).Add(td, header.Difficulty)
+
+		rawdb.WriteHeader(batch, header)
+		rawdb.WriteTd(batch, hash, number, td)
+		rawdb.WriteCanonicalHash(batch, hash, number)
+
+		if batch.ValueSize() > ethdb.IdealBatchSize {
+			if err := batch.Write(); err != nil {
+				return err
+			}
+			batch.Reset()
+		}
+		if time.Since(logged) > 8*time.Second {
+			log.Info("Importing header chain", "number", number, "elapsed", common.PrettyDuration(time.Since(start)))
+			logged = time.Now()
+		}
+		parent = header
+	}
+	if parent.Hash() != meta.Hash {
+		return fmt.Errorf("head hash mismatch: have %x, want %x", parent.Hash(), meta.Hash)
+	}
+	if parent.Root != meta.Root {
+		return fmt.Errorf("head root mismatch: have %x, want %x", parent.Root, meta.Root)
+	}
+	// Import the body of the head block, so the chain can be continued from it
+	body := new(types.Body)
+	if err := stream.Decode(body); err != nil {
+		return fmt.Errorf("failed to parse head body: %v", err)
+	}
+	block := types.NewBlockWithHeader(parent).WithBody(*body)
+	if hash := types.DeriveSha(types.Transactions(body.Transactions), trie.NewStackTrie(nil)); hash != parent.TxHash {
+		return fmt.Errorf("head transaction root mismatch: have %x, want %x", hash, parent.TxHash)
+	}
+	if hash := types.CalcUncleHash(body.Uncles); hash != parent.UncleHash {
+		return fmt.Errorf("head uncle root mismatch: have %x, want %x", hash, parent.UncleHash)
+	}
+	if parent.WithdrawalsHash != nil {
+		if hash := types.DeriveSha(types.Withdrawals(body.Withdrawals), trie.NewStackTrie(nil)); hash != *parent.WithdrawalsHash {
+			return fmt.Errorf("head withdrawal root mismatch: have %x, want %x", hash, *parent.WithdrawalsHash)
+		}
+	}
+	// Import the receipts of the head block. The storage encoding omits the
+	// transaction types, which are needed to verify them against the header.
+	var storageReceipts []*types.ReceiptForStorage
+	if err := stream.Decode(&storageReceipts); err != nil {
+		return fmt.Errorf("failed to parse head receipts: %v", err)
+	}
+	if len(storageReceipts) != len(body.Transactions) {
+		return fmt.Errorf("head receipt count mismatch: have %d, want %d", len(storageReceipts), len(body.Transactions))
+	}
+	receipts := make(types.Receipts, len(storageReceipts))
+	for i, receipt := range storageReceipts {
+		receipts[i] = (*types.Receipt)(receipt)
+		receipts[i].Type = body.Transactions[i].Type()
+	}
+	if hash := types.DeriveSha(receipts, trie.NewStackTrie(nil)); hash != parent.ReceiptHash {
+		return fmt.Errorf("head receipt root mismatch: have %x, want %x", hash, parent.ReceiptHash)
+	}
+	rawdb.WriteBody(batch, block.Hash(), block.NumberU64(), body)
+	rawdb.WriteReceipts(batch, block.Hash(), block.NumberU64(), receipts)
+	rawdb.WriteTxLookupEntriesByBlock(batch, block)
+	if err := batch.Write(); err != nil {
+		return err
+	}
+	batch.Reset()
+
+	// Path based trie nodes are keyed by their location, so any nodes left over
+	// from the genesis state would corrupt the imported tries. Wipe them out.
+	scheme := tdb.Scheme()
+	if scheme == rawdb.PathScheme {
+		if err := wipeTrieNodes(db); err != nil {
+			return err
+		}
+	}
+	// Import the accounts and storage slots, regenerating the tries as we go
+	var (
+		accounts uint64
+		slots    uint64
+		last     common.Hash
+
+		nodeWriter = func(owner common.Hash) trie.OnTrieNode {
+			return func(path []byte, hash common.Hash, blob []byte) {
+				rawdb.WriteTrieNode(batch, owner, path, hash, blob, scheme)
+			}
+		}
+		accTrie = trie.NewStackTrie(nodeWriter(common.Hash{}))
+	)
+	for {
+		var entry stateSnapshotAccount
+		if err := stream.Decode(&entry); err != nil {
+			if err == io.EOF {
+				break
+			}
+			return fmt.Errorf("account #%d: failed to parse: %v", accounts, err)
+		}
+		if accounts > 0 && bytes.Compare(entry.Hash[:], last[:]) <= 0 {
+			return fmt.Errorf("account %x: out of order, previous %x", entry.Hash, last)
+		}
+		account, err := types.FullAccount(entry.Account)
+		if err != nil {
+			return fmt.Errorf("account %x: invalid encoding: %v", entry.Hash, err)
+		}
+		if len(entry.Code) > 0 {
+			if hash := crypto.Keccak256Hash(entry.Code); hash != common.BytesToHash(account.CodeHash) {
+				return fmt.Errorf("account %x: code hash mismatch: have %x, want %x", entry.Hash, hash, account.CodeHash)
+			}
+			rawdb.WriteCode(batch, common.BytesToHash(account.CodeHash), entry.Code)
+		} else if common.BytesToHash(account.CodeHash) != types.EmptyCodeHash {
+			return fmt.Errorf("account %x: missing code", entry.Hash)
+		}
+		// Rebuild the storage trie of the account and verify it against the root
+		var (
+			stTrie = trie.NewStackTrie(nodeWriter(entry.Hash))
+			prev   common.Hash
+		)
+		for i := uint64(0); i < entry.Slots; i++ {
+			var slot stateSnapshotSlot
+			if err := stream.Decode(&slot); err != nil {
+				return fmt.Errorf("account %x: slot #%d: failed to parse: %v", entry.Hash, i, err)
+			}
+			if i > 0 && bytes.Compare(slot.Hash[:], prev[:]) <= 0 {
+				return fmt.Errorf("account %x: slot %x: out of order, previous %x", entry.Hash, slot.Hash, prev)
+			}
+			if err := stTrie.Update(slot.Hash[:], slot.Value); err != nil {
+				return err
+			}
+			rawdb.WriteStorageSnapshot(batch, entry.Hash, slot.Hash, slot.Value)
+			prev = slot.Hash
+
+			if batch.ValueSize() > ethdb.IdealBatchSize {
+				if err := batch.Write(); err != nil {
+					return err
+				}
+				batch.Reset()
+			}
+		}
+		if root := stTrie.Hash(); root != account.Root {
+			return fmt.Errorf("account %x: storage root mismatch: have %x, want %x", entry.Hash, root, account.Root)
+		}
+		blob, err := rlp.EncodeToBytes(account)
+		if err != nil {
+			return err
+		}
+		if err := accTrie.Update(entry.Hash[:], blob); err != nil {
+			return err
+		}
+		rawdb.WriteAccountSnapshot(batch, entry.Hash, entry.Account)
+
+		if batch.ValueSize() > ethdb.IdealBatchSize {
+			if err := batch.Write(); err != nil {
+				return err
+			}
+			batch.Reset()
+		}
+		accounts++
+		slots += entry.Slots
+		last = entry.Hash
+
+		if time.Since(logged) > 8*time.Second {
+			log.Info("Importing state snapshot", "accounts", accounts, "slots", slots, "elapsed", common.PrettyDuration(time.Since(start)))
+			logged = time.Now()
+		}
+	}
+	if root := accTrie.Hash(); root != meta.Root {
+		return fmt.Errorf("state root mismatch: have %x, want %x", root, meta.Root)
+	}
+	// State verified, mark the head block as fully synced and activate it
+	rawdb.WriteLastPivotNumber(batch, meta.Number)
+	rawdb.WriteHeadHeaderHash(batch, meta.Hash)
+	rawdb.WriteHeadFastBlockHash(batch, meta.Hash)
+	rawdb.WriteHeadBlockHash(batch, meta.Hash)
+	if err := batch.Write(); err != nil {
+		return err
+	}
+	if scheme == rawdb.PathScheme {
+		if err := tdb.Enable(meta.Root); err != nil {
+			return err
+		}
+	}
+	log.Info("Imported state snapshot", "file", fn, "number", meta.Number, "hash", meta.Hash,
+		"accounts", accounts, "slots", slots, "elapsed", common.PrettyDuration(time.Since(start)))
+	return nil
+}
+
+// wipeTrieNodes deletes all path based account and storage trie nodes from the
+// database.
+func wipeTrieNodes(db ethdb.Database) error {
+	batch := db.NewBatch()
+	for _, prefix := range [][]byte{rawdb.TrieNodeAccountPrefix, rawdb.TrieNodeStoragePrefix} {
+		it := db.NewIterator(prefix, nil)
+		for it.Next() {
+			key := it.Key()
+			if !rawdb.IsAccountTrieNode(key) && !rawdb.IsStorageTrieNode(key) {
+				continue
+			}
+			batch.Delete(key)
+			if batch.ValueSize() > ethdb.IdealBatchSize {
+				if err := batch.Write(); err != nil {
+					it.Release()
+					return err
+				}
+				batch.Reset()
+			}
+		}
+		it.Release()
+	}
+	return batch.Write()
+}
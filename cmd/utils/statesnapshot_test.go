@@ -0,0 +1,152 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package utils
+
+import (
+	"math/big"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/trie"
+	"github.com/ethereum/go-ethereum/triedb"
+	"github.com/ethereum/go-ethereum/triedb/pathdb"
+)
+
+func TestStateSnapshotImportAndExport(t *testing.T) {
+	t.Parallel()
+	for _, scheme := range []string{rawdb.HashScheme, rawdb.PathScheme} {
+		for _, fn := range []string{"state.rlp", "state.rlp.gz"} {
+			testStateSnapshotImportAndExport(t, scheme, fn)
+		}
+	}
+}
+
+func testStateSnapshotImportAndExport(t *testing.T, scheme string, fn string) {
+	var (
+		key, _   = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		address  = crypto.PubkeyToAddress(key.PublicKey)
+		contract = common.Address{0xcc}
+		genesis  = &core.Genesis{
+			Config: params.TestChainConfig,
+			Alloc: types.GenesisAlloc{
+				address: {Balance: big.NewInt(1000000000000000000)},
+				contract: {
+					Code:    []byte{byte(vm.CALLVALUE), byte(vm.NUMBER), byte(vm.SSTORE)}, // storage[number] = value
+					Storage: map[common.Hash]common.Hash{{0xff}: {0x01}},
+				},
+			},
+		}
+		signer = types.LatestSigner(genesis.Config)
+	)
+	// Generate a chain with some value transfers and storage writes
+	db, blocks, _ := core.GenerateChainWithGenesis(genesis, ethash.NewFaker(), 32, func(i int, g *core.BlockGen) {
+		tx, err := types.SignNewTx(key, signer, &types.DynamicFeeTx{
+			ChainID:   genesis.Config.ChainID,
+			Nonce:     uint64(i),
+			GasTipCap: common.Big0,
+			GasFeeCap: g.PrevBlock(-1).BaseFee(),
+			Gas:       50000,
+			To:        &contract,
+			Value:     big.NewInt(int64(i + 1)),
+		})
+		if err != nil {
+			t.Fatalf("error creating tx: %v", err)
+		}
+		g.AddTx(tx)
+	})
+	chain, err := core.NewBlockChain(db, core.DefaultCacheConfigWithScheme(scheme), genesis, nil, ethash.NewFaker(), vm.Config{}, nil)
+	if err != nil {
+		t.Fatalf("unable to initialize chain: %v", err)
+	}
+	defer chain.Stop()
+
+	if _, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("error inserting chain: %v", err)
+	}
+	head := chain.CurrentBlock()
+
+	// Export the head state and import it into a fresh database
+	path := filepath.Join(t.TempDir(), fn)
+	if err := ExportStateSnapshot(db, chain.Snapshots(), head, path); err != nil {
+		t.Fatalf("error exporting state snapshot: %v", err)
+	}
+	var (
+		importdb = rawdb.NewMemoryDatabase()
+		config   = triedb.HashDefaults
+	)
+	if scheme == rawdb.PathScheme {
+		config = &triedb.Config{PathDB: pathdb.Defaults}
+	}
+	tdb := triedb.NewDatabase(importdb, config)
+	genesis.MustCommit(importdb, tdb)
+
+	if err := ImportStateSnapshot(importdb, tdb, path); err != nil {
+		t.Fatalf("error importing state snapshot: %v", err)
+	}
+	tdb.Close()
+
+	// Ensure the imported chain is usable and matches the exported one
+	imported, err := core.NewBlockChain(importdb, core.DefaultCacheConfigWithScheme(scheme), genesis, nil, ethash.NewFaker(), vm.Config{}, nil)
+	if err != nil {
+		t.Fatalf("unable to initialize imported chain: %v", err)
+	}
+	defer imported.Stop()
+
+	if have, want := imported.CurrentBlock().Hash(), head.Hash(); have != want {
+		t.Fatalf("head block mismatch: have %x, want %x", have, want)
+	}
+	if have, want := imported.CurrentSnapBlock().Hash(), head.Hash(); have != want {
+		t.Fatalf("head snap block mismatch: have %x, want %x", have, want)
+	}
+	if have, want := imported.GetReceiptsByHash(head.Hash()), chain.GetReceiptsByHash(head.Hash()); len(have) != 1 || types.DeriveSha(have, trie.NewStackTrie(nil)) != types.DeriveSha(want, trie.NewStackTrie(nil)) {
+		t.Fatalf("head receipts mismatch: have %d receipts", len(have))
+	}
+	want, _ := chain.State()
+	have, err := imported.State()
+	if err != nil {
+		t.Fatalf("failed to open imported state: %v", err)
+	}
+	for _, addr := range []common.Address{address, contract} {
+		if have.GetBalance(addr).Cmp(want.GetBalance(addr)) != 0 {
+			t.Errorf("balance mismatch for %x: have %v, want %v", addr, have.GetBalance(addr), want.GetBalance(addr))
+		}
+		if have.GetNonce(addr) != want.GetNonce(addr) {
+			t.Errorf("nonce mismatch for %x: have %d, want %d", addr, have.GetNonce(addr), want.GetNonce(addr))
+		}
+	}
+	for i := uint64(0); i <= head.Number.Uint64(); i++ {
+		slot := common.BigToHash(new(big.Int).SetUint64(i))
+		if have.GetState(contract, slot) != want.GetState(contract, slot) {
+			t.Errorf("storage mismatch for slot %d: have %x, want %x", i, have.GetState(contract, slot), want.GetState(contract, slot))
+		}
+	}
+	// Ensure the chain can be continued on top of the imported state
+	more, _ := core.GenerateChain(genesis.Config, chain.GetBlockByHash(head.Hash()), ethash.NewFaker(), db, 1, func(i int, g *core.BlockGen) {
+		g.SetCoinbase(common.Address{0x01})
+	})
+	if _, err := imported.InsertChain(more); err != nil {
+		t.Fatalf("failed to extend imported chain: %v", err)
+	}
+}
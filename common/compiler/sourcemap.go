@@ -0,0 +1,283 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package compiler
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// SourceLocation is a decoded entry of a solc source map. It describes the range
+// of source code an instruction was generated from.
+type SourceLocation struct {
+	Start         int  // byte offset into the source file
+	Length        int  // length of the source range in bytes
+	File          int  // index of the source file, -1 for compiler-generated code
+	Jump          byte // 'i' for jumps into a function, 'o' for returns, '-' otherwise
+	ModifierDepth int  // depth of the modifier the instruction belongs to
+}
+
+// ParseSourceMap decodes a compressed solc source map, as found in the srcmap and
+// srcmap-runtime fields of the --combined-json output. The result contains one
+// location per instruction.
+func ParseSourceMap(srcmap string) ([]SourceLocation, error) {
+	if srcmap == "" {
+		return nil, nil
+	}
+	var (
+		entries = strings.Split(srcmap, ";")
+		locs    = make([]SourceLocation, len(entries))
+		prev    = SourceLocation{File: -1, Jump: '-'}
+	)
+	for i, entry := range entries {
+		loc := prev
+		for j, field := range strings.Split(entry, ":") {
+			if field == "" {
+				continue // empty fields are inherited from the previous entry
+			}
+			if j == 3 {
+				if len(field) != 1 {
+					return nil, fmt.Errorf("entry %d: invalid jump type %q", i, field)
+				}
+				loc.Jump = field[0]
+				continue
+			}
+			v, err := strconv.Atoi(field)
+			if err != nil {
+				return nil, fmt.Errorf("entry %d: %v", i, err)
+			}
+			switch j {
+			case 0:
+				loc.Start = v
+			case 1:
+				loc.Length = v
+			case 2:
+				loc.File = v
+			case 4:
+				loc.ModifierDepth = v
+			default:
+				return nil, fmt.Errorf("entry %d: too many fields", i)
+			}
+		}
+		locs[i], prev = loc, loc
+	}
+	return locs, nil
+}
+
+// SourceMap resolves program counters of a contract's bytecode to source locations.
+type SourceMap struct {
+	locs   []SourceLocation
+	pcs    []uint64       // program counters of the instructions
+	instrs map[uint64]int // instruction index by program counter
+}
+
+// NewSourceMap creates a source map for the given bytecode.
+func NewSourceMap(code []byte, srcmap string) (*SourceMap, error) {
+	locs, err := ParseSourceMap(srcmap)
+	if err != nil {
+		return nil, err
+	}
+	m := &SourceMap{locs: locs, instrs: make(map[uint64]int)}
+	for pc, i := uint64(0), 0; pc < uint64(len(code)); i++ {
+		m.pcs = append(m.pcs, pc)
+		m.instrs[pc] = i
+		// Skip over the immediate data of PUSH1..PUSH32.
+		if op := code[pc]; op >= 0x60 && op <= 0x7f {
+			pc += uint64(op - 0x5f)
+		}
+		pc++
+	}
+	return m, nil
+}
+
+// Lookup returns the source location of the instruction at pc. It returns false if
+// pc doesn't point to an instruction covered by the source map.
+func (m *SourceMap) Lookup(pc uint64) (SourceLocation, bool) {
+	i, ok := m.instrs[pc]
+	if !ok || i >= len(m.locs) {
+		return SourceLocation{}, false
+	}
+	return m.locs[i], true
+}
+
+// Instructions returns the program counters of all instructions of the code, in
+// ascending order.
+func (m *SourceMap) Instructions() []uint64 {
+	return m.pcs
+}
+
+// SourceFile is a source file referenced by source maps.
+type SourceFile struct {
+	Name    string
+	Content []byte // nil if the file could not be read
+	lines   []int  // offsets of line starts
+}
+
+func newSourceFile(name string, content []byte) *SourceFile {
+	f := &SourceFile{Name: name, Content: content, lines: []int{0}}
+	for i, c := range content {
+		if c == '\n' {
+			f.lines = append(f.lines, i+1)
+		}
+	}
+	return f
+}
+
+// Position converts a byte offset into a 1-based line and column number.
+func (f *SourceFile) Position(offset int) (line, col int) {
+	i := sort.Search(len(f.lines), func(i int) bool { return f.lines[i] > offset }) - 1
+	if i < 0 {
+		i = 0
+	}
+	return i + 1, offset - f.lines[i] + 1
+}
+
+// Line returns the content of the given 1-based line, without the line terminator.
+func (f *SourceFile) Line(n int) string {
+	if n < 1 || n > len(f.lines) || f.Content == nil {
+		return ""
+	}
+	start, end := f.lines[n-1], len(f.Content)
+	if n < len(f.lines) {
+		end = f.lines[n] - 1
+	}
+	return strings.TrimRight(string(f.Content[start:end]), "\r")
+}
+
+// Lines returns the number of lines in the file.
+func (f *SourceFile) Lines() int {
+	return len(f.lines)
+}
+
+// ContractDebugInfo holds the source maps of a single compiled contract.
+type ContractDebugInfo struct {
+	Name       string
+	Code       []byte     // creation bytecode
+	Runtime    []byte     // deployed bytecode
+	CodeMap    *SourceMap // source map of the creation bytecode
+	RuntimeMap *SourceMap // source map of the deployed bytecode
+}
+
+// DebugInfo holds the source maps and source files of a solc compilation.
+type DebugInfo struct {
+	Sources   []*SourceFile // indexed by the file ID used in source maps
+	Contracts []*ContractDebugInfo
+}
+
+// LoadDebugInfo reads the output of solc --combined-json, which needs to contain
+// at least bin, bin-runtime, srcmap and srcmap-runtime. Source files are looked up
+// relative to the current directory and to the directory of the JSON file.
+func LoadDebugInfo(path string) (*DebugInfo, error) {
+	combinedJSON, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	readFile := func(name string) ([]byte, error) {
+		content, err := os.ReadFile(name)
+		if err != nil && !filepath.IsAbs(name) {
+			content, err = os.ReadFile(filepath.Join(filepath.Dir(path), name))
+		}
+		return content, err
+	}
+	return ParseDebugInfo(combinedJSON, readFile)
+}
+
+// ParseDebugInfo decodes the output of solc --combined-json. The readFile function
+// is used to load the source files. Sources which can't be read are kept without
+// content, so locations can still be resolved to file names.
+func ParseDebugInfo(combinedJSON []byte, readFile func(name string) ([]byte, error)) (*DebugInfo, error) {
+	var output struct {
+		Contracts map[string]struct {
+			Bin           string `json:"bin"`
+			BinRuntime    string `json:"bin-runtime"`
+			SrcMap        string `json:"srcmap"`
+			SrcMapRuntime string `json:"srcmap-runtime"`
+		}
+		SourceList []string               `json:"sourceList"`
+		Sources    map[string]interface{} `json:"sources"`
+	}
+	if err := json.Unmarshal(combinedJSON, &output); err != nil {
+		return nil, err
+	}
+	// Source IDs are assigned in the order of the source list. Older compilers omit
+	// the list, in which case the IDs follow the sorted source unit names.
+	names := output.SourceList
+	if names == nil {
+		for name := range output.Sources {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+	}
+	info := new(DebugInfo)
+	for _, name := range names {
+		content, _ := readFile(name)
+		info.Sources = append(info.Sources, newSourceFile(name, content))
+	}
+	for name, c := range output.Contracts {
+		// Skip contracts with unlinked library references, their code can't be matched.
+		code, err1 := hex.DecodeString(c.Bin)
+		runtime, err2 := hex.DecodeString(c.BinRuntime)
+		if err1 != nil || err2 != nil {
+			continue
+		}
+		contract := &ContractDebugInfo{Name: name, Code: code, Runtime: runtime}
+		if contract.CodeMap, err1 = NewSourceMap(code, c.SrcMap); err1 != nil {
+			return nil, fmt.Errorf("contract %s: invalid srcmap: %v", name, err1)
+		}
+		if contract.RuntimeMap, err2 = NewSourceMap(runtime, c.SrcMapRuntime); err2 != nil {
+			return nil, fmt.Errorf("contract %s: invalid srcmap-runtime: %v", name, err2)
+		}
+		info.Contracts = append(info.Contracts, contract)
+	}
+	sort.Slice(info.Contracts, func(i, j int) bool { return info.Contracts[i].Name < info.Contracts[j].Name })
+	return info, nil
+}
+
+// Find returns the contract and source map matching the given code. Deployed code
+// must match exactly, while creation code may be followed by constructor arguments.
+// It returns nil if no contract matches.
+func (d *DebugInfo) Find(code []byte) (*ContractDebugInfo, *SourceMap) {
+	if d == nil || len(code) == 0 {
+		return nil, nil
+	}
+	for _, c := range d.Contracts {
+		if bytes.Equal(code, c.Runtime) {
+			return c, c.RuntimeMap
+		}
+	}
+	for _, c := range d.Contracts {
+		if len(c.Code) > 0 && bytes.HasPrefix(code, c.Code) {
+			return c, c.CodeMap
+		}
+	}
+	return nil, nil
+}
+
+// File returns the source file for a location, or nil for compiler-generated code.
+func (d *DebugInfo) File(loc SourceLocation) *SourceFile {
+	if loc.File < 0 || loc.File >= len(d.Sources) {
+		return nil
+	}
+	return d.Sources[loc.File]
+}
@@ -0,0 +1,111 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package compiler
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestParseSourceMap(t *testing.T) {
+	locs, err := ParseSourceMap("1:2:1;:9;2:1:2:i;;3::-1:o:1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []SourceLocation{
+		{Start: 1, Length: 2, File: 1, Jump: '-'},
+		{Start: 1, Length: 9, File: 1, Jump: '-'},
+		{Start: 2, Length: 1, File: 2, Jump: 'i'},
+		{Start: 2, Length: 1, File: 2, Jump: 'i'},
+		{Start: 3, Length: 1, File: -1, Jump: 'o', ModifierDepth: 1},
+	}
+	if !reflect.DeepEqual(locs, want) {
+		t.Fatalf("wrong locations:\nhave %+v\nwant %+v", locs, want)
+	}
+	for _, invalid := range []string{"x", "1:2:3:ii", "1:2:3:i:0:5"} {
+		if _, err := ParseSourceMap(invalid); err == nil {
+			t.Errorf("invalid source map %q accepted", invalid)
+		}
+	}
+}
+
+func TestSourceMapLookup(t *testing.T) {
+	// PUSH2 0x0102, PUSH1 0x01, ADD
+	code := common.FromHex("61010260010100")
+	m, err := NewSourceMap(code, "0:1:0;1:1;2:1;3:1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for pc, start := range map[uint64]int{0: 0, 3: 1, 5: 2, 6: 3} {
+		loc, ok := m.Lookup(pc)
+		if !ok || loc.Start != start {
+			t.Errorf("pc %d: wrong location %+v (ok %v), want start %d", pc, loc, ok, start)
+		}
+	}
+	for _, pc := range []uint64{1, 2, 4, 7} {
+		if _, ok := m.Lookup(pc); ok {
+			t.Errorf("pc %d: location found for non-instruction", pc)
+		}
+	}
+}
+
+func TestDebugInfo(t *testing.T) {
+	combinedJSON := `{
+		"contracts": {
+			"a.sol:A": {"bin": "6001600255", "bin-runtime": "600100", "srcmap": "0:1:0", "srcmap-runtime": "4:3:0"},
+			"b.sol:Lib": {"bin": "__$abc$__", "bin-runtime": "00", "srcmap": "", "srcmap-runtime": ""}
+		},
+		"sourceList": ["a.sol", "b.sol"]
+	}`
+	readFile := func(name string) ([]byte, error) {
+		if name == "a.sol" {
+			return []byte("one\ntwo\r\nthree"), nil
+		}
+		return nil, errors.New("not found")
+	}
+	info, err := ParseDebugInfo([]byte(combinedJSON), readFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(info.Sources) != 2 || info.Sources[1].Content != nil {
+		t.Fatalf("wrong sources: %+v", info.Sources)
+	}
+	if len(info.Contracts) != 1 {
+		t.Fatalf("unlinked contract not skipped: %d contracts", len(info.Contracts))
+	}
+	// Deployed code and creation code with constructor arguments are found.
+	c, m := info.Find(common.FromHex("600100"))
+	if c == nil || m != c.RuntimeMap {
+		t.Fatal("deployed code not found")
+	}
+	if c, m = info.Find(common.FromHex("6001600255ffff")); c == nil || m != c.CodeMap {
+		t.Fatal("creation code not found")
+	}
+	if unknown, _ := info.Find(common.FromHex("6001")); unknown != nil {
+		t.Fatal("unknown code found")
+	}
+	// Resolve the runtime location to a line.
+	loc, _ := c.RuntimeMap.Lookup(0)
+	file := info.File(loc)
+	line, col := file.Position(loc.Start)
+	if line != 2 || col != 1 || file.Line(line) != "two" || file.Line(3) != "three" {
+		t.Fatalf("wrong position %d:%d, line %q", line, col, file.Line(line))
+	}
+}
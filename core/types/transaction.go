@@ -48,6 +48,7 @@ const (
 	AccessListTxType = 0x01
 	DynamicFeeTxType = 0x02
 	BlobTxType       = 0x03
+	SetCodeTxType    = 0x04
 )
 
 // Transaction is an Ethereum transaction.
@@ -205,6 +206,8 @@ func (tx *Transaction) decodeTyped(b []byte) (TxData, error) {
 		inner = new(DynamicFeeTx)
 	case BlobTxType:
 		inner = new(BlobTx)
+	case SetCodeTxType:
+		inner = new(SetCodeTx)
 	default:
 		return nil, ErrTxTypeNotSupported
 	}
@@ -413,6 +416,30 @@ func (tx *Transaction) BlobHashes() []common.Hash {
 	return nil
 }
 
+// SetCodeAuthorizations returns the authorizations of set code transactions, nil otherwise.
+func (tx *Transaction) SetCodeAuthorizations() []SetCodeAuthorization {
+	if setcodetx, ok := tx.inner.(*SetCodeTx); ok {
+		return setcodetx.AuthList
+	}
+	return nil
+}
+
+// SetCodeAuthorities returns the accounts which authorized the delegations of
+// a set code transaction, skipping the authorizations with invalid signatures.
+func (tx *Transaction) SetCodeAuthorities() []common.Address {
+	setcodetx, ok := tx.inner.(*SetCodeTx)
+	if !ok {
+		return nil
+	}
+	auths := make([]common.Address, 0, len(setcodetx.AuthList))
+	for _, auth := range setcodetx.AuthList {
+		if addr, err := auth.Authority(); err == nil {
+			auths = append(auths, addr)
+		}
+	}
+	return auths
+}
+
 // BlobTxSidecar returns the sidecar of a blob transaction, nil otherwise.
 func (tx *Transaction) BlobTxSidecar() *BlobTxSidecar {
 	if blobtx, ok := tx.inner.(*BlobTx); ok {
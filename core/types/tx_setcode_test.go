@@ -0,0 +1,208 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package types
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/holiman/uint256"
+)
+
+// TestParseDelegation tests a few possible delegation designator values and
+// ensures they are parsed correctly.
+func TestParseDelegation(t *testing.T) {
+	addr := common.Address{0x42}
+	for _, tt := range []struct {
+		val  []byte
+		want *common.Address
+	}{
+		{ // simple correct delegation
+			val:  append(DelegationPrefix, addr.Bytes()...),
+			want: &addr,
+		},
+		{ // wrong address size
+			val: append(DelegationPrefix, addr.Bytes()[0:19]...),
+		},
+		{ // short address
+			val: append(DelegationPrefix, 0x42),
+		},
+		{ // long address
+			val: append(append(DelegationPrefix, addr.Bytes()...), 0x42),
+		},
+		{ // wrong prefix size
+			val: append(DelegationPrefix[:2], addr.Bytes()...),
+		},
+		{ // wrong prefix
+			val: append([]byte{0xef, 0x01, 0x01}, addr.Bytes()...),
+		},
+		{ // wrong prefix
+			val: append([]byte{0xef, 0x00, 0x00}, addr.Bytes()...),
+		},
+		{ // no prefix
+			val: addr.Bytes(),
+		},
+		{ // no address
+			val: DelegationPrefix,
+		},
+	} {
+		got, ok := ParseDelegation(tt.val)
+		if ok && tt.want == nil {
+			t.Fatalf("expected fail, got %s", got.Hex())
+		}
+		if !ok && tt.want != nil {
+			t.Fatalf("failed to parse, want %s", tt.want.Hex())
+		}
+	}
+	if d := AddressToDelegation(addr); !bytes.Equal(d, append(DelegationPrefix, addr.Bytes()...)) {
+		t.Fatalf("wrong delegation %x", d)
+	}
+	if !bytes.Equal(DelegationPrefix, []byte{0xef, 0x01, 0x00}) {
+		t.Fatalf("delegation prefix modified: %x", DelegationPrefix)
+	}
+}
+
+func TestSetCodeAuthorization(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	auth, err := SignSetCode(key, SetCodeAuthorization{
+		ChainID: *uint256.NewInt(1),
+		Address: common.Address{0x42},
+		Nonce:   7,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	authority, err := auth.Authority()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := crypto.PubkeyToAddress(key.PublicKey); authority != want {
+		t.Fatalf("wrong authority: have %v, want %v", authority, want)
+	}
+	// The signed hash is keccak256(0x05 || rlp([chain_id, address, nonce])).
+	enc, _ := rlp.EncodeToBytes([]interface{}{big.NewInt(1), common.Address{0x42}, uint64(7)})
+	if want := crypto.Keccak256Hash(append([]byte{0x05}, enc...)); auth.SigHash() != want {
+		t.Fatalf("wrong signature hash: have %x, want %x", auth.SigHash(), want)
+	}
+	// Changing any signed field changes the authority.
+	modified := auth
+	modified.Nonce++
+	if other, err := modified.Authority(); err == nil && other == authority {
+		t.Fatal("authority unchanged after modifying nonce")
+	}
+	// Malleable signatures are rejected.
+	malleable := auth
+	malleable.S.Sub(uint256.MustFromBig(crypto.S256().Params().N), &malleable.S)
+	if _, err := malleable.Authority(); err == nil {
+		t.Fatal("expected error for high s value")
+	}
+	// JSON encoding roundtrips.
+	blob, err := json.Marshal(auth)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var dec SetCodeAuthorization
+	if err := json.Unmarshal(blob, &dec); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(dec, auth) {
+		t.Fatalf("JSON roundtrip mismatch: have %+v, want %+v", dec, auth)
+	}
+	if err := json.Unmarshal([]byte(`{"chainId":"0x1","address":"0x4200000000000000000000000000000000000000","nonce":"0x7"}`), &dec); err == nil {
+		t.Fatal("expected error for missing signature")
+	}
+}
+
+func TestSetCodeTransaction(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	auth, _ := SignSetCode(key, SetCodeAuthorization{
+		ChainID: *uint256.NewInt(1),
+		Address: common.Address{0x42},
+		Nonce:   1,
+	})
+	signer := NewPragueSigner(big.NewInt(1))
+	tx, err := SignNewTx(key, signer, &SetCodeTx{
+		ChainID:   uint256.NewInt(1),
+		Nonce:     2,
+		GasTipCap: uint256.NewInt(1),
+		GasFeeCap: uint256.NewInt(10),
+		Gas:       100000,
+		To:        common.Address{0x01},
+		Value:     uint256.NewInt(5),
+		Data:      []byte{0x01, 0x02},
+		AuthList:  []SetCodeAuthorization{auth},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tx.Type() != SetCodeTxType {
+		t.Fatalf("wrong type %d", tx.Type())
+	}
+	from, err := Sender(signer, tx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := crypto.PubkeyToAddress(key.PublicKey); from != want {
+		t.Fatalf("wrong sender: have %v, want %v", from, want)
+	}
+	if authorities := tx.SetCodeAuthorities(); len(authorities) != 1 || authorities[0] != from {
+		t.Fatalf("wrong authorities %v", authorities)
+	}
+	// The authorization list is part of the signature hash.
+	modified := tx.inner.copy().(*SetCodeTx)
+	modified.AuthList = nil
+	if signer.Hash(NewTx(modified)) == signer.Hash(tx) {
+		t.Fatal("signature hash doesn't cover the authorization list")
+	}
+	// Older signers reject the transaction.
+	if _, err := Sender(NewCancunSigner(big.NewInt(1)), tx); err == nil {
+		t.Fatal("expected error from cancun signer")
+	}
+	// Binary encoding roundtrips.
+	enc, err := tx.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if enc[0] != SetCodeTxType {
+		t.Fatalf("wrong type byte %x", enc[0])
+	}
+	var decoded Transaction
+	if err := decoded.UnmarshalBinary(enc); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.Hash() != tx.Hash() || !reflect.DeepEqual(decoded.SetCodeAuthorizations(), tx.SetCodeAuthorizations()) {
+		t.Fatal("binary roundtrip mismatch")
+	}
+	// JSON encoding roundtrips.
+	blob, err := json.Marshal(tx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var parsed Transaction
+	if err := json.Unmarshal(blob, &parsed); err != nil {
+		t.Fatal(err)
+	}
+	if parsed.Hash() != tx.Hash() {
+		t.Fatalf("JSON roundtrip mismatch: %s", blob)
+	}
+}
@@ -0,0 +1,136 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package vm
+
+import (
+	"math"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/lru"
+	gmath "github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// blockCacheSize is the number of codes whose basic block analysis is cached.
+const blockCacheSize = 1024
+
+// blockCache caches the basic block analysis of codes across transactions. The
+// jump table is part of the key, as the analysis depends on the gas costs of the
+// fork.
+var blockCache = lru.NewCache[blockCacheKey, *codeBlocks](blockCacheSize)
+
+type blockCacheKey struct {
+	codeHash common.Hash
+	table    *JumpTable
+}
+
+// basicBlock is a sequence of instructions without dynamic gas costs, which can
+// only be entered at its first instruction. Its constant gas and the stack bounds
+// of all its instructions are checked at once before executing it.
+//
+// Instructions reading the remaining gas (GAS) or changing the control flow end a
+// block. An instruction failing within a block (e.g. INVALID) consumes all gas, so
+// charging the gas of the instructions after it has no observable effect.
+type basicBlock struct {
+	gas      uint64 // sum of the constant gas of the instructions
+	ops      uint32 // number of instructions
+	minStack int32  // minimum stack size on entry, to not underflow in any instruction
+	maxStack int32  // maximum stack size on entry, to not overflow in any instruction
+}
+
+// codeBlocks is the basic block analysis of a code.
+type codeBlocks struct {
+	index  []uint16 // number of the block starting at a pc plus one, zero if none starts there
+	blocks []basicBlock
+}
+
+// at returns the block starting at pc, or nil if there is none.
+func (c *codeBlocks) at(pc uint64) *basicBlock {
+	if pc < uint64(len(c.index)) {
+		if n := c.index[pc]; n != 0 {
+			return &c.blocks[n-1]
+		}
+	}
+	return nil
+}
+
+// analyseBlocks splits the code into the basic blocks of the jump table.
+// Instructions with dynamic gas or undefined in the jump table are not part of
+// any block and are executed one by one.
+func analyseBlocks(code []byte, table *JumpTable) *codeBlocks {
+	var (
+		c     = &codeBlocks{index: make([]uint16, len(code))}
+		block = -1 // index of the block being built, -1 if none
+		depth int  // stack size relative to the entry of the current block
+	)
+	for pc := uint64(0); pc < uint64(len(code)); pc++ {
+		op := OpCode(code[pc])
+		operation := table[op]
+		if op == JUMPDEST || operation.dynamicGas != nil || operation.undefined {
+			block = -1
+		}
+		if operation.dynamicGas == nil && !operation.undefined {
+			// Gas costs overridden by the chain config may be large enough for
+			// the sum to overflow, end the block before that happens.
+			if block >= 0 {
+				if _, overflow := gmath.SafeAdd(c.blocks[block].gas, operation.constantGas); overflow {
+					block = -1
+				}
+			}
+			if block < 0 {
+				if len(c.blocks) == math.MaxUint16 {
+					break // remaining code is executed one by one
+				}
+				c.blocks = append(c.blocks, basicBlock{maxStack: math.MaxInt32})
+				block, depth = len(c.blocks)-1, 0
+				c.index[pc] = uint16(len(c.blocks))
+			}
+			b := &c.blocks[block]
+			b.gas += operation.constantGas
+			b.ops++
+			b.minStack = max(b.minStack, int32(operation.minStack-depth))
+			b.maxStack = min(b.maxStack, int32(operation.maxStack-depth))
+			depth += int(params.StackLimit) - operation.maxStack // pushes minus pops
+
+			switch op {
+			case JUMP, JUMPI, STOP, GAS:
+				block = -1
+			}
+		}
+		if op.IsPush() {
+			pc += uint64(op - PUSH0)
+		}
+	}
+	return c
+}
+
+// blocks returns the basic block analysis of the contract's code, or nil if the
+// code is not cacheable.
+func (in *EVMInterpreter) blocks(contract *Contract) *codeBlocks {
+	// Interpreters with extra EIPs use private copies of the jump tables, which
+	// would flood the cache.
+	if contract.CodeHash == (common.Hash{}) || len(in.evm.Config.ExtraEips) > 0 {
+		return nil
+	}
+	key := blockCacheKey{contract.CodeHash, in.table}
+	if c, ok := blockCache.Get(key); ok {
+		return c
+	}
+	c := analyseBlocks(contract.Code, in.table)
+	blockCache.Add(key, c)
+	return c
+}
@@ -0,0 +1,161 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package vm
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+	"math/rand"
+	"slices"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/tracing"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/holiman/uint256"
+)
+
+func TestAnalyseBlocks(t *testing.T) {
+	// PUSH1 1, PUSH1 2, ADD, JUMPDEST, POP, SLOAD, GAS, STOP
+	code := common.FromHex("6001600201" + "5b50" + "54" + "5a" + "00")
+	c := analyseBlocks(code, &cancunInstructionSet)
+
+	if want := []uint16{1, 0, 0, 0, 0, 2, 0, 0, 3, 4}; !slices.Equal(c.index, want) {
+		t.Fatalf("wrong index: %v, want %v", c.index, want)
+	}
+	want := []basicBlock{
+		{gas: 9, ops: 3, minStack: 0, maxStack: 1022},
+		{gas: 3, ops: 2, minStack: 1, maxStack: 1024}, // SLOAD has dynamic gas
+		{gas: 2, ops: 1, minStack: 0, maxStack: 1023}, // GAS ends the block
+		{gas: 0, ops: 1, minStack: 0, maxStack: 1024},
+	}
+	if !slices.Equal(c.blocks, want) {
+		t.Fatalf("wrong blocks: %+v, want %+v", c.blocks, want)
+	}
+	// SLOAD has constant gas before Berlin.
+	c = analyseBlocks(code, &frontierInstructionSet)
+	if len(c.blocks) != 3 || c.blocks[1].ops != 4 || c.blocks[1].gas != 1+2+50+2 {
+		t.Fatalf("wrong frontier blocks: %+v", c.blocks)
+	}
+}
+
+// Tests that blocks are split before their constant gas overflows.
+func TestAnalyseBlocksGasOverflow(t *testing.T) {
+	table := cancunInstructionSet
+	add := *table[ADD]
+	add.constantGas = 1 << 63
+	table[ADD] = &add
+
+	// PUSH1 1, PUSH1 1, PUSH1 1, ADD, ADD, STOP
+	c := analyseBlocks(common.FromHex("600160016001010100"), &table)
+	want := []basicBlock{
+		{gas: 9 + 1<<63, ops: 4, minStack: 0, maxStack: 1021},
+		{gas: 1 << 63, ops: 2, minStack: 2, maxStack: 1025},
+	}
+	if !slices.Equal(c.blocks, want) {
+		t.Fatalf("wrong blocks: %+v, want %+v", c.blocks, want)
+	}
+}
+
+// executeCode runs the code with the given gas and returns the observable results.
+func executeCode(config *params.ChainConfig, code []byte, gas uint64, tracer *tracing.Hooks) string {
+	var (
+		address = common.BytesToAddress([]byte("contract"))
+		vmctx   = BlockContext{
+			CanTransfer: func(StateDB, common.Address, *uint256.Int) bool { return true },
+			Transfer:    func(StateDB, common.Address, common.Address, *uint256.Int) {},
+			GetHash:     func(n uint64) common.Hash { return common.BigToHash(new(big.Int).SetUint64(n)) },
+			BlockNumber: big.NewInt(1),
+			Difficulty:  big.NewInt(0),
+			Random:      &common.Hash{},
+			BaseFee:     big.NewInt(1),
+			BlobBaseFee: big.NewInt(1),
+			GasLimit:    30_000_000,
+		}
+	)
+	statedb, _ := state.New(types.EmptyRootHash, state.NewDatabaseForTesting())
+	statedb.CreateAccount(address)
+	statedb.SetCode(address, code)
+	statedb.SetState(address, common.Hash{}, common.Hash{1})
+	statedb.Finalise(true)
+
+	evm := NewEVM(vmctx, TxContext{GasPrice: big.NewInt(1)}, statedb, config, Config{Tracer: tracer})
+	ret, left, err := evm.Call(AccountRef(common.Address{}), address, []byte{1, 2, 3}, gas, new(uint256.Int))
+	return fmt.Sprintf("ret %x, gas %d, err %v, root %v", ret, left, err, statedb.IntermediateRoot(true))
+}
+
+// checkBlocks executes the code with and without basic block batching, which is
+// disabled by tracing, and compares the results.
+func checkBlocks(t *testing.T, code []byte, gas uint64) {
+	configs := []*params.ChainConfig{
+		{ChainID: big.NewInt(1)}, // Frontier
+		params.MergedTestChainConfig,
+	}
+	for _, config := range configs {
+		have := executeCode(config, code, gas, nil)
+		want := executeCode(config, code, gas, &tracing.Hooks{})
+		if have != want {
+			t.Fatalf("code %x, gas %d: results differ\nhave %s\nwant %s", code, gas, have, want)
+		}
+	}
+}
+
+// randomCode returns code with a lot of stack operations and jumps.
+func randomCode(rnd *rand.Rand) []byte {
+	var code []byte
+	for len(code) < 100 {
+		switch n := rnd.Intn(10); {
+		case n < 3:
+			code = append(code, byte(PUSH1), byte(rnd.Intn(len(code)+8)))
+		case n < 4:
+			code = append(code, byte(JUMPDEST))
+		case n < 5:
+			code = append(code, []byte{byte(JUMP), byte(JUMPI), byte(GAS), byte(DUP1), byte(SWAP1)}[rnd.Intn(5)])
+		default:
+			code = append(code, byte(rnd.Intn(256)))
+		}
+	}
+	return code
+}
+
+func TestBlocksEquivalence(t *testing.T) {
+	for _, code := range loopInterruptTests {
+		checkBlocks(t, common.FromHex(code), 100_000)
+	}
+	rnd := rand.New(rand.NewSource(1))
+	for i := 0; i < 2000; i++ {
+		checkBlocks(t, randomCode(rnd), uint64(rnd.Intn(20_000)))
+	}
+	// A contract running out of gas within a block, and a stack underflow in
+	// the middle of a block.
+	checkBlocks(t, bytes.Repeat([]byte{byte(PUSH1), 1}, 10), 3*10+1)
+	checkBlocks(t, bytes.Repeat([]byte{byte(PUSH1), 1}, 10), 3*10-1)
+	checkBlocks(t, common.FromHex("600101015b00"), 100)
+}
+
+func FuzzBlocks(f *testing.F) {
+	for _, code := range loopInterruptTests {
+		f.Add(common.FromHex(code), uint64(100_000))
+	}
+	f.Add(common.FromHex("600101015b00"), uint64(100))
+	f.Fuzz(func(t *testing.T, code []byte, gas uint64) {
+		checkBlocks(t, code, gas%1_000_000)
+	})
+}
@@ -24,6 +24,7 @@ import (
 	"maps"
 	"math"
 	"math/big"
+	"slices"
 
 	"github.com/consensys/gnark-crypto/ecc"
 	bls12381 "github.com/consensys/gnark-crypto/ecc/bls12-381"
@@ -174,6 +175,21 @@ func init() {
 }
 
 func activePrecompiledContracts(rules params.Rules) PrecompiledContracts {
+	contracts := forkPrecompiledContracts(rules)
+	if len(rules.Precompiles) == 0 {
+		return contracts
+	}
+	contracts = maps.Clone(contracts)
+	for addr, name := range rules.Precompiles {
+		if p := registeredPrecompile(name); p != nil {
+			contracts[addr] = p
+		}
+	}
+	return contracts
+}
+
+// forkPrecompiledContracts returns the precompiled contracts defined by the fork.
+func forkPrecompiledContracts(rules params.Rules) PrecompiledContracts {
 	switch {
 	case rules.IsVerkle:
 		return PrecompiledContractsVerkle
@@ -199,6 +215,22 @@ func ActivePrecompiledContracts(rules params.Rules) PrecompiledContracts {
 
 // ActivePrecompiles returns the precompile addresses enabled with the current configuration.
 func ActivePrecompiles(rules params.Rules) []common.Address {
+	addresses := forkPrecompiles(rules)
+	if len(rules.Precompiles) == 0 {
+		return addresses
+	}
+	var custom []common.Address
+	for addr, name := range rules.Precompiles {
+		if registeredPrecompile(name) != nil {
+			custom = append(custom, addr)
+		}
+	}
+	slices.SortFunc(custom, common.Address.Cmp)
+	return append(slices.Clone(addresses), custom...)
+}
+
+// forkPrecompiles returns the precompile addresses defined by the fork.
+func forkPrecompiles(rules params.Rules) []common.Address {
 	switch {
 	case rules.IsPrague:
 		return PrecompiledAddressesPrague
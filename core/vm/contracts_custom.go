@@ -0,0 +1,77 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package vm
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+var (
+	customPrecompiles   = make(map[string]PrecompiledContract)
+	customPrecompilesMu sync.RWMutex
+)
+
+// RegisterPrecompile registers the implementation of a custom precompiled
+// contract under the given name. The contract is activated at an address and
+// time by the Precompiles of the chain configuration referring to the name.
+//
+// Precompiles must be registered before the chain is set up, typically from an
+// init function of the embedding program. Registering a name twice panics.
+func RegisterPrecompile(name string, p PrecompiledContract) {
+	customPrecompilesMu.Lock()
+	defer customPrecompilesMu.Unlock()
+
+	if name == "" || p == nil {
+		panic("vm: invalid precompile registration")
+	}
+	if _, ok := customPrecompiles[name]; ok {
+		panic(fmt.Sprintf("vm: precompile %q registered twice", name))
+	}
+	customPrecompiles[name] = p
+}
+
+// registeredPrecompile returns the custom precompile registered under the name,
+// or nil if there is none.
+func registeredPrecompile(name string) PrecompiledContract {
+	customPrecompilesMu.RLock()
+	defer customPrecompilesMu.RUnlock()
+
+	return customPrecompiles[name]
+}
+
+// CheckPrecompiles checks that the custom precompiles of the chain configuration
+// are registered and don't clash with each other or the precompiles of any fork.
+func CheckPrecompiles(config *params.ChainConfig) error {
+	seen := make(map[common.Address]bool)
+	for _, p := range config.Precompiles {
+		if registeredPrecompile(p.Name) == nil {
+			return fmt.Errorf("precompile %q at %v is not registered", p.Name, p.Address)
+		}
+		if _, ok := PrecompiledContractsVerkle[p.Address]; ok {
+			return fmt.Errorf("precompile %q at %v overrides a built-in precompile", p.Name, p.Address)
+		}
+		if seen[p.Address] {
+			return fmt.Errorf("multiple precompiles at %v", p.Address)
+		}
+		seen[p.Address] = true
+	}
+	return nil
+}
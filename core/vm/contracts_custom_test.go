@@ -0,0 +1,119 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package vm
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/big"
+	"slices"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/holiman/uint256"
+)
+
+// reverse is a custom precompile returning its input reversed.
+type reverse struct{}
+
+func (reverse) RequiredGas(input []byte) uint64 { return 100 + uint64(len(input)) }
+
+func (reverse) Run(input []byte) ([]byte, error) {
+	out := slices.Clone(input)
+	slices.Reverse(out)
+	return out, nil
+}
+
+func init() {
+	RegisterPrecompile("test-reverse", reverse{})
+}
+
+func TestCustomPrecompile(t *testing.T) {
+	var config params.ChainConfig
+	blob := []byte(`{
+		"chainId": 1337,
+		"homesteadBlock": 0, "eip150Block": 0, "eip155Block": 0, "eip158Block": 0,
+		"byzantiumBlock": 0, "constantinopleBlock": 0, "petersburgBlock": 0,
+		"istanbulBlock": 0, "berlinBlock": 0, "londonBlock": 0,
+		"precompiles": [{"name": "test-reverse", "address": "0x0000000000000000000000000000000000000100", "time": 10}]
+	}`)
+	if err := json.Unmarshal(blob, &config); err != nil {
+		t.Fatal(err)
+	}
+	if err := CheckPrecompiles(&config); err != nil {
+		t.Fatal(err)
+	}
+	addr := common.BytesToAddress([]byte{1, 0})
+
+	call := func(time uint64) ([]byte, uint64) {
+		statedb, _ := state.New(types.EmptyRootHash, state.NewDatabaseForTesting())
+		vmctx := BlockContext{
+			CanTransfer: func(StateDB, common.Address, *uint256.Int) bool { return true },
+			Transfer:    func(StateDB, common.Address, common.Address, *uint256.Int) {},
+			BlockNumber: big.NewInt(1),
+			Time:        time,
+		}
+		evm := NewEVM(vmctx, TxContext{}, statedb, &config, Config{})
+		ret, gas, err := evm.Call(AccountRef(common.Address{}), addr, []byte{1, 2, 3}, 1000, new(uint256.Int))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return ret, gas
+	}
+	// Before activation, the address is an empty account.
+	if ret, gas := call(9); len(ret) != 0 || gas != 1000 {
+		t.Fatalf("precompile active before activation: ret %x, gas %d", ret, gas)
+	}
+	if slices.Contains(ActivePrecompiles(config.Rules(big.NewInt(1), false, 9)), addr) {
+		t.Fatal("precompile warm before activation")
+	}
+	if ret, gas := call(10); !bytes.Equal(ret, []byte{3, 2, 1}) || gas != 1000-103 {
+		t.Fatalf("wrong precompile result: ret %x, gas %d", ret, gas)
+	}
+	rules := config.Rules(big.NewInt(1), false, 10)
+	if !slices.Contains(ActivePrecompiles(rules), addr) {
+		t.Fatal("precompile not warm after activation")
+	}
+	if _, ok := ActivePrecompiledContracts(rules)[addr]; !ok {
+		t.Fatal("precompile missing from active contracts")
+	}
+	// The built-in precompiles must not be modified.
+	if _, ok := PrecompiledContractsBerlin[addr]; ok || slices.Contains(PrecompiledAddressesBerlin, addr) {
+		t.Fatal("built-in precompiles modified")
+	}
+}
+
+func TestCheckPrecompiles(t *testing.T) {
+	tests := []struct {
+		precompiles []params.PrecompileConfig
+		ok          bool
+	}{
+		{[]params.PrecompileConfig{{Name: "test-reverse", Address: common.Address{0xff}}}, true},
+		{[]params.PrecompileConfig{{Name: "unknown", Address: common.Address{0xff}}}, false},
+		{[]params.PrecompileConfig{{Name: "test-reverse", Address: common.BytesToAddress([]byte{1})}}, false},
+		{[]params.PrecompileConfig{{Name: "test-reverse", Address: common.Address{0xff}}, {Name: "test-reverse", Address: common.Address{0xff}}}, false},
+	}
+	for i, test := range tests {
+		err := CheckPrecompiles(&params.ChainConfig{Precompiles: test.precompiles})
+		if ok := err == nil; ok != test.ok {
+			t.Errorf("test %d: wrong result, err %v", i, err)
+		}
+	}
+}
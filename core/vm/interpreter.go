@@ -109,6 +109,8 @@ func NewEVMInterpreter(evm *EVM) *EVMInterpreter {
 	case evm.chainRules.IsVerkle:
 		// TODO replace with proper instruction set when fork is specified
 		table = &verkleInstructionSet
+	case evm.chainRules.IsPrague:
+		table = &pragueInstructionSet
 	case evm.chainRules.IsCancun:
 		table = &cancunInstructionSet
 	case evm.chainRules.IsShanghai:
@@ -134,6 +136,8 @@ func NewEVMInterpreter(evm *EVM) *EVMInterpreter {
 	default:
 		table = &frontierInstructionSet
 	}
+	table = applyVMOverrides(table, evm.chainRules.VMOverrides)
+
 	var extraEips []int
 	if len(evm.Config.ExtraEips) > 0 {
 		// Deep-copy jumptable to prevent modification of opcodes in other tables
@@ -221,11 +225,39 @@ func (in *EVMInterpreter) Run(contract *Contract, input []byte, readOnly bool) (
 			}
 		}()
 	}
+	// Without tracing, the static gas and stack bounds are checked once per basic
+	// block instead of per instruction. Verkle charges for code chunks on every
+	// instruction, so it can't be batched.
+	var blocks *codeBlocks
+	if !debug && !in.evm.chainRules.IsEIP4762 {
+		blocks = in.blocks(contract)
+	}
 	// The Interpreter main run loop (contextual). This loop runs until either an
 	// explicit STOP, RETURN or SELFDESTRUCT is executed, an error occurred during
 	// the execution of one of the operations or until the done flag is set by the
 	// parent context.
 	for {
+		if blocks != nil {
+			// Execute the block starting at pc in one go if its gas and stack
+			// requirements are met. Otherwise, it's executed instruction by
+			// instruction below, failing at the same instruction as always.
+			if block := blocks.at(pc); block != nil && contract.Gas >= block.gas {
+				if sLen := int32(stack.len()); sLen >= block.minStack && sLen <= block.maxStack {
+					contract.Gas -= block.gas
+					for n := block.ops; n > 0; n-- {
+						op = contract.GetOp(pc)
+						if res, err = in.table[op].execute(&pc, in, callContext); err != nil {
+							break
+						}
+						pc++
+					}
+					if err != nil {
+						break
+					}
+					continue
+				}
+			}
+		}
 		if debug {
 			// Capture pre-execution values for tracing.
 			logged, pcCopy, gasCopy = false, pc, contract.Gas
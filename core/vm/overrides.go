@@ -0,0 +1,110 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package vm
+
+import (
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common/lru"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// overrideTables caches the jump tables with VM overrides applied, so that they
+// are not rebuilt for every transaction.
+var overrideTables = lru.NewCache[overrideTableKey, *JumpTable](64)
+
+// overrideTableKey identifies a jump table with VM overrides applied. The active
+// overrides are always a prefix of the overrides of the chain config, so they are
+// identified by their first element and count.
+type overrideTableKey struct {
+	base      *JumpTable
+	overrides *params.VMOverride
+	count     int
+}
+
+// applyVMOverrides returns the jump table of the fork with the active VM overrides
+// applied, or the jump table itself if there are none.
+func applyVMOverrides(base *JumpTable, overrides []params.VMOverride) *JumpTable {
+	if len(overrides) == 0 {
+		return base
+	}
+	key := overrideTableKey{base, &overrides[0], len(overrides)}
+	if table, ok := overrideTables.Get(key); ok {
+		return table
+	}
+	// Deep-copy jumptable to prevent modification of opcodes in other tables
+	table := copyJumpTable(base)
+	for i, override := range overrides {
+		if err := applyVMOverride(table, override); err != nil {
+			// The overrides are checked when setting up the chain, this is
+			// only reached by chain configs bypassing the checks.
+			log.Error("VM override activation failed", "override", i, "error", err)
+		}
+	}
+	overrideTables.Add(key, table)
+	return table
+}
+
+// applyVMOverride enables the EIPs of the override and then sets its gas costs.
+func applyVMOverride(table *JumpTable, override params.VMOverride) error {
+	for _, eip := range override.EIPs {
+		if err := EnableEIP(eip, table); err != nil {
+			return err
+		}
+	}
+	for name, gas := range override.GasCosts {
+		op, ok := stringToOp[name]
+		if !ok {
+			return fmt.Errorf("unknown opcode %q", name)
+		}
+		if gas > params.MaxGasLimit {
+			return fmt.Errorf("gas cost %d of %s exceeds maximum %d", gas, name, params.MaxGasLimit)
+		}
+		// Only the constant part of the gas cost is overridden, which would be
+		// charged on top of the dynamic cost instead of repricing the opcode.
+		if table[op].dynamicGas != nil {
+			return fmt.Errorf("gas cost of %s is dynamic and cannot be overridden", name)
+		}
+		table[op].constantGas = gas
+	}
+	return nil
+}
+
+// CheckVMOverrides checks that the VM overrides of the chain configuration only
+// refer to supported EIPs and known opcodes with a constant gas cost in every fork,
+// and that the gas costs are within bounds.
+func CheckVMOverrides(config *params.ChainConfig) error {
+	if len(config.VMOverrides) == 0 {
+		return nil
+	}
+	for _, base := range []*JumpTable{
+		&frontierInstructionSet, &homesteadInstructionSet, &tangerineWhistleInstructionSet,
+		&spuriousDragonInstructionSet, &byzantiumInstructionSet, &constantinopleInstructionSet,
+		&istanbulInstructionSet, &berlinInstructionSet, &londonInstructionSet,
+		&mergeInstructionSet, &shanghaiInstructionSet, &cancunInstructionSet,
+		&pragueInstructionSet, &verkleInstructionSet,
+	} {
+		table := copyJumpTable(base)
+		for i, override := range config.VMOverrides {
+			if err := applyVMOverride(table, override); err != nil {
+				return fmt.Errorf("invalid VM override %d: %w", i, err)
+			}
+		}
+	}
+	return nil
+}
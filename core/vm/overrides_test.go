@@ -0,0 +1,103 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package vm
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/holiman/uint256"
+)
+
+func TestVMOverrides(t *testing.T) {
+	var (
+		config      = *params.TestChainConfig // London
+		ten, twenty = uint64(10), uint64(20)
+	)
+	config.VMOverrides = []params.VMOverride{
+		{Time: &ten, EIPs: []int{3855}},
+		{Time: &twenty, GasCosts: map[string]uint64{"ADD": 10}},
+	}
+	if err := config.CheckConfigForkOrder(); err != nil {
+		t.Fatal(err)
+	}
+	if err := CheckVMOverrides(&config); err != nil {
+		t.Fatal(err)
+	}
+	var (
+		address = common.BytesToAddress([]byte("contract"))
+		code    = []byte{byte(PUSH0), byte(PUSH0), byte(ADD), byte(STOP)}
+	)
+	call := func(time uint64) (uint64, error) {
+		statedb, _ := state.New(types.EmptyRootHash, state.NewDatabaseForTesting())
+		statedb.SetCode(address, code)
+		vmctx := BlockContext{
+			CanTransfer: func(StateDB, common.Address, *uint256.Int) bool { return true },
+			Transfer:    func(StateDB, common.Address, common.Address, *uint256.Int) {},
+			BlockNumber: big.NewInt(1),
+			Time:        time,
+		}
+		evm := NewEVM(vmctx, TxContext{}, statedb, &config, Config{})
+		_, gas, err := evm.Call(AccountRef(common.Address{}), address, nil, 1000, new(uint256.Int))
+		return 1000 - gas, err
+	}
+	// PUSH0 is undefined before the first override.
+	if _, err := call(9); err == nil {
+		t.Fatal("PUSH0 executed before activation")
+	}
+	if used, err := call(10); err != nil || used != 2+2+3 {
+		t.Fatalf("wrong execution after EIP activation: used %d, err %v", used, err)
+	}
+	if used, err := call(20); err != nil || used != 2+2+10 {
+		t.Fatalf("wrong execution after gas override: used %d, err %v", used, err)
+	}
+	// The global jump tables must not be modified, and the overridden ones are
+	// reused across interpreters.
+	if londonInstructionSet[ADD].constantGas != GasFastestStep || !londonInstructionSet[PUSH0].undefined {
+		t.Fatal("global jump table modified")
+	}
+	rules := config.Rules(big.NewInt(1), false, 20)
+	if applyVMOverrides(&londonInstructionSet, rules.VMOverrides) != applyVMOverrides(&londonInstructionSet, rules.VMOverrides) {
+		t.Fatal("overridden jump table not cached")
+	}
+}
+
+func TestCheckVMOverrides(t *testing.T) {
+	tests := []struct {
+		override params.VMOverride
+		ok       bool
+	}{
+		{params.VMOverride{EIPs: []int{3855}, GasCosts: map[string]uint64{"ADD": 100, "PUSH0": 1}}, true},
+		{params.VMOverride{EIPs: []int{1}}, false},
+		{params.VMOverride{GasCosts: map[string]uint64{"NOTANOPCODE": 1}}, false},
+		{params.VMOverride{GasCosts: map[string]uint64{"ADD": params.MaxGasLimit}}, true},
+		{params.VMOverride{GasCosts: map[string]uint64{"ADD": params.MaxGasLimit + 1}}, false},
+		{params.VMOverride{GasCosts: map[string]uint64{"SLOAD": 100}}, false},   // dynamic since Berlin
+		{params.VMOverride{GasCosts: map[string]uint64{"BALANCE": 100}}, false}, // dynamic since Berlin
+		{params.VMOverride{GasCosts: map[string]uint64{"EXP": 100}}, false},
+	}
+	for i, test := range tests {
+		err := CheckVMOverrides(&params.ChainConfig{VMOverrides: []params.VMOverride{test.override}})
+		if ok := err == nil; ok != test.ok {
+			t.Errorf("test %d: wrong result, err %v", i, err)
+		}
+	}
+}
@@ -26,6 +26,8 @@ import (
 
 	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/eth/throttle"
+	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/rlp"
 )
 
@@ -79,6 +81,23 @@ func (api *AdminAPI) ExportChain(file string, first *uint64, last *uint64) (bool
 	return true, nil
 }
 
+// SyncBudget returns the network and disk budget the chain and state syncers
+// currently adhere to. Zero values mean unlimited.
+func (api *AdminAPI) SyncBudget() throttle.Limits {
+	return api.eth.Downloader().Budget().Limits()
+}
+
+// SetSyncBudget updates the network bandwidth (bytes per second) and disk write
+// rate (4KiB operations per second) the chain and state syncers are allowed to
+// use. Zero values lift the respective limit.
+func (api *AdminAPI) SetSyncBudget(bandwidth uint64, iops uint64) throttle.Limits {
+	limits := throttle.Limits{Bandwidth: bandwidth, IOPS: iops}
+	api.eth.Downloader().Budget().SetLimits(limits)
+
+	log.Info("Updated sync budget", "bandwidth", limits.Bandwidth, "iops", limits.IOPS)
+	return limits
+}
+
 func hasAllBlocks(chain *core.BlockChain, bs []*types.Block) bool {
 	for _, b := range bs {
 		if !chain.HasBlock(b.Hash(), b.NumberU64()) {
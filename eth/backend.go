@@ -19,6 +19,7 @@ package eth
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"math/big"
 	"runtime"
@@ -42,6 +43,7 @@ import (
 	"github.com/ethereum/go-ethereum/eth/gasprice"
 	"github.com/ethereum/go-ethereum/eth/protocols/eth"
 	"github.com/ethereum/go-ethereum/eth/protocols/snap"
+	"github.com/ethereum/go-ethereum/eth/throttle"
 	"github.com/ethereum/go-ethereum/eth/tracers"
 	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/event"
@@ -54,6 +56,7 @@ import (
 	"github.com/ethereum/go-ethereum/p2p"
 	"github.com/ethereum/go-ethereum/p2p/dnsdisc"
 	"github.com/ethereum/go-ethereum/p2p/enode"
+	"github.com/ethereum/go-ethereum/p2p/filedisc"
 	"github.com/ethereum/go-ethereum/params"
 	"github.com/ethereum/go-ethereum/rlp"
 	"github.com/ethereum/go-ethereum/rpc"
@@ -71,8 +74,9 @@ type Ethereum struct {
 	txPool     *txpool.TxPool
 	blockchain *core.BlockChain
 
-	handler *handler
-	discmix *enode.FairMix
+	handler   *handler
+	discmix   *enode.FairMix
+	nodesFile *filedisc.Source // static peers source, nil if no nodes file is configured
 
 	// DB interfaces
 	chainDb ethdb.Database // Block chain database
@@ -209,6 +213,12 @@ func New(stack *node.Node, config *ethconfig.Config) (*Ethereum, error) {
 		}
 		vmConfig.Tracer = t
 	}
+	if config.VMTracer != nil {
+		if vmConfig.Tracer != nil {
+			return nil, errors.New("VMTrace and VMTracer can't be used together")
+		}
+		vmConfig.Tracer = config.VMTracer
+	}
 	// Override the chain config with provided settings.
 	var overrides core.ChainOverrides
 	if config.OverrideCancun != nil {
@@ -246,6 +256,8 @@ func New(stack *node.Node, config *ethconfig.Config) (*Ethereum, error) {
 		TxPool:         eth.txPool,
 		Network:        networkID,
 		Sync:           config.SyncMode,
+		SyncBudget:     throttle.Limits{Bandwidth: config.SyncBandwidth, IOPS: config.SyncIOPS},
+		Reputation:     eth.p2pServer.Reputation(),
 		BloomCache:     uint64(cacheLimit),
 		EventMux:       eth.eventMux,
 		RequiredBlocks: config.RequiredBlocks,
@@ -354,7 +366,9 @@ func (s *Ethereum) Protocols() []p2p.Protocol {
 // Start implements node.Lifecycle, starting all internal goroutines needed by the
 // Ethereum protocol implementation.
 func (s *Ethereum) Start() error {
-	s.setupDiscovery()
+	if err := s.setupDiscovery(); err != nil {
+		return err
+	}
 
 	// Start the bloom bits servicing goroutines
 	s.startBloomHandlers(params.BloomBitsBlocks)
@@ -389,6 +403,16 @@ func (s *Ethereum) setupDiscovery() error {
 		s.discmix.AddSource(iter)
 	}
 
+	// Add nodes from the local nodes file, keeping them as static peers.
+	if s.config.NodesFile != "" {
+		src, err := filedisc.New(s.config.NodesFile, filedisc.Config{Peers: s.p2pServer})
+		if err != nil {
+			return fmt.Errorf("failed to load nodes file: %w", err)
+		}
+		s.nodesFile = src
+		s.discmix.AddSource(src.NewIterator())
+	}
+
 	// Add DHT nodes from discv5.
 	if s.p2pServer.DiscoveryV5() != nil {
 		filter := eth.NewNodeFilter(s.blockchain)
@@ -403,6 +427,9 @@ func (s *Ethereum) setupDiscovery() error {
 // Ethereum protocol.
 func (s *Ethereum) Stop() error {
 	// Stop all the peer-related stuff first.
+	if s.nodesFile != nil {
+		s.nodesFile.Close()
+	}
 	s.discmix.Close()
 	s.handler.Stop()
 
@@ -31,9 +31,11 @@ import (
 	"github.com/ethereum/go-ethereum/core/state/snapshot"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/eth/protocols/snap"
+	"github.com/ethereum/go-ethereum/eth/throttle"
 	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/event"
 	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/p2p/reputation"
 	"github.com/ethereum/go-ethereum/params"
 	"github.com/ethereum/go-ethereum/triedb"
 )
@@ -122,6 +124,12 @@ type Downloader struct {
 	SnapSyncer     *snap.Syncer // TODO(karalabe): make private! hack for now
 	stateSyncStart chan *stateSync
 
+	// Resource throttling
+	budget *throttle.Budget // Network and disk budget shared by the chain and state syncers
+
+	// Peer quality tracking
+	reputation *reputation.Tracker // Peer scores to report to and route requests by (nil = disabled)
+
 	// Cancellation and termination
 	cancelCh   chan struct{}  // Channel to cancel mid-flight syncs
 	cancelLock sync.RWMutex   // Lock to protect the cancel channel and peer in delivers
@@ -206,8 +214,11 @@ func New(stateDb ethdb.Database, mux *event.TypeMux, chain BlockChain, dropPeer
 		quitCh:         make(chan struct{}),
 		SnapSyncer:     snap.NewSyncer(stateDb, chain.TrieDB().Scheme()),
 		stateSyncStart: make(chan *stateSync),
+		budget:         throttle.New(throttle.Limits{}),
 		syncStartBlock: chain.CurrentSnapBlock().Number.Uint64(),
 	}
+	dl.SnapSyncer.SetBudget(dl.budget)
+
 	// Create the post-merge skeleton syncer and start the process
 	dl.skeleton = newSkeleton(stateDb, dl.peers, dropPeer, newBeaconBackfiller(dl, success))
 
@@ -215,6 +226,20 @@ func New(stateDb ethdb.Database, mux *event.TypeMux, chain BlockChain, dropPeer
 	return dl
 }
 
+// Budget returns the network and disk budget shared by the block body, receipt
+// and state fetchers. It can be used to adjust the sync limits at runtime.
+func (d *Downloader) Budget() *throttle.Budget {
+	return d.budget
+}
+
+// SetReputation sets the peer reputation tracker the chain and state syncers
+// report request outcomes to and use to route requests to better peers. It must
+// be called before syncing starts.
+func (d *Downloader) SetReputation(tracker *reputation.Tracker) {
+	d.reputation = tracker
+	d.SnapSyncer.SetReputation(tracker)
+}
+
 // Progress retrieves the synchronisation boundaries, specifically the origin
 // block where synchronisation started at (may have failed/suspended); the block
 // or header sync is currently at; and the latest known block which the sync targets.
@@ -25,6 +25,7 @@ import (
 	"github.com/ethereum/go-ethereum/common/prque"
 	"github.com/ethereum/go-ethereum/eth/protocols/eth"
 	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/p2p/reputation"
 )
 
 // timeoutGracePeriod is the amount of time to allow for a peer to deliver a
@@ -126,11 +127,18 @@ func (d *Downloader) concurrentFetch(queue typedQueue) error {
 	// Prepare the queue and fetch block parts until the block header fetcher's done
 	finished := false
 	for {
-		// If there's nothing more to fetch, wait or terminate
+		// If there's nothing more to fetch, wait or terminate. If the network
+		// budget is depleted, wait until it's replenished before assigning any
+		// new tasks.
+		var budgetWait <-chan time.Time
+
 		if queue.pending() == 0 {
 			if len(pending) == 0 && finished {
 				return nil
 			}
+		} else if delay := d.budget.Delay(); delay > 0 {
+			budgetWait = time.After(delay)
+			budgetThrottleCounter.Inc(1)
 		} else {
 			// Send a download request to all idle peers, until throttled
 			var (
@@ -141,7 +149,7 @@ func (d *Downloader) concurrentFetch(queue typedQueue) error {
 				pending, stale := pending[peer.id], stales[peer.id]
 				if pending == nil && stale == nil {
 					idles = append(idles, peer)
-					caps = append(caps, queue.capacity(peer, time.Second))
+					caps = append(caps, d.weighCapacity(peer, queue.capacity(peer, time.Second)))
 				} else if stale != nil {
 					if waited := time.Since(stale.Sent); waited > timeoutGracePeriod {
 						// Request has been in flight longer than the grace period
@@ -167,7 +175,7 @@ func (d *Downloader) concurrentFetch(queue typedQueue) error {
 				// Reserve a chunk of fetches for a peer. A nil can mean either that
 				// no more headers are available, or that the peer is known not to
 				// have them.
-				request, _, throttle := queue.reserve(peer, queue.capacity(peer, d.peers.rates.TargetRoundTrip()))
+				request, _, throttle := queue.reserve(peer, d.weighCapacity(peer, queue.capacity(peer, d.peers.rates.TargetRoundTrip())))
 				if throttle {
 					throttled = true
 					throttleCounter.Inc(1)
@@ -205,6 +213,9 @@ func (d *Downloader) concurrentFetch(queue typedQueue) error {
 			// be dropped when they arrive
 			return errCanceled
 
+		case <-budgetWait:
+			// The sync budget was replenished, loop back to assign new tasks
+
 		case event := <-peering:
 			// A peer joined or left, the tasks queue and allocations need to be
 			// checked for potential assignment or reassignment
@@ -295,6 +306,7 @@ func (d *Downloader) concurrentFetch(queue typedQueue) error {
 				log.Error("Delivery timeout from unknown peer", "peer", req.Peer)
 				continue
 			}
+			d.reputation.ReportPeer(peer.id, reputation.Timeout)
 			if fails > 2 {
 				queue.updateCapacity(peer, 0, 0)
 			} else {
@@ -333,6 +345,12 @@ func (d *Downloader) concurrentFetch(queue typedQueue) error {
 			if peer := d.peers.Peer(res.Req.Peer); peer != nil {
 				// Deliver the received chunk of data and check chain validity
 				accepted, err := queue.deliver(peer, res)
+				switch {
+				case err == nil && accepted > 0:
+					d.reputation.ReportPeer(peer.id, reputation.Delivery)
+				case err != nil && !errors.Is(err, errStaleDelivery):
+					d.reputation.ReportPeer(peer.id, reputation.InvalidData)
+				}
 				if errors.Is(err, errInvalidChain) {
 					return err
 				}
@@ -352,3 +370,9 @@ func (d *Downloader) concurrentFetch(queue typedQueue) error {
 		}
 	}
 }
+
+// weighCapacity scales the estimated retrieval capacity of a peer by its
+// reputation, routing less work towards peers which misbehaved recently.
+func (d *Downloader) weighCapacity(peer *peerConnection, capacity int) int {
+	return max(min(capacity, 1), int(float64(capacity)*d.reputation.Weight(peer.id)))
+}
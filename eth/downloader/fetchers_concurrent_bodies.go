@@ -20,6 +20,7 @@ import (
 	"time"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/eth/protocols/eth"
 	"github.com/ethereum/go-ethereum/log"
 )
@@ -91,6 +92,20 @@ func (q *bodyQueue) deliver(peer *peerConnection, packet *eth.Response) (int, er
 	txs, uncles, withdrawals := packet.Res.(*eth.BlockBodiesResponse).Unpack()
 	hashsets := packet.Meta.([][]common.Hash) // {txs hashes, uncle hashes, withdrawal hashes}
 
+	var size common.StorageSize
+	for i := range txs {
+		for _, tx := range txs[i] {
+			size += common.StorageSize(tx.Size())
+		}
+		for _, uncle := range uncles[i] {
+			size += uncle.Size()
+		}
+		if withdrawals[i] != nil {
+			size += common.StorageSize(types.Withdrawals(withdrawals[i]).Size())
+		}
+	}
+	q.budget.ChargeTraffic(int(size))
+
 	accepted, err := q.queue.DeliverBodies(peer.id, txs, hashsets[0], uncles, hashsets[1], withdrawals, hashsets[2])
 	switch {
 	case err == nil && len(txs) == 0:
@@ -91,6 +91,14 @@ func (q *receiptQueue) deliver(peer *peerConnection, packet *eth.Response) (int,
 	receipts := *packet.Res.(*eth.ReceiptsResponse)
 	hashes := packet.Meta.([]common.Hash) // {receipt hashes}
 
+	var size common.StorageSize
+	for _, set := range receipts {
+		for _, receipt := range set {
+			size += receipt.Size()
+		}
+	}
+	q.budget.ChargeTraffic(int(size))
+
 	accepted, err := q.queue.DeliverReceipts(peer.id, receipts, hashes)
 	switch {
 	case err == nil && len(receipts) == 0:
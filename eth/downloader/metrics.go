@@ -38,5 +38,6 @@ var (
 	receiptDropMeter    = metrics.NewRegisteredMeter("eth/downloader/receipts/drop", nil)
 	receiptTimeoutMeter = metrics.NewRegisteredMeter("eth/downloader/receipts/timeout", nil)
 
-	throttleCounter = metrics.NewRegisteredCounter("eth/downloader/throttle", nil)
+	throttleCounter       = metrics.NewRegisteredCounter("eth/downloader/throttle", nil)
+	budgetThrottleCounter = metrics.NewRegisteredCounter("eth/downloader/throttle/budget", nil)
 )
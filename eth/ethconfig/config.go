@@ -27,6 +27,7 @@ import (
 	"github.com/ethereum/go-ethereum/consensus/clique"
 	"github.com/ethereum/go-ethereum/consensus/ethash"
 	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/tracing"
 	"github.com/ethereum/go-ethereum/core/txpool/blobpool"
 	"github.com/ethereum/go-ethereum/core/txpool/legacypool"
 	"github.com/ethereum/go-ethereum/eth/downloader"
@@ -81,11 +82,22 @@ type Config struct {
 	NetworkId uint64
 	SyncMode  downloader.SyncMode
 
+	// Sync budgets limiting the resources the chain and state syncers may use.
+	// Zero values mean unlimited. They can also be adjusted at runtime through
+	// the admin API.
+	SyncBandwidth uint64 `toml:",omitempty"` // Maximum sync download rate in bytes per second
+	SyncIOPS      uint64 `toml:",omitempty"` // Maximum sync disk write rate in 4KiB operations per second
+
 	// This can be set to list of enrtree:// URLs which will be queried for
 	// nodes to connect to.
 	EthDiscoveryURLs  []string
 	SnapDiscoveryURLs []string
 
+	// NodesFile can be set to the path of a nodes file (in the nodes.json format
+	// of the devp2p tool) whose nodes are kept as static peers. The file is
+	// watched and changes are applied while running.
+	NodesFile string `toml:",omitempty"`
+
 	// State options.
 	NoPruning  bool // Whether to disable pruning and flush everything to disk
 	NoPrefetch bool // Whether to disable prefetching and only load state on demand
@@ -138,6 +150,11 @@ type Config struct {
 	VMTrace           string
 	VMTraceJsonConfig string
 
+	// VMTracer is a live tracer instance to attach to the VM, for embedders that
+	// construct their tracer directly instead of by name. It can't be used together
+	// with VMTrace.
+	VMTracer *tracing.Hooks `toml:"-"`
+
 	// RPCGasCap is the global gas cap for eth-call variants.
 	RPCGasCap uint64
 
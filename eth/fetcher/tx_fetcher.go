@@ -31,6 +31,7 @@ import (
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/ethereum/go-ethereum/p2p/reputation"
 )
 
 const (
@@ -202,6 +203,8 @@ type TxFetcher struct {
 	fetchTxs func(string, []common.Hash) error  // Retrieves a set of txs from a remote peer
 	dropPeer func(string)                       // Drops a peer in case of announcement violation
 
+	reputation *reputation.Tracker // Peer scores to report relay quality to (nil = disabled)
+
 	step  chan struct{} // Notification channel when the fetcher loop iterates
 	clock mclock.Clock  // Time wrapper to simulate in tests
 	rand  *mrand.Rand   // Randomizer to use in tests instead of map range loops (soft-random)
@@ -241,6 +244,13 @@ func NewTxFetcherForTests(
 	}
 }
 
+// SetReputation sets the peer reputation tracker the fetcher reports the
+// quality of relayed transactions to. It must be called before the fetcher
+// is started.
+func (f *TxFetcher) SetReputation(tracker *reputation.Tracker) {
+	f.reputation = tracker
+}
+
 // Notify announces the fetcher of the potential availability of a new batch of
 // transactions in the network.
 func (f *TxFetcher) Notify(peer string, types []byte, sizes []uint32, hashes []common.Hash) error {
@@ -348,16 +358,21 @@ func (f *TxFetcher) Enqueue(peer string, txs []*types.Transaction, direct bool)
 			}
 			// Track a few interesting failure types
 			switch {
-			case err == nil: // Noop, but need to handle to not count these
+			case err == nil:
+				f.reputation.ReportPeer(peer, reputation.TxAccepted)
 
 			case errors.Is(err, txpool.ErrAlreadyKnown):
+				// Honest peers broadcast the same transactions concurrently,
+				// so duplicates are not held against the delivering peer.
 				duplicate++
 
 			case errors.Is(err, txpool.ErrUnderpriced) || errors.Is(err, txpool.ErrReplaceUnderpriced):
 				underpriced++
+				f.reputation.ReportPeer(peer, reputation.TxRejected)
 
 			default:
 				otherreject++
+				f.reputation.ReportPeer(peer, reputation.TxRejected)
 			}
 			added = append(added, batch[j].Hash())
 			metas = append(metas, txMetadata{
@@ -66,4 +66,8 @@ var (
 	// discarded during the snap sync.
 	largeStorageDiscardGauge = metrics.NewRegisteredGauge("eth/protocols/snap/sync/storage/chunk/discard", nil)
 	largeStorageResumedGauge = metrics.NewRegisteredGauge("eth/protocols/snap/sync/storage/chunk/resume", nil)
+
+	// throttleMeter is the metric to track how many times the task scheduling
+	// was paused due to the sync budget being depleted.
+	throttleMeter = metrics.NewRegisteredMeter("eth/protocols/snap/sync/throttle", nil)
 )
@@ -35,10 +35,12 @@ import (
 	"github.com/ethereum/go-ethereum/core/state"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/eth/throttle"
 	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/event"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/p2p/msgrate"
+	"github.com/ethereum/go-ethereum/p2p/reputation"
 	"github.com/ethereum/go-ethereum/rlp"
 	"github.com/ethereum/go-ethereum/trie"
 	"github.com/ethereum/go-ethereum/trie/trienode"
@@ -451,10 +453,12 @@ type Syncer struct {
 	healer  *healTask      // Current state healing task being executed
 	update  chan struct{}  // Notification channel for possible sync progression
 
-	peers    map[string]SyncPeer // Currently active peers to download from
-	peerJoin *event.Feed         // Event feed to react to peers joining
-	peerDrop *event.Feed         // Event feed to react to peers dropping
-	rates    *msgrate.Trackers   // Message throughput rates for peers
+	peers      map[string]SyncPeer // Currently active peers to download from
+	peerJoin   *event.Feed         // Event feed to react to peers joining
+	peerDrop   *event.Feed         // Event feed to react to peers dropping
+	rates      *msgrate.Trackers   // Message throughput rates for peers
+	budget     *throttle.Budget    // Network and disk budget to adhere to (nil = unlimited)
+	reputation *reputation.Tracker // Peer scores to report request outcomes to (nil = disabled)
 
 	// Request tracking during syncing phase
 	statelessPeers map[string]struct{} // Peers that failed to deliver state data
@@ -542,6 +546,18 @@ func NewSyncer(db ethdb.KeyValueStore, scheme string) *Syncer {
 	}
 }
 
+// SetBudget sets the network and disk budget the syncer needs to adhere to.
+// It must be called before any sync cycle is started.
+func (s *Syncer) SetBudget(budget *throttle.Budget) {
+	s.budget = budget
+}
+
+// SetReputation sets the peer reputation tracker the syncer reports request
+// outcomes to. It must be called before syncing starts.
+func (s *Syncer) SetReputation(tracker *reputation.Tracker) {
+	s.reputation = tracker
+}
+
 // Register injects a new data source into the syncer's peerset.
 func (s *Syncer) Register(peer SyncPeer) error {
 	// Make sure the peer is not registered yet
@@ -687,15 +703,22 @@ func (s *Syncer) Sync(root common.Hash, cancel chan struct{}) error {
 		if len(s.tasks) == 0 && s.healer.scheduler.Pending() == 0 {
 			return nil
 		}
-		// Assign all the data retrieval tasks to any free peers
-		s.assignAccountTasks(accountResps, accountReqFails, cancel)
-		s.assignBytecodeTasks(bytecodeResps, bytecodeReqFails, cancel)
-		s.assignStorageTasks(storageResps, storageReqFails, cancel)
-
-		if len(s.tasks) == 0 {
-			// Sync phase done, run heal phase
-			s.assignTrienodeHealTasks(trienodeHealResps, trienodeHealReqFails, cancel)
-			s.assignBytecodeHealTasks(bytecodeHealResps, bytecodeHealReqFails, cancel)
+		// Assign all the data retrieval tasks to any free peers, unless the
+		// network or disk budget is depleted, in which case wait it out
+		var throttled <-chan time.Time
+		if delay := s.budget.Delay(); delay > 0 {
+			throttled = time.After(delay)
+			throttleMeter.Mark(1)
+		} else {
+			s.assignAccountTasks(accountResps, accountReqFails, cancel)
+			s.assignBytecodeTasks(bytecodeResps, bytecodeReqFails, cancel)
+			s.assignStorageTasks(storageResps, storageReqFails, cancel)
+
+			if len(s.tasks) == 0 {
+				// Sync phase done, run heal phase
+				s.assignTrienodeHealTasks(trienodeHealResps, trienodeHealReqFails, cancel)
+				s.assignBytecodeHealTasks(bytecodeHealResps, bytecodeHealReqFails, cancel)
+			}
 		}
 		// Update sync progress
 		s.lock.Lock()
@@ -716,6 +739,8 @@ func (s *Syncer) Sync(root common.Hash, cancel chan struct{}) error {
 		select {
 		case <-s.update:
 			// Something happened (new peer, delivery, timeout), recheck tasks
+		case <-throttled:
+			// The sync budget was replenished, resume scheduling tasks
 		case <-peerJoin:
 			// A new peer joined, try to schedule it new tasks
 		case id := <-peerDrop:
@@ -1065,6 +1090,7 @@ func (s *Syncer) assignAccountTasks(success chan *accountResponse, fail chan *ac
 		}
 		req.timeout = time.AfterFunc(s.rates.TargetTimeout(), func() {
 			peer.Log().Debug("Account range request timed out", "reqid", reqid)
+			s.reputation.ReportPeer(idle, reputation.Timeout)
 			s.rates.Update(idle, AccountRangeMsg, 0, 0)
 			s.scheduleRevertAccountRequest(req)
 		})
@@ -1176,6 +1202,7 @@ func (s *Syncer) assignBytecodeTasks(success chan *bytecodeResponse, fail chan *
 		}
 		req.timeout = time.AfterFunc(s.rates.TargetTimeout(), func() {
 			peer.Log().Debug("Bytecode request timed out", "reqid", reqid)
+			s.reputation.ReportPeer(idle, reputation.Timeout)
 			s.rates.Update(idle, ByteCodesMsg, 0, 0)
 			s.scheduleRevertBytecodeRequest(req)
 		})
@@ -1324,6 +1351,7 @@ func (s *Syncer) assignStorageTasks(success chan *storageResponse, fail chan *st
 		}
 		req.timeout = time.AfterFunc(s.rates.TargetTimeout(), func() {
 			peer.Log().Debug("Storage request timed out", "reqid", reqid)
+			s.reputation.ReportPeer(idle, reputation.Timeout)
 			s.rates.Update(idle, StorageRangesMsg, 0, 0)
 			s.scheduleRevertStorageRequest(req)
 		})
@@ -1461,6 +1489,7 @@ func (s *Syncer) assignTrienodeHealTasks(success chan *trienodeHealResponse, fai
 		}
 		req.timeout = time.AfterFunc(s.rates.TargetTimeout(), func() {
 			peer.Log().Debug("Trienode heal request timed out", "reqid", reqid)
+			s.reputation.ReportPeer(idle, reputation.Timeout)
 			s.rates.Update(idle, TrieNodesMsg, 0, 0)
 			s.scheduleRevertTrienodeHealRequest(req)
 		})
@@ -1577,6 +1606,7 @@ func (s *Syncer) assignBytecodeHealTasks(success chan *bytecodeHealResponse, fai
 		}
 		req.timeout = time.AfterFunc(s.rates.TargetTimeout(), func() {
 			peer.Log().Debug("Bytecode heal request timed out", "reqid", reqid)
+			s.reputation.ReportPeer(idle, reputation.Timeout)
 			s.rates.Update(idle, ByteCodesMsg, 0, 0)
 			s.scheduleRevertBytecodeHealRequest(req)
 		})
@@ -2013,6 +2043,7 @@ func (s *Syncer) processBytecodeResponse(res *bytecodeResponse) {
 	if err := batch.Write(); err != nil {
 		log.Crit("Failed to persist bytecodes", "err", err)
 	}
+	s.budget.ChargeWrite(batch.ValueSize())
 	s.bytecodeSynced += codes
 	s.bytecodeBytes += bytes
 
@@ -2254,6 +2285,7 @@ func (s *Syncer) processStorageResponse(res *storageResponse) {
 	if err := batch.Write(); err != nil {
 		log.Crit("Failed to persist storage slots", "err", err)
 	}
+	s.budget.ChargeWrite(batch.ValueSize())
 	s.storageSynced += uint64(slots)
 
 	log.Debug("Persisted set of storage slots", "accounts", len(res.hashes), "slots", slots, "bytes", s.storageBytes-oldStorageBytes)
@@ -2358,6 +2390,7 @@ func (s *Syncer) commitHealer(force bool) {
 	if err := batch.Write(); err != nil {
 		log.Crit("Failed to persist healing data", "err", err)
 	}
+	s.budget.ChargeWrite(batch.ValueSize())
 	log.Debug("Persisted set of healing data", "type", "trienodes", "bytes", common.StorageSize(batch.ValueSize()))
 }
 
@@ -2440,6 +2473,7 @@ func (s *Syncer) forwardAccountTask(task *accountTask) {
 	if err := batch.Write(); err != nil {
 		log.Crit("Failed to persist accounts", "err", err)
 	}
+	s.budget.ChargeWrite(batch.ValueSize())
 	s.accountSynced += uint64(len(res.accounts))
 
 	// Task filling persisted, push it the chunk marker forward to the first
@@ -2493,6 +2527,7 @@ func (s *Syncer) OnAccounts(peer SyncPeer, id uint64, hashes []common.Hash, acco
 	}
 	logger := peer.Log().New("reqid", id)
 	logger.Trace("Delivering range of accounts", "hashes", len(hashes), "accounts", len(accounts), "proofs", len(proof), "bytes", size)
+	s.budget.ChargeTraffic(int(size))
 
 	// Whether or not the response is valid, we can mark the peer as idle and
 	// notify the scheduler to assign a new task. If the response is invalid,
@@ -2557,6 +2592,7 @@ func (s *Syncer) OnAccounts(peer SyncPeer, id uint64, hashes []common.Hash, acco
 		logger.Warn("Account range failed proof", "err", err)
 		// Signal this request as failed, and ready for rescheduling
 		s.scheduleRevertAccountRequest(req)
+		s.reputation.ReportPeer(peer.ID(), reputation.InvalidData)
 		return err
 	}
 	accs := make([]*types.StateAccount, len(accounts))
@@ -2573,6 +2609,8 @@ func (s *Syncer) OnAccounts(peer SyncPeer, id uint64, hashes []common.Hash, acco
 		accounts: accs,
 		cont:     cont,
 	}
+	s.reputation.ReportPeer(peer.ID(), reputation.Delivery)
+
 	select {
 	case req.deliver <- response:
 	case <-req.cancel:
@@ -2603,6 +2641,7 @@ func (s *Syncer) onByteCodes(peer SyncPeer, id uint64, bytecodes [][]byte) error
 	}
 	logger := peer.Log().New("reqid", id)
 	logger.Trace("Delivering set of bytecodes", "bytecodes", len(bytecodes), "bytes", size)
+	s.budget.ChargeTraffic(int(size))
 
 	// Whether or not the response is valid, we can mark the peer as idle and
 	// notify the scheduler to assign a new task. If the response is invalid,
@@ -2676,6 +2715,7 @@ func (s *Syncer) onByteCodes(peer SyncPeer, id uint64, bytecodes [][]byte) error
 		logger.Warn("Unexpected bytecodes", "count", len(bytecodes)-i)
 		// Signal this request as failed, and ready for rescheduling
 		s.scheduleRevertBytecodeRequest(req)
+		s.reputation.ReportPeer(peer.ID(), reputation.InvalidData)
 		return errors.New("unexpected bytecode")
 	}
 	// Response validated, send it to the scheduler for filling
@@ -2684,6 +2724,8 @@ func (s *Syncer) onByteCodes(peer SyncPeer, id uint64, bytecodes [][]byte) error
 		hashes: req.hashes,
 		codes:  codes,
 	}
+	s.reputation.ReportPeer(peer.ID(), reputation.Delivery)
+
 	select {
 	case req.deliver <- response:
 	case <-req.cancel:
@@ -2716,6 +2758,7 @@ func (s *Syncer) OnStorage(peer SyncPeer, id uint64, hashes [][]common.Hash, slo
 	}
 	logger := peer.Log().New("reqid", id)
 	logger.Trace("Delivering ranges of storage slots", "accounts", len(hashes), "hashes", hashCount, "slots", slotCount, "proofs", len(proof), "size", size)
+	s.budget.ChargeTraffic(int(size))
 
 	// Whether or not the response is valid, we can mark the peer as idle and
 	// notify the scheduler to assign a new task. If the response is invalid,
@@ -2757,12 +2800,14 @@ func (s *Syncer) OnStorage(peer SyncPeer, id uint64, hashes [][]common.Hash, slo
 		s.lock.Unlock()
 		s.scheduleRevertStorageRequest(req) // reschedule request
 		logger.Warn("Hash and slot set size mismatch", "hashset", len(hashes), "slotset", len(slots))
+		s.reputation.ReportPeer(peer.ID(), reputation.InvalidData)
 		return errors.New("hash and slot set size mismatch")
 	}
 	if len(hashes) > len(req.accounts) {
 		s.lock.Unlock()
 		s.scheduleRevertStorageRequest(req) // reschedule request
 		logger.Warn("Hash set larger than requested", "hashset", len(hashes), "requested", len(req.accounts))
+		s.reputation.ReportPeer(peer.ID(), reputation.InvalidData)
 		return errors.New("hash set larger than requested")
 	}
 	// Response is valid, but check if peer is signalling that it does not have
@@ -2808,6 +2853,7 @@ func (s *Syncer) OnStorage(peer SyncPeer, id uint64, hashes [][]common.Hash, slo
 			if err != nil {
 				s.scheduleRevertStorageRequest(req) // reschedule request
 				logger.Warn("Storage slots failed proof", "err", err)
+				s.reputation.ReportPeer(peer.ID(), reputation.InvalidData)
 				return err
 			}
 		} else {
@@ -2819,6 +2865,7 @@ func (s *Syncer) OnStorage(peer SyncPeer, id uint64, hashes [][]common.Hash, slo
 			if err != nil {
 				s.scheduleRevertStorageRequest(req) // reschedule request
 				logger.Warn("Storage range failed proof", "err", err)
+				s.reputation.ReportPeer(peer.ID(), reputation.InvalidData)
 				return err
 			}
 		}
@@ -2833,6 +2880,8 @@ func (s *Syncer) OnStorage(peer SyncPeer, id uint64, hashes [][]common.Hash, slo
 		slots:    slots,
 		cont:     cont,
 	}
+	s.reputation.ReportPeer(peer.ID(), reputation.Delivery)
+
 	select {
 	case req.deliver <- response:
 	case <-req.cancel:
@@ -2850,6 +2899,7 @@ func (s *Syncer) OnTrieNodes(peer SyncPeer, id uint64, trienodes [][]byte) error
 	}
 	logger := peer.Log().New("reqid", id)
 	logger.Trace("Delivering set of healing trienodes", "trienodes", len(trienodes), "bytes", size)
+	s.budget.ChargeTraffic(int(size))
 
 	// Whether or not the response is valid, we can mark the peer as idle and
 	// notify the scheduler to assign a new task. If the response is invalid,
@@ -2927,6 +2977,7 @@ func (s *Syncer) OnTrieNodes(peer SyncPeer, id uint64, trienodes [][]byte) error
 
 		// Signal this request as failed, and ready for rescheduling
 		s.scheduleRevertTrienodeHealRequest(req)
+		s.reputation.ReportPeer(peer.ID(), reputation.InvalidData)
 		return errors.New("unexpected healing trienode")
 	}
 	// Response validated, send it to the scheduler for filling
@@ -2940,6 +2991,8 @@ func (s *Syncer) OnTrieNodes(peer SyncPeer, id uint64, trienodes [][]byte) error
 		hashes: req.hashes,
 		nodes:  nodes,
 	}
+	s.reputation.ReportPeer(peer.ID(), reputation.Delivery)
+
 	select {
 	case req.deliver <- response:
 	case <-req.cancel:
@@ -2957,6 +3010,7 @@ func (s *Syncer) onHealByteCodes(peer SyncPeer, id uint64, bytecodes [][]byte) e
 	}
 	logger := peer.Log().New("reqid", id)
 	logger.Trace("Delivering set of healing bytecodes", "bytecodes", len(bytecodes), "bytes", size)
+	s.budget.ChargeTraffic(int(size))
 
 	// Whether or not the response is valid, we can mark the peer as idle and
 	// notify the scheduler to assign a new task. If the response is invalid,
@@ -3030,6 +3084,7 @@ func (s *Syncer) onHealByteCodes(peer SyncPeer, id uint64, bytecodes [][]byte) e
 		logger.Warn("Unexpected healing bytecodes", "count", len(bytecodes)-i)
 		// Signal this request as failed, and ready for rescheduling
 		s.scheduleRevertBytecodeHealRequest(req)
+		s.reputation.ReportPeer(peer.ID(), reputation.InvalidData)
 		return errors.New("unexpected healing bytecode")
 	}
 	// Response validated, send it to the scheduler for filling
@@ -3038,6 +3093,8 @@ func (s *Syncer) onHealByteCodes(peer SyncPeer, id uint64, bytecodes [][]byte) e
 		hashes: req.hashes,
 		codes:  codes,
 	}
+	s.reputation.ReportPeer(peer.ID(), reputation.Delivery)
+
 	select {
 	case req.deliver <- response:
 	case <-req.cancel:
@@ -3068,6 +3125,7 @@ func (s *Syncer) onHealState(paths [][]byte, value []byte) error {
 	}
 	if s.stateWriter.ValueSize() > ethdb.IdealBatchSize {
 		s.stateWriter.Write() // It's fine to ignore the error here
+		s.budget.ChargeWrite(s.stateWriter.ValueSize())
 		s.stateWriter.Reset()
 	}
 	return nil
@@ -0,0 +1,174 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package throttle implements network and disk budgets for the chain and state
+// syncers, allowing a node to sync in the background without starving other
+// services running on the same machine.
+package throttle
+
+import (
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/mclock"
+)
+
+// ioPageSize is the size of a single disk write operation used to convert the
+// number of bytes persisted into IOPS. It matches the page size of most modern
+// block devices and filesystems.
+const ioPageSize = 4096
+
+// Limits is the set of budgets the syncers need to adhere to. A zero value for
+// any of the fields means that the given resource is not throttled.
+type Limits struct {
+	Bandwidth uint64 `json:"bandwidth"` // Maximum number of sync bytes to download per second
+	IOPS      uint64 `json:"iops"`      // Maximum number of 4KiB disk writes to issue per second
+}
+
+// Budget is a shared throttler tracking the network and disk usage of all the
+// syncers. Consumers charge the resources used after the fact and consult the
+// budget before scheduling new work. A nil budget is valid and never throttles.
+type Budget struct {
+	clock     mclock.Clock
+	bandwidth *bucket // Token bucket tracking the downloaded bytes
+	iops      *bucket // Token bucket tracking the disk write operations
+	lock      sync.Mutex
+}
+
+// New creates a sync budget with the given initial limits.
+func New(limits Limits) *Budget {
+	return newBudget(limits, mclock.System{})
+}
+
+// newBudget creates a sync budget with the given limits and a custom clock.
+func newBudget(limits Limits, clock mclock.Clock) *Budget {
+	now := clock.Now()
+	return &Budget{
+		clock:     clock,
+		bandwidth: newBucket(limits.Bandwidth, now),
+		iops:      newBucket(limits.IOPS, now),
+	}
+}
+
+// Limits returns the currently configured budgets.
+func (b *Budget) Limits() Limits {
+	if b == nil {
+		return Limits{}
+	}
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	return Limits{
+		Bandwidth: b.bandwidth.rate,
+		IOPS:      b.iops.rate,
+	}
+}
+
+// SetLimits updates the budgets at runtime. Any debt accumulated under the old
+// limits is forgiven, so raising a limit takes effect immediately.
+func (b *Budget) SetLimits(limits Limits) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	now := b.clock.Now()
+	b.bandwidth = newBucket(limits.Bandwidth, now)
+	b.iops = newBucket(limits.IOPS, now)
+}
+
+// ChargeTraffic accounts for the given number of bytes downloaded from the
+// network by a syncer.
+func (b *Budget) ChargeTraffic(bytes int) {
+	if b == nil {
+		return
+	}
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	b.bandwidth.charge(uint64(bytes), b.clock.Now())
+}
+
+// ChargeWrite accounts for the given number of bytes persisted to disk by a
+// syncer, converted to the number of page writes needed.
+func (b *Budget) ChargeWrite(bytes int) {
+	if b == nil || bytes <= 0 {
+		return
+	}
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	b.iops.charge(uint64((bytes+ioPageSize-1)/ioPageSize), b.clock.Now())
+}
+
+// Delay returns the amount of time the syncers need to wait before scheduling
+// new work in order to stay within the configured budgets. Zero means there is
+// spare capacity available.
+func (b *Budget) Delay() time.Duration {
+	if b == nil {
+		return 0
+	}
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	now := b.clock.Now()
+	return max(b.bandwidth.delay(now), b.iops.delay(now))
+}
+
+// bucket is a token bucket allowing a burst of up to one second worth of usage,
+// and permitting consumers to go into debt which they need to wait out.
+type bucket struct {
+	rate   uint64         // Number of tokens replenished per second, 0 = unlimited
+	tokens float64        // Number of tokens currently available (negative = debt)
+	last   mclock.AbsTime // Time when the tokens were last replenished
+}
+
+// newBucket creates a full token bucket with the given replenishment rate.
+func newBucket(rate uint64, now mclock.AbsTime) *bucket {
+	return &bucket{
+		rate:   rate,
+		tokens: float64(rate),
+		last:   now,
+	}
+}
+
+// refill replenishes the tokens accumulated since the last update, capping the
+// bucket at one second worth of tokens.
+func (b *bucket) refill(now mclock.AbsTime) {
+	if elapsed := now.Sub(b.last); elapsed > 0 {
+		b.tokens = min(b.tokens+elapsed.Seconds()*float64(b.rate), float64(b.rate))
+	}
+	b.last = now
+}
+
+// charge consumes the given number of tokens from the bucket.
+func (b *bucket) charge(tokens uint64, now mclock.AbsTime) {
+	if b.rate == 0 {
+		return
+	}
+	b.refill(now)
+	b.tokens -= float64(tokens)
+}
+
+// delay returns the time needed to pay back any outstanding debt.
+func (b *bucket) delay(now mclock.AbsTime) time.Duration {
+	if b.rate == 0 {
+		return 0
+	}
+	b.refill(now)
+	if b.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-b.tokens / float64(b.rate) * float64(time.Second))
+}
@@ -0,0 +1,98 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package throttle
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/mclock"
+)
+
+// Tests that a nil or unlimited budget never throttles.
+func TestUnlimitedBudget(t *testing.T) {
+	var nilBudget *Budget
+	nilBudget.ChargeTraffic(1 << 30)
+	nilBudget.ChargeWrite(1 << 30)
+	if delay := nilBudget.Delay(); delay != 0 {
+		t.Fatalf("nil budget throttled: have %v, want 0", delay)
+	}
+	budget := newBudget(Limits{}, new(mclock.Simulated))
+	budget.ChargeTraffic(1 << 30)
+	budget.ChargeWrite(1 << 30)
+	if delay := budget.Delay(); delay != 0 {
+		t.Fatalf("unlimited budget throttled: have %v, want 0", delay)
+	}
+}
+
+// Tests that going over the bandwidth budget results in a delay proportional
+// to the debt, which is paid back as time passes.
+func TestBandwidthBudget(t *testing.T) {
+	clock := new(mclock.Simulated)
+	budget := newBudget(Limits{Bandwidth: 1000}, clock)
+
+	// Consuming the burst allowance should not throttle
+	budget.ChargeTraffic(1000)
+	if delay := budget.Delay(); delay != 0 {
+		t.Fatalf("burst throttled: have %v, want 0", delay)
+	}
+	// Going into debt should throttle until it's paid back
+	budget.ChargeTraffic(2000)
+	if delay := budget.Delay(); delay != 2*time.Second {
+		t.Fatalf("delay mismatch: have %v, want %v", delay, 2*time.Second)
+	}
+	clock.Run(time.Second)
+	if delay := budget.Delay(); delay != time.Second {
+		t.Fatalf("delay mismatch: have %v, want %v", delay, time.Second)
+	}
+	clock.Run(time.Second)
+	if delay := budget.Delay(); delay != 0 {
+		t.Fatalf("delay mismatch: have %v, want 0", delay)
+	}
+}
+
+// Tests that disk writes are converted to page operations.
+func TestIOPSBudget(t *testing.T) {
+	clock := new(mclock.Simulated)
+	budget := newBudget(Limits{IOPS: 10}, clock)
+
+	budget.ChargeWrite(10 * ioPageSize)
+	if delay := budget.Delay(); delay != 0 {
+		t.Fatalf("burst throttled: have %v, want 0", delay)
+	}
+	budget.ChargeWrite(1) // partial pages count as a full write
+	if delay := budget.Delay(); delay != 100*time.Millisecond {
+		t.Fatalf("delay mismatch: have %v, want %v", delay, 100*time.Millisecond)
+	}
+}
+
+// Tests that updating the limits at runtime forgives outstanding debt.
+func TestSetLimits(t *testing.T) {
+	budget := newBudget(Limits{Bandwidth: 100, IOPS: 100}, new(mclock.Simulated))
+
+	budget.ChargeTraffic(1000)
+	if delay := budget.Delay(); delay == 0 {
+		t.Fatalf("budget not throttled")
+	}
+	budget.SetLimits(Limits{Bandwidth: 1000})
+	if delay := budget.Delay(); delay != 0 {
+		t.Fatalf("debt not forgiven: have %v, want 0", delay)
+	}
+	if have, want := budget.Limits(), (Limits{Bandwidth: 1000}); have != want {
+		t.Fatalf("limits mismatch: have %+v, want %+v", have, want)
+	}
+}
@@ -0,0 +1,281 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package coverage implements a tracer collecting code coverage of contracts.
+//
+// The tracer records the executed instructions, the outcome of conditional jumps
+// and the invoked precompiles for every code it sees, keyed by code hash. It can be
+// attached to any number of transactions, e.g. as the live tracer of a simulated
+// backend running a contract test suite, and aggregates all of them.
+package coverage
+
+import (
+	"bytes"
+	"math/big"
+	"sort"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/tracing"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// Tracer collects code coverage.
+type Tracer struct {
+	mu          sync.Mutex
+	config      *params.ChainConfig
+	precompiles map[common.Address]bool // precompiles active in the current transaction
+
+	codes   map[common.Hash]*codeCoverage
+	frames  []*frame
+	hits    map[common.Address]uint64 // precompile invocations
+	txCount uint64
+}
+
+// frame is a call frame of the traced execution.
+type frame struct {
+	to       common.Address
+	cov      *codeCoverage // resolved at the first opcode
+	executed bool
+}
+
+// codeCoverage is the coverage of a single code.
+type codeCoverage struct {
+	hash     common.Hash
+	code     []byte
+	hits     []uint64 // execution count by pc
+	branches map[uint64]*Branch
+}
+
+// New creates a coverage tracer.
+func New() *Tracer {
+	return &Tracer{
+		codes: make(map[common.Hash]*codeCoverage),
+		hits:  make(map[common.Address]uint64),
+	}
+}
+
+// Hooks returns the tracing hooks of the tracer.
+func (t *Tracer) Hooks() *tracing.Hooks {
+	return &tracing.Hooks{
+		OnBlockchainInit: t.onBlockchainInit,
+		OnTxStart:        t.onTxStart,
+		OnEnter:          t.onEnter,
+		OnExit:           t.onExit,
+		OnOpcode:         t.onOpcode,
+	}
+}
+
+func (t *Tracer) onBlockchainInit(config *params.ChainConfig) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.config = config
+}
+
+func (t *Tracer) onTxStart(env *tracing.VMContext, tx *types.Transaction, from common.Address) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	// Without a chain config, any known precompile is assumed to be active.
+	addrs := vm.PrecompiledAddressesPrague
+	if t.config != nil {
+		addrs = vm.ActivePrecompiles(t.config.Rules(env.BlockNumber, env.Random != nil, env.Time))
+	}
+	t.precompiles = make(map[common.Address]bool, len(addrs))
+	for _, addr := range addrs {
+		t.precompiles[addr] = true
+	}
+	t.frames = t.frames[:0]
+	t.txCount++
+}
+
+func (t *Tracer) onEnter(depth int, typ byte, from common.Address, to common.Address, input []byte, gas uint64, value *big.Int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.frames = append(t.frames, &frame{to: to})
+}
+
+func (t *Tracer) onExit(depth int, output []byte, gasUsed uint64, err error, reverted bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if len(t.frames) == 0 {
+		return
+	}
+	f := t.frames[len(t.frames)-1]
+	t.frames = t.frames[:len(t.frames)-1]
+	if !f.executed && t.isPrecompile(f.to) {
+		t.hits[f.to]++
+	}
+}
+
+func (t *Tracer) isPrecompile(addr common.Address) bool {
+	if t.precompiles == nil {
+		for _, a := range vm.PrecompiledAddressesPrague {
+			if a == addr {
+				return true
+			}
+		}
+		return false
+	}
+	return t.precompiles[addr]
+}
+
+func (t *Tracer) onOpcode(pc uint64, op byte, gas, cost uint64, scope tracing.OpContext, rData []byte, depth int, err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if len(t.frames) == 0 {
+		return
+	}
+	f := t.frames[len(t.frames)-1]
+	if !f.executed {
+		f.executed = true
+		f.cov = t.lookup(scope.ContractCode())
+	}
+	cov := f.cov
+	if pc >= uint64(len(cov.hits)) {
+		return // STOP past the end of the code
+	}
+	cov.hits[pc]++
+
+	if vm.OpCode(op) == vm.JUMPI {
+		stack := scope.StackData()
+		if len(stack) < 2 {
+			return // stack underflow, the instruction fails
+		}
+		b := cov.branches[pc]
+		if b == nil {
+			b = &Branch{PC: pc}
+			cov.branches[pc] = b
+		}
+		if stack[len(stack)-2].IsZero() {
+			b.NotTaken++
+		} else {
+			b.Taken++
+		}
+	}
+}
+
+// lookup returns the coverage of the given code, creating it if necessary.
+func (t *Tracer) lookup(code []byte) *codeCoverage {
+	hash := crypto.Keccak256Hash(code)
+	if cov, ok := t.codes[hash]; ok {
+		return cov
+	}
+	cov := &codeCoverage{
+		hash:     hash,
+		code:     common.CopyBytes(code),
+		hits:     make([]uint64, len(code)),
+		branches: make(map[uint64]*Branch),
+	}
+	t.codes[hash] = cov
+	return cov
+}
+
+// Branch is the coverage of a conditional jump.
+type Branch struct {
+	PC       uint64 `json:"pc"`
+	Taken    uint64 `json:"taken"`
+	NotTaken uint64 `json:"notTaken"`
+}
+
+// Contract is the coverage of a single code.
+type Contract struct {
+	CodeHash     common.Hash   `json:"codeHash"`
+	Code         hexutil.Bytes `json:"code"`
+	Instructions int           `json:"instructions"`       // number of instructions in the code
+	Covered      int           `json:"covered"`            // number of executed instructions
+	Bitmap       hexutil.Bytes `json:"bitmap"`             // executed pcs, MSB first
+	Branches     []Branch      `json:"branches,omitempty"` // conditional jumps, by pc
+	hits         []uint64
+}
+
+// Executed reports whether the instruction at pc was executed.
+func (c *Contract) Executed(pc uint64) bool {
+	return pc < uint64(len(c.Code)) && c.Bitmap[pc/8]&(0x80>>(pc%8)) != 0
+}
+
+// Hits returns the number of times the instruction at pc was executed.
+func (c *Contract) Hits(pc uint64) uint64 {
+	if pc >= uint64(len(c.hits)) {
+		return 0
+	}
+	return c.hits[pc]
+}
+
+// Report is the coverage collected by a tracer.
+type Report struct {
+	Transactions uint64                    `json:"transactions"`
+	Contracts    []*Contract               `json:"contracts"`   // by code hash
+	Precompiles  map[common.Address]uint64 `json:"precompiles"` // invocations by address
+}
+
+// Report returns the coverage collected so far.
+func (t *Tracer) Report() *Report {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	r := &Report{
+		Transactions: t.txCount,
+		Precompiles:  make(map[common.Address]uint64, len(t.hits)),
+	}
+	for addr, n := range t.hits {
+		r.Precompiles[addr] = n
+	}
+	for _, cov := range t.codes {
+		c := &Contract{
+			CodeHash: cov.hash,
+			Code:     cov.code,
+			Bitmap:   make([]byte, (len(cov.code)+7)/8),
+			hits:     append([]uint64(nil), cov.hits...),
+		}
+		for pc := 0; pc < len(cov.code); pc++ {
+			c.Instructions++
+			if cov.hits[pc] > 0 {
+				c.Covered++
+				c.Bitmap[pc/8] |= 0x80 >> (pc % 8)
+			}
+			// Skip over the immediate data of PUSH1..PUSH32.
+			if op := vm.OpCode(cov.code[pc]); op.IsPush() {
+				pc += int(op - vm.PUSH0)
+			}
+		}
+		for _, b := range cov.branches {
+			c.Branches = append(c.Branches, *b)
+		}
+		sort.Slice(c.Branches, func(i, j int) bool { return c.Branches[i].PC < c.Branches[j].PC })
+		r.Contracts = append(r.Contracts, c)
+	}
+	sort.Slice(r.Contracts, func(i, j int) bool {
+		return bytes.Compare(r.Contracts[i].CodeHash[:], r.Contracts[j].CodeHash[:]) < 0
+	})
+	return r
+}
+
+// Reset discards the collected coverage.
+func (t *Tracer) Reset() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	clear(t.codes)
+	clear(t.hits)
+	t.txCount = 0
+}
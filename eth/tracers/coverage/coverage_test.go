@@ -0,0 +1,135 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package coverage
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/compiler"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm/runtime"
+)
+
+var (
+	branchAddr = common.HexToAddress("0xaaaa")
+	// branchCode jumps to the end if there is calldata:
+	// CALLDATASIZE PUSH1 7 JUMPI PUSH1 1 STOP JUMPDEST STOP
+	branchCode = common.FromHex("366007576001005b00")
+
+	identityAddr = common.HexToAddress("0xbbbb")
+	// identityCode calls the identity precompile.
+	identityCode = common.FromHex("60006000600060006004" + "5afa00")
+)
+
+func newTestConfig(t *Tracer) *runtime.Config {
+	statedb, _ := state.New(types.EmptyRootHash, state.NewDatabaseForTesting())
+	statedb.SetCode(branchAddr, branchCode)
+	statedb.SetCode(identityAddr, identityCode)
+	cfg := &runtime.Config{State: statedb}
+	cfg.EVMConfig.Tracer = t.Hooks()
+	return cfg
+}
+
+func TestCoverage(t *testing.T) {
+	tracer := New()
+	cfg := newTestConfig(tracer)
+	if _, _, err := runtime.Call(branchAddr, nil, cfg); err != nil {
+		t.Fatal(err)
+	}
+	report := tracer.Report()
+	if len(report.Contracts) != 1 {
+		t.Fatalf("wrong number of contracts: %d", len(report.Contracts))
+	}
+	c := report.Contracts[0]
+	if c.Instructions != 7 || c.Covered != 5 {
+		t.Fatalf("wrong coverage: %d of %d instructions, want 5 of 7", c.Covered, c.Instructions)
+	}
+	if !bytes.Equal(c.Bitmap, []byte{0xda, 0x00}) {
+		t.Fatalf("wrong bitmap: %x", c.Bitmap)
+	}
+	if want := []Branch{{PC: 3, NotTaken: 1}}; !reflect.DeepEqual(c.Branches, want) {
+		t.Fatalf("wrong branches: %+v, want %+v", c.Branches, want)
+	}
+
+	// Coverage is aggregated across transactions.
+	if _, _, err := runtime.Call(branchAddr, []byte{1}, cfg); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := runtime.Call(identityAddr, nil, cfg); err != nil {
+		t.Fatal(err)
+	}
+	report = tracer.Report()
+	if report.Transactions != 3 || len(report.Contracts) != 2 {
+		t.Fatalf("wrong report: %d transactions, %d contracts", report.Transactions, len(report.Contracts))
+	}
+	for _, c := range report.Contracts {
+		if c.Covered != c.Instructions {
+			t.Errorf("code %x: %d of %d instructions covered", c.Code, c.Covered, c.Instructions)
+		}
+		if bytes.Equal(c.Code, branchCode) {
+			if !bytes.Equal(c.Bitmap, []byte{0xdb, 0x80}) {
+				t.Errorf("wrong bitmap: %x", c.Bitmap)
+			}
+			if want := []Branch{{PC: 3, Taken: 1, NotTaken: 1}}; !reflect.DeepEqual(c.Branches, want) {
+				t.Errorf("wrong branches: %+v, want %+v", c.Branches, want)
+			}
+		}
+	}
+	if n := report.Precompiles[common.BytesToAddress([]byte{4})]; n != 1 || len(report.Precompiles) != 1 {
+		t.Fatalf("wrong precompile hits: %v", report.Precompiles)
+	}
+}
+
+func TestLCOV(t *testing.T) {
+	const src = "if (x) {\n  a;\n}\nb;\n"
+	combinedJSON := `{
+		"contracts": {"test.sol:Branch": {"bin": "00", "bin-runtime": "366007576001005b00", "srcmap": "", "srcmap-runtime": "0:1:0;;;9:2;;16:2;"}},
+		"sourceList": ["test.sol"]
+	}`
+	info, err := compiler.ParseDebugInfo([]byte(combinedJSON), func(string) ([]byte, error) { return []byte(src), nil })
+	if err != nil {
+		t.Fatal(err)
+	}
+	tracer := New()
+	if _, _, err := runtime.Call(branchAddr, nil, newTestConfig(tracer)); err != nil {
+		t.Fatal(err)
+	}
+	var out strings.Builder
+	if err := tracer.Report().WriteLCOV(&out, info); err != nil {
+		t.Fatal(err)
+	}
+	want := `SF:test.sol
+DA:1,1
+DA:2,1
+DA:4,0
+BRDA:1,0,0,0
+BRDA:1,0,1,1
+BRF:2
+BRH:1
+LF:3
+LH:2
+end_of_record
+`
+	if out.String() != want {
+		t.Fatalf("wrong lcov output:\n%s\nwant:\n%s", out.String(), want)
+	}
+}
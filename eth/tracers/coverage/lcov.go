@@ -0,0 +1,158 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package coverage
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/common/compiler"
+	"github.com/ethereum/go-ethereum/core/vm"
+)
+
+// fileCoverage is the line and branch coverage of a source file.
+type fileCoverage struct {
+	lines    map[int]uint64 // execution count by line
+	branches []lineBranch
+}
+
+type lineBranch struct {
+	line     int
+	executed bool
+	Branch
+}
+
+// WriteLCOV writes the coverage of the contracts in info as an lcov tracefile.
+//
+// Every instruction of the compiled contracts is mapped to a source line, so lines
+// of code that was never deployed or called are reported as not covered. A line is
+// reported with the highest execution count of its instructions. Each conditional
+// jump is reported as a branch block with the taken and not taken branches.
+func (r *Report) WriteLCOV(w io.Writer, info *compiler.DebugInfo) error {
+	// Sum up the execution counts of the collected code by source map. The same
+	// compiled code may have been deployed multiple times, possibly with different
+	// constructor arguments.
+	var (
+		hits     = make(map[*compiler.SourceMap][]uint64)
+		branches = make(map[*compiler.SourceMap]map[uint64]Branch)
+	)
+	for _, c := range r.Contracts {
+		_, m := info.Find(c.Code)
+		if m == nil {
+			continue
+		}
+		if hits[m] == nil {
+			hits[m] = make([]uint64, len(c.Code))
+			branches[m] = make(map[uint64]Branch)
+		}
+		for pc := range hits[m] {
+			hits[m][pc] += c.Hits(uint64(pc))
+		}
+		for _, b := range c.Branches {
+			sum := branches[m][b.PC]
+			sum.PC, sum.Taken, sum.NotTaken = b.PC, sum.Taken+b.Taken, sum.NotTaken+b.NotTaken
+			branches[m][b.PC] = sum
+		}
+	}
+	// Attribute every instruction of the compiled contracts to its source line.
+	files := make(map[*compiler.SourceFile]*fileCoverage)
+	for _, c := range info.Contracts {
+		for _, code := range []struct {
+			code []byte
+			m    *compiler.SourceMap
+		}{{c.Code, c.CodeMap}, {c.Runtime, c.RuntimeMap}} {
+			for _, pc := range code.m.Instructions() {
+				loc, ok := code.m.Lookup(pc)
+				if !ok {
+					continue
+				}
+				file := info.File(loc)
+				if file == nil {
+					continue // compiler-generated code
+				}
+				fc := files[file]
+				if fc == nil {
+					fc = &fileCoverage{lines: make(map[int]uint64)}
+					files[file] = fc
+				}
+				line, _ := file.Position(loc.Start)
+				var count uint64
+				if pc < uint64(len(hits[code.m])) {
+					count = hits[code.m][pc]
+				}
+				fc.lines[line] = max(fc.lines[line], count)
+
+				if vm.OpCode(code.code[pc]) == vm.JUMPI {
+					fc.branches = append(fc.branches, lineBranch{
+						line:     line,
+						executed: count > 0,
+						Branch:   branches[code.m][pc],
+					})
+				}
+			}
+		}
+	}
+	sorted := make([]*compiler.SourceFile, 0, len(files))
+	for file := range files {
+		sorted = append(sorted, file)
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Name < sorted[j].Name })
+
+	bw := bufio.NewWriter(w)
+	for _, file := range sorted {
+		writeLCOVRecord(bw, file.Name, files[file])
+	}
+	return bw.Flush()
+}
+
+func writeLCOVRecord(w io.Writer, name string, fc *fileCoverage) {
+	fmt.Fprintf(w, "SF:%s\n", name)
+
+	lines := make([]int, 0, len(fc.lines))
+	for line := range fc.lines {
+		lines = append(lines, line)
+	}
+	sort.Ints(lines)
+
+	var hitLines int
+	for _, line := range lines {
+		fmt.Fprintf(w, "DA:%d,%d\n", line, fc.lines[line])
+		if fc.lines[line] > 0 {
+			hitLines++
+		}
+	}
+	var hitBranches int
+	for i, b := range fc.branches {
+		if !b.executed {
+			fmt.Fprintf(w, "BRDA:%d,%d,0,-\nBRDA:%d,%d,1,-\n", b.line, i, b.line, i)
+			continue
+		}
+		fmt.Fprintf(w, "BRDA:%d,%d,0,%d\nBRDA:%d,%d,1,%d\n", b.line, i, b.Taken, b.line, i, b.NotTaken)
+		if b.Taken > 0 {
+			hitBranches++
+		}
+		if b.NotTaken > 0 {
+			hitBranches++
+		}
+	}
+	if len(fc.branches) > 0 {
+		fmt.Fprintf(w, "BRF:%d\nBRH:%d\n", 2*len(fc.branches), hitBranches)
+	}
+	fmt.Fprintf(w, "LF:%d\nLH:%d\nend_of_record\n", len(lines), hitLines)
+}
@@ -0,0 +1,90 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package live
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/ethereum/go-ethereum/common/compiler"
+	"github.com/ethereum/go-ethereum/core/tracing"
+	"github.com/ethereum/go-ethereum/eth/tracers"
+	"github.com/ethereum/go-ethereum/eth/tracers/coverage"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+func init() {
+	tracers.LiveDirectory.Register("coverage", newCoverageTracer)
+}
+
+type coverageTracerConfig struct {
+	Path string `json:"path"` // Path to the directory where the coverage report is written
+	Solc string `json:"solc"` // Optional solc --combined-json output for an lcov report
+}
+
+// newCoverageTracer creates a live tracer collecting the code coverage of all
+// processed transactions. On shutdown, the coverage is written to coverage.json,
+// and to coverage.lcov if solc source maps are configured.
+func newCoverageTracer(cfg json.RawMessage) (*tracing.Hooks, error) {
+	var config coverageTracerConfig
+	if err := json.Unmarshal(cfg, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse config: %v", err)
+	}
+	if config.Path == "" {
+		return nil, errors.New("coverage tracer output path is required")
+	}
+	var info *compiler.DebugInfo
+	if config.Solc != "" {
+		var err error
+		if info, err = compiler.LoadDebugInfo(config.Solc); err != nil {
+			return nil, fmt.Errorf("failed to load solc output: %v", err)
+		}
+	}
+	t := coverage.New()
+	hooks := t.Hooks()
+	hooks.OnClose = func() {
+		if err := writeCoverage(t.Report(), config.Path, info); err != nil {
+			log.Error("Failed to write coverage report", "err", err)
+		}
+	}
+	return hooks, nil
+}
+
+func writeCoverage(report *coverage.Report, dir string, info *compiler.DebugInfo) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	blob, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(dir, "coverage.json"), blob, 0644); err != nil {
+		return err
+	}
+	if info == nil {
+		return nil
+	}
+	f, err := os.Create(filepath.Join(dir, "coverage.lcov"))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return report.WriteLCOV(f, info)
+}
@@ -0,0 +1,66 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package live
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/core/tracing"
+	"github.com/ethereum/go-ethereum/eth/tracers"
+	"github.com/ethereum/go-ethereum/eth/tracers/otel"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+func init() {
+	tracers.LiveDirectory.Register("otel", newOtelTracer)
+}
+
+// otelQueueSize is the number of blocks waiting to be exported before their
+// spans are dropped.
+const otelQueueSize = 256
+
+// newOtelTracer creates a live tracer exporting every processed block as
+// OpenTelemetry spans to a file and/or a collector.
+func newOtelTracer(cfg json.RawMessage) (*tracing.Hooks, error) {
+	var config otel.Config
+	if err := json.Unmarshal(cfg, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse config: %v", err)
+	}
+	exporter, err := config.NewExporter()
+	if err != nil {
+		return nil, err
+	}
+	if exporter == nil {
+		return nil, errors.New("otel tracer output path or collector endpoint is required")
+	}
+	exporter = otel.NewQueue(exporter, otelQueueSize)
+
+	t := otel.New(config.ServiceName(), nil, func(data *otel.TracesData) {
+		if err := exporter.Export(data); err != nil {
+			log.Warn("Failed to export spans", "err", err)
+		}
+	})
+	hooks := t.Hooks()
+	hooks.OnClose = func() {
+		if err := exporter.Close(); err != nil {
+			log.Error("Failed to close span exporter", "err", err)
+		}
+	}
+	return hooks, nil
+}
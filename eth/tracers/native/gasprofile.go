@@ -0,0 +1,257 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package native
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+	"sync/atomic"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/tracing"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/eth/tracers"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/google/pprof/profile"
+)
+
+func init() {
+	tracers.DefaultDirectory.Register("gasProfiler", newGasProfiler, false)
+}
+
+// intrinsicGasFrame is the stack under which the intrinsic gas of a transaction
+// is reported.
+const intrinsicGasFrame = "[intrinsic]"
+
+// gasProfiler aggregates the gas used by a transaction into a profile that can be
+// fed to flamegraph tools. Every call frame is a level of the profile, and within a
+// frame the gas is attributed to code blocks, which start at the beginning of the
+// code and at every JUMPDEST. Solidity functions begin at a JUMPDEST, so the blocks
+// give a rough per-function breakdown without requiring source maps.
+//
+// The default output is the folded stack format of flamegraph.pl and inferno:
+//
+//	> debug.traceTransaction("0x...", {tracer: "gasProfiler"})
+//	"CALL 0xA0b8...;0xA0b8...:0x0 412\nCALL 0xA0b8...;0xA0b8...:0x2f;DELEGATECALL 0x43...;0x43...:0x0 2187\n..."
+//
+// With {"format": "pprof"}, the result is a gzipped pprof protobuf profile, as a
+// base64 encoded JSON string.
+//
+// The gas of an opcode is the gas it consumed itself. Gas forwarded to a child
+// call frame is attributed to the child. Gas refunds at the end of the
+// transaction are not part of the profile.
+type gasProfiler struct {
+	config    gasProfilerConfig
+	frames    []*profileFrame
+	samples   map[string]uint64 // gas used by folded stack
+	interrupt atomic.Bool       // Atomic flag to signal execution interruption
+	reason    error             // Textual reason for the interruption
+}
+
+type gasProfilerConfig struct {
+	Format string `json:"format"` // Output format, "folded" (default) or "pprof"
+}
+
+// profileFrame tracks the gas usage of a call frame.
+type profileFrame struct {
+	addr     common.Address
+	prefix   string // folded stack of the frame
+	label    string // stack element of the current code block
+	startGas uint64 // gas available to the frame
+
+	// The gas used by an opcode is known when the next opcode of the frame is
+	// executed, or when the frame exits.
+	pending      bool
+	pendingLabel string // code block of the pending opcode
+	pendingGas   uint64 // gas available before the pending opcode
+	childGas     uint64 // gas used by child frames of the pending opcode
+}
+
+func newGasProfiler(ctx *tracers.Context, cfg json.RawMessage, chainConfig *params.ChainConfig) (*tracers.Tracer, error) {
+	var config gasProfilerConfig
+	if err := json.Unmarshal(cfg, &config); err != nil {
+		return nil, err
+	}
+	switch config.Format {
+	case "":
+		config.Format = "folded"
+	case "folded", "pprof":
+	default:
+		return nil, fmt.Errorf("unknown profile format %q", config.Format)
+	}
+	t := &gasProfiler{config: config, samples: make(map[string]uint64)}
+	return &tracers.Tracer{
+		Hooks: &tracing.Hooks{
+			OnEnter:     t.OnEnter,
+			OnExit:      t.OnExit,
+			OnOpcode:    t.OnOpcode,
+			OnGasChange: t.OnGasChange,
+		},
+		GetResult: t.GetResult,
+		Stop:      t.Stop,
+	}, nil
+}
+
+func blockLabel(addr common.Address, pc uint64) string {
+	return fmt.Sprintf("%v:%#x", addr, pc)
+}
+
+// OnEnter is called when EVM enters a new scope (via call, create or selfdestruct).
+func (t *gasProfiler) OnEnter(depth int, typ byte, from common.Address, to common.Address, input []byte, gas uint64, value *big.Int) {
+	if t.interrupt.Load() {
+		return
+	}
+	f := &profileFrame{
+		addr:     to,
+		prefix:   fmt.Sprintf("%v %v", vm.OpCode(typ), to),
+		label:    blockLabel(to, 0),
+		startGas: gas,
+	}
+	if len(t.frames) > 0 {
+		parent := t.frames[len(t.frames)-1]
+		f.prefix = parent.prefix + ";" + parent.label + ";" + f.prefix
+	}
+	t.frames = append(t.frames, f)
+}
+
+// OnExit is called when EVM exits a scope, even if the scope didn't
+// execute any code.
+func (t *gasProfiler) OnExit(depth int, output []byte, gasUsed uint64, err error, reverted bool) {
+	if t.interrupt.Load() || len(t.frames) == 0 {
+		return
+	}
+	f := t.frames[len(t.frames)-1]
+	t.frames = t.frames[:len(t.frames)-1]
+
+	if f.pending {
+		var remaining uint64
+		if f.startGas > gasUsed {
+			remaining = f.startGas - gasUsed
+		}
+		t.settle(f, remaining)
+	} else if gasUsed > 0 {
+		// Frames without code, like precompiles, are reported as a whole.
+		t.samples[f.prefix] += gasUsed
+	}
+	if len(t.frames) > 0 {
+		t.frames[len(t.frames)-1].childGas += gasUsed
+	}
+}
+
+// OnOpcode is called before an opcode is executed.
+func (t *gasProfiler) OnOpcode(pc uint64, op byte, gas, cost uint64, scope tracing.OpContext, rData []byte, depth int, err error) {
+	if t.interrupt.Load() || len(t.frames) == 0 {
+		return
+	}
+	f := t.frames[len(t.frames)-1]
+	if f.pending {
+		t.settle(f, gas)
+	}
+	if vm.OpCode(op) == vm.JUMPDEST {
+		f.label = blockLabel(f.addr, pc)
+	}
+	f.pending, f.pendingLabel, f.pendingGas, f.childGas = true, f.label, gas, 0
+}
+
+// settle attributes the gas used by the pending opcode of a frame, given the gas
+// remaining after it.
+func (t *gasProfiler) settle(f *profileFrame, remaining uint64) {
+	if used := f.pendingGas - min(remaining, f.pendingGas); used > f.childGas {
+		t.samples[f.prefix+";"+f.pendingLabel] += used - f.childGas
+	}
+	f.pending = false
+}
+
+// OnGasChange is called when gas is either consumed or refunded.
+func (t *gasProfiler) OnGasChange(old, new uint64, reason tracing.GasChangeReason) {
+	if reason == tracing.GasChangeTxIntrinsicGas && old > new && !t.interrupt.Load() {
+		t.samples[intrinsicGasFrame] += old - new
+	}
+}
+
+// GetResult returns the gas profile in the configured format.
+func (t *gasProfiler) GetResult() (json.RawMessage, error) {
+	stacks := make([]string, 0, len(t.samples))
+	for stack := range t.samples {
+		stacks = append(stacks, stack)
+	}
+	sort.Strings(stacks)
+
+	if t.config.Format == "pprof" {
+		var buf bytes.Buffer
+		if err := t.profile(stacks).Write(&buf); err != nil {
+			return nil, err
+		}
+		res, err := json.Marshal(buf.Bytes())
+		if err != nil {
+			return nil, err
+		}
+		return res, t.reason
+	}
+	var folded strings.Builder
+	for _, stack := range stacks {
+		fmt.Fprintf(&folded, "%s %d\n", stack, t.samples[stack])
+	}
+	res, err := json.Marshal(folded.String())
+	if err != nil {
+		return nil, err
+	}
+	return res, t.reason
+}
+
+// profile converts the samples into a pprof profile.
+func (t *gasProfiler) profile(stacks []string) *profile.Profile {
+	var (
+		p = &profile.Profile{
+			SampleType: []*profile.ValueType{{Type: "gas", Unit: "count"}},
+			PeriodType: &profile.ValueType{Type: "gas", Unit: "count"},
+			Period:     1,
+		}
+		locations = make(map[string]*profile.Location)
+	)
+	location := func(name string) *profile.Location {
+		if loc, ok := locations[name]; ok {
+			return loc
+		}
+		fn := &profile.Function{ID: uint64(len(p.Function) + 1), Name: name}
+		loc := &profile.Location{ID: uint64(len(p.Location) + 1), Line: []profile.Line{{Function: fn}}}
+		p.Function = append(p.Function, fn)
+		p.Location = append(p.Location, loc)
+		locations[name] = loc
+		return loc
+	}
+	for _, stack := range stacks {
+		// Locations of pprof samples are ordered from the leaf to the root.
+		elems := strings.Split(stack, ";")
+		sample := &profile.Sample{Value: []int64{int64(t.samples[stack])}}
+		for i := len(elems) - 1; i >= 0; i-- {
+			sample.Location = append(sample.Location, location(elems[i]))
+		}
+		p.Sample = append(p.Sample, sample)
+	}
+	return p
+}
+
+// Stop terminates execution of the tracer at the first opportune moment.
+func (t *gasProfiler) Stop(err error) {
+	t.reason = err
+	t.interrupt.Store(true)
+}
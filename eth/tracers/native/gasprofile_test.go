@@ -0,0 +1,137 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package native_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm/runtime"
+	"github.com/ethereum/go-ethereum/eth/tracers"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/google/pprof/profile"
+)
+
+var (
+	profiledOuter = common.HexToAddress("0xaaaa")
+	profiledInner = common.HexToAddress("0xbbbb")
+)
+
+// runGasProfiler executes a contract storing 42 and calling a second contract,
+// which stores 1 after jumping over some code. It returns the profiler result and
+// the gas used.
+func runGasProfiler(t *testing.T, config string) (json.RawMessage, uint64) {
+	statedb, _ := state.New(types.EmptyRootHash, state.NewDatabaseForTesting())
+	// PUSH1 42 PUSH1 1 SSTORE, CALL(gas, inner, 0, 0, 0, 0, 0), POP, STOP
+	statedb.SetCode(profiledOuter, common.FromHex("602a600155"+"60006000600060006000"+"73"+profiledInner.Hex()[2:]+"5af15000"))
+	// PUSH1 5 JUMP INVALID INVALID JUMPDEST PUSH1 1 PUSH1 0 SSTORE STOP
+	statedb.SetCode(profiledInner, common.FromHex("600556fefe5b600160005500"))
+
+	tracer, err := tracers.DefaultDirectory.New("gasProfiler", &tracers.Context{}, json.RawMessage(config), params.MainnetChainConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := &runtime.Config{State: statedb, GasLimit: 1_000_000}
+	cfg.EVMConfig.Tracer = tracer.Hooks
+	_, leftOver, err := runtime.Call(profiledOuter, nil, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := tracer.GetResult()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return res, cfg.GasLimit - leftOver
+}
+
+func TestGasProfilerFolded(t *testing.T) {
+	res, gasUsed := runGasProfiler(t, "")
+	var folded string
+	if err := json.Unmarshal(res, &folded); err != nil {
+		t.Fatal(err)
+	}
+	var (
+		total   uint64
+		samples = make(map[string]uint64)
+	)
+	for _, line := range strings.Split(strings.TrimSpace(folded), "\n") {
+		i := strings.LastIndexByte(line, ' ')
+		gas, err := strconv.ParseUint(line[i+1:], 10, 64)
+		if err != nil {
+			t.Fatalf("invalid line %q", line)
+		}
+		samples[line[:i]] = gas
+		total += gas
+	}
+	if total != gasUsed {
+		t.Errorf("profile total %d doesn't match gas used %d", total, gasUsed)
+	}
+	var (
+		outerBlock = "CALL " + profiledOuter.Hex() + ";" + profiledOuter.Hex() + ":0x0"
+		innerFrame = outerBlock + ";CALL " + profiledInner.Hex()
+	)
+	want := map[string]uint64{
+		// PUSH1, PUSH1, SSTORE (22100), 5 PUSH1, PUSH20, GAS, CALL (cold access), POP, STOP
+		outerBlock: 3 + 3 + 22100 + 5*3 + 3 + 2 + 2600 + 2,
+		// PUSH1, JUMP
+		innerFrame + ";" + profiledInner.Hex() + ":0x0": 3 + 8,
+		// JUMPDEST, PUSH1, PUSH1, SSTORE (22100), STOP
+		innerFrame + ";" + profiledInner.Hex() + ":0x5": 1 + 3 + 3 + 22100,
+	}
+	for stack, gas := range want {
+		if samples[stack] != gas {
+			t.Errorf("wrong gas for %q: have %d, want %d\nprofile:\n%s", stack, samples[stack], gas, folded)
+		}
+	}
+	if len(samples) != len(want) {
+		t.Errorf("wrong number of stacks %d, want %d\nprofile:\n%s", len(samples), len(want), folded)
+	}
+}
+
+func TestGasProfilerPprof(t *testing.T) {
+	res, gasUsed := runGasProfiler(t, `{"format": "pprof"}`)
+	var blob []byte
+	if err := json.Unmarshal(res, &blob); err != nil {
+		t.Fatal(err)
+	}
+	p, err := profile.Parse(bytes.NewReader(blob))
+	if err != nil {
+		t.Fatal("invalid pprof profile:", err)
+	}
+	var total int64
+	for _, s := range p.Sample {
+		total += s.Value[0]
+		if root := s.Location[len(s.Location)-1].Line[0].Function.Name; !strings.HasPrefix(root, "CALL ") {
+			t.Errorf("wrong root location %q", root)
+		}
+	}
+	if uint64(total) != gasUsed {
+		t.Errorf("profile total %d doesn't match gas used %d", total, gasUsed)
+	}
+}
+
+func TestGasProfilerInvalidFormat(t *testing.T) {
+	if _, err := tracers.DefaultDirectory.New("gasProfiler", &tracers.Context{}, json.RawMessage(`{"format": "svg"}`), params.MainnetChainConfig); err == nil {
+		t.Fatal("unknown format accepted")
+	}
+}
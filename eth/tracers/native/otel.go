@@ -0,0 +1,90 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package native
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/tracing"
+	"github.com/ethereum/go-ethereum/eth/tracers"
+	"github.com/ethereum/go-ethereum/eth/tracers/otel"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+func init() {
+	tracers.DefaultDirectory.Register("otelTracer", newOtelTracer, false)
+}
+
+// otelTracer converts the call frames of a transaction into OpenTelemetry spans.
+// The result is the OTLP/JSON encoding of the spans, which can be imported into
+// any OpenTelemetry backend. Only the service name of the config is used: the
+// file and collector exporters are available to the operator through the live
+// tracer, as callers of the tracing API must not make the node write files or
+// send requests.
+//
+// Example:
+//
+//	> debug.traceTransaction("0x...", {tracer: "otelTracer", tracerConfig: {service: "geth"}})
+type otelTracer struct {
+	result *otel.TracesData
+	reason error // Textual reason for the interruption
+}
+
+func newOtelTracer(ctx *tracers.Context, cfg json.RawMessage, chainConfig *params.ChainConfig) (*tracers.Tracer, error) {
+	var config otel.Config
+	if cfg != nil {
+		if err := json.Unmarshal(cfg, &config); err != nil {
+			return nil, err
+		}
+	}
+	t := new(otelTracer)
+	tracer := otel.New(config.ServiceName(), chainConfig, func(data *otel.TracesData) {
+		t.result = data
+	})
+	if ctx != nil && ctx.BlockHash != (common.Hash{}) {
+		tracer.SetTraceID(ctx.BlockHash)
+	}
+	hooks := tracer.Hooks()
+	return &tracers.Tracer{
+		Hooks: &tracing.Hooks{
+			OnTxStart: hooks.OnTxStart,
+			OnTxEnd:   hooks.OnTxEnd,
+			OnEnter:   hooks.OnEnter,
+			OnExit:    hooks.OnExit,
+		},
+		GetResult: t.GetResult,
+		Stop:      t.Stop,
+	}, nil
+}
+
+// GetResult returns the spans of the transaction.
+func (t *otelTracer) GetResult() (json.RawMessage, error) {
+	if t.reason != nil {
+		return nil, t.reason
+	}
+	if t.result == nil {
+		return nil, errors.New("no transaction traced")
+	}
+	return json.Marshal(t.result)
+}
+
+// Stop terminates execution of the tracer at the first opportune moment.
+func (t *otelTracer) Stop(err error) {
+	t.reason = err
+}
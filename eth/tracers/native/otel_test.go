@@ -0,0 +1,73 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package native_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/core/vm/runtime"
+	"github.com/ethereum/go-ethereum/eth/tracers"
+	"github.com/ethereum/go-ethereum/eth/tracers/otel"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// Tests that the per-call OpenTelemetry tracer only returns the spans, without
+// exporting them to the file or collector given by the caller.
+func TestOtelTracerNoExport(t *testing.T) {
+	var requests int
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+	}))
+	defer collector.Close()
+
+	path := filepath.Join(t.TempDir(), "spans.json")
+	config := fmt.Sprintf(`{"path": %q, "endpoint": %q, "service": "test"}`, path, collector.URL)
+	tracer, err := tracers.DefaultDirectory.New("otelTracer", &tracers.Context{}, json.RawMessage(config), params.MergedTestChainConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	runtime.Execute([]byte{byte(vm.STOP)}, nil, &runtime.Config{
+		ChainConfig: params.MergedTestChainConfig,
+		Random:      &common.Hash{},
+		EVMConfig:   vm.Config{Tracer: tracer.Hooks},
+	})
+	res, err := tracer.GetResult()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var data otel.TracesData
+	if err := json.Unmarshal(res, &data); err != nil {
+		t.Fatal(err)
+	}
+	if spans := data.ResourceSpans[0].ScopeSpans[0].Spans; len(spans) == 0 {
+		t.Fatal("no spans returned")
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("spans written to caller provided path: %v", err)
+	}
+	if requests != 0 {
+		t.Errorf("spans sent to caller provided endpoint")
+	}
+}
@@ -0,0 +1,209 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package otel
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// Config is the configuration of the tracer's exporters.
+type Config struct {
+	Path     string `json:"path"`     // File to append the spans to as OTLP/JSON lines
+	Endpoint string `json:"endpoint"` // OTLP/HTTP endpoint of a collector, e.g. http://localhost:4318/v1/traces
+	Service  string `json:"service"`  // Service name of the spans, defaults to "geth"
+}
+
+// ServiceName returns the configured service name or the default one.
+func (c *Config) ServiceName() string {
+	if c.Service == "" {
+		return "geth"
+	}
+	return c.Service
+}
+
+// NewExporter creates an exporter sending the spans to the configured file and
+// endpoint. It returns nil if neither is configured.
+func (c *Config) NewExporter() (Exporter, error) {
+	var exporters multiExporter
+	if c.Path != "" {
+		e, err := NewFileExporter(c.Path)
+		if err != nil {
+			return nil, err
+		}
+		exporters = append(exporters, e)
+	}
+	if c.Endpoint != "" {
+		exporters = append(exporters, NewHTTPExporter(c.Endpoint))
+	}
+	switch len(exporters) {
+	case 0:
+		return nil, nil
+	case 1:
+		return exporters[0], nil
+	default:
+		return exporters, nil
+	}
+}
+
+// Exporter sends batches of spans to an OpenTelemetry backend.
+type Exporter interface {
+	Export(data *TracesData) error
+	Close() error
+}
+
+// fileExporter appends batches to a file, one JSON document per line. This is the
+// format of the file exporter of the OpenTelemetry collector, which can also read
+// it back with its OTLP/JSON file receiver.
+type fileExporter struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+// NewFileExporter creates an exporter appending OTLP/JSON lines to a file.
+func NewFileExporter(path string) (Exporter, error) {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, err
+	}
+	return &fileExporter{file: file}, nil
+}
+
+func (e *fileExporter) Export(data *TracesData) error {
+	blob, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	_, err = e.file.Write(append(blob, '\n'))
+	return err
+}
+
+func (e *fileExporter) Close() error {
+	return e.file.Close()
+}
+
+// httpExporter posts batches to the OTLP/HTTP endpoint of a collector.
+type httpExporter struct {
+	endpoint string
+	client   *http.Client
+}
+
+// NewHTTPExporter creates an exporter posting OTLP/JSON to a collector endpoint,
+// e.g. http://localhost:4318/v1/traces.
+func NewHTTPExporter(endpoint string) Exporter {
+	return &httpExporter{
+		endpoint: endpoint,
+		client:   &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+func (e *httpExporter) Export(data *TracesData) error {
+	blob, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	res, err := e.client.Post(e.endpoint, "application/json", bytes.NewReader(blob))
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode/100 != 2 {
+		body, _ := io.ReadAll(io.LimitReader(res.Body, 1024))
+		return fmt.Errorf("collector returned %s: %s", res.Status, bytes.TrimSpace(body))
+	}
+	return nil
+}
+
+func (e *httpExporter) Close() error {
+	e.client.CloseIdleConnections()
+	return nil
+}
+
+// multiExporter exports to multiple exporters.
+type multiExporter []Exporter
+
+func (m multiExporter) Export(data *TracesData) error {
+	var errs []error
+	for _, e := range m {
+		errs = append(errs, e.Export(data))
+	}
+	return errors.Join(errs...)
+}
+
+func (m multiExporter) Close() error {
+	var errs []error
+	for _, e := range m {
+		errs = append(errs, e.Close())
+	}
+	return errors.Join(errs...)
+}
+
+// queue exports in the background, so that slow backends don't hold up the
+// traced execution.
+type queue struct {
+	exporter Exporter
+	batches  chan *TracesData
+	done     chan struct{}
+}
+
+// NewQueue wraps an exporter to export in the background. Batches are dropped if
+// more than size of them are waiting to be exported.
+func NewQueue(exporter Exporter, size int) Exporter {
+	q := &queue{
+		exporter: exporter,
+		batches:  make(chan *TracesData, size),
+		done:     make(chan struct{}),
+	}
+	go q.loop()
+	return q
+}
+
+func (q *queue) loop() {
+	defer close(q.done)
+	for data := range q.batches {
+		if err := q.exporter.Export(data); err != nil {
+			log.Warn("Failed to export spans", "err", err)
+		}
+	}
+}
+
+func (q *queue) Export(data *TracesData) error {
+	select {
+	case q.batches <- data:
+		return nil
+	default:
+		return errors.New("export queue full, spans dropped")
+	}
+}
+
+// Close exports the queued batches and closes the wrapped exporter.
+func (q *queue) Close() error {
+	close(q.batches)
+	<-q.done
+	return q.exporter.Close()
+}
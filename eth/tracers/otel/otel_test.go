@@ -0,0 +1,209 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package otel
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/tracing"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/core/vm/runtime"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// revertingCode calls the sha256 precompile and reverts with reason "boom".
+func revertingCode() []byte {
+	reason := crypto.Keccak256([]byte("Error(string)"))[:4]
+	reason = append(reason, common.LeftPadBytes([]byte{0x20}, 32)...)
+	reason = append(reason, common.LeftPadBytes([]byte{4}, 32)...)
+	reason = append(reason, common.RightPadBytes([]byte("boom"), 32)...)
+
+	code := []byte{
+		// STATICCALL(gas, 0x02, 0, 0, 0, 32)
+		byte(vm.PUSH1), 32, byte(vm.PUSH1), 0, byte(vm.PUSH1), 0, byte(vm.PUSH1), 0,
+		byte(vm.PUSH1), 2, byte(vm.GAS), byte(vm.STATICCALL), byte(vm.POP),
+		// CODECOPY(0, 25, 100), REVERT(0, 100)
+		byte(vm.PUSH1), 100, byte(vm.PUSH1), 25, byte(vm.PUSH1), 0, byte(vm.CODECOPY),
+		byte(vm.PUSH1), 100, byte(vm.PUSH1), 0, byte(vm.REVERT),
+	}
+	return append(code, reason...)
+}
+
+func TestTracerTransaction(t *testing.T) {
+	var exported []*TracesData
+	tracer := New("test", params.MergedTestChainConfig, func(data *TracesData) {
+		exported = append(exported, data)
+	})
+	runtime.Execute(revertingCode(), nil, &runtime.Config{
+		ChainConfig: params.MergedTestChainConfig,
+		Random:      &common.Hash{},
+		EVMConfig:   vm.Config{Tracer: tracer.Hooks()},
+	})
+	if len(exported) != 1 {
+		t.Fatalf("exported %d batches, want 1", len(exported))
+	}
+	spans := exported[0].ResourceSpans[0].ScopeSpans[0].Spans
+	if len(spans) != 3 {
+		t.Fatalf("have %d spans, want 3", len(spans))
+	}
+	// Spans are reported as they finish, innermost first.
+	precompile, call, tx := spans[0], spans[1], spans[2]
+	if tx.Name != "transaction" || tx.ParentSpanID != "" || tx.TraceID != spans[0].TraceID {
+		t.Errorf("wrong transaction span: %+v", tx)
+	}
+	if call.Name != "CALL" || call.ParentSpanID != tx.SpanID {
+		t.Errorf("wrong call span: %+v", call)
+	}
+	if call.Status.Code != StatusError || call.Status.Message != "execution reverted: boom" {
+		t.Errorf("wrong call status: %+v", call.Status)
+	}
+	if reason := call.Attribute("evm.revert_reason"); reason == nil || *reason.StringValue != "boom" {
+		t.Errorf("wrong revert reason: %v", reason)
+	}
+	if precompile.Name != "STATICCALL" || precompile.ParentSpanID != call.SpanID || precompile.Status.Code != StatusUnset {
+		t.Errorf("wrong precompile span: %+v", precompile)
+	}
+	if p := precompile.Attribute("evm.precompile"); p == nil || !*p.BoolValue {
+		t.Error("precompile not marked")
+	}
+	if p := call.Attribute("evm.precompile"); p == nil || *p.BoolValue {
+		t.Error("contract marked as precompile")
+	}
+	if gas := precompile.Attribute("evm.gas_used"); gas == nil || *gas.IntValue != int64(params.Sha256BaseGas) {
+		t.Errorf("wrong precompile gas: %v", gas)
+	}
+}
+
+func TestTracerBlock(t *testing.T) {
+	var exported []*TracesData
+	tracer := New("test", nil, func(data *TracesData) {
+		exported = append(exported, data)
+	})
+	hooks := tracer.Hooks()
+
+	block := types.NewBlockWithHeader(&types.Header{Number: big.NewInt(5), GasLimit: 30_000_000})
+	tx := types.NewTx(&types.LegacyTx{Gas: 21000, To: &common.Address{1}})
+	hooks.OnBlockStart(tracing.BlockEvent{Block: block})
+	for i := 0; i < 2; i++ {
+		hooks.OnTxStart(&tracing.VMContext{BlockNumber: block.Number()}, tx, common.Address{2})
+		hooks.OnEnter(0, byte(vm.CALL), common.Address{2}, common.Address{1}, nil, 0, new(big.Int))
+		hooks.OnExit(0, nil, 0, nil, false)
+		hooks.OnTxEnd(&types.Receipt{GasUsed: 21000, Status: types.ReceiptStatusSuccessful}, nil)
+	}
+	if len(exported) != 0 {
+		t.Fatal("spans exported before the end of the block")
+	}
+	hooks.OnBlockEnd(nil)
+	if len(exported) != 1 {
+		t.Fatalf("exported %d batches, want 1", len(exported))
+	}
+	spans := exported[0].ResourceSpans[0].ScopeSpans[0].Spans
+	if len(spans) != 5 {
+		t.Fatalf("have %d spans, want 5", len(spans))
+	}
+	root := spans[4]
+	if root.Name != "block" || root.TraceID != traceID(block.Hash()) {
+		t.Fatalf("wrong block span: %+v", root)
+	}
+	for _, i := range []int{1, 3} {
+		if spans[i].Name != "transaction" || spans[i].ParentSpanID != root.SpanID {
+			t.Errorf("wrong transaction span: %+v", spans[i])
+		}
+		if spans[i-1].ParentSpanID != spans[i].SpanID {
+			t.Errorf("call span not nested in transaction")
+		}
+	}
+}
+
+func TestFileExporter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "spans.jsonl")
+	for i := 0; i < 2; i++ {
+		exporter, err := (&Config{Path: path}).NewExporter()
+		if err != nil {
+			t.Fatal(err)
+		}
+		span := &Span{TraceID: "01", SpanID: "02", Name: "test", StartTime: 1, EndTime: 2}
+		span.setInt("gas", 21000)
+		if err := exporter.Export(newTracesData("test", []*Span{span})); err != nil {
+			t.Fatal(err)
+		}
+		exporter.Close()
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	var lines int
+	for scanner := bufio.NewScanner(f); scanner.Scan(); lines++ {
+		// 64 bit integers are encoded as strings in OTLP/JSON.
+		want := `"startTimeUnixNano":"1","endTimeUnixNano":"2","attributes":[{"key":"gas","value":{"intValue":"21000"}}]`
+		if !strings.Contains(scanner.Text(), want) {
+			t.Fatalf("wrong encoding: %s", scanner.Text())
+		}
+		var data TracesData
+		if err := json.Unmarshal(scanner.Bytes(), &data); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if lines != 2 {
+		t.Fatalf("have %d lines, want 2", lines)
+	}
+}
+
+func TestHTTPExporter(t *testing.T) {
+	received := make(chan []byte, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/traces" || r.Header.Get("Content-Type") != "application/json" {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		received <- body
+	}))
+	defer srv.Close()
+
+	exporter := NewQueue(NewHTTPExporter(srv.URL+"/v1/traces"), 1)
+	if err := exporter.Export(newTracesData("test", []*Span{{Name: "test"}})); err != nil {
+		t.Fatal(err)
+	}
+	if err := exporter.Close(); err != nil {
+		t.Fatal(err)
+	}
+	var data TracesData
+	if err := json.Unmarshal(<-received, &data); err != nil {
+		t.Fatal(err)
+	}
+	if name := data.ResourceSpans[0].ScopeSpans[0].Spans[0].Name; name != "test" {
+		t.Fatalf("wrong span received: %s", name)
+	}
+	if err := NewHTTPExporter(srv.URL + "/wrong").Export(&TracesData{}); err == nil {
+		t.Fatal("collector error not reported")
+	}
+}
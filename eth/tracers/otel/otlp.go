@@ -0,0 +1,130 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package otel
+
+// The types below are the subset of the OTLP/JSON trace encoding produced by the
+// tracer, see https://opentelemetry.io/docs/specs/otlp/#json-protobuf-encoding.
+
+// TracesData is a batch of spans, as sent to a collector.
+type TracesData struct {
+	ResourceSpans []ResourceSpans `json:"resourceSpans"`
+}
+
+// ResourceSpans are the spans produced by a resource, i.e. the node.
+type ResourceSpans struct {
+	Resource   Resource     `json:"resource"`
+	ScopeSpans []ScopeSpans `json:"scopeSpans"`
+}
+
+// Resource describes the entity producing the spans.
+type Resource struct {
+	Attributes []KeyValue `json:"attributes"`
+}
+
+// ScopeSpans are the spans produced by an instrumentation scope.
+type ScopeSpans struct {
+	Scope Scope   `json:"scope"`
+	Spans []*Span `json:"spans"`
+}
+
+// Scope is the instrumentation scope, i.e. the tracer.
+type Scope struct {
+	Name string `json:"name"`
+}
+
+// Span kinds.
+const (
+	SpanKindInternal = 1
+	SpanKindServer   = 2
+)
+
+// Span status codes.
+const (
+	StatusUnset = 0
+	StatusOk    = 1
+	StatusError = 2
+)
+
+// Span is a timed operation: a block, a transaction or a call frame.
+type Span struct {
+	TraceID      string     `json:"traceId"`
+	SpanID       string     `json:"spanId"`
+	ParentSpanID string     `json:"parentSpanId,omitempty"`
+	Name         string     `json:"name"`
+	Kind         int        `json:"kind"`
+	StartTime    uint64     `json:"startTimeUnixNano,string"`
+	EndTime      uint64     `json:"endTimeUnixNano,string"`
+	Attributes   []KeyValue `json:"attributes,omitempty"`
+	Status       Status     `json:"status"`
+}
+
+// Status is the outcome of a span.
+type Status struct {
+	Code    int    `json:"code,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+// KeyValue is an attribute of a span or resource.
+type KeyValue struct {
+	Key   string   `json:"key"`
+	Value AnyValue `json:"value"`
+}
+
+// AnyValue is the value of an attribute. Exactly one of the fields is set.
+type AnyValue struct {
+	StringValue *string `json:"stringValue,omitempty"`
+	IntValue    *int64  `json:"intValue,string,omitempty"`
+	BoolValue   *bool   `json:"boolValue,omitempty"`
+}
+
+// Attribute returns the value of the span attribute with the given key, or nil
+// if the span has no such attribute.
+func (s *Span) Attribute(key string) *AnyValue {
+	for i := range s.Attributes {
+		if s.Attributes[i].Key == key {
+			return &s.Attributes[i].Value
+		}
+	}
+	return nil
+}
+
+func (s *Span) setString(key, value string) {
+	s.Attributes = append(s.Attributes, KeyValue{key, AnyValue{StringValue: &value}})
+}
+
+func (s *Span) setInt(key string, value int64) {
+	s.Attributes = append(s.Attributes, KeyValue{key, AnyValue{IntValue: &value}})
+}
+
+func (s *Span) setBool(key string, value bool) {
+	s.Attributes = append(s.Attributes, KeyValue{key, AnyValue{BoolValue: &value}})
+}
+
+// newTracesData wraps the spans produced by the named service into a batch.
+func newTracesData(service string, spans []*Span) *TracesData {
+	return &TracesData{
+		ResourceSpans: []ResourceSpans{{
+			Resource: Resource{Attributes: []KeyValue{
+				{Key: "service.name", Value: AnyValue{StringValue: &service}},
+			}},
+			ScopeSpans: []ScopeSpans{{
+				Scope: Scope{Name: scopeName},
+				Spans: spans,
+			}},
+		}},
+	}
+}
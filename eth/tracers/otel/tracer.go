@@ -0,0 +1,248 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package otel implements a tracer exporting EVM execution as OpenTelemetry spans.
+//
+// Every traced block, transaction and call frame becomes a span, nested in this
+// order. The trace ID of a block is derived from its hash, and the trace ID of a
+// transaction traced on its own from the hash of its block, or its own hash if it
+// isn't part of a block. This way, the traces can be looked up by hash in the
+// tracing backend, and spans of the same block end up in the same trace.
+package otel
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/tracing"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+const scopeName = "github.com/ethereum/go-ethereum/eth/tracers/otel"
+
+// Tracer converts the execution of blocks and transactions into spans. The spans
+// of a block, or of a transaction executed outside of a block, are passed to the
+// export function once it has been processed.
+type Tracer struct {
+	service     string
+	config      *params.ChainConfig
+	export      func(*TracesData)
+	traceID     string                  // fixed trace ID of transactions outside of a block
+	precompiles map[common.Address]bool // precompiles active in the current transaction
+
+	block  *Span
+	tx     *Span
+	frames []*Span
+	spans  []*Span // finished spans of the current block or transaction
+}
+
+// New creates a tracer exporting the spans as the given service. The chain config
+// is used to identify precompiles, it is updated when tracing a blockchain.
+func New(service string, config *params.ChainConfig, export func(*TracesData)) *Tracer {
+	return &Tracer{service: service, config: config, export: export}
+}
+
+// SetTraceID overrides the trace ID of transactions executed outside of a block,
+// so that transactions traced individually end up in the trace of their block.
+func (t *Tracer) SetTraceID(blockHash common.Hash) {
+	t.traceID = traceID(blockHash)
+}
+
+// Hooks returns the tracing hooks of the tracer.
+func (t *Tracer) Hooks() *tracing.Hooks {
+	return &tracing.Hooks{
+		OnBlockchainInit: t.onBlockchainInit,
+		OnBlockStart:     t.onBlockStart,
+		OnBlockEnd:       t.onBlockEnd,
+		OnTxStart:        t.onTxStart,
+		OnTxEnd:          t.onTxEnd,
+		OnEnter:          t.onEnter,
+		OnExit:           t.onExit,
+	}
+}
+
+func (t *Tracer) onBlockchainInit(config *params.ChainConfig) {
+	t.config = config
+}
+
+func (t *Tracer) onBlockStart(ev tracing.BlockEvent) {
+	b := ev.Block
+	t.block = t.start(traceID(b.Hash()), "", "block", SpanKindServer)
+	t.block.setInt("eth.block.number", int64(b.NumberU64()))
+	t.block.setString("eth.block.hash", b.Hash().Hex())
+	t.block.setString("eth.block.coinbase", b.Coinbase().Hex())
+	t.block.setInt("eth.block.gas_limit", int64(b.GasLimit()))
+	t.block.setInt("eth.block.gas_used", int64(b.GasUsed()))
+	t.block.setInt("eth.block.tx_count", int64(len(b.Transactions())))
+	if b.BaseFee() != nil {
+		t.block.setString("eth.block.base_fee", b.BaseFee().String())
+	}
+}
+
+func (t *Tracer) onBlockEnd(err error) {
+	if t.block == nil {
+		return
+	}
+	t.finish(t.block, err)
+	t.block = nil
+	t.flush()
+}
+
+func (t *Tracer) onTxStart(env *tracing.VMContext, tx *types.Transaction, from common.Address) {
+	// Without a chain config, any known precompile is assumed to be active.
+	addrs := vm.PrecompiledAddressesPrague
+	if t.config != nil {
+		addrs = vm.ActivePrecompiles(t.config.Rules(env.BlockNumber, env.Random != nil, env.Time))
+	}
+	t.precompiles = make(map[common.Address]bool, len(addrs))
+	for _, addr := range addrs {
+		t.precompiles[addr] = true
+	}
+	t.frames = t.frames[:0]
+
+	switch {
+	case t.block != nil:
+		t.tx = t.start(t.block.TraceID, t.block.SpanID, "transaction", SpanKindInternal)
+	case t.traceID != "":
+		t.tx = t.start(t.traceID, "", "transaction", SpanKindServer)
+	default:
+		t.tx = t.start(traceID(tx.Hash()), "", "transaction", SpanKindServer)
+	}
+	t.tx.setString("eth.tx.hash", tx.Hash().Hex())
+	t.tx.setString("eth.tx.from", from.Hex())
+	if to := tx.To(); to != nil {
+		t.tx.setString("eth.tx.to", to.Hex())
+	}
+	t.tx.setInt("eth.tx.type", int64(tx.Type()))
+	t.tx.setInt("eth.tx.nonce", int64(tx.Nonce()))
+	t.tx.setInt("eth.tx.gas_limit", int64(tx.Gas()))
+	t.tx.setString("eth.tx.value", tx.Value().String())
+	if env.GasPrice != nil {
+		t.tx.setString("eth.tx.gas_price", env.GasPrice.String())
+	}
+}
+
+func (t *Tracer) onTxEnd(receipt *types.Receipt, err error) {
+	if t.tx == nil {
+		return
+	}
+	if receipt != nil {
+		t.tx.setInt("eth.tx.gas_used", int64(receipt.GasUsed))
+		t.tx.setInt("eth.tx.status", int64(receipt.Status))
+	}
+	t.finish(t.tx, err)
+	t.tx = nil
+	if t.block == nil {
+		t.flush()
+	}
+}
+
+func (t *Tracer) onEnter(depth int, typ byte, from common.Address, to common.Address, input []byte, gas uint64, value *big.Int) {
+	var parent *Span
+	switch {
+	case len(t.frames) > 0:
+		parent = t.frames[len(t.frames)-1]
+	case t.tx != nil:
+		parent = t.tx
+	default:
+		return // calls outside of a transaction, e.g. system calls, are not traced
+	}
+	span := t.start(parent.TraceID, parent.SpanID, vm.OpCode(typ).String(), SpanKindInternal)
+	span.setInt("evm.depth", int64(depth))
+	span.setString("evm.from", from.Hex())
+	span.setString("evm.to", to.Hex())
+	span.setInt("evm.gas", int64(gas))
+	if value != nil {
+		span.setString("evm.value", value.String())
+	}
+	span.setInt("evm.input_size", int64(len(input)))
+	if len(input) >= 4 {
+		span.setString("evm.selector", hexutil.Encode(input[:4]))
+	}
+	span.setBool("evm.precompile", t.precompiles[to])
+	t.frames = append(t.frames, span)
+}
+
+func (t *Tracer) onExit(depth int, output []byte, gasUsed uint64, err error, reverted bool) {
+	if len(t.frames) == 0 {
+		return
+	}
+	span := t.frames[len(t.frames)-1]
+	t.frames = t.frames[:len(t.frames)-1]
+
+	span.setInt("evm.gas_used", int64(gasUsed))
+	span.setInt("evm.output_size", int64(len(output)))
+	if errors.Is(err, vm.ErrExecutionReverted) {
+		if reason, unpackErr := abi.UnpackRevert(output); unpackErr == nil {
+			span.setString("evm.revert_reason", reason)
+			err = errors.New(err.Error() + ": " + reason)
+		}
+	}
+	t.finish(span, err)
+}
+
+// start creates a span starting now.
+func (t *Tracer) start(traceID, parentID, name string, kind int) *Span {
+	return &Span{
+		TraceID:      traceID,
+		SpanID:       spanID(),
+		ParentSpanID: parentID,
+		Name:         name,
+		Kind:         kind,
+		StartTime:    uint64(time.Now().UnixNano()),
+	}
+}
+
+// finish ends the span now, failing it if an error is given.
+func (t *Tracer) finish(span *Span, err error) {
+	span.EndTime = uint64(time.Now().UnixNano())
+	if err != nil {
+		span.Status = Status{Code: StatusError, Message: err.Error()}
+	}
+	t.spans = append(t.spans, span)
+}
+
+// flush exports the finished spans.
+func (t *Tracer) flush() {
+	if len(t.spans) == 0 {
+		return
+	}
+	spans := t.spans
+	t.spans = nil
+	if t.export != nil {
+		t.export(newTracesData(t.service, spans))
+	}
+}
+
+// traceID derives a trace ID from a hash.
+func traceID(hash common.Hash) string {
+	return hex.EncodeToString(hash[:16])
+}
+
+// spanID returns a random span ID.
+func spanID() string {
+	var id [8]byte
+	rand.Read(id[:])
+	return hex.EncodeToString(id[:])
+}
@@ -0,0 +1,154 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package bundler provides an RPC client for the API of ERC-4337 bundlers, and a
+// transactor sending the transactions of contract bindings as user operations.
+package bundler
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/erc4337"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+//go:generate go run github.com/fjl/gencodec -type UserOperationReceipt -field-override userOperationReceiptMarshaling -out gen_receipt_json.go
+
+// Client is a wrapper around rpc.Client that implements the bundler API.
+type Client struct {
+	c *rpc.Client
+}
+
+// Dial connects a client to the given bundler URL.
+func Dial(rawurl string) (*Client, error) {
+	return DialContext(context.Background(), rawurl)
+}
+
+// DialContext connects a client to the given bundler URL with context.
+func DialContext(ctx context.Context, rawurl string) (*Client, error) {
+	c, err := rpc.DialContext(ctx, rawurl)
+	if err != nil {
+		return nil, err
+	}
+	return New(c), nil
+}
+
+// New creates a client that uses the given RPC client.
+func New(c *rpc.Client) *Client {
+	return &Client{c}
+}
+
+// Close closes the underlying RPC connection.
+func (bc *Client) Close() {
+	bc.c.Close()
+}
+
+// ChainID retrieves the chain ID of the bundler.
+func (bc *Client) ChainID(ctx context.Context) (*big.Int, error) {
+	var result hexutil.Big
+	if err := bc.c.CallContext(ctx, &result, "eth_chainId"); err != nil {
+		return nil, err
+	}
+	return (*big.Int)(&result), nil
+}
+
+// SupportedEntryPoints returns the entry point contracts supported by the bundler.
+func (bc *Client) SupportedEntryPoints(ctx context.Context) ([]common.Address, error) {
+	var result []common.Address
+	err := bc.c.CallContext(ctx, &result, "eth_supportedEntryPoints")
+	return result, err
+}
+
+// SendUserOperation submits a signed user operation to the bundler, returning
+// its hash.
+func (bc *Client) SendUserOperation(ctx context.Context, op *erc4337.UserOperation, entryPoint common.Address) (common.Hash, error) {
+	var hash common.Hash
+	err := bc.c.CallContext(ctx, &hash, "eth_sendUserOperation", op, entryPoint)
+	return hash, err
+}
+
+// EstimateUserOperationGas estimates the gas limits of a user operation, which
+// must be signed with a dummy signature. Paymasters gas limits are estimated by
+// some bundlers, but are not returned.
+func (bc *Client) EstimateUserOperationGas(ctx context.Context, op *erc4337.UserOperation, entryPoint common.Address) (*erc4337.GasEstimate, error) {
+	var result struct {
+		PreVerificationGas   hexutil.Uint64 `json:"preVerificationGas"`
+		VerificationGasLimit hexutil.Uint64 `json:"verificationGasLimit"`
+		CallGasLimit         hexutil.Uint64 `json:"callGasLimit"`
+	}
+	if err := bc.c.CallContext(ctx, &result, "eth_estimateUserOperationGas", op, entryPoint); err != nil {
+		return nil, err
+	}
+	return &erc4337.GasEstimate{
+		PreVerificationGas:   uint64(result.PreVerificationGas),
+		VerificationGasLimit: uint64(result.VerificationGasLimit),
+		CallGasLimit:         uint64(result.CallGasLimit),
+	}, nil
+}
+
+// UserOperationByHash returns the user operation with the given hash, and the
+// entry point it was sent to.
+func (bc *Client) UserOperationByHash(ctx context.Context, hash common.Hash) (*erc4337.UserOperation, common.Address, error) {
+	var result *struct {
+		UserOperation *erc4337.UserOperation `json:"userOperation"`
+		EntryPoint    common.Address         `json:"entryPoint"`
+	}
+	if err := bc.c.CallContext(ctx, &result, "eth_getUserOperationByHash", hash); err != nil {
+		return nil, common.Address{}, err
+	}
+	if result == nil || result.UserOperation == nil {
+		return nil, common.Address{}, ethereum.NotFound
+	}
+	return result.UserOperation, result.EntryPoint, nil
+}
+
+// UserOperationReceipt is the receipt of a user operation included in a bundle.
+type UserOperationReceipt struct {
+	UserOpHash    common.Hash     `json:"userOpHash" gencodec:"required"`
+	EntryPoint    common.Address  `json:"entryPoint" gencodec:"required"`
+	Sender        common.Address  `json:"sender" gencodec:"required"`
+	Nonce         *big.Int        `json:"nonce" gencodec:"required"`
+	Paymaster     *common.Address `json:"paymaster,omitempty"`
+	ActualGasCost *big.Int        `json:"actualGasCost" gencodec:"required"`
+	ActualGasUsed uint64          `json:"actualGasUsed" gencodec:"required"`
+	Success       bool            `json:"success" gencodec:"required"`
+	Reason        []byte          `json:"reason,omitempty"`
+	Logs          []*types.Log    `json:"logs"`
+	Receipt       *types.Receipt  `json:"receipt" gencodec:"required"`
+}
+
+type userOperationReceiptMarshaling struct {
+	Nonce         *hexutil.Big
+	ActualGasCost *hexutil.Big
+	ActualGasUsed hexutil.Uint64
+	Reason        hexutil.Bytes
+}
+
+// UserOperationReceipt returns the receipt of the user operation with the given
+// hash. If the operation is not included yet, ethereum.NotFound is returned.
+func (bc *Client) UserOperationReceipt(ctx context.Context, hash common.Hash) (*UserOperationReceipt, error) {
+	var receipt *UserOperationReceipt
+	err := bc.c.CallContext(ctx, &receipt, "eth_getUserOperationReceipt", hash)
+	if err == nil && receipt == nil {
+		return nil, ethereum.NotFound
+	}
+	return receipt, err
+}
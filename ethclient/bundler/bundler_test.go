@@ -0,0 +1,250 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package bundler_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"math/big"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/accounts/erc4337"
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient/bundler"
+	"github.com/ethereum/go-ethereum/ethclient/simulated"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// standIn is a bundler which records the operations sent to it, and includes
+// them immediately.
+type standIn struct {
+	chainID *big.Int
+	lock    sync.Mutex
+	ops     map[common.Hash]*erc4337.UserOperation
+}
+
+func (s *standIn) ChainId() *hexutil.Big {
+	return (*hexutil.Big)(s.chainID)
+}
+
+func (s *standIn) SupportedEntryPoints() []common.Address {
+	return []common.Address{erc4337.EntryPoint07}
+}
+
+func (s *standIn) EstimateUserOperationGas(op erc4337.UserOperation, entryPoint common.Address) (map[string]hexutil.Uint64, error) {
+	if entryPoint != erc4337.EntryPoint07 {
+		return nil, errors.New("unsupported entry point")
+	}
+	verification := hexutil.Uint64(50000)
+	if op.Factory != nil {
+		verification += 200000
+	}
+	return map[string]hexutil.Uint64{
+		"preVerificationGas":   45000,
+		"verificationGasLimit": verification,
+		"callGasLimit":         30000,
+	}, nil
+}
+
+func (s *standIn) SendUserOperation(op erc4337.UserOperation, entryPoint common.Address) (common.Hash, error) {
+	hash := op.Hash(entryPoint, s.chainID)
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.ops[hash] = &op
+	return hash, nil
+}
+
+func (s *standIn) GetUserOperationByHash(hash common.Hash) (interface{}, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	op, ok := s.ops[hash]
+	if !ok {
+		return nil, nil
+	}
+	return map[string]interface{}{"userOperation": op, "entryPoint": erc4337.EntryPoint07}, nil
+}
+
+func (s *standIn) GetUserOperationReceipt(hash common.Hash) (*bundler.UserOperationReceipt, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	op, ok := s.ops[hash]
+	if !ok {
+		return nil, nil
+	}
+	log := &types.Log{Address: op.Sender, Topics: []common.Hash{hash}, Data: []byte{}}
+	return &bundler.UserOperationReceipt{
+		UserOpHash:    hash,
+		EntryPoint:    erc4337.EntryPoint07,
+		Sender:        op.Sender,
+		Nonce:         op.Nonce,
+		ActualGasCost: big.NewInt(1),
+		ActualGasUsed: 1,
+		Success:       true,
+		Logs:          []*types.Log{log},
+		Receipt:       &types.Receipt{Status: types.ReceiptStatusFailed, TxHash: common.Hash{0x01}, Logs: []*types.Log{}},
+	}, nil
+}
+
+func (s *standIn) operations() []*erc4337.UserOperation {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	var ops []*erc4337.UserOperation
+	for _, op := range s.ops {
+		ops = append(ops, op)
+	}
+	return ops
+}
+
+func TestTransactor(t *testing.T) {
+	var (
+		deployed   = common.HexToAddress("0xacc0")
+		undeployed = common.HexToAddress("0xacc1")
+		target     = common.HexToAddress("0x7a76e7")
+		factory    = common.HexToAddress("0xfac7")
+	)
+	sim := simulated.NewBackend(types.GenesisAlloc{
+		deployed:             {Code: []byte{0x00}},
+		target:               {Code: []byte{0x00}},
+		erc4337.EntryPoint07: {Code: hexutil.MustDecode("0x600560005260206000f3")}, // getNonce returns 5
+	})
+	defer sim.Close()
+
+	stand := &standIn{chainID: big.NewInt(1337), ops: make(map[common.Hash]*erc4337.UserOperation)}
+	server := rpc.NewServer()
+	defer server.Stop()
+	if err := server.RegisterName("eth", stand); err != nil {
+		t.Fatal(err)
+	}
+	client := bundler.New(rpc.DialInProc(server))
+	defer client.Close()
+
+	ks := keystore.NewKeyStore(t.TempDir(), keystore.LightScryptN, keystore.LightScryptP)
+	owner, err := ks.NewAccount("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ks.Unlock(owner, ""); err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	account := erc4337.NewSimpleAccount(deployed, ks.Wallets()[0], owner)
+	transactor, err := bundler.NewTransactor(ctx, client, sim.Client(), account, erc4337.EntryPoint07)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, err := abi.ParseHumanReadable([]string{"function store(uint256 value)"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	contract := bind.NewBoundContract(target, parsed, sim.Client(), transactor, sim.Client())
+	tx, err := contract.Transact(transactor.TransactOpts(ctx), "store", big.NewInt(42))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ops := stand.operations()
+	if len(ops) != 1 {
+		t.Fatalf("have %d operations, want 1", len(ops))
+	}
+	op := ops[0]
+	input, _ := parsed.Pack("store", big.NewInt(42))
+	callData, _ := account.EncodeCallData(target, new(big.Int), input)
+	if op.Sender != deployed || op.Nonce.Uint64() != 5 || !bytes.Equal(op.CallData, callData) || op.Factory != nil {
+		t.Errorf("unexpected operation %+v", op)
+	}
+	if op.PreVerificationGas != 45000 || op.VerificationGasLimit != 50000 || op.CallGasLimit != max(30000, tx.Gas()) {
+		t.Errorf("gas mismatch: %d %d %d", op.PreVerificationGas, op.VerificationGasLimit, op.CallGasLimit)
+	}
+	if op.MaxFeePerGas.Cmp(tx.GasFeeCap()) != 0 || op.MaxPriorityFeePerGas.Cmp(tx.GasTipCap()) != 0 {
+		t.Errorf("fees mismatch: %v %v", op.MaxFeePerGas, op.MaxPriorityFeePerGas)
+	}
+	hash := op.Hash(erc4337.EntryPoint07, big.NewInt(1337))
+	sig := common.CopyBytes(op.Signature)
+	sig[64] -= 27
+	pub, err := crypto.SigToPub(accounts.TextHash(hash[:]), sig)
+	if err != nil || crypto.PubkeyToAddress(*pub) != owner.Address {
+		t.Errorf("signature not made by the owner: %v", err)
+	}
+	if opHash, ok := transactor.UserOperationHash(tx); !ok || opHash != hash {
+		t.Errorf("operation hash mismatch: have %x, want %x", opHash, hash)
+	}
+	// The receipt is the receipt of the bundle, with the status of the operation.
+	waitCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	receipt, err := bind.WaitMined(waitCtx, transactor, tx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if receipt.Status != types.ReceiptStatusSuccessful || receipt.TxHash != (common.Hash{0x01}) || len(receipt.Logs) != 1 {
+		t.Errorf("unexpected receipt %+v", receipt)
+	}
+	sent, entryPoint, err := client.UserOperationByHash(ctx, hash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if entryPoint != erc4337.EntryPoint07 || sent.Hash(entryPoint, big.NewInt(1337)) != hash {
+		t.Errorf("unexpected operation %+v sent to %v", sent, entryPoint)
+	}
+	if _, err := client.UserOperationReceipt(ctx, common.Hash{0xff}); !errors.Is(err, ethereum.NotFound) {
+		t.Errorf("unexpected error for unknown operation: %v", err)
+	}
+
+	// Accounts without code are deployed by their factory.
+	account = erc4337.NewSimpleAccount(undeployed, ks.Wallets()[0], owner)
+	transactor, err = bundler.NewTransactor(ctx, client, sim.Client(), account, erc4337.EntryPoint07)
+	if err != nil {
+		t.Fatal(err)
+	}
+	contract = bind.NewBoundContract(target, parsed, sim.Client(), transactor, sim.Client())
+	if _, err := contract.Transact(transactor.TransactOpts(ctx), "store", big.NewInt(1)); err == nil || !strings.Contains(err.Error(), "has no factory") {
+		t.Fatalf("unexpected error without factory: %v", err)
+	}
+	if err := account.SetFactory(factory, big.NewInt(0)); err != nil {
+		t.Fatal(err)
+	}
+	tx, err = contract.Transact(transactor.TransactOpts(ctx), "store", big.NewInt(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	opHash, _ := transactor.UserOperationHash(tx)
+	op, _, err = client.UserOperationByHash(ctx, opHash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if op.Factory == nil || *op.Factory != factory || op.VerificationGasLimit != 250000 {
+		t.Errorf("account not deployed by the operation: %+v", op)
+	}
+	// Contract creations can't be sent.
+	if err := transactor.SendTransaction(ctx, types.NewTx(&types.DynamicFeeTx{})); !errors.Is(err, bundler.ErrContractCreation) {
+		t.Errorf("unexpected error for contract creation: %v", err)
+	}
+	entryPoints, err := client.SupportedEntryPoints(ctx)
+	if err != nil || len(entryPoints) != 1 || entryPoints[0] != erc4337.EntryPoint07 {
+		t.Errorf("unexpected entry points %v: %v", entryPoints, err)
+	}
+}
@@ -0,0 +1,233 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package bundler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/accounts/erc4337"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+var (
+	// ErrContractCreation is returned when sending a contract creation, which
+	// smart contract accounts don't support.
+	ErrContractCreation = errors.New("bundler: contract creation is not supported")
+
+	// ErrUnknownTransaction is returned for a transaction which wasn't sent as a
+	// user operation by the transactor.
+	ErrUnknownTransaction = errors.New("bundler: unknown transaction")
+)
+
+// Transactor sends the transactions of contract bindings as user operations of
+// a smart contract account, through a bundler. It implements
+// bind.ContractTransactor, serving the requests which are not about the account
+// from a node, and bind.DeployBackend so that bind.WaitMined waits for the user
+// operations.
+//
+// The transactions are signed by the signer of TransactOpts, which leaves them
+// unsigned, and are sent with the calls they contain encoded in the call data
+// of a user operation. Their nonce is the nonce of the account in the entry
+// point, their gas fees are the fees of the operation, and their gas limit is
+// the minimum call gas limit of the operation.
+type Transactor struct {
+	bundler    *Client
+	node       bind.ContractBackend
+	account    erc4337.Account
+	entryPoint common.Address
+	chainID    *big.Int
+
+	lock sync.Mutex
+	ops  map[common.Hash]common.Hash // Hashes of the operations of the transactions sent
+}
+
+// NewTransactor creates a transactor sending the operations of the account to
+// the entry point through the bundler.
+func NewTransactor(ctx context.Context, bundler *Client, node bind.ContractBackend, account erc4337.Account, entryPoint common.Address) (*Transactor, error) {
+	chainID, err := bundler.ChainID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &Transactor{
+		bundler:    bundler,
+		node:       node,
+		account:    account,
+		entryPoint: entryPoint,
+		chainID:    chainID,
+		ops:        make(map[common.Hash]common.Hash),
+	}, nil
+}
+
+// TransactOpts returns the options to transact with the contract bindings from
+// the smart contract account.
+func (t *Transactor) TransactOpts(ctx context.Context) *bind.TransactOpts {
+	return &bind.TransactOpts{
+		From: t.account.Address(),
+		Signer: func(address common.Address, tx *types.Transaction) (*types.Transaction, error) {
+			if address != t.account.Address() {
+				return nil, bind.ErrNotAuthorized
+			}
+			return tx, nil
+		},
+		Context: ctx,
+	}
+}
+
+// HeaderByNumber implements bind.ContractTransactor.
+func (t *Transactor) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	return t.node.HeaderByNumber(ctx, number)
+}
+
+// PendingCodeAt implements bind.ContractTransactor.
+func (t *Transactor) PendingCodeAt(ctx context.Context, account common.Address) ([]byte, error) {
+	return t.node.PendingCodeAt(ctx, account)
+}
+
+// PendingNonceAt implements bind.ContractTransactor, returning the nonce of the
+// next operation of the smart contract account.
+func (t *Transactor) PendingNonceAt(ctx context.Context, account common.Address) (uint64, error) {
+	if account != t.account.Address() {
+		return t.node.PendingNonceAt(ctx, account)
+	}
+	nonce, err := erc4337.GetNonce(ctx, t.node, t.entryPoint, account, nil)
+	if err != nil {
+		return 0, err
+	}
+	if !nonce.IsUint64() {
+		return 0, fmt.Errorf("bundler: nonce %v exceeds 64 bits", nonce)
+	}
+	return nonce.Uint64(), nil
+}
+
+// SuggestGasPrice implements bind.ContractTransactor.
+func (t *Transactor) SuggestGasPrice(ctx context.Context) (*big.Int, error) {
+	return t.node.SuggestGasPrice(ctx)
+}
+
+// SuggestGasTipCap implements bind.ContractTransactor.
+func (t *Transactor) SuggestGasTipCap(ctx context.Context) (*big.Int, error) {
+	return t.node.SuggestGasTipCap(ctx)
+}
+
+// EstimateGas implements bind.ContractTransactor, estimating the gas of the call
+// made by the account. The fees are left out of the call, as the operations are
+// paid from the deposit of the account rather than its balance.
+func (t *Transactor) EstimateGas(ctx context.Context, call ethereum.CallMsg) (uint64, error) {
+	if call.From == t.account.Address() {
+		call.GasPrice, call.GasFeeCap, call.GasTipCap = nil, nil, nil
+	}
+	return t.node.EstimateGas(ctx, call)
+}
+
+// SendTransaction implements bind.ContractTransactor, sending the call of the
+// transaction as a user operation. The account is deployed by the operation if
+// it has no code.
+func (t *Transactor) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	if tx.To() == nil {
+		return ErrContractCreation
+	}
+	callData, err := t.account.EncodeCallData(*tx.To(), tx.Value(), tx.Data())
+	if err != nil {
+		return err
+	}
+	op := &erc4337.UserOperation{
+		Sender:               t.account.Address(),
+		Nonce:                new(big.Int).SetUint64(tx.Nonce()),
+		CallData:             callData,
+		MaxFeePerGas:         tx.GasFeeCap(),
+		MaxPriorityFeePerGas: tx.GasTipCap(),
+		Signature:            t.account.DummySignature(),
+	}
+	code, err := t.node.CodeAt(ctx, op.Sender, nil)
+	if err != nil {
+		return err
+	}
+	if len(code) == 0 {
+		factory, data := t.account.Factory()
+		if factory == nil {
+			return fmt.Errorf("bundler: account %v is not deployed and has no factory", op.Sender)
+		}
+		op.Factory, op.FactoryData = factory, data
+	}
+	estimate, err := t.bundler.EstimateUserOperationGas(ctx, op, t.entryPoint)
+	if err != nil {
+		return err
+	}
+	op.PreVerificationGas = estimate.PreVerificationGas
+	op.VerificationGasLimit = estimate.VerificationGasLimit
+	op.CallGasLimit = max(estimate.CallGasLimit, tx.Gas())
+
+	if op.Signature, err = t.account.SignUserOperation(op, op.Hash(t.entryPoint, t.chainID)); err != nil {
+		return err
+	}
+	hash, err := t.bundler.SendUserOperation(ctx, op, t.entryPoint)
+	if err != nil {
+		return err
+	}
+	log.Debug("Sent user operation", "tx", tx.Hash(), "hash", hash, "sender", op.Sender, "nonce", op.Nonce)
+
+	t.lock.Lock()
+	t.ops[tx.Hash()] = hash
+	t.lock.Unlock()
+	return nil
+}
+
+// UserOperationHash returns the hash of the user operation sent for the
+// transaction.
+func (t *Transactor) UserOperationHash(tx *types.Transaction) (common.Hash, bool) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	hash, ok := t.ops[tx.Hash()]
+	return hash, ok
+}
+
+// CodeAt implements bind.DeployBackend.
+func (t *Transactor) CodeAt(ctx context.Context, account common.Address, blockNumber *big.Int) ([]byte, error) {
+	return t.node.CodeAt(ctx, account, blockNumber)
+}
+
+// TransactionReceipt implements bind.DeployBackend, returning the receipt of the
+// bundle which included the user operation of the transaction, with the status
+// and the logs of the operation.
+func (t *Transactor) TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error) {
+	t.lock.Lock()
+	hash, ok := t.ops[txHash]
+	t.lock.Unlock()
+	if !ok {
+		return nil, ErrUnknownTransaction
+	}
+	opReceipt, err := t.bundler.UserOperationReceipt(ctx, hash)
+	if err != nil {
+		return nil, err
+	}
+	receipt := *opReceipt.Receipt
+	receipt.Logs = opReceipt.Logs
+	receipt.Status = types.ReceiptStatusSuccessful
+	if !opReceipt.Success {
+		receipt.Status = types.ReceiptStatusFailed
+	}
+	return &receipt, nil
+}
@@ -19,6 +19,7 @@ package simulated
 import (
 	"math/big"
 
+	"github.com/ethereum/go-ethereum/core/tracing"
 	"github.com/ethereum/go-ethereum/eth/ethconfig"
 	"github.com/ethereum/go-ethereum/node"
 )
@@ -53,3 +54,12 @@ func WithMinerMinTip(tip *big.Int) func(nodeConf *node.Config, ethConf *ethconfi
 		ethConf.Miner.GasPrice = tip
 	}
 }
+
+// WithTracer attaches a live tracer to the simulated backend, which is invoked
+// for all transactions included in blocks. This allows collecting, for example,
+// the code coverage of a contract test suite.
+func WithTracer(hooks *tracing.Hooks) func(nodeConf *node.Config, ethConf *ethconfig.Config) {
+	return func(nodeConf *node.Config, ethConf *ethconfig.Config) {
+		ethConf.VMTracer = hooks
+	}
+}
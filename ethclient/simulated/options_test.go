@@ -23,7 +23,9 @@ import (
 	"testing"
 
 	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/tracing"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/params"
 )
@@ -72,3 +74,29 @@ func TestWithCallGasLimitOption(t *testing.T) {
 		t.Fatalf("error mismatch: have %v, want %v", err, core.ErrIntrinsicGas)
 	}
 }
+
+// Tests that the tracer set by the options is invoked for included transactions.
+func TestWithTracerOption(t *testing.T) {
+	var txs []common.Hash
+	hooks := &tracing.Hooks{
+		OnTxStart: func(env *tracing.VMContext, tx *types.Transaction, from common.Address) {
+			txs = append(txs, tx.Hash())
+		},
+	}
+	sim := NewBackend(types.GenesisAlloc{
+		testAddr: {Balance: big.NewInt(10000000000000000)},
+	}, WithTracer(hooks))
+	defer sim.Close()
+
+	tx, err := newTx(sim, testKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := sim.Client().SendTransaction(context.Background(), tx); err != nil {
+		t.Fatal(err)
+	}
+	sim.Commit()
+	if len(txs) != 1 || txs[0] != tx.Hash() {
+		t.Fatalf("wrong traced transactions: have %v, want [%v]", txs, tx.Hash())
+	}
+}
@@ -126,6 +126,12 @@ web3._extend({
 			call: 'admin_sleepBlocks',
 			params: 2
 		}),
+		new web3._extend.Method({
+			name: 'setSyncBudget',
+			call: 'admin_setSyncBudget',
+			params: 2,
+			inputFormatter: [null, null]
+		}),
 		new web3._extend.Method({
 			name: 'startHTTP',
 			call: 'admin_startHTTP',
@@ -164,6 +170,10 @@ web3._extend({
 			name: 'nodeInfo',
 			getter: 'admin_nodeInfo'
 		}),
+		new web3._extend.Property({
+			name: 'syncBudget',
+			getter: 'admin_syncBudget'
+		}),
 		new web3._extend.Property({
 			name: 'peers',
 			getter: 'admin_peers'
@@ -32,6 +32,7 @@ import (
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/p2p/enode"
 	"github.com/ethereum/go-ethereum/p2p/netutil"
+	"github.com/ethereum/go-ethereum/p2p/reputation"
 )
 
 const (
@@ -77,6 +78,7 @@ var (
 	errRecentlyDialed   = errors.New("recently dialed")
 	errNetRestrict      = errors.New("not contained in netrestrict list")
 	errNoPort           = errors.New("node does not provide TCP port")
+	errLowReputation    = errors.New("low reputation")
 )
 
 // dialer creates outbound connections and submits them into Server.
@@ -135,6 +137,7 @@ type dialConfig struct {
 	log            log.Logger
 	clock          mclock.Clock
 	rand           *mrand.Rand
+	reputation     *reputation.Tracker // peer scores, used to rank dial candidates if set
 }
 
 func (cfg dialConfig) withDefaults() dialConfig {
@@ -241,6 +244,8 @@ loop:
 		case node := <-nodesCh:
 			if err := d.checkDial(node); err != nil {
 				d.log.Trace("Discarding dial candidate", "id", node.ID(), "ip", node.IPAddr(), "reason", err)
+			} else if !d.reputation.Dialable(node.ID()) {
+				d.log.Trace("Discarding dial candidate", "id", node.ID(), "ip", node.IPAddr(), "reason", errLowReputation)
 			} else {
 				d.startDial(newDialTask(node, dynDialedConn))
 			}
@@ -397,7 +402,12 @@ func (d *dialScheduler) checkDial(n *enode.Node) error {
 // startStaticDials starts n static dial tasks.
 func (d *dialScheduler) startStaticDials(n int) (started int) {
 	for started = 0; started < n && len(d.staticPool) > 0; started++ {
-		idx := d.rand.Intn(len(d.staticPool))
+		var idx int
+		if d.reputation != nil {
+			idx = d.bestStatic()
+		} else {
+			idx = d.rand.Intn(len(d.staticPool))
+		}
 		task := d.staticPool[idx]
 		d.startDial(task)
 		d.removeFromStaticPool(idx)
@@ -405,6 +415,29 @@ func (d *dialScheduler) startStaticDials(n int) (started int) {
 	return started
 }
 
+// bestStatic returns the index of a static pool entry with the highest
+// reputation, picked at random if several of them share the best score.
+func (d *dialScheduler) bestStatic() int {
+	var (
+		best      = 0
+		bestScore = d.reputation.Score(d.staticPool[0].dest().ID())
+		ties      = 1
+	)
+	for i, task := range d.staticPool[1:] {
+		switch score := d.reputation.Score(task.dest().ID()); {
+		case score > bestScore:
+			best, bestScore, ties = i+1, score, 1
+		case score == bestScore:
+			// Keep each tied entry with equal probability.
+			ties++
+			if d.rand.Intn(ties) == 0 {
+				best = i + 1
+			}
+		}
+	}
+	return best
+}
+
 // updateStaticPool attempts to move the given static dial back into staticPool.
 func (d *dialScheduler) updateStaticPool(id enode.ID) {
 	task, ok := d.static[id]
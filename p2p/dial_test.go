@@ -32,6 +32,7 @@ import (
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/p2p/enode"
 	"github.com/ethereum/go-ethereum/p2p/netutil"
+	"github.com/ethereum/go-ethereum/p2p/reputation"
 )
 
 // This test checks that dynamic dials are launched from discovery results.
@@ -311,6 +312,37 @@ func TestDialSchedManyStaticNodes(t *testing.T) {
 	})
 }
 
+// This test checks that static dials prefer nodes with a better reputation,
+// picking at random between the ones that share the best score.
+func TestDialSchedBestStatic(t *testing.T) {
+	t.Parallel()
+
+	tracker := reputation.NewTracker(nil)
+	d := &dialScheduler{dialConfig: dialConfig{
+		rand:       rand.New(rand.NewSource(0x1111)),
+		reputation: tracker,
+	}}
+	for id := uint16(0); id < 4; id++ {
+		d.addToStaticPool(newDialTask(newNode(uintID(id), "127.0.0.1:30303"), staticDialedConn))
+	}
+	bad := uintID(0)
+	tracker.Connect(bad)
+	tracker.Report(bad, reputation.Timeout)
+
+	picked := make(map[int]int)
+	for i := 0; i < 300; i++ {
+		picked[d.bestStatic()]++
+	}
+	if picked[0] != 0 {
+		t.Fatalf("node with low reputation picked %d times", picked[0])
+	}
+	for idx := 1; idx < 4; idx++ {
+		if picked[idx] == 0 {
+			t.Fatalf("tied node %d never picked", idx)
+		}
+	}
+}
+
 // This test checks that past dials are not retried for some time.
 func TestDialSchedHistory(t *testing.T) {
 	t.Parallel()
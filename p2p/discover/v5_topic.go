@@ -0,0 +1,497 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package discover
+
+import (
+	"context"
+	"crypto/hmac"
+	crand "crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"net/netip"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/common/mclock"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/p2p/discover/v5wire"
+	"github.com/ethereum/go-ethereum/p2p/enode"
+	"github.com/ethereum/go-ethereum/p2p/enr"
+	"github.com/ethereum/go-ethereum/p2p/netutil"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+const (
+	topicAdLifetime       = 15 * time.Minute // how long a registrar keeps an advertisement
+	maxAdsPerTopic        = 100              // advertisement limit of a single topic queue
+	maxTopicAds           = 5000             // advertisement limit of the whole topic table
+	topicQueryResultLimit = 16               // applies in TOPICQUERY handler
+	ticketGracePeriod     = 10 * time.Second // how long a ticket can be used after its wait time
+
+	topicRegistrars        = 8                // number of registrars an advertisement is placed at
+	topicRegisterAttempts  = 5                // number of tickets to wait for at a single registrar
+	topicReregisterTimeout = 10 * time.Minute // time between advertisement rounds, less than the lifetime
+	topicSearchPause       = 10 * time.Second // time between topic search rounds
+)
+
+var (
+	errTicketInvalid = errors.New("invalid ticket")
+	errTicketEarly   = errors.New("ticket used before wait time")
+	errTicketExpired = errors.New("ticket expired")
+)
+
+// Topic is the identifier of a topic under which nodes can advertise themselves.
+type Topic [32]byte
+
+// NewTopic creates a topic identifier from an application-defined topic name.
+func NewTopic(name string) Topic {
+	return Topic(crypto.Keccak256Hash([]byte(name)))
+}
+
+// String returns the hex encoding of the topic identifier.
+func (t Topic) String() string {
+	return hexutil.Encode(t[:])
+}
+
+// topicAd is an advertisement stored by a registrar.
+type topicAd struct {
+	node    *enode.Node
+	expires mclock.AbsTime
+}
+
+// topicTable is the registrar-side storage of topic advertisements. Each topic has
+// a queue of advertisements ordered by expiry. When a queue (or the table as a whole)
+// is full, registrants are handed tickets telling them how long to wait until a slot
+// becomes available.
+//
+// The table is only accessed by the UDPv5 dispatch loop and needs no locking.
+type topicTable struct {
+	queues map[Topic][]*topicAd
+	count  int
+	key    [32]byte // secret for authenticating tickets
+}
+
+// ticket is the content of a ticket issued by a registrar. Tickets are opaque to the
+// registrant, the registrar authenticates them so it doesn't need to keep state about
+// pending registrations.
+type ticket struct {
+	Requester enode.ID
+	Topic     Topic
+	Issued    uint64 // mclock.AbsTime the ticket was issued at
+	WaitTime  uint64 // nanoseconds to wait before using the ticket
+}
+
+func newTopicTable() *topicTable {
+	tab := &topicTable{queues: make(map[Topic][]*topicAd)}
+	crand.Read(tab.key[:])
+	return tab
+}
+
+// expire drops all advertisements which have reached the end of their lifetime.
+func (tab *topicTable) expire(now mclock.AbsTime) {
+	for topic, queue := range tab.queues {
+		var n int
+		for n < len(queue) && queue[n].expires <= now {
+			n++
+		}
+		if n == 0 {
+			continue
+		}
+		tab.count -= n
+		if n == len(queue) {
+			delete(tab.queues, topic)
+		} else {
+			tab.queues[topic] = queue[n:]
+		}
+	}
+}
+
+// register places an advertisement of the node under the topic. If there is no
+// space available, it returns the time to wait until a slot frees up.
+func (tab *topicTable) register(n *enode.Node, topic Topic, now mclock.AbsTime) time.Duration {
+	tab.expire(now)
+
+	// Refresh the advertisement if the node is already registered.
+	queue := tab.queues[topic]
+	for i, ad := range queue {
+		if ad.node.ID() == n.ID() {
+			copy(queue[i:], queue[i+1:])
+			queue[len(queue)-1] = &topicAd{node: n, expires: now.Add(topicAdLifetime)}
+			return 0
+		}
+	}
+	// Place a new advertisement if there is space for it, otherwise compute the wait
+	// time from the advertisement expiring the soonest.
+	switch {
+	case len(queue) >= maxAdsPerTopic:
+		return time.Duration(queue[0].expires - now)
+	case tab.count >= maxTopicAds:
+		next := mclock.AbsTime(0)
+		for _, q := range tab.queues {
+			if next == 0 || q[0].expires < next {
+				next = q[0].expires
+			}
+		}
+		return time.Duration(next - now)
+	}
+	tab.queues[topic] = append(queue, &topicAd{node: n, expires: now.Add(topicAdLifetime)})
+	tab.count++
+	return 0
+}
+
+// nodes returns the most recently registered nodes advertised under the topic.
+func (tab *topicTable) nodes(topic Topic, now mclock.AbsTime, limit int) []*enode.Node {
+	tab.expire(now)
+
+	queue := tab.queues[topic]
+	nodes := make([]*enode.Node, 0, min(len(queue), limit))
+	for i := len(queue) - 1; i >= 0 && len(nodes) < limit; i-- {
+		nodes = append(nodes, queue[i].node)
+	}
+	return nodes
+}
+
+// issueTicket creates an authenticated ticket for a registration attempt.
+func (tab *topicTable) issueTicket(t *ticket) []byte {
+	enc, _ := rlp.EncodeToBytes(t)
+	mac := hmac.New(sha256.New, tab.key[:])
+	mac.Write(enc)
+	return mac.Sum(enc)
+}
+
+// checkTicket authenticates a ticket and verifies that it was issued to the given
+// node for the given topic, and that it is being used at the right time.
+func (tab *topicTable) checkTicket(blob []byte, id enode.ID, topic Topic, now mclock.AbsTime) error {
+	if len(blob) < sha256.Size {
+		return errTicketInvalid
+	}
+	enc, sum := blob[:len(blob)-sha256.Size], blob[len(blob)-sha256.Size:]
+	mac := hmac.New(sha256.New, tab.key[:])
+	mac.Write(enc)
+	if !hmac.Equal(mac.Sum(nil), sum) {
+		return errTicketInvalid
+	}
+	var t ticket
+	if err := rlp.DecodeBytes(enc, &t); err != nil {
+		return errTicketInvalid
+	}
+	if t.Requester != id || t.Topic != topic {
+		return errTicketInvalid
+	}
+	valid := mclock.AbsTime(t.Issued).Add(time.Duration(t.WaitTime))
+	switch {
+	case now < valid:
+		return errTicketEarly
+	case now > valid.Add(ticketGracePeriod):
+		return errTicketExpired
+	}
+	return nil
+}
+
+// handleRegtopic processes a topic registration attempt.
+func (t *UDPv5) handleRegtopic(p *v5wire.Regtopic, fromID enode.ID, fromAddr netip.AddrPort) {
+	node, err := t.verifyRegistrant(p.ENR, fromID, fromAddr)
+	if err != nil {
+		t.log.Debug("Invalid "+p.Name(), "id", fromID, "addr", fromAddr, "err", err)
+		return
+	}
+	var (
+		topic = Topic(p.Topic)
+		now   = t.clock.Now()
+		tab   = t.topics.table
+	)
+	// A ticket presented from an earlier attempt must be authentic and mature,
+	// otherwise the attempt is dropped without issuing a new ticket.
+	if len(p.Ticket) > 0 {
+		if err := tab.checkTicket(p.Ticket, fromID, topic, now); err != nil {
+			t.log.Debug("Rejected "+p.Name(), "id", fromID, "addr", fromAddr, "err", err)
+			return
+		}
+	}
+	resp := &v5wire.Ticket{ReqID: p.ReqID}
+	if wait := tab.register(node, topic, now); wait > 0 {
+		resp.Ticket = tab.issueTicket(&ticket{
+			Requester: fromID,
+			Topic:     topic,
+			Issued:    uint64(now),
+			WaitTime:  uint64(wait),
+		})
+		// Round the wait time up, the ticket must not be used before it.
+		resp.WaitTime = uint((wait + time.Second - 1) / time.Second)
+	}
+	t.sendResponse(fromID, fromAddr, resp)
+}
+
+// verifyRegistrant checks the record of a node requesting topic registration.
+func (t *UDPv5) verifyRegistrant(r *enr.Record, fromID enode.ID, fromAddr netip.AddrPort) (*enode.Node, error) {
+	if r == nil {
+		return nil, errors.New("missing record")
+	}
+	node, err := enode.New(t.validSchemes, r)
+	if err != nil {
+		return nil, err
+	}
+	if node.ID() != fromID {
+		return nil, errors.New("record does not match sender")
+	}
+	if node.IPAddr() != fromAddr.Addr() {
+		return nil, errors.New("record does not match sender endpoint")
+	}
+	if t.netrestrict != nil && !t.netrestrict.ContainsAddr(node.IPAddr()) {
+		return nil, errors.New("not contained in netrestrict list")
+	}
+	return node, nil
+}
+
+// handleTopicQuery returns the nodes advertised under a topic to the requester.
+func (t *UDPv5) handleTopicQuery(p *v5wire.TopicQuery, fromID enode.ID, fromAddr netip.AddrPort) {
+	var nodes []*enode.Node
+	for _, n := range t.topics.table.nodes(Topic(p.Topic), t.clock.Now(), topicQueryResultLimit) {
+		// Apply the same relay checks as for FINDNODE responses.
+		if netutil.CheckRelayAddr(fromAddr.Addr(), n.IPAddr()) == nil {
+			nodes = append(nodes, n)
+		}
+	}
+	for _, resp := range packNodes(p.ReqID, nodes) {
+		t.sendResponse(fromID, fromAddr, resp)
+	}
+}
+
+// regtopic calls REGTOPIC on a node and waits for a TICKET response.
+func (t *UDPv5) regtopic(n *enode.Node, topic Topic, ticket []byte) (*v5wire.Ticket, error) {
+	req := &v5wire.Regtopic{Topic: topic, ENR: t.localNode.Node().Record(), Ticket: ticket}
+	resp := t.callToNode(n, v5wire.TicketMsg, req)
+	defer t.callDone(resp)
+
+	select {
+	case respMsg := <-resp.ch:
+		return respMsg.(*v5wire.Ticket), nil
+	case err := <-resp.err:
+		return nil, err
+	}
+}
+
+// topicQuery calls TOPICQUERY on a node and waits for NODES responses.
+func (t *UDPv5) topicQuery(n *enode.Node, topic Topic) ([]*enode.Node, error) {
+	resp := t.callToNode(n, v5wire.NodesMsg, &v5wire.TopicQuery{Topic: topic})
+	return t.waitForNodes(resp, nil)
+}
+
+// topicSystem manages the topic advertisements of the local node, along with the
+// registrar-side topic table.
+type topicSystem struct {
+	transport *UDPv5
+	table     *topicTable
+
+	mutex sync.Mutex
+	ads   map[Topic]context.CancelFunc
+	wg    sync.WaitGroup
+}
+
+func newTopicSystem(transport *UDPv5) *topicSystem {
+	return &topicSystem{
+		transport: transport,
+		table:     newTopicTable(),
+		ads:       make(map[Topic]context.CancelFunc),
+	}
+}
+
+// advertise starts advertising the local node under the given topic.
+func (ts *topicSystem) advertise(topic Topic) {
+	ts.mutex.Lock()
+	defer ts.mutex.Unlock()
+
+	if _, ok := ts.ads[topic]; ok {
+		return
+	}
+	ctx, cancel := context.WithCancel(ts.transport.closeCtx)
+	ts.ads[topic] = cancel
+
+	ts.wg.Add(1)
+	go ts.advertiseLoop(ctx, topic)
+}
+
+// stopAdvertise stops advertising the local node under the given topic. Existing
+// advertisements are not revoked, they expire at the registrars eventually.
+func (ts *topicSystem) stopAdvertise(topic Topic) {
+	ts.mutex.Lock()
+	defer ts.mutex.Unlock()
+
+	if cancel, ok := ts.ads[topic]; ok {
+		cancel()
+		delete(ts.ads, topic)
+	}
+}
+
+// wait blocks until all advertisement loops have terminated.
+func (ts *topicSystem) wait() {
+	ts.wg.Wait()
+}
+
+// advertiseLoop periodically places advertisements of the local node at the nodes
+// closest to the topic identifier.
+func (ts *topicSystem) advertiseLoop(ctx context.Context, topic Topic) {
+	defer ts.wg.Done()
+
+	log := ts.transport.log.New("topic", topic)
+	for {
+		var (
+			registrars = ts.transport.newLookup(ctx, enode.ID(topic)).run()
+			registered = make(chan bool, topicRegistrars)
+		)
+		registrars = registrars[:min(len(registrars), topicRegistrars)]
+		for _, n := range registrars {
+			go func(n *enode.Node) {
+				registered <- ts.register(ctx, n, topic)
+			}(n)
+		}
+		var count int
+		for range registrars {
+			if <-registered {
+				count++
+			}
+		}
+		log.Debug("Placed topic advertisements", "registrars", len(registrars), "registered", count)
+
+		// Re-register in time before the advertisements expire. If no registrar
+		// was found at all, retry a bit earlier.
+		timeout := topicReregisterTimeout
+		if len(registrars) == 0 {
+			timeout = topicSearchPause
+		}
+		timer := ts.transport.clock.NewTimer(timeout)
+		select {
+		case <-timer.C():
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		}
+	}
+}
+
+// register places an advertisement of the local node at the given registrar,
+// waiting for tickets if the registrar is full.
+func (ts *topicSystem) register(ctx context.Context, n *enode.Node, topic Topic) bool {
+	var ticket []byte
+	for i := 0; i < topicRegisterAttempts; i++ {
+		resp, err := ts.transport.regtopic(n, topic, ticket)
+		if err != nil {
+			return false
+		}
+		if resp.WaitTime == 0 {
+			return true
+		}
+		wait := time.Duration(resp.WaitTime) * time.Second
+		if wait > topicAdLifetime {
+			return false // registrar is misbehaving, don't bother waiting
+		}
+		ticket = resp.Ticket
+
+		timer := ts.transport.clock.NewTimer(wait)
+		select {
+		case <-timer.C():
+		case <-ctx.Done():
+			timer.Stop()
+			return false
+		}
+	}
+	return false
+}
+
+// topicSearchIterator walks the DHT towards the topic identifier, querying every
+// node found for the advertisements it stores. When a walk finishes, a new one is
+// started after a pause.
+type topicSearchIterator struct {
+	transport *UDPv5
+	topic     Topic
+	ctx       context.Context
+	cancel    func()
+
+	lookup *lookup
+	buffer []*enode.Node
+	seen   map[enode.ID]struct{}
+}
+
+func newTopicSearchIterator(t *UDPv5, topic Topic) *topicSearchIterator {
+	ctx, cancel := context.WithCancel(t.closeCtx)
+	return &topicSearchIterator{
+		transport: t,
+		topic:     topic,
+		ctx:       ctx,
+		cancel:    cancel,
+		seen:      make(map[enode.ID]struct{}),
+	}
+}
+
+// Node returns the current node.
+func (it *topicSearchIterator) Node() *enode.Node {
+	if len(it.buffer) == 0 {
+		return nil
+	}
+	return it.buffer[0]
+}
+
+// Next moves to the next node.
+func (it *topicSearchIterator) Next() bool {
+	// Consume next node in buffer.
+	if len(it.buffer) > 0 {
+		it.buffer = it.buffer[1:]
+	}
+	// Advance the lookup and query the found nodes to refill the buffer.
+	for len(it.buffer) == 0 {
+		if it.ctx.Err() != nil {
+			it.lookup = nil
+			it.buffer = nil
+			return false
+		}
+		if it.lookup == nil {
+			it.lookup = it.transport.newLookup(it.ctx, enode.ID(it.topic))
+			continue
+		}
+		if !it.lookup.advance() {
+			// Walk finished, pause a bit before the next round. Nodes are
+			// reported again in the next round, since they may have changed.
+			it.lookup = nil
+			clear(it.seen)
+
+			timer := it.transport.clock.NewTimer(topicSearchPause)
+			select {
+			case <-timer.C():
+			case <-it.ctx.Done():
+				timer.Stop()
+			}
+			continue
+		}
+		for _, n := range it.lookup.replyBuffer {
+			nodes, _ := it.transport.topicQuery(n, it.topic)
+			for _, found := range nodes {
+				if _, ok := it.seen[found.ID()]; ok || found.ID() == it.transport.Self().ID() {
+					continue
+				}
+				it.seen[found.ID()] = struct{}{}
+				it.buffer = append(it.buffer, found)
+			}
+		}
+	}
+	return true
+}
+
+// Close ends the iterator.
+func (it *topicSearchIterator) Close() {
+	it.cancel()
+}
@@ -0,0 +1,203 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package discover
+
+import (
+	"bytes"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/mclock"
+	"github.com/ethereum/go-ethereum/p2p/discover/v5wire"
+	"github.com/ethereum/go-ethereum/p2p/enode"
+)
+
+func TestTopicTable(t *testing.T) {
+	var (
+		tab   = newTopicTable()
+		topic = NewTopic("test")
+		now   = mclock.AbsTime(0)
+		nodes = nodesAtDistance(enode.ID{}, 256, maxAdsPerTopic+1)
+	)
+	for i, n := range nodes[:maxAdsPerTopic] {
+		if wait := tab.register(n, topic, now); wait != 0 {
+			t.Fatalf("registration %d rejected, wait %v", i, wait)
+		}
+		now += mclock.AbsTime(time.Second)
+	}
+	// The queue is full, the next registration needs to wait for the first ad to expire.
+	wait := tab.register(nodes[maxAdsPerTopic], topic, now)
+	if want := topicAdLifetime - maxAdsPerTopic*time.Second; wait != want {
+		t.Fatalf("wrong wait time: have %v, want %v", wait, want)
+	}
+	// Refreshing an existing registration works even if the queue is full.
+	if wait := tab.register(nodes[0], topic, now); wait != 0 {
+		t.Fatalf("refresh rejected, wait %v", wait)
+	}
+	if result := tab.nodes(topic, now, 1); len(result) != 1 || result[0].ID() != nodes[0].ID() {
+		t.Fatalf("refreshed node not returned first: %v", result)
+	}
+	// After the next ad expires, a slot becomes available. Note the first ad
+	// was refreshed, so it's the second one expiring a second later.
+	now = now.Add(wait + time.Second)
+	if wait := tab.register(nodes[maxAdsPerTopic], topic, now); wait != 0 {
+		t.Fatalf("registration rejected after expiry, wait %v", wait)
+	}
+	if tab.count != maxAdsPerTopic {
+		t.Fatalf("wrong ad count %d, want %d", tab.count, maxAdsPerTopic)
+	}
+	if result := tab.nodes(NewTopic("other"), now, topicQueryResultLimit); len(result) != 0 {
+		t.Fatalf("nodes returned for unknown topic: %v", result)
+	}
+}
+
+func TestTopicTicket(t *testing.T) {
+	var (
+		tab   = newTopicTable()
+		topic = NewTopic("test")
+		id    = enode.ID{1}
+		blob  = tab.issueTicket(&ticket{Requester: id, Topic: topic, Issued: 0, WaitTime: uint64(time.Minute)})
+	)
+	tests := []struct {
+		id    enode.ID
+		topic Topic
+		blob  []byte
+		now   time.Duration
+		err   error
+	}{
+		{id: id, topic: topic, blob: blob, now: time.Minute, err: nil},
+		{id: id, topic: topic, blob: blob, now: time.Minute + ticketGracePeriod, err: nil},
+		{id: id, topic: topic, blob: blob, now: time.Second, err: errTicketEarly},
+		{id: id, topic: topic, blob: blob, now: 2 * time.Minute, err: errTicketExpired},
+		{id: enode.ID{2}, topic: topic, blob: blob, now: time.Minute, err: errTicketInvalid},
+		{id: id, topic: NewTopic("other"), blob: blob, now: time.Minute, err: errTicketInvalid},
+		{id: id, topic: topic, blob: append(bytes.Clone(blob[:len(blob)-1]), 0), now: time.Minute, err: errTicketInvalid},
+		{id: id, topic: topic, blob: []byte{1, 2, 3}, now: time.Minute, err: errTicketInvalid},
+	}
+	for i, test := range tests {
+		if err := tab.checkTicket(test.blob, test.id, test.topic, mclock.AbsTime(test.now)); err != test.err {
+			t.Errorf("test %d: wrong error %v, want %v", i, err, test.err)
+		}
+	}
+}
+
+// This test checks that incoming REGTOPIC and TOPICQUERY requests are handled.
+func TestUDPv5_topicHandling(t *testing.T) {
+	t.Parallel()
+	test := newUDPV5Test(t)
+	defer test.close()
+
+	var (
+		topic  = NewTopic("test")
+		remote = test.getNode(test.remotekey, test.remoteaddr).Node()
+	)
+	// Registrations with a record not matching the sender are ignored.
+	otherkey, otheraddr := newkey(), netip.MustParseAddrPort("10.0.1.100:30303")
+	other := test.getNode(otherkey, otheraddr).Node()
+	test.packetIn(&v5wire.Regtopic{ReqID: []byte{0}, Topic: topic, ENR: other.Record()})
+
+	// Valid registration gets confirmed.
+	test.packetIn(&v5wire.Regtopic{ReqID: []byte{1}, Topic: topic, ENR: remote.Record()})
+	test.waitPacketOut(func(p *v5wire.Ticket, addr netip.AddrPort, _ v5wire.Nonce) {
+		if !bytes.Equal(p.ReqID, []byte{1}) {
+			t.Error("wrong request ID in response:", p.ReqID)
+		}
+		if p.WaitTime != 0 || len(p.Ticket) != 0 {
+			t.Errorf("registration not confirmed: wait %d, ticket %x", p.WaitTime, p.Ticket)
+		}
+	})
+	// Registered node is returned for the topic, but not for others.
+	test.packetInFrom(otherkey, otheraddr, &v5wire.TopicQuery{ReqID: []byte{2}, Topic: topic})
+	test.expectNodes([]byte{2}, 1, []*enode.Node{remote})
+
+	test.packetInFrom(otherkey, otheraddr, &v5wire.TopicQuery{ReqID: []byte{3}, Topic: NewTopic("other")})
+	test.expectNodes([]byte{3}, 1, nil)
+}
+
+// This test checks that outgoing REGTOPIC calls wait for tickets.
+func TestUDPv5_regtopicCall(t *testing.T) {
+	t.Parallel()
+	test := newUDPV5Test(t)
+	defer test.close()
+
+	var (
+		topic  = NewTopic("test")
+		remote = test.getNode(test.remotekey, test.remoteaddr).Node()
+		done   = make(chan *v5wire.Ticket, 1)
+	)
+	go func() {
+		resp, _ := test.udp.regtopic(remote, topic, []byte("ticket"))
+		done <- resp
+	}()
+	test.waitPacketOut(func(p *v5wire.Regtopic, addr netip.AddrPort, _ v5wire.Nonce) {
+		if Topic(p.Topic) != topic {
+			t.Errorf("wrong topic in request: %v", Topic(p.Topic))
+		}
+		if string(p.Ticket) != "ticket" {
+			t.Errorf("wrong ticket in request: %q", p.Ticket)
+		}
+		n, err := enode.New(enode.ValidSchemesForTesting, p.ENR)
+		if err != nil || n.ID() != test.udp.Self().ID() {
+			t.Errorf("wrong record in request: %v", err)
+		}
+		test.packetIn(&v5wire.Ticket{ReqID: p.ReqID, Ticket: []byte("next"), WaitTime: 5})
+	})
+	resp := <-done
+	if resp == nil || resp.WaitTime != 5 || string(resp.Ticket) != "next" {
+		t.Fatalf("wrong response: %+v", resp)
+	}
+}
+
+// Real sockets, real crypto: this test checks that topic advertisements can be
+// found by other nodes.
+func TestUDPv5_topicE2E(t *testing.T) {
+	t.Parallel()
+
+	const N = 5
+	var nodes []*UDPv5
+	for i := 0; i < N; i++ {
+		var cfg Config
+		if len(nodes) > 0 {
+			bn := nodes[0].Self()
+			cfg.Bootnodes = []*enode.Node{bn}
+		}
+		node := startLocalhostV5(t, cfg)
+		nodes = append(nodes, node)
+		defer node.Close()
+	}
+	topic := NewTopic("test")
+	nodes[1].AdvertiseTopic(topic)
+
+	it := nodes[N-1].TopicSearch(topic)
+	defer it.Close()
+
+	found := make(chan *enode.Node, 1)
+	go func() {
+		if it.Next() {
+			found <- it.Node()
+		}
+	}()
+	select {
+	case n := <-found:
+		if n.ID() != nodes[1].Self().ID() {
+			t.Fatalf("wrong node found: %v", n.ID())
+		}
+	case <-time.After(2 * topicSearchPause):
+		t.Fatal("advertised node not found")
+	}
+}
@@ -78,6 +78,9 @@ type UDPv5 struct {
 	// talkreq handler registry
 	talk *talkSystem
 
+	// topic advertisements and registrar state
+	topics *topicSystem
+
 	// channels into dispatch
 	packetInCh    chan ReadPacket
 	readNextCh    chan struct{}
@@ -176,6 +179,7 @@ func newUDPv5(conn UDPConn, ln *enode.LocalNode, cfg Config) (*UDPv5, error) {
 		cancelCloseCtx: cancelCloseCtx,
 	}
 	t.talk = newTalkSystem(t)
+	t.topics = newTopicSystem(t)
 	tab, err := newTable(t, t.db, cfg)
 	if err != nil {
 		return nil, err
@@ -195,6 +199,7 @@ func (t *UDPv5) Close() {
 		t.cancelCloseCtx()
 		t.conn.Close()
 		t.talk.wait()
+		t.topics.wait()
 		t.wg.Wait()
 		t.tab.close()
 	})
@@ -279,6 +284,24 @@ func (t *UDPv5) TalkRequestToID(id enode.ID, addr netip.AddrPort, protocol strin
 	}
 }
 
+// AdvertiseTopic starts advertising the local node under the given topic. The
+// advertisements are placed at the nodes closest to the topic identifier and
+// renewed periodically until StopAdvertiseTopic is called.
+func (t *UDPv5) AdvertiseTopic(topic Topic) {
+	t.topics.advertise(topic)
+}
+
+// StopAdvertiseTopic stops renewing the advertisements of the local node under
+// the given topic.
+func (t *UDPv5) StopAdvertiseTopic(topic Topic) {
+	t.topics.stopAdvertise(topic)
+}
+
+// TopicSearch returns an iterator that finds nodes advertised under the given topic.
+func (t *UDPv5) TopicSearch(topic Topic) enode.Iterator {
+	return newTopicSearchIterator(t, topic)
+}
+
 // RandomNodes returns an iterator that finds random nodes in the DHT.
 func (t *UDPv5) RandomNodes() enode.Iterator {
 	if t.tab.len() == 0 {
@@ -769,6 +792,12 @@ func (t *UDPv5) handle(p v5wire.Packet, fromID enode.ID, fromAddr netip.AddrPort
 		t.talk.handleRequest(fromID, fromAddr, p)
 	case *v5wire.TalkResponse:
 		t.handleCallResponse(fromID, fromAddr, p)
+	case *v5wire.Regtopic:
+		t.handleRegtopic(p, fromID, fromAddr)
+	case *v5wire.Ticket:
+		t.handleCallResponse(fromID, fromAddr, p)
+	case *v5wire.TopicQuery:
+		t.handleTopicQuery(p, fromID, fromAddr)
 	}
 }
 
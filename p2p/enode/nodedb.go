@@ -41,6 +41,7 @@ const (
 	dbVersionKey   = "version" // Version of the database to flush if changes
 	dbNodePrefix   = "n:"      // Identifier to prefix node entries with
 	dbLocalPrefix  = "local:"
+	dbScorePrefix  = "score:"
 	dbDiscoverRoot = "v4"
 	dbDiscv5Root   = "v5"
 
@@ -54,6 +55,11 @@ const (
 	// Local information is keyed by ID only, the full key is "local:<ID>:seq".
 	// Use localItemKey to create those keys.
 	dbLocalSeq = "seq"
+
+	// Peer reputation is keyed by ID only, the full key is "score:<ID>:value".
+	// Use scoreItemKey to create those keys.
+	dbScoreValue = "value"
+	dbScoreTime  = "time"
 )
 
 const (
@@ -194,6 +200,14 @@ func localItemKey(id ID, field string) []byte {
 	return key
 }
 
+// scoreItemKey returns the key of a peer reputation item.
+func scoreItemKey(id ID, field string) []byte {
+	key := append([]byte(dbScorePrefix), id[:]...)
+	key = append(key, ':')
+	key = append(key, field...)
+	return key
+}
+
 // fetchInt64 retrieves an integer associated with a particular key.
 func (db *DB) fetchInt64(key []byte) int64 {
 	blob, err := db.lvl.Get(key, nil)
@@ -427,6 +441,24 @@ func (db *DB) UpdateFindFailsV5(id ID, ip netip.Addr, fails int) error {
 	return db.storeInt64(v5Key(id, ip, dbNodeFindFails), int64(fails))
 }
 
+// PeerScore retrieves the persisted reputation score of a node along with the
+// time it was last updated. A zero time is returned for unknown nodes.
+func (db *DB) PeerScore(id ID) (int64, time.Time) {
+	updated := db.fetchInt64(scoreItemKey(id, dbScoreTime))
+	if updated == 0 {
+		return 0, time.Time{}
+	}
+	return db.fetchInt64(scoreItemKey(id, dbScoreValue)), time.Unix(updated, 0)
+}
+
+// UpdatePeerScore updates the persisted reputation score of a node.
+func (db *DB) UpdatePeerScore(id ID, score int64, updated time.Time) error {
+	if err := db.storeInt64(scoreItemKey(id, dbScoreValue), score); err != nil {
+		return err
+	}
+	return db.storeInt64(scoreItemKey(id, dbScoreTime), updated.Unix())
+}
+
 // localSeq retrieves the local record sequence counter, defaulting to the current
 // timestamp if no previous exists. This ensures that wiping all data associated
 // with a node (apart from its key) will not generate already used sequence nums.
@@ -0,0 +1,308 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package filedisc implements node discovery from a local nodes file.
+//
+// The file uses the nodes.json format of the devp2p tool: a JSON object keyed by
+// node ID, where every entry holds the node record in its "record" field, either
+// as an ENR or as an enode URL. The file is watched for changes and reloaded, so
+// it can be maintained by external orchestration while the node is running.
+package filedisc
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/mclock"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/p2p/enode"
+)
+
+// defaultPollInterval is the interval at which the nodes file is checked for
+// modifications if not configured otherwise.
+const defaultPollInterval = 5 * time.Second
+
+// PeerSet is the set of static peers a Source reconciles with the nodes file.
+// It is implemented by p2p.Server.
+type PeerSet interface {
+	AddPeer(node *enode.Node)
+	RemovePeer(node *enode.Node)
+}
+
+// Config holds the settings of a nodes file source.
+type Config struct {
+	// Peers, if set, is kept in sync with the content of the nodes file: nodes
+	// added to the file are added as static peers, nodes removed from the file
+	// are removed from the static peers.
+	Peers PeerSet
+
+	PollInterval time.Duration // interval of checking the file for changes
+	Logger       log.Logger    // logger of the source
+	Clock        mclock.Clock  // clock used for polling, defaults to the system clock
+}
+
+func (cfg Config) withDefaults() Config {
+	if cfg.PollInterval == 0 {
+		cfg.PollInterval = defaultPollInterval
+	}
+	if cfg.Logger == nil {
+		cfg.Logger = log.Root()
+	}
+	if cfg.Clock == nil {
+		cfg.Clock = mclock.System{}
+	}
+	return cfg
+}
+
+// nodeJSON is an entry of the nodes file. Fields other than the record, such as
+// the liveness statistics maintained by the devp2p crawler, are ignored.
+type nodeJSON struct {
+	N *enode.Node `json:"record"`
+}
+
+// Source provides the nodes listed in a nodes file, reloading them whenever the
+// file changes.
+type Source struct {
+	path string
+	cfg  Config
+	log  log.Logger
+
+	mu      sync.Mutex
+	nodes   map[enode.ID]*enode.Node
+	changed chan struct{} // closed and replaced whenever nodes change
+	content []byte        // raw content of the file, to skip reloading unchanged files
+	modTime time.Time
+	size    int64
+
+	closeCh   chan struct{}
+	closeOnce sync.Once
+	wg        sync.WaitGroup
+}
+
+// New creates a source for the given nodes file. The file is loaded right away
+// and an error is returned if it can't be read or parsed. Later failures to load
+// the file are logged and the previous set of nodes is kept.
+func New(path string, cfg Config) (*Source, error) {
+	cfg = cfg.withDefaults()
+	s := &Source{
+		path:    path,
+		cfg:     cfg,
+		log:     cfg.Logger.New("file", path),
+		nodes:   make(map[enode.ID]*enode.Node),
+		changed: make(chan struct{}),
+		closeCh: make(chan struct{}),
+	}
+	if _, err := s.reload(); err != nil {
+		return nil, err
+	}
+	s.wg.Add(1)
+	go s.loop()
+	return s, nil
+}
+
+// Close stops watching the nodes file and terminates all iterators.
+func (s *Source) Close() {
+	s.closeOnce.Do(func() {
+		close(s.closeCh)
+		s.wg.Wait()
+	})
+}
+
+// Nodes returns the nodes currently listed in the nodes file.
+func (s *Source) Nodes() []*enode.Node {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	nodes := make([]*enode.Node, 0, len(s.nodes))
+	for _, n := range s.nodes {
+		nodes = append(nodes, n)
+	}
+	slices.SortFunc(nodes, func(a, b *enode.Node) int {
+		return bytes.Compare(a.ID().Bytes(), b.ID().Bytes())
+	})
+	return nodes
+}
+
+// NewIterator creates an iterator over the nodes of the file. The iterator
+// returns every listed node once, then blocks until the file changes and returns
+// the nodes which were added or updated.
+func (s *Source) NewIterator() enode.Iterator {
+	return &iterator{
+		src:    s,
+		seen:   make(map[enode.ID]string),
+		closed: make(chan struct{}),
+	}
+}
+
+// loop polls the nodes file for modifications.
+func (s *Source) loop() {
+	defer s.wg.Done()
+
+	for {
+		timer := s.cfg.Clock.NewTimer(s.cfg.PollInterval)
+		select {
+		case <-timer.C():
+			if _, err := s.reload(); err != nil {
+				s.log.Warn("Failed to reload nodes file", "err", err)
+			}
+		case <-s.closeCh:
+			timer.Stop()
+			return
+		}
+	}
+}
+
+// reload loads the nodes file if it was modified since the last load, and
+// reconciles the peer set with the new content. It reports whether the set
+// of nodes changed.
+func (s *Source) reload() (bool, error) {
+	info, err := os.Stat(s.path)
+	if err != nil {
+		return false, err
+	}
+	if info.ModTime().Equal(s.modTime) && info.Size() == s.size {
+		return false, nil
+	}
+	content, err := os.ReadFile(s.path)
+	if err != nil {
+		return false, err
+	}
+	s.modTime, s.size = info.ModTime(), info.Size()
+	if bytes.Equal(content, s.content) {
+		return false, nil
+	}
+	nodes, err := parseNodes(content)
+	if err != nil {
+		return false, err
+	}
+	s.content = content
+
+	// Swap in the new node set and compute the differences.
+	s.mu.Lock()
+	var added, removed []*enode.Node
+	for id, n := range nodes {
+		if old, ok := s.nodes[id]; !ok || old.String() != n.String() {
+			added = append(added, n)
+		}
+	}
+	for id, n := range s.nodes {
+		// Updated records are removed too, static dials don't pick up changes.
+		if updated, ok := nodes[id]; !ok || updated.String() != n.String() {
+			removed = append(removed, n)
+		}
+	}
+	s.nodes = nodes
+	if len(added) > 0 || len(removed) > 0 {
+		close(s.changed)
+		s.changed = make(chan struct{})
+	}
+	s.mu.Unlock()
+
+	if len(added) == 0 && len(removed) == 0 {
+		return false, nil
+	}
+	s.log.Info("Loaded nodes file", "nodes", len(nodes), "added", len(added), "removed", len(removed))
+
+	// Reconcile the static peers.
+	if s.cfg.Peers != nil {
+		for _, n := range removed {
+			s.cfg.Peers.RemovePeer(n)
+		}
+		for _, n := range added {
+			s.cfg.Peers.AddPeer(n)
+		}
+	}
+	return true, nil
+}
+
+// parseNodes decodes the content of a nodes file.
+func parseNodes(content []byte) (map[enode.ID]*enode.Node, error) {
+	var set map[enode.ID]nodeJSON
+	if err := json.Unmarshal(content, &set); err != nil {
+		return nil, err
+	}
+	nodes := make(map[enode.ID]*enode.Node, len(set))
+	for id, entry := range set {
+		if entry.N == nil {
+			return nil, fmt.Errorf("missing record for node %v", id)
+		}
+		if entry.N.ID() != id {
+			return nil, fmt.Errorf("record of node %v has mismatching ID %v", id, entry.N.ID())
+		}
+		nodes[id] = entry.N
+	}
+	return nodes, nil
+}
+
+// iterator returns the nodes of a Source as they appear in the file.
+type iterator struct {
+	src    *Source
+	seen   map[enode.ID]string // node records already returned
+	buffer []*enode.Node
+	cur    *enode.Node
+
+	closed    chan struct{}
+	closeOnce sync.Once
+}
+
+// Node returns the current node.
+func (it *iterator) Node() *enode.Node {
+	return it.cur
+}
+
+// Next moves to the next node.
+func (it *iterator) Next() bool {
+	it.cur = nil
+	for len(it.buffer) == 0 {
+		it.src.mu.Lock()
+		for id := range it.seen {
+			if _, ok := it.src.nodes[id]; !ok {
+				delete(it.seen, id) // removed from the file, return again if re-added
+			}
+		}
+		for id, n := range it.src.nodes {
+			if it.seen[id] != n.String() {
+				it.seen[id] = n.String()
+				it.buffer = append(it.buffer, n)
+			}
+		}
+		changed := it.src.changed
+		it.src.mu.Unlock()
+
+		if len(it.buffer) > 0 {
+			break
+		}
+		select {
+		case <-changed:
+		case <-it.closed:
+			return false
+		case <-it.src.closeCh:
+			return false
+		}
+	}
+	it.cur, it.buffer = it.buffer[0], it.buffer[1:]
+	return true
+}
+
+// Close ends the iterator.
+func (it *iterator) Close() {
+	it.closeOnce.Do(func() { close(it.closed) })
+}
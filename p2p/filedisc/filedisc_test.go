@@ -0,0 +1,169 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package filedisc
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common/mclock"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/p2p/enode"
+)
+
+// testPeers records the static peer changes made by a Source.
+type testPeers struct {
+	peers map[enode.ID]*enode.Node
+}
+
+func (p *testPeers) AddPeer(n *enode.Node)    { p.peers[n.ID()] = n }
+func (p *testPeers) RemovePeer(n *enode.Node) { delete(p.peers, n.ID()) }
+
+func (p *testPeers) nodes() []*enode.Node {
+	var nodes []*enode.Node
+	for _, n := range p.peers {
+		nodes = append(nodes, n)
+	}
+	return nodes
+}
+
+func testNodes(t *testing.T, n int) []*enode.Node {
+	nodes := make([]*enode.Node, n)
+	for i := range nodes {
+		key, err := crypto.GenerateKey()
+		if err != nil {
+			t.Fatal(err)
+		}
+		nodes[i] = enode.NewV4(&key.PublicKey, net.IP{127, 0, 0, 1}, 30303+i, 30303+i)
+	}
+	return nodes
+}
+
+// writeNodes writes a nodes file in the devp2p format. Records are written
+// alternately as ENRs and enode URLs.
+func writeNodes(t *testing.T, path string, nodes []*enode.Node) {
+	set := make(map[string]map[string]any)
+	for i, n := range nodes {
+		record := n.String()
+		if i%2 == 1 {
+			record = n.URLv4()
+		}
+		set[n.ID().String()] = map[string]any{"seq": n.Seq(), "record": record, "score": 1}
+	}
+	blob, err := json.Marshal(set)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, blob, 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func checkNodes(t *testing.T, have []*enode.Node, want []*enode.Node) {
+	t.Helper()
+	ids := func(nodes []*enode.Node) []string {
+		var s []string
+		for _, n := range nodes {
+			s = append(s, n.ID().String())
+		}
+		slices.Sort(s)
+		return s
+	}
+	if fmt.Sprint(ids(have)) != fmt.Sprint(ids(want)) {
+		t.Fatalf("node set mismatch:\nhave %v\nwant %v", ids(have), ids(want))
+	}
+}
+
+func TestSourceReload(t *testing.T) {
+	var (
+		path  = filepath.Join(t.TempDir(), "nodes.json")
+		nodes = testNodes(t, 4)
+		peers = &testPeers{peers: make(map[enode.ID]*enode.Node)}
+	)
+	writeNodes(t, path, nodes[:2])
+
+	src, err := New(path, Config{Peers: peers, Clock: new(mclock.Simulated)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer src.Close()
+
+	checkNodes(t, src.Nodes(), nodes[:2])
+	checkNodes(t, peers.nodes(), nodes[:2])
+
+	it := src.NewIterator()
+	defer it.Close()
+	checkNodes(t, enode.ReadNodes(it, 2), nodes[:2])
+
+	// Replace a node and add a new one.
+	writeNodes(t, path, []*enode.Node{nodes[1], nodes[2], nodes[3]})
+	if changed, err := src.reload(); err != nil || !changed {
+		t.Fatalf("reload failed: changed %v, err %v", changed, err)
+	}
+	checkNodes(t, src.Nodes(), nodes[1:])
+	checkNodes(t, peers.nodes(), nodes[1:])
+
+	// Only the new nodes are returned by the iterator.
+	checkNodes(t, enode.ReadNodes(it, 2), nodes[2:])
+
+	// Invalid files are rejected and the previous state is kept.
+	if err := os.WriteFile(path, []byte("{invalid"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := src.reload(); err == nil {
+		t.Fatal("invalid nodes file accepted")
+	}
+	checkNodes(t, src.Nodes(), nodes[1:])
+}
+
+func TestSourceInvalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nodes.json")
+	if _, err := New(path, Config{}); err == nil {
+		t.Fatal("missing nodes file accepted")
+	}
+	nodes := testNodes(t, 2)
+	blob := fmt.Sprintf(`{"%s": {"record": "%s"}}`, nodes[0].ID(), nodes[1].String())
+	if err := os.WriteFile(path, []byte(blob), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := New(path, Config{}); err == nil {
+		t.Fatal("nodes file with mismatching ID accepted")
+	}
+}
+
+func TestIteratorClose(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nodes.json")
+	writeNodes(t, path, nil)
+
+	src, err := New(path, Config{Clock: new(mclock.Simulated)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	it := src.NewIterator()
+	done := make(chan bool)
+	go func() { done <- it.Next() }()
+
+	src.Close()
+	if <-done {
+		t.Fatal("Next returned true after close")
+	}
+}
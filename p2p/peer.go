@@ -114,6 +114,7 @@ type Peer struct {
 	closed   chan struct{}
 	pingRecv chan struct{}
 	disc     chan DiscReason
+	evicting bool // set by the server run loop when disconnecting for a better peer
 
 	// events receives message send / receive events if set
 	events   *event.Feed
@@ -0,0 +1,281 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package reputation implements peer scoring based on the quality of the service
+// remote peers provide across the various sub-protocols.
+//
+// Scores are accumulated from events reported by the protocol handlers (useful
+// deliveries, timeouts, invalid data and transaction relay quality), decay back
+// towards neutral over time and are persisted in the node database, so they are
+// retained across restarts and reconnects.
+package reputation
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/p2p/enode"
+)
+
+// Event is a quality signal about a remote peer reported by a protocol handler.
+type Event int
+
+const (
+	Delivery    Event = iota // Requested data delivered in time and accepted
+	Timeout                  // Request was not served within the allotted time
+	InvalidData              // Delivered data failed validation
+	TxAccepted               // Relayed transaction was accepted into the pool
+	TxRejected               // Relayed transaction was underpriced or invalid
+)
+
+// eventWeights is the score change caused by a single event of each type.
+var eventWeights = [...]float64{
+	Delivery:    1,
+	Timeout:     -4,
+	InvalidData: -40,
+	TxAccepted:  0.1,
+	TxRejected:  -0.5,
+}
+
+const (
+	// MaxScore and MinScore are the bounds of a peer's score. Unknown peers start
+	// with a neutral score of zero.
+	MaxScore = 100
+	MinScore = -100
+
+	// DialThreshold is the score below which discovered nodes are not dialed.
+	DialThreshold = -50
+
+	// EvictionMargin is the score difference by which a new peer needs to beat
+	// the worst connected peer to replace it when the peer limit is reached.
+	EvictionMargin = 10
+
+	// scoreHalfLife is the time it takes a peer's score to decay halfway back
+	// to neutral, allowing misbehaving peers to eventually be given a new chance
+	// and preventing well behaved ones to build up unlimited credit.
+	scoreHalfLife = 6 * time.Hour
+)
+
+// score is the reputation of a single peer at a given point in time.
+type score struct {
+	value   float64   // Score at the time of the last update
+	updated time.Time // Time of the last update, used for decay
+}
+
+// decayed returns the score value decayed to the given time.
+func (s *score) decayed(now time.Time) float64 {
+	elapsed := now.Sub(s.updated)
+	if elapsed <= 0 {
+		return s.value
+	}
+	return s.value * math.Exp2(-float64(elapsed)/float64(scoreHalfLife))
+}
+
+// Tracker maintains the reputation of remote peers. A nil tracker is valid and
+// reports a neutral score for every peer.
+type Tracker struct {
+	db     *enode.DB           // Node database to persist scores in (nil = memory only)
+	scores map[enode.ID]*score // Cached scores of connected peers
+	now    func() time.Time    // Time source, overridable for testing
+	lock   sync.Mutex
+}
+
+// NewTracker creates a peer reputation tracker, persisting the scores into the
+// given node database. The database may be nil, in which case the scores are
+// only kept in memory.
+func NewTracker(db *enode.DB) *Tracker {
+	return &Tracker{
+		db:     db,
+		scores: make(map[enode.ID]*score),
+		now:    time.Now,
+	}
+}
+
+// SetDB sets the node database used to persist scores. Any scores cached in
+// memory are flushed into the previous database first.
+func (t *Tracker) SetDB(db *enode.DB) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	t.flush()
+	t.db = db
+}
+
+// Connect loads the score of a newly connected peer into the memory cache, so
+// events can be reported for it until it is saved on disconnect.
+func (t *Tracker) Connect(id enode.ID) {
+	if t == nil {
+		return
+	}
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if _, ok := t.scores[id]; !ok {
+		t.scores[id] = t.read(id)
+	}
+}
+
+// Report records a quality event for the given peer. Events for peers which are
+// not connected (e.g. late deliveries or timeouts after a disconnect) are
+// ignored, otherwise they would be cached without ever being dropped.
+func (t *Tracker) Report(id enode.ID, event Event) {
+	if t == nil {
+		return
+	}
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	s, ok := t.scores[id]
+	if !ok {
+		return
+	}
+	now := t.now()
+	s.value = min(max(s.decayed(now)+eventWeights[event], MinScore), MaxScore)
+	s.updated = now
+}
+
+// Score returns the current reputation of the given peer.
+func (t *Tracker) Score(id enode.ID) float64 {
+	if t == nil {
+		return 0
+	}
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if s, ok := t.scores[id]; ok {
+		return s.decayed(t.now())
+	}
+	// Avoid caching peers which were only looked up, otherwise the cache would
+	// grow with every node seen by the discovery
+	return t.read(id).decayed(t.now())
+}
+
+// ReportPeer is a convenience wrapper around Report for protocol handlers which
+// identify peers by the hex encoding of their node ID. Events for identifiers
+// which are not node IDs are ignored.
+func (t *Tracker) ReportPeer(peer string, event Event) {
+	if t == nil {
+		return
+	}
+	if id, err := enode.ParseID(peer); err == nil {
+		t.Report(id, event)
+	}
+}
+
+// ScorePeer is a convenience wrapper around Score for protocol handlers which
+// identify peers by the hex encoding of their node ID.
+func (t *Tracker) ScorePeer(peer string) float64 {
+	if t == nil {
+		return 0
+	}
+	id, err := enode.ParseID(peer)
+	if err != nil {
+		return 0
+	}
+	return t.Score(id)
+}
+
+// Weight returns a multiplier in the range (0, 1] by which the estimated serving
+// capacity of a peer should be scaled when routing requests. Peers with neutral
+// or positive reputation get full weight, misbehaving peers get less work.
+func (t *Tracker) Weight(peer string) float64 {
+	return 1 + min(t.ScorePeer(peer), 0)/(2*-MinScore)
+}
+
+// Dialable reports whether a discovered node has good enough reputation to be
+// worth dialing.
+func (t *Tracker) Dialable(id enode.ID) bool {
+	return t.Score(id) >= DialThreshold
+}
+
+// Evict decides whether a new peer should replace one of the given connected
+// peers, returning the one to be evicted if so.
+func (t *Tracker) Evict(candidate enode.ID, peers []enode.ID) (enode.ID, bool) {
+	if t == nil || len(peers) == 0 {
+		return enode.ID{}, false
+	}
+	var (
+		worst      enode.ID
+		worstScore = math.Inf(1)
+	)
+	for _, id := range peers {
+		if s := t.Score(id); s < worstScore {
+			worst, worstScore = id, s
+		}
+	}
+	if t.Score(candidate) < worstScore+EvictionMargin {
+		return enode.ID{}, false
+	}
+	return worst, true
+}
+
+// Save persists the score of the given peer into the node database and drops it
+// from the memory cache. It is meant to be called when a peer disconnects.
+func (t *Tracker) Save(id enode.ID) {
+	if t == nil {
+		return
+	}
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if s, ok := t.scores[id]; ok {
+		t.store(id, s)
+		delete(t.scores, id)
+	}
+}
+
+// Flush persists all the cached scores into the node database.
+func (t *Tracker) Flush() {
+	if t == nil {
+		return
+	}
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	t.flush()
+}
+
+// flush persists all the cached scores into the node database. The lock must be
+// held by the caller.
+func (t *Tracker) flush() {
+	for id, s := range t.scores {
+		t.store(id, s)
+	}
+}
+
+// read retrieves the score of a peer from the database, or a neutral one if it
+// is unknown. The lock must be held by the caller.
+func (t *Tracker) read(id enode.ID) *score {
+	s := &score{updated: t.now()}
+	if t.db != nil {
+		if value, updated := t.db.PeerScore(id); !updated.IsZero() {
+			s.value, s.updated = float64(value)/scorePrecision, updated
+		}
+	}
+	return s
+}
+
+// store persists the score of a peer into the database. The lock must be held
+// by the caller.
+func (t *Tracker) store(id enode.ID, s *score) {
+	if t.db != nil {
+		t.db.UpdatePeerScore(id, int64(math.Round(s.value*scorePrecision)), s.updated)
+	}
+}
+
+// scorePrecision is the multiplier used to store fractional scores as integers.
+const scorePrecision = 1000
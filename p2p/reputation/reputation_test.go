@@ -0,0 +1,125 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package reputation
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/p2p/enode"
+)
+
+// newTestTracker creates a tracker with a manually advanced clock.
+func newTestTracker(db *enode.DB) (*Tracker, *time.Time) {
+	now := time.Unix(1700000000, 0)
+	t := NewTracker(db)
+	t.now = func() time.Time { return now }
+	return t, &now
+}
+
+func TestScoreBoundsAndDecay(t *testing.T) {
+	tracker, now := newTestTracker(nil)
+	id := enode.ID{0x01}
+	tracker.Connect(id)
+
+	for i := 0; i < 200; i++ {
+		tracker.Report(id, Delivery)
+	}
+	if score := tracker.Score(id); score != MaxScore {
+		t.Fatalf("score not capped: have %v, want %v", score, MaxScore)
+	}
+	for i := 0; i < 10; i++ {
+		tracker.Report(id, InvalidData)
+	}
+	if score := tracker.Score(id); score != MinScore {
+		t.Fatalf("score not floored: have %v, want %v", score, MinScore)
+	}
+	if tracker.Dialable(id) {
+		t.Fatal("misbehaving peer dialable")
+	}
+	if weight := tracker.Weight(id.String()); weight != 0.5 {
+		t.Fatalf("weight mismatch: have %v, want 0.5", weight)
+	}
+	*now = now.Add(scoreHalfLife)
+	if score := tracker.Score(id); math.Abs(score-MinScore/2) > 1e-9 {
+		t.Fatalf("score not decayed: have %v, want %v", score, MinScore/2)
+	}
+	if !tracker.Dialable(id) {
+		t.Fatal("decayed peer not dialable")
+	}
+}
+
+func TestScorePersistence(t *testing.T) {
+	db, err := enode.OpenDB("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	tracker, _ := newTestTracker(db)
+	id := enode.ID{0x01}
+	tracker.Connect(id)
+	tracker.Report(id, Timeout)
+	tracker.Report(id, TxAccepted)
+	tracker.Save(id)
+
+	if len(tracker.scores) != 0 {
+		t.Fatalf("saved peer still cached")
+	}
+	// Late reports for the disconnected peer are ignored
+	tracker.Report(id, InvalidData)
+	if len(tracker.scores) != 0 {
+		t.Fatalf("disconnected peer cached again")
+	}
+	// Ensure a fresh tracker sees the same score
+	fresh, _ := newTestTracker(db)
+	if have, want := fresh.Score(id), eventWeights[Timeout]+eventWeights[TxAccepted]; math.Abs(have-want) > 1.0/scorePrecision {
+		t.Fatalf("persisted score mismatch: have %v, want %v", have, want)
+	}
+	if len(fresh.scores) != 0 {
+		t.Fatalf("looked up peer cached")
+	}
+}
+
+func TestEvict(t *testing.T) {
+	tracker, _ := newTestTracker(nil)
+	var (
+		good  = enode.ID{0x01}
+		bad   = enode.ID{0x02}
+		fresh = enode.ID{0x03}
+	)
+	tracker.Connect(good)
+	tracker.Connect(bad)
+	for i := 0; i < 20; i++ {
+		tracker.Report(good, Delivery)
+	}
+	tracker.Report(bad, Timeout)
+
+	// A neutral newcomer is not better enough than a slightly bad peer
+	if _, ok := tracker.Evict(fresh, []enode.ID{good, bad}); ok {
+		t.Fatal("peer evicted for marginally better candidate")
+	}
+	tracker.Report(bad, InvalidData)
+	if id, ok := tracker.Evict(fresh, []enode.ID{good, bad}); !ok || id != bad {
+		t.Fatalf("wrong eviction: have %v/%v, want %v", id, ok, bad)
+	}
+	// Nil trackers never evict
+	if _, ok := (*Tracker)(nil).Evict(fresh, []enode.ID{bad}); ok {
+		t.Fatal("nil tracker evicted peer")
+	}
+}
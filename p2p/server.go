@@ -41,6 +41,7 @@ import (
 	"github.com/ethereum/go-ethereum/p2p/enr"
 	"github.com/ethereum/go-ethereum/p2p/nat"
 	"github.com/ethereum/go-ethereum/p2p/netutil"
+	"github.com/ethereum/go-ethereum/p2p/reputation"
 )
 
 const (
@@ -70,6 +71,7 @@ var (
 	errServerStopped       = errors.New("server stopped")
 	errEncHandshakeError   = errors.New("rlpx enc error")
 	errProtoHandshakeError = errors.New("rlpx proto error")
+	errNoDiscoveryV5       = errors.New("discovery v5 not running")
 )
 
 // Config holds Server options.
@@ -197,6 +199,9 @@ type Server struct {
 	discmix   *enode.FairMix
 	dialsched *dialScheduler
 
+	reputation     *reputation.Tracker
+	reputationOnce sync.Once
+
 	// This is read by the NAT port mapping loop.
 	portMappingRegister chan *portMapping
 
@@ -311,6 +316,16 @@ func (srv *Server) LocalNode() *enode.LocalNode {
 	return srv.localnode
 }
 
+// Reputation returns the tracker of remote peer scores. Protocol handlers may
+// report events to it before the server is started; the scores are persisted
+// into the node database once it's opened.
+func (srv *Server) Reputation() *reputation.Tracker {
+	srv.reputationOnce.Do(func() {
+		srv.reputation = reputation.NewTracker(nil)
+	})
+	return srv.reputation
+}
+
 // Peers returns all connected peers.
 func (srv *Server) Peers() []*Peer {
 	var ps []*Peer
@@ -412,6 +427,35 @@ func (srv *Server) DiscoveryV5() *discover.UDPv5 {
 	return srv.discv5
 }
 
+// AdvertiseTopic starts advertising the local node under the given topic name
+// through discovery v5, allowing other nodes to find it with TopicNodes.
+func (srv *Server) AdvertiseTopic(topic string) error {
+	if srv.discv5 == nil {
+		return errNoDiscoveryV5
+	}
+	srv.discv5.AdvertiseTopic(discover.NewTopic(topic))
+	return nil
+}
+
+// StopAdvertiseTopic stops advertising the local node under the given topic name.
+func (srv *Server) StopAdvertiseTopic(topic string) error {
+	if srv.discv5 == nil {
+		return errNoDiscoveryV5
+	}
+	srv.discv5.StopAdvertiseTopic(discover.NewTopic(topic))
+	return nil
+}
+
+// TopicNodes returns an iterator over the nodes advertised under the given topic
+// name in discovery v5. The iterator can be used as a dial candidate source of
+// protocols which want to connect to the nodes of a topic only.
+func (srv *Server) TopicNodes(topic string) (enode.Iterator, error) {
+	if srv.discv5 == nil {
+		return nil, errNoDiscoveryV5
+	}
+	return srv.discv5.TopicSearch(discover.NewTopic(topic)), nil
+}
+
 // Stop terminates the server and all active peer connections.
 // It blocks until all active connections have been closed.
 func (srv *Server) Stop() {
@@ -530,6 +574,7 @@ func (srv *Server) setupLocalNode() error {
 		return err
 	}
 	srv.nodedb = db
+	srv.Reputation().SetDB(db)
 	srv.localnode = enode.NewLocalNode(db, srv.PrivateKey)
 	srv.localnode.SetFallbackIP(net.IP{127, 0, 0, 1})
 	// TODO: check conflicts
@@ -613,6 +658,7 @@ func (srv *Server) setupDialScheduler() {
 		netRestrict:    srv.NetRestrict,
 		dialer:         srv.Dialer,
 		clock:          srv.clock,
+		reputation:     srv.Reputation(),
 	}
 	if srv.discv4 != nil {
 		config.resolver = srv.discv4
@@ -716,6 +762,7 @@ func (srv *Server) run() {
 	srv.log.Info("Started P2P networking", "self", srv.localnode.Node().URLv4())
 	defer srv.loopWG.Done()
 	defer srv.nodedb.Close()
+	defer srv.reputation.Flush()
 	defer srv.discmix.Close()
 	defer srv.dialsched.stop()
 
@@ -776,6 +823,7 @@ running:
 			err := srv.addPeerChecks(peers, inboundCount, c)
 			if err == nil {
 				// The handshakes are done and it passed all checks.
+				srv.reputation.Connect(c.node.ID())
 				p := srv.launchPeer(c)
 				peers[c.node.ID()] = p
 				srv.log.Debug("Adding p2p peer", "peercount", len(peers), "id", p.ID(), "conn", c.flags, "addr", p.RemoteAddr(), "name", p.Name())
@@ -796,6 +844,7 @@ running:
 			// A peer disconnected.
 			d := common.PrettyDuration(mclock.Now() - pd.created)
 			delete(peers, pd.ID())
+			srv.reputation.Save(pd.ID())
 			srv.log.Debug("Removing p2p peer", "peercount", len(peers), "id", pd.ID(), "duration", d, "req", pd.requested, "err", pd.err)
 			srv.dialsched.peerRemoved(pd.rw)
 			if pd.Inbound() {
@@ -828,12 +877,13 @@ running:
 		p := <-srv.delpeer
 		p.log.Trace("<-delpeer (spindown)")
 		delete(peers, p.ID())
+		srv.reputation.Save(p.ID())
 	}
 }
 
 func (srv *Server) postHandshakeChecks(peers map[enode.ID]*Peer, inboundCount int, c *conn) error {
 	switch {
-	case !c.is(trustedConn) && len(peers) >= srv.MaxPeers:
+	case !c.is(trustedConn) && activePeers(peers) >= srv.MaxPeers && !srv.evictPeer(peers, c):
 		return DiscTooManyPeers
 	case !c.is(trustedConn) && c.is(inboundConn) && inboundCount >= srv.maxInboundConns():
 		return DiscTooManyPeers
@@ -846,6 +896,39 @@ func (srv *Server) postHandshakeChecks(peers map[enode.ID]*Peer, inboundCount in
 	}
 }
 
+// activePeers returns the number of peers which are not being evicted.
+func activePeers(peers map[enode.ID]*Peer) int {
+	var n int
+	for _, p := range peers {
+		if !p.evicting {
+			n++
+		}
+	}
+	return n
+}
+
+// evictPeer tries to make room for a new connection when the peer limit is
+// reached, by disconnecting the connected peer with the worst reputation if
+// the new one is sufficiently better. Trusted and static peers are never
+// evicted.
+func (srv *Server) evictPeer(peers map[enode.ID]*Peer, c *conn) bool {
+	var candidates []enode.ID
+	for id, p := range peers {
+		if !p.evicting && !p.rw.is(trustedConn|staticDialedConn) {
+			candidates = append(candidates, id)
+		}
+	}
+	id, ok := srv.reputation.Evict(c.node.ID(), candidates)
+	if !ok {
+		return false
+	}
+	p := peers[id]
+	p.evicting = true
+	p.log.Debug("Evicting peer with low reputation", "score", srv.reputation.Score(id), "replacement", c.node.ID())
+	p.Disconnect(DiscTooManyPeers)
+	return true
+}
+
 func (srv *Server) addPeerChecks(peers map[enode.ID]*Peer, inboundCount int, c *conn) error {
 	// Drop connections with no matching protocols.
 	if len(srv.Protocols) > 0 && countMatchingProtocols(srv.Protocols, c.caps) == 0 {
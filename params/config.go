@@ -18,8 +18,10 @@ package params
 
 import (
 	"fmt"
+	"maps"
 	"math"
 	"math/big"
+	"slices"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/params/forks"
@@ -326,11 +328,36 @@ type ChainConfig struct {
 
 	DepositContractAddress common.Address `json:"depositContractAddress,omitempty"`
 
+	// Precompiles activates custom precompiled contracts on private networks.
+	Precompiles []PrecompileConfig `json:"precompiles,omitempty"`
+
+	// VMOverrides modifies the EVM on research networks to trial changes not
+	// scheduled in any fork. They are applied in order on top of the active fork.
+	VMOverrides []VMOverride `json:"vmOverrides,omitempty"`
+
 	// Various consensus engines
 	Ethash *EthashConfig `json:"ethash,omitempty"`
 	Clique *CliqueConfig `json:"clique,omitempty"`
 }
 
+// PrecompileConfig activates a custom precompiled contract at an address. The
+// implementation of the contract is registered under its name by the embedder of
+// the node, see vm.RegisterPrecompile.
+type PrecompileConfig struct {
+	Name    string         `json:"name"`
+	Address common.Address `json:"address"`
+	Time    *uint64        `json:"time"` // Activation time (nil = disabled, 0 = active from genesis)
+}
+
+// VMOverride enables extra EIPs and overrides the gas costs of opcodes, e.g.
+// "ADD", from its activation time onwards. The EIPs are those supported by
+// vm.EnableEIP. Only opcodes with a constant gas cost can be repriced.
+type VMOverride struct {
+	Time     *uint64           `json:"time"` // Activation time (nil = disabled, 0 = active from genesis)
+	EIPs     []int             `json:"eips,omitempty"`
+	GasCosts map[string]uint64 `json:"gasCosts,omitempty"`
+}
+
 // EthashConfig is the consensus engine configs for proof-of-work based sealing.
 type EthashConfig struct{}
 
@@ -422,6 +449,24 @@ func (c *ChainConfig) Description() string {
 	if c.VerkleTime != nil {
 		banner += fmt.Sprintf(" - Verkle:                      @%-10v\n", *c.VerkleTime)
 	}
+	if len(c.Precompiles) > 0 {
+		banner += "\n"
+		banner += "Custom precompiles (timestamp based):\n"
+		for _, p := range c.Precompiles {
+			if p.Time != nil {
+				banner += fmt.Sprintf(" - %-28s @%-10v (%v)\n", p.Name+":", *p.Time, p.Address)
+			}
+		}
+	}
+	if len(c.VMOverrides) > 0 {
+		banner += "\n"
+		banner += "VM overrides (timestamp based):\n"
+		for _, o := range c.VMOverrides {
+			if o.Time != nil {
+				banner += fmt.Sprintf(" - EIPs %v, gas costs %v @%v\n", o.EIPs, o.GasCosts, *o.Time)
+			}
+		}
+	}
 	return banner
 }
 
@@ -620,6 +665,18 @@ func (c *ChainConfig) CheckConfigForkOrder() error {
 			lastFork = cur
 		}
 	}
+	// VM overrides are applied in order, so they must also be activated in order.
+	for i := 1; i < len(c.VMOverrides); i++ {
+		last, cur := c.VMOverrides[i-1].Time, c.VMOverrides[i].Time
+		switch {
+		case last == nil && cur != nil:
+			return fmt.Errorf("unsupported VM override ordering: override %d not enabled, but override %d enabled at timestamp %v",
+				i-1, i, *cur)
+		case last != nil && cur != nil && *last > *cur:
+			return fmt.Errorf("unsupported VM override ordering: override %d enabled at timestamp %v, but override %d enabled at timestamp %v",
+				i-1, *last, i, *cur)
+		}
+	}
 	return nil
 }
 
@@ -691,6 +748,60 @@ func (c *ChainConfig) checkCompatible(newcfg *ChainConfig, headNumber *big.Int,
 	if isForkTimestampIncompatible(c.VerkleTime, newcfg.VerkleTime, headTimestamp) {
 		return newTimestampCompatError("Verkle fork timestamp", c.VerkleTime, newcfg.VerkleTime)
 	}
+	if err := checkPrecompilesCompatible(c.Precompiles, newcfg.Precompiles, headTimestamp); err != nil {
+		return err
+	}
+	if err := checkVMOverridesCompatible(c.VMOverrides, newcfg.VMOverrides, headTimestamp); err != nil {
+		return err
+	}
+	return nil
+}
+
+// checkVMOverridesCompatible checks whether the VM overrides active at the head
+// are the same in both configurations.
+func checkVMOverridesCompatible(stored, new []VMOverride, headTimestamp uint64) *ConfigCompatError {
+	for i := 0; i < max(len(stored), len(new)); i++ {
+		var storedOverride, newOverride VMOverride
+		if i < len(stored) {
+			storedOverride = stored[i]
+		}
+		if i < len(new) {
+			newOverride = new[i]
+		}
+		if isForkTimestampIncompatible(storedOverride.Time, newOverride.Time, headTimestamp) {
+			return newTimestampCompatError(fmt.Sprintf("VM override %d activation timestamp", i), storedOverride.Time, newOverride.Time)
+		}
+		changed := !slices.Equal(storedOverride.EIPs, newOverride.EIPs) || !maps.Equal(storedOverride.GasCosts, newOverride.GasCosts)
+		if changed && isTimestampForked(storedOverride.Time, headTimestamp) {
+			return newTimestampCompatError(fmt.Sprintf("VM override %d content", i), storedOverride.Time, newOverride.Time)
+		}
+	}
+	return nil
+}
+
+// checkPrecompilesCompatible checks whether the custom precompiles active at the
+// head are the same in both configurations.
+func checkPrecompilesCompatible(stored, new []PrecompileConfig, headTimestamp uint64) *ConfigCompatError {
+	find := func(list []PrecompileConfig, addr common.Address) (string, *uint64) {
+		for _, p := range list {
+			if p.Address == addr {
+				return p.Name, p.Time
+			}
+		}
+		return "", nil
+	}
+	for _, list := range [][]PrecompileConfig{stored, new} {
+		for _, p := range list {
+			storedName, storedTime := find(stored, p.Address)
+			newName, newTime := find(new, p.Address)
+			if isForkTimestampIncompatible(storedTime, newTime, headTimestamp) {
+				return newTimestampCompatError(fmt.Sprintf("precompile %v activation timestamp", p.Address), storedTime, newTime)
+			}
+			if storedName != newName && isTimestampForked(storedTime, headTimestamp) {
+				return newTimestampCompatError(fmt.Sprintf("precompile %v name", p.Address), storedTime, newTime)
+			}
+		}
+	}
 	return nil
 }
 
@@ -863,6 +974,12 @@ type Rules struct {
 	IsBerlin, IsLondon                                      bool
 	IsMerge, IsShanghai, IsCancun, IsPrague                 bool
 	IsVerkle                                                bool
+
+	// Precompiles holds the names of the active custom precompiles by address.
+	Precompiles map[common.Address]string
+
+	// VMOverrides holds the active VM overrides, in order of application.
+	VMOverrides []VMOverride
 }
 
 // Rules ensures c's ChainID is not nil.
@@ -874,6 +991,19 @@ func (c *ChainConfig) Rules(num *big.Int, isMerge bool, timestamp uint64) Rules
 	// disallow setting Merge out of order
 	isMerge = isMerge && c.IsLondon(num)
 	isVerkle := isMerge && c.IsVerkle(num, timestamp)
+	var overrides int
+	for overrides < len(c.VMOverrides) && isTimestampForked(c.VMOverrides[overrides].Time, timestamp) {
+		overrides++
+	}
+	var precompiles map[common.Address]string
+	for _, p := range c.Precompiles {
+		if isTimestampForked(p.Time, timestamp) {
+			if precompiles == nil {
+				precompiles = make(map[common.Address]string)
+			}
+			precompiles[p.Address] = p.Name
+		}
+	}
 	return Rules{
 		ChainID:          new(big.Int).Set(chainID),
 		IsHomestead:      c.IsHomestead(num),
@@ -893,5 +1023,7 @@ func (c *ChainConfig) Rules(num *big.Int, isMerge bool, timestamp uint64) Rules
 		IsPrague:         isMerge && c.IsPrague(num, timestamp),
 		IsVerkle:         isVerkle,
 		IsEIP4762:        isVerkle,
+		Precompiles:      precompiles,
+		VMOverrides:      c.VMOverrides[:overrides:overrides],
 	}
 }
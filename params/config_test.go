@@ -23,6 +23,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/stretchr/testify/require"
 )
 
@@ -110,6 +111,51 @@ func TestCheckCompatible(t *testing.T) {
 				RewindToTime: 9,
 			},
 		},
+		{
+			stored:        &ChainConfig{},
+			new:           &ChainConfig{Precompiles: []PrecompileConfig{{Name: "oracle", Address: common.Address{0xff}, Time: newUint64(20)}}},
+			headTimestamp: 10,
+			wantErr:       nil,
+		},
+		{
+			stored:        &ChainConfig{Precompiles: []PrecompileConfig{{Name: "oracle", Address: common.Address{0xff}, Time: newUint64(20)}}},
+			new:           &ChainConfig{},
+			headTimestamp: 25,
+			wantErr: &ConfigCompatError{
+				What:         "precompile 0xfF00000000000000000000000000000000000000 activation timestamp",
+				StoredTime:   newUint64(20),
+				NewTime:      nil,
+				RewindToTime: 19,
+			},
+		},
+		{
+			stored:        &ChainConfig{Precompiles: []PrecompileConfig{{Name: "oracle", Address: common.Address{0xff}, Time: newUint64(20)}}},
+			new:           &ChainConfig{Precompiles: []PrecompileConfig{{Name: "oracle2", Address: common.Address{0xff}, Time: newUint64(20)}}},
+			headTimestamp: 25,
+			wantErr: &ConfigCompatError{
+				What:         "precompile 0xfF00000000000000000000000000000000000000 name",
+				StoredTime:   newUint64(20),
+				NewTime:      newUint64(20),
+				RewindToTime: 19,
+			},
+		},
+		{
+			stored:        &ChainConfig{VMOverrides: []VMOverride{{Time: newUint64(20), EIPs: []int{3855}}}},
+			new:           &ChainConfig{VMOverrides: []VMOverride{{Time: newUint64(20), EIPs: []int{3855}}, {Time: newUint64(30)}}},
+			headTimestamp: 25,
+			wantErr:       nil,
+		},
+		{
+			stored:        &ChainConfig{VMOverrides: []VMOverride{{Time: newUint64(20), EIPs: []int{3855}}}},
+			new:           &ChainConfig{VMOverrides: []VMOverride{{Time: newUint64(20), GasCosts: map[string]uint64{"ADD": 5}}}},
+			headTimestamp: 25,
+			wantErr: &ConfigCompatError{
+				What:         "VM override 0 content",
+				StoredTime:   newUint64(20),
+				NewTime:      newUint64(20),
+				RewindToTime: 19,
+			},
+		},
 	}
 
 	for _, test := range tests {
@@ -137,6 +183,28 @@ func TestConfigRules(t *testing.T) {
 	if r := c.Rules(big.NewInt(0), true, stamp); !r.IsShanghai {
 		t.Errorf("expected %v to be shanghai", stamp)
 	}
+
+	c.Precompiles = []PrecompileConfig{
+		{Name: "oracle", Address: common.Address{0xff}, Time: newUint64(600)},
+		{Name: "disabled", Address: common.Address{0xfe}},
+	}
+	if r := c.Rules(big.NewInt(0), true, 599); len(r.Precompiles) != 0 {
+		t.Errorf("expected no precompiles, have %v", r.Precompiles)
+	}
+	if r := c.Rules(big.NewInt(0), true, 600); !reflect.DeepEqual(r.Precompiles, map[common.Address]string{{0xff}: "oracle"}) {
+		t.Errorf("wrong precompiles: %v", r.Precompiles)
+	}
+
+	c.VMOverrides = []VMOverride{{Time: newUint64(700), EIPs: []int{3855}}, {Time: newUint64(800)}, {}}
+	if r := c.Rules(big.NewInt(0), true, 699); len(r.VMOverrides) != 0 {
+		t.Errorf("expected no VM overrides, have %v", r.VMOverrides)
+	}
+	if r := c.Rules(big.NewInt(0), true, 750); len(r.VMOverrides) != 1 || r.VMOverrides[0].EIPs[0] != 3855 {
+		t.Errorf("wrong VM overrides: %v", r.VMOverrides)
+	}
+	if r := c.Rules(big.NewInt(0), true, 800); len(r.VMOverrides) != 2 {
+		t.Errorf("wrong VM overrides: %v", r.VMOverrides)
+	}
 }
 
 func TestTimestampCompatError(t *testing.T) {
@@ -155,3 +223,18 @@ func TestTimestampCompatError(t *testing.T) {
 	require.Equal(t, newTimestampCompatError(errWhat, newUint64(0), newUint64(1681338455)).Error(),
 		"mismatching Shanghai fork timestamp in database (have timestamp 0, want timestamp 1681338455, rewindto timestamp 0)")
 }
+
+func TestVMOverrideOrder(t *testing.T) {
+	c := &ChainConfig{VMOverrides: []VMOverride{{Time: newUint64(20)}, {Time: newUint64(10)}}}
+	if err := c.CheckConfigForkOrder(); err == nil {
+		t.Error("unordered VM overrides accepted")
+	}
+	c.VMOverrides = []VMOverride{{}, {Time: newUint64(10)}}
+	if err := c.CheckConfigForkOrder(); err == nil {
+		t.Error("VM override after disabled one accepted")
+	}
+	c.VMOverrides = []VMOverride{{Time: newUint64(10)}, {Time: newUint64(10)}, {}}
+	if err := c.CheckConfigForkOrder(); err != nil {
+		t.Errorf("valid VM overrides rejected: %v", err)
+	}
+}
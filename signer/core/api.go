@@ -33,6 +33,7 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/internal/ethapi"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/rpc"
@@ -44,9 +45,9 @@ const (
 	// numberOfAccountsToDerive For hardware wallets, the number of accounts to derive
 	numberOfAccountsToDerive = 10
 	// ExternalAPIVersion -- see extapi_changelog.md
-	ExternalAPIVersion = "6.1.0"
+	ExternalAPIVersion = "6.2.0"
 	// InternalAPIVersion -- see intapi_changelog.md
-	InternalAPIVersion = "7.0.1"
+	InternalAPIVersion = "7.2.0"
 )
 
 // ExternalAPI defines the external API through which signing requests are made.
@@ -67,6 +68,8 @@ type ExternalAPI interface {
 	Version(ctx context.Context) (string, error)
 	// SignGnosisSafeTx signs/confirms a gnosis-safe multisig transaction
 	SignGnosisSafeTx(ctx context.Context, signerAddress common.MixedcaseAddress, gnosisTx GnosisSafeTx, methodSelector *string) (*GnosisSafeTx, error)
+	// SignAuthorization signs an EIP-7702 code delegation authorization
+	SignAuthorization(ctx context.Context, addr common.MixedcaseAddress, auth types.SetCodeAuthorization) (*types.SetCodeAuthorization, error)
 }
 
 // UIClientAPI specifies what method a UI needs to implement to be able to be used as a
@@ -128,6 +131,7 @@ type Metadata struct {
 	Scheme    string `json:"scheme"`
 	UserAgent string `json:"User-Agent"`
 	Origin    string `json:"Origin"`
+	Client    string `json:"client,omitempty"` // Name of the client authenticated by its TLS certificate
 }
 
 func StartClefAccountManager(ksLocation string, nousb, lightKDF bool, scpath string) *accounts.Manager {
@@ -193,7 +197,7 @@ func StartClefAccountManager(ksLocation string, nousb, lightKDF bool, scpath str
 func MetadataFromContext(ctx context.Context) Metadata {
 	info := rpc.PeerInfoFromContext(ctx)
 
-	m := Metadata{"NA", "NA", "NA", "", ""} // batman
+	m := Metadata{"NA", "NA", "NA", "", "", ""} // batman
 
 	if info.Transport != "" {
 		if info.Transport == "http" {
@@ -209,6 +213,9 @@ func MetadataFromContext(ctx context.Context) Metadata {
 	}
 	m.Origin = info.HTTP.Origin
 	m.UserAgent = info.HTTP.UserAgent
+	if client := clientFromContext(ctx); client != nil {
+		m.Client = client.Name
+	}
 	return m
 }
 
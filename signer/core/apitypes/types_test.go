@@ -27,28 +27,6 @@ import (
 	"github.com/holiman/uint256"
 )
 
-func TestIsPrimitive(t *testing.T) {
-	t.Parallel()
-	// Expected positives
-	for i, tc := range []string{
-		"int24", "int24[]", "uint88", "uint88[]", "uint", "uint[]", "int256", "int256[]",
-		"uint96", "uint96[]", "int96", "int96[]", "bytes17[]", "bytes17",
-	} {
-		if !isPrimitiveTypeValid(tc) {
-			t.Errorf("test %d: expected '%v' to be a valid primitive", i, tc)
-		}
-	}
-	// Expected negatives
-	for i, tc := range []string{
-		"int257", "int257[]", "uint88 ", "uint88 []", "uint257", "uint-1[]",
-		"uint0", "uint0[]", "int95", "int95[]", "uint1", "uint1[]", "bytes33[]", "bytess",
-	} {
-		if isPrimitiveTypeValid(tc) {
-			t.Errorf("test %d: expected '%v' to not be a valid primitive", i, tc)
-		}
-	}
-}
-
 func TestTxArgs(t *testing.T) {
 	for i, tc := range []struct {
 		data     []byte
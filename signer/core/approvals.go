@@ -0,0 +1,359 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/internal/ethapi"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrUnknownPendingRequest is returned when approving or rejecting a
+	// request which is not pending, e.g. because it expired.
+	ErrUnknownPendingRequest = errors.New("unknown pending request")
+
+	// ErrNotApprover is returned if an approval or rejection is made by
+	// someone not configured as an approver.
+	ErrNotApprover = errors.New("not an approver")
+
+	// ErrAlreadyApproved is returned if an approver approves the same request
+	// twice.
+	ErrAlreadyApproved = errors.New("request already approved by approver")
+)
+
+// UIApprover is the approver name of all approvals and rejections made through
+// the UI API. The UI can't authenticate who is operating it, so it counts as a
+// single approver, which needs to be configured among the approvers.
+const UIApprover = "ui"
+
+// ApprovalConfig configures signing requests which need to be approved by a
+// threshold of approvers.
+type ApprovalConfig struct {
+	Threshold int              // Number of approvals required
+	Approvers []string         // Names of the approvers
+	Accounts  []common.Address // Accounts requiring approvals, all if empty
+	Expiry    time.Duration    // Time after which pending requests are rejected
+}
+
+// PendingRequest is a signing request waiting for approvals.
+type PendingRequest struct {
+	ID          string                    `json:"id"`
+	Account     common.Address            `json:"account"`
+	Transaction *apitypes.SendTxArgs      `json:"transaction,omitempty"`
+	ContentType string                    `json:"contentType,omitempty"`
+	Messages    []*apitypes.NameValueType `json:"messages,omitempty"`
+	Hash        hexutil.Bytes             `json:"hash,omitempty"`
+	Callinfo    []apitypes.ValidationInfo `json:"callInfo"`
+	Meta        Metadata                  `json:"meta"`
+	Created     time.Time                 `json:"created"`
+	Expires     time.Time                 `json:"expires"`
+	Approvals   []string                  `json:"approvals"`
+}
+
+// pendingRequest tracks the state of a request while it's pending.
+type pendingRequest struct {
+	info     PendingRequest
+	approved bool
+	done     chan struct{} // Closed once the request is decided
+}
+
+// ApprovalQueue is a UIClientAPI holding transaction and data signing requests
+// for the configured accounts pending until enough approvers approve them,
+// either over the UI API or the approver API. Approved requests are then passed
+// to the next UI like all other requests, so they are still subject to the rules
+// and can still be rejected there.
+type ApprovalQueue struct {
+	next   UIClientAPI
+	config ApprovalConfig
+	log    log.Logger
+
+	pending map[string]*pendingRequest
+	lock    sync.Mutex
+}
+
+// NewApprovalQueue creates a queue for requests requiring approvals.
+func NewApprovalQueue(next UIClientAPI, config ApprovalConfig) (*ApprovalQueue, error) {
+	if config.Threshold <= 0 {
+		return nil, errors.New("approval threshold must be positive")
+	}
+	if config.Threshold > len(config.Approvers) {
+		return nil, fmt.Errorf("approval threshold %d exceeds number of approvers %d", config.Threshold, len(config.Approvers))
+	}
+	for i, approver := range config.Approvers {
+		if approver == "" || slices.Contains(config.Approvers[:i], approver) {
+			return nil, fmt.Errorf("invalid or duplicate approver %q", approver)
+		}
+	}
+	if config.Expiry <= 0 {
+		return nil, errors.New("approval expiry must be positive")
+	}
+	return &ApprovalQueue{
+		next:    next,
+		config:  config,
+		log:     log.Root(),
+		pending: make(map[string]*pendingRequest),
+	}, nil
+}
+
+// SetAuditLogger makes the queue record pending requests, approvals and
+// decisions in the audit log.
+func (q *ApprovalQueue) SetAuditLogger(l *AuditLogger) {
+	q.log = l.log
+}
+
+// covers returns whether requests for the account require approvals.
+func (q *ApprovalQueue) covers(account common.Address) bool {
+	return len(q.config.Accounts) == 0 || slices.Contains(q.config.Accounts, account)
+}
+
+// hold queues the request and waits until it's approved, rejected or expires.
+func (q *ApprovalQueue) hold(info PendingRequest) bool {
+	info.ID = uuid.New().String()
+	info.Created = time.Now()
+	info.Expires = info.Created.Add(q.config.Expiry)
+	info.Approvals = []string{}
+	req := &pendingRequest{info: info, done: make(chan struct{})}
+
+	q.lock.Lock()
+	q.pending[info.ID] = req
+	q.lock.Unlock()
+
+	q.log.Info("Approval", "type", "pending", "id", info.ID, "account", info.Account,
+		"metadata", info.Meta.String(), "expires", info.Expires)
+	q.next.ShowInfo(fmt.Sprintf("Request %s for account %v requires %d of %d approvals, expires at %v",
+		info.ID, info.Account, q.config.Threshold, len(q.config.Approvers), info.Expires.Format(time.RFC3339)))
+
+	timer := time.NewTimer(q.config.Expiry)
+	defer timer.Stop()
+
+	select {
+	case <-req.done:
+	case <-timer.C:
+		q.lock.Lock()
+		if q.pending[info.ID] == req {
+			q.decide(req, false)
+			q.log.Info("Approval", "type", "expired", "id", info.ID, "approvals", req.info.Approvals)
+		}
+		q.lock.Unlock()
+	}
+	return req.approved
+}
+
+// decide resolves a pending request, the lock must be held.
+func (q *ApprovalQueue) decide(req *pendingRequest, approved bool) {
+	req.approved = approved
+	delete(q.pending, req.info.ID)
+	close(req.done)
+}
+
+// Pending returns the requests waiting for approvals, oldest first.
+func (q *ApprovalQueue) Pending() []PendingRequest {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	list := make([]PendingRequest, 0, len(q.pending))
+	for _, req := range q.pending {
+		info := req.info
+		info.Approvals = slices.Clone(info.Approvals)
+		list = append(list, info)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Created.Before(list[j].Created) })
+	return list
+}
+
+// Approve records the approval of a pending request by the approver, approving
+// the request once the threshold is reached.
+func (q *ApprovalQueue) Approve(id string, approver string, channel string) error {
+	if !slices.Contains(q.config.Approvers, approver) {
+		return fmt.Errorf("%w: %q", ErrNotApprover, approver)
+	}
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	req := q.pending[id]
+	if req == nil {
+		return ErrUnknownPendingRequest
+	}
+	if slices.Contains(req.info.Approvals, approver) {
+		return ErrAlreadyApproved
+	}
+	req.info.Approvals = append(req.info.Approvals, approver)
+	q.log.Info("Approval", "type", "approve", "id", id, "approver", approver, "channel", channel,
+		"approvals", len(req.info.Approvals), "threshold", q.config.Threshold)
+
+	if len(req.info.Approvals) >= q.config.Threshold {
+		q.decide(req, true)
+		q.log.Info("Approval", "type", "approved", "id", id, "approvals", req.info.Approvals)
+	}
+	return nil
+}
+
+// Reject rejects a pending request on behalf of the approver.
+func (q *ApprovalQueue) Reject(id string, approver string, channel string) error {
+	if !slices.Contains(q.config.Approvers, approver) {
+		return fmt.Errorf("%w: %q", ErrNotApprover, approver)
+	}
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	req := q.pending[id]
+	if req == nil {
+		return ErrUnknownPendingRequest
+	}
+	q.decide(req, false)
+	q.log.Info("Approval", "type", "rejected", "id", id, "approver", approver, "channel", channel,
+		"approvals", req.info.Approvals)
+	return nil
+}
+
+func (q *ApprovalQueue) ApproveTx(request *SignTxRequest) (SignTxResponse, error) {
+	if !q.covers(request.Transaction.From.Address()) {
+		return q.next.ApproveTx(request)
+	}
+	tx := request.Transaction
+	approved := q.hold(PendingRequest{
+		Account:     tx.From.Address(),
+		Transaction: &tx,
+		Callinfo:    request.Callinfo,
+		Meta:        request.Meta,
+	})
+	if !approved {
+		return SignTxResponse{Approved: false}, nil
+	}
+	return q.next.ApproveTx(request)
+}
+
+func (q *ApprovalQueue) ApproveSignData(request *SignDataRequest) (SignDataResponse, error) {
+	if !q.covers(request.Address.Address()) {
+		return q.next.ApproveSignData(request)
+	}
+	approved := q.hold(PendingRequest{
+		Account:     request.Address.Address(),
+		ContentType: request.ContentType,
+		Messages:    request.Messages,
+		Hash:        request.Hash,
+		Callinfo:    request.Callinfo,
+		Meta:        request.Meta,
+	})
+	if !approved {
+		return SignDataResponse{Approved: false}, nil
+	}
+	return q.next.ApproveSignData(request)
+}
+
+func (q *ApprovalQueue) ApproveListing(request *ListRequest) (ListResponse, error) {
+	return q.next.ApproveListing(request)
+}
+
+func (q *ApprovalQueue) ApproveNewAccount(request *NewAccountRequest) (NewAccountResponse, error) {
+	return q.next.ApproveNewAccount(request)
+}
+
+func (q *ApprovalQueue) ShowError(message string) {
+	q.next.ShowError(message)
+}
+
+func (q *ApprovalQueue) ShowInfo(message string) {
+	q.next.ShowInfo(message)
+}
+
+func (q *ApprovalQueue) OnApprovedTx(tx ethapi.SignTransactionResult) {
+	q.next.OnApprovedTx(tx)
+}
+
+func (q *ApprovalQueue) OnSignerStartup(info StartupInfo) {
+	q.next.OnSignerStartup(info)
+}
+
+func (q *ApprovalQueue) OnInputRequired(info UserInputRequest) (UserInputResponse, error) {
+	return q.next.OnInputRequired(info)
+}
+
+// RegisterUIServer makes the queue available to the UI, so it can list,
+// approve and reject pending requests.
+func (q *ApprovalQueue) RegisterUIServer(api *UIServerAPI) {
+	api.approvals = q
+	q.next.RegisterUIServer(api)
+}
+
+// ApproverAPI lets approvers authenticated by their TLS client certificate
+// list, approve and reject pending requests. The client names configured for
+// the certificates are the approver names.
+type ApproverAPI struct {
+	queue *ApprovalQueue
+}
+
+// NewApproverAPI creates the approver API for the queue.
+func NewApproverAPI(queue *ApprovalQueue) *ApproverAPI {
+	return &ApproverAPI{queue: queue}
+}
+
+// approver returns the name of the approver making the request.
+func (api *ApproverAPI) approver(ctx context.Context, method string) (string, error) {
+	client := clientFromContext(ctx)
+	if client == nil {
+		return "", errors.New("approver not authenticated")
+	}
+	if !slices.Contains(client.Methods, method) {
+		return "", fmt.Errorf("%w: %s may not call %s", ErrClientNotPermitted, client.Name, method)
+	}
+	return client.Name, nil
+}
+
+// Pending lists the requests waiting for approvals.
+// Example call
+// {"jsonrpc":"2.0","method":"approver_pending","params":[], "id":1}
+func (api *ApproverAPI) Pending(ctx context.Context) ([]PendingRequest, error) {
+	if _, err := api.approver(ctx, "approver_pending"); err != nil {
+		return nil, err
+	}
+	return api.queue.Pending(), nil
+}
+
+// Approve approves a pending request.
+// Example call
+// {"jsonrpc":"2.0","method":"approver_approve","params":["<id>"], "id":2}
+func (api *ApproverAPI) Approve(ctx context.Context, id string) error {
+	approver, err := api.approver(ctx, "approver_approve")
+	if err != nil {
+		return err
+	}
+	return api.queue.Approve(id, approver, "rpc")
+}
+
+// Reject rejects a pending request.
+// Example call
+// {"jsonrpc":"2.0","method":"approver_reject","params":["<id>"], "id":3}
+func (api *ApproverAPI) Reject(ctx context.Context, id string) error {
+	approver, err := api.approver(ctx, "approver_reject")
+	if err != nil {
+		return err
+	}
+	return api.queue.Reject(id, approver, "rpc")
+}
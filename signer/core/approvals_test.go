@@ -0,0 +1,313 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core_test
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/ethereum/go-ethereum/signer/core"
+	"github.com/ethereum/go-ethereum/signer/fourbyte"
+	"github.com/ethereum/go-ethereum/signer/rules"
+	"github.com/ethereum/go-ethereum/signer/storage"
+)
+
+// setupApprovals creates two accounts and a signer requiring two approvals of
+// the given approvers for the first one. If a policy is given, approved requests
+// are passed to a policy engine before reaching the UI.
+func setupApprovals(t *testing.T, approvers []string, expiry time.Duration, policy string) (*core.SignerAPI, *core.ApprovalQueue, *headlessUi, []common.Address) {
+	db, err := fourbyte.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ui := &headlessUi{make(chan string, 20), make(chan string, 20)}
+	am := core.StartClefAccountManager(tmpDirName(t), true, true, "")
+	plain := core.NewSignerAPI(am, 1337, true, ui, db, true, &storage.NoStorage{})
+	createAccount(ui, plain, t)
+	createAccount(ui, plain, t)
+	accounts, err := list(ui, plain, t)
+	if err != nil || len(accounts) != 2 {
+		t.Fatalf("failed to create accounts: %v %v", accounts, err)
+	}
+	var next core.UIClientAPI = ui
+	if policy != "" {
+		p, err := rules.ParsePolicy([]byte(policy))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if next, err = rules.NewPolicyEvaluator(ui, p, storage.NewEphemeralStorage()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	queue, err := core.NewApprovalQueue(next, core.ApprovalConfig{
+		Threshold: 2,
+		Approvers: approvers,
+		Accounts:  accounts[:1],
+		Expiry:    expiry,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	api := core.NewSignerAPI(am, 1337, true, queue, db, true, &storage.NoStorage{})
+	return api, queue, ui, accounts
+}
+
+// signAsync signs a transaction in the background, returning the result.
+func signAsync(api *core.SignerAPI, from common.Address) chan error {
+	errc := make(chan error, 1)
+	go func() {
+		_, err := api.SignTransaction(context.Background(), mkTestTx(common.NewMixedcaseAddress(from)), nil)
+		errc <- err
+	}()
+	return errc
+}
+
+// waitPending waits until a request is pending.
+func waitPending(t *testing.T, queue *core.ApprovalQueue) core.PendingRequest {
+	t.Helper()
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		if pending := queue.Pending(); len(pending) > 0 {
+			return pending[0]
+		}
+	}
+	t.Fatal("no pending request")
+	return core.PendingRequest{}
+}
+
+func TestApprovalQueue(t *testing.T) {
+	api, queue, control, accounts := setupApprovals(t, []string{core.UIApprover, "bob", "carol"}, time.Minute, "")
+	uiapi := core.NewUIServerAPI(api)
+	queue.RegisterUIServer(uiapi)
+
+	auditlog := filepath.Join(t.TempDir(), "audit.log")
+	logger, err := core.NewAuditLogger(auditlog, api)
+	if err != nil {
+		t.Fatal(err)
+	}
+	queue.SetAuditLogger(logger)
+
+	// Accounts not requiring approvals go to the UI.
+	control.approveCh <- "Y"
+	control.inputCh <- "a_long_password"
+	if err := <-signAsync(api, accounts[1]); err != nil {
+		t.Fatalf("failed to sign for account without approvals: %v", err)
+	}
+	// The others are held until two approvers approve.
+	errc := signAsync(api, accounts[0])
+	pending := waitPending(t, queue)
+	if pending.Account != accounts[0] || pending.Transaction == nil {
+		t.Fatalf("wrong pending request %+v", pending)
+	}
+	if listed, err := uiapi.PendingRequests(); err != nil || len(listed) != 1 || listed[0].ID != pending.ID {
+		t.Fatalf("pending request not listed: %v %v", listed, err)
+	}
+	if err := queue.Approve(pending.ID, "mallory", "rpc"); !errors.Is(err, core.ErrNotApprover) {
+		t.Fatalf("expected approver error, got %v", err)
+	}
+	// All UI approvals count as one approver, so the UI can't reach the
+	// threshold on its own.
+	if err := uiapi.ApproveRequest(pending.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := uiapi.ApproveRequest(pending.ID); !errors.Is(err, core.ErrAlreadyApproved) {
+		t.Fatalf("expected duplicate approval error, got %v", err)
+	}
+	select {
+	case err := <-errc:
+		t.Fatalf("request decided after one approval: %v", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+	if listed, _ := uiapi.PendingRequests(); len(listed) != 1 || fmt.Sprint(listed[0].Approvals) != "[ui]" {
+		t.Fatalf("wrong approvals %+v", listed)
+	}
+	// Once approved, the request is passed on to the UI.
+	control.approveCh <- "Y"
+	control.inputCh <- "a_long_password"
+	if err := queue.Approve(pending.ID, "bob", "rpc"); err != nil {
+		t.Fatal(err)
+	}
+	if err := <-errc; err != nil {
+		t.Fatalf("failed to sign approved request: %v", err)
+	}
+	if err := queue.Approve(pending.ID, "carol", "rpc"); !errors.Is(err, core.ErrUnknownPendingRequest) {
+		t.Fatalf("expected unknown request error, got %v", err)
+	}
+	// A single rejection rejects the request.
+	errc = signAsync(api, accounts[0])
+	pending = waitPending(t, queue)
+	if err := queue.Approve(pending.ID, "bob", "rpc"); err != nil {
+		t.Fatal(err)
+	}
+	if err := uiapi.RejectRequest(pending.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := <-errc; err != core.ErrRequestDenied {
+		t.Fatalf("expected denied request, got %v", err)
+	}
+	// The approvals and decisions are audit logged.
+	logs, err := os.ReadFile(auditlog)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"type=pending", "approver=ui", "channel=ui", "approver=bob", "type=approved", "type=rejected"} {
+		if !strings.Contains(string(logs), want) {
+			t.Errorf("audit log is missing %q:\n%s", want, logs)
+		}
+	}
+}
+
+// Tests that the UI can't approve requests unless it's configured as an approver.
+func TestApprovalUINotApprover(t *testing.T) {
+	api, queue, _, accounts := setupApprovals(t, []string{"alice", "bob"}, time.Minute, "")
+	uiapi := core.NewUIServerAPI(api)
+	queue.RegisterUIServer(uiapi)
+
+	errc := signAsync(api, accounts[0])
+	pending := waitPending(t, queue)
+	if err := uiapi.ApproveRequest(pending.ID); !errors.Is(err, core.ErrNotApprover) {
+		t.Fatalf("expected approver error, got %v", err)
+	}
+	if err := queue.Reject(pending.ID, "alice", "rpc"); err != nil {
+		t.Fatal(err)
+	}
+	if err := <-errc; err != core.ErrRequestDenied {
+		t.Fatalf("expected denied request, got %v", err)
+	}
+}
+
+// Tests that requests approved by the approvers are still rejected if the rules
+// reject them.
+func TestApprovalPolicyReject(t *testing.T) {
+	api, queue, _, accounts := setupApprovals(t, []string{"alice", "bob"}, time.Minute, "unmatched: reject\nrules: []\n")
+
+	errc := signAsync(api, accounts[0])
+	pending := waitPending(t, queue)
+	if err := queue.Approve(pending.ID, "alice", "rpc"); err != nil {
+		t.Fatal(err)
+	}
+	if err := queue.Approve(pending.ID, "bob", "rpc"); err != nil {
+		t.Fatal(err)
+	}
+	if err := <-errc; err != core.ErrRequestDenied {
+		t.Fatalf("expected request rejected by policy, got %v", err)
+	}
+}
+
+func TestApprovalExpiry(t *testing.T) {
+	api, queue, _, accounts := setupApprovals(t, []string{"alice", "bob"}, 100*time.Millisecond, "")
+
+	errc := signAsync(api, accounts[0])
+	pending := waitPending(t, queue)
+	if err := queue.Approve(pending.ID, "alice", "rpc"); err != nil {
+		t.Fatal(err)
+	}
+	if err := <-errc; err != core.ErrRequestDenied {
+		t.Fatalf("expected expired request to be denied, got %v", err)
+	}
+	if pending := queue.Pending(); len(pending) != 0 {
+		t.Fatalf("expired request still pending: %v", pending)
+	}
+	if err := queue.Approve(pending.ID, "bob", "rpc"); !errors.Is(err, core.ErrUnknownPendingRequest) {
+		t.Fatalf("expected unknown request error, got %v", err)
+	}
+}
+
+func TestApproverAPI(t *testing.T) {
+	api, queue, control, accounts := setupApprovals(t, []string{core.UIApprover, "bob", "carol"}, time.Minute, "")
+	uiapi := core.NewUIServerAPI(api)
+	queue.RegisterUIServer(uiapi)
+
+	// Unauthenticated approvers are rejected.
+	approvers := core.NewApproverAPI(queue)
+	if _, err := approvers.Pending(context.Background()); err == nil {
+		t.Fatal("expected error for unauthenticated approver")
+	}
+	// Serve the approver API over mutual TLS, where bob authenticates with a
+	// client certificate.
+	var (
+		ca  = makeCert(t, "ca", nil)
+		bob = makeCert(t, "bob", &ca)
+		dir = t.TempDir()
+	)
+	config := `[{"name": "bob", "commonName": "bob", "methods": ["approver_pending", "approver_approve"]}]`
+	if err := os.WriteFile(filepath.Join(dir, "clients.json"), []byte(config), 0600); err != nil {
+		t.Fatal(err)
+	}
+	clients, err := core.LoadClients(filepath.Join(dir, "clients.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := rpc.NewServer()
+	defer srv.Stop()
+	if err := srv.RegisterName("approver", approvers); err != nil {
+		t.Fatal(err)
+	}
+	pool := x509.NewCertPool()
+	pool.AddCert(ca.Leaf)
+	server := httptest.NewUnstartedServer(core.NewClientAuthHandler(clients, srv))
+	server.TLS = &tls.Config{ClientCAs: pool, ClientAuth: tls.RequireAndVerifyClientCert}
+	server.StartTLS()
+	defer server.Close()
+
+	roots := x509.NewCertPool()
+	roots.AddCert(server.Certificate())
+	httpClient := &http.Client{Transport: &http.Transport{
+		TLSClientConfig: &tls.Config{RootCAs: roots, Certificates: []tls.Certificate{bob}},
+	}}
+	client, err := rpc.DialOptions(context.Background(), server.URL, rpc.WithHTTPClient(httpClient))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	// The UI approves, and bob over the approver API.
+	errc := signAsync(api, accounts[0])
+	pending := waitPending(t, queue)
+	if err := uiapi.ApproveRequest(pending.ID); err != nil {
+		t.Fatal(err)
+	}
+	var listed []core.PendingRequest
+	if err := client.Call(&listed, "approver_pending"); err != nil {
+		t.Fatal(err)
+	}
+	if len(listed) != 1 || listed[0].ID != pending.ID || fmt.Sprint(listed[0].Approvals) != "[ui]" {
+		t.Fatalf("wrong pending requests %+v", listed)
+	}
+	if err := client.Call(nil, "approver_reject", pending.ID); err == nil || !strings.Contains(err.Error(), core.ErrClientNotPermitted.Error()) {
+		t.Fatalf("expected permission error, got %v", err)
+	}
+	control.approveCh <- "Y"
+	control.inputCh <- "a_long_password"
+	if err := client.Call(nil, "approver_approve", pending.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := <-errc; err != nil {
+		t.Fatalf("failed to sign approved request: %v", err)
+	}
+}
@@ -24,6 +24,7 @@ import (
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/internal/ethapi"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/signer/core/apitypes"
@@ -91,6 +92,20 @@ func (l *AuditLogger) SignGnosisSafeTx(ctx context.Context, addr common.Mixedcas
 	return res, e
 }
 
+func (l *AuditLogger) SignAuthorization(ctx context.Context, addr common.MixedcaseAddress, auth types.SetCodeAuthorization) (*types.SetCodeAuthorization, error) {
+	data, _ := json.Marshal(auth) // can ignore error, marshalling what we just unmarshalled
+	l.log.Info("SignAuthorization", "type", "request", "metadata", MetadataFromContext(ctx).String(),
+		"addr", addr.String(), "data", string(data))
+	res, e := l.api.SignAuthorization(ctx, addr, auth)
+	if res != nil {
+		data, _ := json.Marshal(res)
+		l.log.Info("SignAuthorization", "type", "response", "data", string(data), "error", e)
+	} else {
+		l.log.Info("SignAuthorization", "type", "response", "data", res, "error", e)
+	}
+	return res, e
+}
+
 func (l *AuditLogger) SignTypedData(ctx context.Context, addr common.MixedcaseAddress, data apitypes.TypedData) (hexutil.Bytes, error) {
 	l.log.Info("SignTypedData", "type", "request", "metadata", MetadataFromContext(ctx).String(),
 		"addr", addr.String(), "data", data)
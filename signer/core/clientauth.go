@@ -0,0 +1,254 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/internal/ethapi"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+)
+
+// ErrClientNotPermitted is returned if an authenticated client calls a method
+// or uses an account it is not permitted to.
+var ErrClientNotPermitted = errors.New("client not permitted")
+
+// externalMethods are the methods of the external and approver APIs clients
+// can be permitted to call.
+var externalMethods = []string{
+	"account_list",
+	"account_new",
+	"account_signTransaction",
+	"account_signData",
+	"account_signTypedData",
+	"account_ecRecover",
+	"account_version",
+	"account_signGnosisSafeTx",
+	"account_signAuthorization",
+	"approver_pending",
+	"approver_approve",
+	"approver_reject",
+}
+
+// Client is a client of the external API, authenticated by its TLS certificate,
+// along with the methods it may call and the accounts it may use.
+type Client struct {
+	Name        string           `json:"name"`
+	Fingerprint string           `json:"fingerprint"` // Hex SHA-256 hash of the DER encoded certificate
+	CommonName  string           `json:"commonName"`  // Subject common name of the certificate
+	Methods     []string         `json:"methods"`
+	Accounts    []common.Address `json:"accounts"`
+}
+
+// LoadClients reads the list of clients from a JSON file.
+func LoadClients(path string) ([]*Client, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var clients []*Client
+	if err := json.Unmarshal(data, &clients); err != nil {
+		return nil, fmt.Errorf("invalid clients file: %w", err)
+	}
+	names := make(map[string]bool)
+	for i, c := range clients {
+		if c.Name == "" {
+			return nil, fmt.Errorf("client %d has no name", i)
+		}
+		if names[c.Name] {
+			return nil, fmt.Errorf("duplicate client %q", c.Name)
+		}
+		names[c.Name] = true
+
+		if c.Fingerprint == "" && c.CommonName == "" {
+			return nil, fmt.Errorf("client %q has neither fingerprint nor common name", c.Name)
+		}
+		if c.Fingerprint != "" {
+			fp, err := hex.DecodeString(strings.ReplaceAll(strings.TrimPrefix(c.Fingerprint, "0x"), ":", ""))
+			if err != nil || len(fp) != sha256.Size {
+				return nil, fmt.Errorf("client %q has invalid fingerprint %q", c.Name, c.Fingerprint)
+			}
+			c.Fingerprint = hex.EncodeToString(fp)
+		}
+		if len(c.Methods) == 0 {
+			return nil, fmt.Errorf("client %q has no permitted methods", c.Name)
+		}
+		for _, method := range c.Methods {
+			if !slices.Contains(externalMethods, method) {
+				return nil, fmt.Errorf("client %q has unknown method %q", c.Name, method)
+			}
+		}
+	}
+	return clients, nil
+}
+
+// matches checks whether the certificate identifies the client.
+func (c *Client) matches(cert *x509.Certificate) bool {
+	if c.Fingerprint != "" {
+		fp := sha256.Sum256(cert.Raw)
+		if hex.EncodeToString(fp[:]) != c.Fingerprint {
+			return false
+		}
+	}
+	if c.CommonName != "" && cert.Subject.CommonName != c.CommonName {
+		return false
+	}
+	return true
+}
+
+type clientKey struct{}
+
+// clientFromContext returns the authenticated client of a request, or nil if
+// the request was not made over an authenticated transport.
+func clientFromContext(ctx context.Context) *Client {
+	client, _ := ctx.Value(clientKey{}).(*Client)
+	return client
+}
+
+// NewClientAuthHandler returns a handler which identifies the client of each
+// request by its verified TLS certificate, rejecting unknown clients. The
+// client is available to the external API through the request context.
+func NewClientAuthHandler(clients []*Client, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.TLS == nil || len(r.TLS.VerifiedChains) == 0 {
+			http.Error(w, "client certificate required", http.StatusUnauthorized)
+			return
+		}
+		cert := r.TLS.VerifiedChains[0][0]
+		for _, client := range clients {
+			if client.matches(cert) {
+				next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), clientKey{}, client)))
+				return
+			}
+		}
+		log.Warn("Rejected unknown client certificate", "remote", r.RemoteAddr, "subject", cert.Subject)
+		http.Error(w, "unknown client certificate", http.StatusForbidden)
+	})
+}
+
+// ClientFilter enforces the permissions of authenticated clients on the
+// external API. Requests made over unauthenticated transports are passed
+// through unrestricted.
+type ClientFilter struct {
+	api ExternalAPI
+}
+
+// NewClientFilter creates a filter enforcing client permissions on the api.
+func NewClientFilter(api ExternalAPI) *ClientFilter {
+	return &ClientFilter{api: api}
+}
+
+// check returns an error if the client of the request is not permitted to
+// call the method with the given accounts.
+func (f *ClientFilter) check(ctx context.Context, method string, accounts ...common.Address) error {
+	client := clientFromContext(ctx)
+	if client == nil {
+		return nil
+	}
+	if !slices.Contains(client.Methods, method) {
+		return fmt.Errorf("%w: %s may not call %s", ErrClientNotPermitted, client.Name, method)
+	}
+	for _, account := range accounts {
+		if !slices.Contains(client.Accounts, account) {
+			return fmt.Errorf("%w: %s may not use account %v", ErrClientNotPermitted, client.Name, account)
+		}
+	}
+	return nil
+}
+
+func (f *ClientFilter) List(ctx context.Context) ([]common.Address, error) {
+	if err := f.check(ctx, "account_list"); err != nil {
+		return nil, err
+	}
+	accounts, err := f.api.List(ctx)
+	if client := clientFromContext(ctx); client != nil {
+		accounts = slices.DeleteFunc(accounts, func(account common.Address) bool {
+			return !slices.Contains(client.Accounts, account)
+		})
+	}
+	return accounts, err
+}
+
+func (f *ClientFilter) New(ctx context.Context) (common.Address, error) {
+	if err := f.check(ctx, "account_new"); err != nil {
+		return common.Address{}, err
+	}
+	return f.api.New(ctx)
+}
+
+func (f *ClientFilter) SignTransaction(ctx context.Context, args apitypes.SendTxArgs, methodSelector *string) (*ethapi.SignTransactionResult, error) {
+	if err := f.check(ctx, "account_signTransaction", args.From.Address()); err != nil {
+		return nil, err
+	}
+	return f.api.SignTransaction(ctx, args, methodSelector)
+}
+
+func (f *ClientFilter) SignData(ctx context.Context, contentType string, addr common.MixedcaseAddress, data interface{}) (hexutil.Bytes, error) {
+	if err := f.check(ctx, "account_signData", addr.Address()); err != nil {
+		return nil, err
+	}
+	return f.api.SignData(ctx, contentType, addr, data)
+}
+
+func (f *ClientFilter) SignTypedData(ctx context.Context, addr common.MixedcaseAddress, data apitypes.TypedData) (hexutil.Bytes, error) {
+	if err := f.check(ctx, "account_signTypedData", addr.Address()); err != nil {
+		return nil, err
+	}
+	return f.api.SignTypedData(ctx, addr, data)
+}
+
+func (f *ClientFilter) EcRecover(ctx context.Context, data hexutil.Bytes, sig hexutil.Bytes) (common.Address, error) {
+	if err := f.check(ctx, "account_ecRecover"); err != nil {
+		return common.Address{}, err
+	}
+	return f.api.EcRecover(ctx, data, sig)
+}
+
+func (f *ClientFilter) Version(ctx context.Context) (string, error) {
+	if err := f.check(ctx, "account_version"); err != nil {
+		return "", err
+	}
+	return f.api.Version(ctx)
+}
+
+func (f *ClientFilter) SignGnosisSafeTx(ctx context.Context, signerAddress common.MixedcaseAddress, gnosisTx GnosisSafeTx, methodSelector *string) (*GnosisSafeTx, error) {
+	if err := f.check(ctx, "account_signGnosisSafeTx", signerAddress.Address()); err != nil {
+		return nil, err
+	}
+	return f.api.SignGnosisSafeTx(ctx, signerAddress, gnosisTx, methodSelector)
+}
+
+func (f *ClientFilter) SignAuthorization(ctx context.Context, addr common.MixedcaseAddress, auth types.SetCodeAuthorization) (*types.SetCodeAuthorization, error) {
+	if err := f.check(ctx, "account_signAuthorization", addr.Address()); err != nil {
+		return nil, err
+	}
+	return f.api.SignAuthorization(ctx, addr, auth)
+}
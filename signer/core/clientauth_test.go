@@ -0,0 +1,199 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/ethereum/go-ethereum/signer/core"
+)
+
+// makeCert creates a certificate signed by the parent, or a self-signed
+// certificate authority if the parent is nil.
+func makeCert(t *testing.T, name string, parent *tls.Certificate) tls.Certificate {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	var (
+		signer    = template
+		signerKey = any(key)
+	)
+	if parent == nil {
+		template.IsCA = true
+		template.BasicConstraintsValid = true
+		template.KeyUsage = x509.KeyUsageCertSign
+	} else {
+		signer, signerKey = parent.Leaf, parent.PrivateKey
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, signer, &key.PublicKey, signerKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	leaf, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key, Leaf: leaf}
+}
+
+func TestLoadClients(t *testing.T) {
+	t.Parallel()
+	for i, tt := range []struct {
+		config string
+		err    string
+	}{
+		{`[{"name": "a", "commonName": "a", "methods": ["account_list"]}]`, ""},
+		{`[{"name": "a", "fingerprint": "` + strings.Repeat("ab:", 31) + `ab", "methods": ["account_list"]}]`, ""},
+		{`[{"commonName": "a", "methods": ["account_list"]}]`, "no name"},
+		{`[{"name": "a", "methods": ["account_list"]}]`, "neither fingerprint nor common name"},
+		{`[{"name": "a", "fingerprint": "0x1234", "methods": ["account_list"]}]`, "invalid fingerprint"},
+		{`[{"name": "a", "commonName": "a"}]`, "no permitted methods"},
+		{`[{"name": "a", "commonName": "a", "methods": ["eth_sendTransaction"]}]`, "unknown method"},
+		{`[{"name": "a", "commonName": "a", "methods": ["account_list"]}, {"name": "a", "commonName": "b", "methods": ["account_list"]}]`, "duplicate client"},
+	} {
+		path := filepath.Join(t.TempDir(), "clients.json")
+		if err := os.WriteFile(path, []byte(tt.config), 0600); err != nil {
+			t.Fatal(err)
+		}
+		_, err := core.LoadClients(path)
+		switch {
+		case tt.err == "" && err != nil:
+			t.Errorf("test %d: unexpected error: %v", i, err)
+		case tt.err != "" && (err == nil || !strings.Contains(err.Error(), tt.err)):
+			t.Errorf("test %d: wrong error %v, want %q", i, err, tt.err)
+		}
+	}
+}
+
+func TestClientPermissions(t *testing.T) {
+	api, control := setup(t)
+	createAccount(control, api, t)
+	createAccount(control, api, t)
+	accounts, err := list(control, api, t)
+	if err != nil || len(accounts) != 2 {
+		t.Fatalf("failed to create accounts: %v %v", accounts, err)
+	}
+	var (
+		ca       = makeCert(t, "ca", nil)
+		payments = makeCert(t, "payments", &ca)
+		stranger = makeCert(t, "stranger", &ca)
+		fp       = sha256.Sum256(payments.Leaf.Raw)
+		dir      = t.TempDir()
+	)
+	config := fmt.Sprintf(`[{"name": "payments", "fingerprint": "%x", "methods": ["account_list", "account_signTransaction"], "accounts": ["%v"]}]`, fp, accounts[0])
+	if err := os.WriteFile(filepath.Join(dir, "clients.json"), []byte(config), 0600); err != nil {
+		t.Fatal(err)
+	}
+	clients, err := core.LoadClients(filepath.Join(dir, "clients.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Serve the filtered and audit logged API over mutual TLS.
+	auditlog := filepath.Join(dir, "audit.log")
+	logged, err := core.NewAuditLogger(auditlog, core.NewClientFilter(api))
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := rpc.NewServer()
+	defer srv.Stop()
+	if err := srv.RegisterName("account", logged); err != nil {
+		t.Fatal(err)
+	}
+	pool := x509.NewCertPool()
+	pool.AddCert(ca.Leaf)
+
+	server := httptest.NewUnstartedServer(core.NewClientAuthHandler(clients, srv))
+	server.TLS = &tls.Config{ClientCAs: pool, ClientAuth: tls.RequireAndVerifyClientCert}
+	server.StartTLS()
+	defer server.Close()
+
+	dial := func(cert tls.Certificate) *rpc.Client {
+		roots := x509.NewCertPool()
+		roots.AddCert(server.Certificate())
+		httpClient := &http.Client{Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{RootCAs: roots, Certificates: []tls.Certificate{cert}},
+		}}
+		client, err := rpc.DialOptions(context.Background(), server.URL, rpc.WithHTTPClient(httpClient))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return client
+	}
+	client := dial(payments)
+	defer client.Close()
+
+	// Listing only returns the permitted accounts.
+	control.approveCh <- "A"
+	var listed []common.Address
+	if err := client.Call(&listed, "account_list"); err != nil {
+		t.Fatal(err)
+	}
+	if len(listed) != 1 || listed[0] != accounts[0] {
+		t.Fatalf("wrong accounts listed: %v", listed)
+	}
+	// Other methods and accounts are rejected before reaching the UI.
+	var version string
+	if err := client.Call(&version, "account_version"); err == nil || !strings.Contains(err.Error(), core.ErrClientNotPermitted.Error()) {
+		t.Fatalf("expected permission error, got %v", err)
+	}
+	tx := mkTestTx(common.NewMixedcaseAddress(accounts[1]))
+	if err := client.Call(nil, "account_signTransaction", tx); err == nil || !strings.Contains(err.Error(), core.ErrClientNotPermitted.Error()) {
+		t.Fatalf("expected permission error, got %v", err)
+	}
+	// The audit log records the client identity.
+	logs, err := os.ReadFile(auditlog)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(logs), `\"client\":\"payments\"`) {
+		t.Fatalf("client not recorded in audit log:\n%s", logs)
+	}
+	// Clients with valid certificates not listed in the configuration are
+	// rejected.
+	other := dial(stranger)
+	defer other.Close()
+	if err := other.Call(&listed, "account_list"); err == nil || !strings.Contains(err.Error(), "403") {
+		t.Fatalf("expected forbidden error, got %v", err)
+	}
+}
@@ -219,6 +219,67 @@ func cliqueHeaderHashAndRlp(header *types.Header) (hash, rlp []byte, err error)
 	return hash, rlp, err
 }
 
+// SignAuthorization signs an EIP-7702 authorization, delegating the code of the
+// account to the address of the authorization.
+// hash = keccak256(0x05 || rlp([chain_id, address, nonce]))
+// It returns the authorization along with its signature.
+func (api *SignerAPI) SignAuthorization(ctx context.Context, addr common.MixedcaseAddress, auth types.SetCodeAuthorization) (*types.SetCodeAuthorization, error) {
+	payload, err := rlp.EncodeToBytes([]interface{}{auth.ChainID, auth.Address, auth.Nonce})
+	if err != nil {
+		return nil, err
+	}
+	var (
+		rawdata = append([]byte{apitypes.Authorization.ByteVersion}, payload...)
+		sighash = auth.SigHash()
+	)
+	messages := []*apitypes.NameValueType{
+		{
+			Name:  "This is a request to delegate the code of the account to a contract (see EIP 7702)",
+			Typ:   "description",
+			Value: "",
+		},
+		{
+			Name:  "Delegation target",
+			Typ:   "address",
+			Value: auth.Address.String(),
+		},
+		{
+			Name:  "Chain ID",
+			Typ:   "uint256",
+			Value: auth.ChainID.Dec(),
+		},
+		{
+			Name:  "Account nonce",
+			Typ:   "uint64",
+			Value: fmt.Sprint(auth.Nonce),
+		},
+	}
+	var callinfo []apitypes.ValidationInfo
+	if auth.ChainID.IsZero() {
+		callinfo = append(callinfo, apitypes.ValidationInfo{Typ: apitypes.WARN, Message: "Authorization is valid on all chains"})
+	}
+	if auth.Address == (common.Address{}) {
+		callinfo = append(callinfo, apitypes.ValidationInfo{Typ: apitypes.INFO, Message: "Authorization clears the delegation of the account"})
+	}
+	req := &SignDataRequest{
+		ContentType: apitypes.Authorization.Mime,
+		Address:     addr,
+		Rawdata:     rawdata,
+		Messages:    messages,
+		Callinfo:    callinfo,
+		Hash:        sighash[:],
+		Meta:        MetadataFromContext(ctx),
+	}
+	// Authorizations use V on the form 0 or 1
+	signature, err := api.sign(req, false)
+	if err != nil {
+		api.UI.ShowError(err.Error())
+		return nil, err
+	}
+	signed := auth.WithSignature(signature)
+	return &signed, nil
+}
+
 // SignTypedData signs EIP-712 conformant typed data
 // hash = keccak256("\x19${byteVersion}${domainSeparator}${hashStruct(message)}")
 // It returns
@@ -31,9 +31,11 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/signer/core"
 	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+	"github.com/holiman/uint256"
 )
 
 var typesStandard = apitypes.Types{
@@ -182,6 +184,43 @@ var typedData = apitypes.TypedData{
 	Message:     messageStandard,
 }
 
+func TestSignAuthorization(t *testing.T) {
+	t.Parallel()
+	api, control := setup(t)
+	createAccount(control, api, t)
+	control.approveCh <- "A"
+	list, err := api.List(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	a := common.NewMixedcaseAddress(list[0])
+	auth := types.SetCodeAuthorization{
+		ChainID: *uint256.NewInt(1),
+		Address: common.HexToAddress("0x42"),
+		Nonce:   1,
+	}
+	control.approveCh <- "No way"
+	if _, err := api.SignAuthorization(context.Background(), a, auth); err != core.ErrRequestDenied {
+		t.Errorf("Expected ErrRequestDenied! '%v'", err)
+	}
+	control.approveCh <- "Y"
+	control.inputCh <- "a_long_password"
+	signed, err := api.SignAuthorization(context.Background(), a, auth)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if signed.V > 1 {
+		t.Errorf("Expected yParity of 0 or 1, got %d", signed.V)
+	}
+	authority, err := signed.Authority()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if authority != list[0] {
+		t.Errorf("Wrong authority: have %v, want %v", authority, list[0])
+	}
+}
+
 func TestSignData(t *testing.T) {
 	t.Parallel()
 	api, control := setup(t)
@@ -39,13 +39,14 @@ import (
 // NB: It's very important that these methods are not ever exposed on the external service
 // registry.
 type UIServerAPI struct {
-	extApi *SignerAPI
-	am     *accounts.Manager
+	extApi    *SignerAPI
+	am        *accounts.Manager
+	approvals *ApprovalQueue // Set if requests require multiple approvals
 }
 
 // NewUIServerAPI creates a new UIServerAPI
 func NewUIServerAPI(extapi *SignerAPI) *UIServerAPI {
-	return &UIServerAPI{extapi, extapi.am}
+	return &UIServerAPI{extApi: extapi, am: extapi.am}
 }
 
 // List available accounts. As opposed to the external API definition, this method delivers
@@ -209,6 +210,37 @@ func (api *UIServerAPI) New(ctx context.Context) (common.Address, error) {
 	return api.extApi.newAccount()
 }
 
+// PendingRequests lists the signing requests waiting for approvals.
+// Example call
+// {"jsonrpc":"2.0","method":"clef_pendingRequests","params":[], "id":7}
+func (api *UIServerAPI) PendingRequests() ([]PendingRequest, error) {
+	if api.approvals == nil {
+		return nil, errors.New("multi-approval not enabled")
+	}
+	return api.approvals.Pending(), nil
+}
+
+// ApproveRequest approves a pending signing request. All approvals made through
+// the UI count as the single approver UIApprover.
+// Example call
+// {"jsonrpc":"2.0","method":"clef_approveRequest","params":["<id>"], "id":8}
+func (api *UIServerAPI) ApproveRequest(id string) error {
+	if api.approvals == nil {
+		return errors.New("multi-approval not enabled")
+	}
+	return api.approvals.Approve(id, UIApprover, "ui")
+}
+
+// RejectRequest rejects a pending signing request as the approver UIApprover.
+// Example call
+// {"jsonrpc":"2.0","method":"clef_rejectRequest","params":["<id>"], "id":9}
+func (api *UIServerAPI) RejectRequest(id string) error {
+	if api.approvals == nil {
+		return errors.New("multi-approval not enabled")
+	}
+	return api.approvals.Reject(id, UIApprover, "ui")
+}
+
 // Other methods to be added, not yet implemented are:
 // - Ruleset interaction: add rules, attest rulefiles
 // - Store metadata about accounts, e.g. naming of accounts
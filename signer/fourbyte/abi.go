@@ -76,13 +76,17 @@ func verifySelector(selector string, calldata []byte) (*decodedCallData, error)
 
 // parseSelector converts a method selector into an ABI JSON spec. The returned
 // data is a valid JSON string which can be consumed by the standard abi package.
+// Besides bare selectors, human-readable function declarations with parameter
+// names are accepted too.
 func parseSelector(unescapedSelector string) ([]byte, error) {
-	selector, err := abi.ParseSelector(unescapedSelector)
+	if selector, err := abi.ParseSelector(unescapedSelector); err == nil {
+		return json.Marshal([]abi.SelectorMarshaling{selector})
+	}
+	abidata, err := abi.HumanReadableJSON([]string{unescapedSelector})
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse selector: %v", err)
 	}
-
-	return json.Marshal([]abi.SelectorMarshaling{selector})
+	return abidata, nil
 }
 
 // parseCallData matches the provided call data against the ABI definition and
@@ -0,0 +1,521 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rules
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/eip712"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/internal/ethapi"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/signer/core"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+	"github.com/ethereum/go-ethereum/signer/storage"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// UnmatchedManual forwards requests not matched by any rule to the next UI.
+	UnmatchedManual = "manual"
+	// UnmatchedReject rejects requests not matched by any rule.
+	UnmatchedReject = "reject"
+)
+
+// Policy is a declarative ruleset, as an auditable alternative to javascript
+// rule files. Requests are approved by the first rule matching them, requests
+// matched by no rule are handled as configured by Unmatched.
+type Policy struct {
+	Unmatched string       `json:"unmatched" yaml:"unmatched"` // "manual" (default) or "reject"
+	DryRun    bool         `json:"dryRun" yaml:"dryRun"`       // Only report the evaluation, always go to manual
+	Rules     []PolicyRule `json:"rules" yaml:"rules"`
+}
+
+// PolicyRule approves transactions or typed data signing requests. Rules
+// listing EIP-712 domains only match typed data requests, the others only
+// match transactions. Empty lists match anything.
+type PolicyRule struct {
+	Name     string           `json:"name" yaml:"name"`
+	Accounts []common.Address `json:"accounts" yaml:"accounts"` // Senders or signers the rule applies to
+
+	// Transaction restrictions
+	Recipients   []common.Address      `json:"recipients" yaml:"recipients"`
+	Selectors    []string              `json:"selectors" yaml:"selectors"` // 4-byte hex or function signatures
+	AllowCreate  bool                  `json:"allowCreate" yaml:"allowCreate"`
+	MaxValue     *math.HexOrDecimal256 `json:"maxValue" yaml:"maxValue"`
+	MaxGas       *math.HexOrDecimal64  `json:"maxGas" yaml:"maxGas"`
+	MaxFeePerGas *math.HexOrDecimal256 `json:"maxFeePerGas" yaml:"maxFeePerGas"`
+	RateLimit    *RateLimit            `json:"rateLimit" yaml:"rateLimit"`
+
+	// Typed data restrictions
+	Domains []PolicyDomain `json:"domains" yaml:"domains"`
+
+	// AllowWarnings approves requests the validator emitted warnings for.
+	// Critical validation messages always go to manual.
+	AllowWarnings bool `json:"allowWarnings" yaml:"allowWarnings"`
+
+	selectors  [][]byte
+	separators []common.Hash
+	window     time.Duration
+}
+
+// RateLimit restricts the number and the total value of the transactions a
+// rule approves for an account within a sliding time window.
+type RateLimit struct {
+	Window   string                `json:"window" yaml:"window"` // e.g. "1h" or "24h"
+	MaxTxs   uint64                `json:"maxTxs" yaml:"maxTxs"`
+	MaxValue *math.HexOrDecimal256 `json:"maxValue" yaml:"maxValue"`
+}
+
+// PolicyDomain is an EIP-712 domain typed data may be signed for. The fields
+// are the ones of the domain, all set fields need to be specified.
+type PolicyDomain struct {
+	Name              string                `json:"name" yaml:"name"`
+	Version           string                `json:"version" yaml:"version"`
+	ChainId           *math.HexOrDecimal256 `json:"chainId" yaml:"chainId"`
+	VerifyingContract string                `json:"verifyingContract" yaml:"verifyingContract"`
+	Salt              string                `json:"salt" yaml:"salt"`
+}
+
+// ParsePolicy parses a policy in YAML or JSON format and validates it.
+func ParsePolicy(data []byte) (*Policy, error) {
+	var policy Policy
+
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&policy); err != nil {
+		return nil, fmt.Errorf("invalid policy: %w", err)
+	}
+	if err := policy.init(); err != nil {
+		return nil, err
+	}
+	return &policy, nil
+}
+
+// init validates the policy and precomputes the values requests are matched
+// against.
+func (p *Policy) init() error {
+	switch p.Unmatched {
+	case "":
+		p.Unmatched = UnmatchedManual
+	case UnmatchedManual, UnmatchedReject:
+	default:
+		return fmt.Errorf("invalid unmatched behaviour %q", p.Unmatched)
+	}
+	names := make(map[string]bool)
+	for i := range p.Rules {
+		rule := &p.Rules[i]
+		if rule.Name == "" {
+			rule.Name = fmt.Sprintf("rule-%d", i)
+		}
+		if names[rule.Name] {
+			return fmt.Errorf("duplicate rule name %q", rule.Name)
+		}
+		names[rule.Name] = true
+
+		for _, sel := range rule.Selectors {
+			if strings.HasPrefix(sel, "0x") {
+				id, err := hexutil.Decode(sel)
+				if err != nil || len(id) != 4 {
+					return fmt.Errorf("rule %q: invalid selector %q", rule.Name, sel)
+				}
+				rule.selectors = append(rule.selectors, id)
+			} else {
+				if !strings.Contains(sel, "(") || !strings.HasSuffix(sel, ")") {
+					return fmt.Errorf("rule %q: invalid function signature %q", rule.Name, sel)
+				}
+				rule.selectors = append(rule.selectors, crypto.Keccak256([]byte(strings.ReplaceAll(sel, " ", "")))[:4])
+			}
+		}
+		for _, d := range rule.Domains {
+			domain := eip712.TypedDataDomain{
+				Name:              d.Name,
+				Version:           d.Version,
+				ChainId:           d.ChainId,
+				VerifyingContract: d.VerifyingContract,
+				Salt:              d.Salt,
+			}
+			separator, err := domain.Separator()
+			if err != nil {
+				return fmt.Errorf("rule %q: invalid domain: %w", rule.Name, err)
+			}
+			rule.separators = append(rule.separators, separator)
+		}
+		if len(rule.Domains) > 0 && (len(rule.Recipients) > 0 || len(rule.Selectors) > 0 || rule.AllowCreate ||
+			rule.MaxValue != nil || rule.MaxGas != nil || rule.MaxFeePerGas != nil || rule.RateLimit != nil) {
+			return fmt.Errorf("rule %q: typed data rules cannot have transaction restrictions", rule.Name)
+		}
+		if rule.RateLimit != nil {
+			window, err := time.ParseDuration(rule.RateLimit.Window)
+			if err != nil || window <= 0 {
+				return fmt.Errorf("rule %q: invalid rate limit window %q", rule.Name, rule.RateLimit.Window)
+			}
+			rule.window = window
+		}
+	}
+	return nil
+}
+
+// rateEntry is a transaction approved by a rule with a rate limit.
+type rateEntry struct {
+	Time  int64    `json:"time"`
+	Value *big.Int `json:"value"`
+}
+
+// policyUI provides an implementation of UIClientAPI that evaluates a
+// declarative policy for transactions and typed data signing requests.
+type policyUI struct {
+	next    core.UIClientAPI // The next handler, for manual processing
+	storage storage.Storage  // Storage for the rate limits
+	policy  *Policy
+	now     func() time.Time
+
+	lock sync.Mutex // Serializes rate limit checks and updates
+}
+
+// NewPolicyEvaluator creates a UI which approves the requests matched by the
+// policy, storing rate limit state in the given storage.
+func NewPolicyEvaluator(next core.UIClientAPI, policy *Policy, backend storage.Storage) (*policyUI, error) {
+	if policy == nil {
+		return nil, errors.New("no policy")
+	}
+	return &policyUI{
+		next:    next,
+		storage: backend,
+		policy:  policy,
+		now:     time.Now,
+	}, nil
+}
+
+// rateKey is the storage key of the rate limit state of a rule and account.
+func rateKey(rule string, account common.Address) string {
+	return fmt.Sprintf("policy/%s/%s", rule, account.Hex())
+}
+
+// rateEntries returns the transactions recorded for the rule and account
+// which are still within the window.
+func (p *policyUI) rateEntries(rule *PolicyRule, account common.Address) []rateEntry {
+	blob, err := p.storage.Get(rateKey(rule.Name, account))
+	if err != nil {
+		return nil
+	}
+	var entries []rateEntry
+	if err := json.Unmarshal([]byte(blob), &entries); err != nil {
+		log.Warn("Discarding corrupt rate limit state", "rule", rule.Name, "account", account, "err", err)
+		return nil
+	}
+	cutoff := p.now().Add(-rule.window).Unix()
+	for len(entries) > 0 && entries[0].Time <= cutoff {
+		entries = entries[1:]
+	}
+	return entries
+}
+
+// checkRate returns an error if approving a transaction with the given value
+// would exceed the rate limit of the rule.
+func (p *policyUI) checkRate(rule *PolicyRule, account common.Address, value *big.Int) error {
+	entries := p.rateEntries(rule, account)
+	if limit := rule.RateLimit.MaxTxs; limit > 0 && uint64(len(entries)) >= limit {
+		return fmt.Errorf("rate limit of %d transactions per %v reached", limit, rule.window)
+	}
+	if rule.RateLimit.MaxValue != nil {
+		total := new(big.Int).Set(value)
+		for _, entry := range entries {
+			total.Add(total, entry.Value)
+		}
+		if total.Cmp((*big.Int)(rule.RateLimit.MaxValue)) > 0 {
+			return fmt.Errorf("value limit of %v per %v exceeded", (*big.Int)(rule.RateLimit.MaxValue), rule.window)
+		}
+	}
+	return nil
+}
+
+// recordRate records an approved transaction for the rate limit of the rule.
+func (p *policyUI) recordRate(rule *PolicyRule, account common.Address, value *big.Int) {
+	entries := append(p.rateEntries(rule, account), rateEntry{Time: p.now().Unix(), Value: value})
+	blob, err := json.Marshal(entries)
+	if err != nil {
+		log.Warn("Failed to encode rate limit state", "err", err)
+		return
+	}
+	p.storage.Put(rateKey(rule.Name, account), string(blob))
+}
+
+// matchAccount checks whether the rule applies to the account.
+func (rule *PolicyRule) matchAccount(account common.Address) error {
+	if len(rule.Accounts) == 0 {
+		return nil
+	}
+	for _, a := range rule.Accounts {
+		if a == account {
+			return nil
+		}
+	}
+	return fmt.Errorf("account %v not covered", account)
+}
+
+// matchCallinfo checks the validation messages of a request.
+func (rule *PolicyRule) matchCallinfo(callinfo []apitypes.ValidationInfo) error {
+	for _, info := range callinfo {
+		switch {
+		case info.Typ == apitypes.CRIT:
+			return fmt.Errorf("critical validation message: %s", info.Message)
+		case info.Typ == apitypes.WARN && !rule.AllowWarnings:
+			return fmt.Errorf("validation warning: %s", info.Message)
+		}
+	}
+	return nil
+}
+
+// matchTx checks whether the rule approves the transaction, returning the
+// reason if not.
+func (p *policyUI) matchTx(rule *PolicyRule, args *apitypes.SendTxArgs, callinfo []apitypes.ValidationInfo) error {
+	if len(rule.Domains) > 0 {
+		return errors.New("typed data rule")
+	}
+	from := args.From.Address()
+	if err := rule.matchAccount(from); err != nil {
+		return err
+	}
+	// Delegating an account's code hands over full control of it.
+	if len(args.AuthorizationList) > 0 {
+		return errors.New("authorization list not allowed")
+	}
+	if args.To == nil {
+		if !rule.AllowCreate {
+			return errors.New("contract creation not allowed")
+		}
+	} else if len(rule.Recipients) > 0 {
+		to, found := args.To.Address(), false
+		for _, r := range rule.Recipients {
+			if r == to {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("recipient %v not allowed", to)
+		}
+	}
+	if len(rule.selectors) > 0 {
+		var data []byte
+		if args.Input != nil {
+			data = *args.Input
+		} else if args.Data != nil {
+			data = *args.Data
+		}
+		if len(data) < 4 {
+			return errors.New("missing function selector")
+		}
+		found := false
+		for _, sel := range rule.selectors {
+			if bytes.Equal(sel, data[:4]) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("function selector %#x not allowed", data[:4])
+		}
+	}
+	value := args.Value.ToInt()
+	if rule.MaxValue != nil && value.Cmp((*big.Int)(rule.MaxValue)) > 0 {
+		return fmt.Errorf("value %v exceeds %v", value, (*big.Int)(rule.MaxValue))
+	}
+	if rule.MaxGas != nil && uint64(args.Gas) > uint64(*rule.MaxGas) {
+		return fmt.Errorf("gas %d exceeds %d", uint64(args.Gas), uint64(*rule.MaxGas))
+	}
+	if rule.MaxFeePerGas != nil {
+		var fee *big.Int
+		if args.MaxFeePerGas != nil {
+			fee = args.MaxFeePerGas.ToInt()
+		} else if args.GasPrice != nil {
+			fee = args.GasPrice.ToInt()
+		}
+		if fee == nil || fee.Cmp((*big.Int)(rule.MaxFeePerGas)) > 0 {
+			return fmt.Errorf("fee per gas %v exceeds %v", fee, (*big.Int)(rule.MaxFeePerGas))
+		}
+	}
+	if err := rule.matchCallinfo(callinfo); err != nil {
+		return err
+	}
+	if rule.RateLimit != nil {
+		return p.checkRate(rule, from, value)
+	}
+	return nil
+}
+
+// matchTypedData checks whether the rule approves the typed data signing
+// request, returning the reason if not.
+func (p *policyUI) matchTypedData(rule *PolicyRule, request *core.SignDataRequest) error {
+	if len(rule.Domains) == 0 {
+		return errors.New("transaction rule")
+	}
+	if err := rule.matchAccount(request.Address.Address()); err != nil {
+		return err
+	}
+	// The signed data is 0x19 0x01 || domainSeparator || hashStruct(message).
+	if len(request.Rawdata) != 66 {
+		return errors.New("malformed typed data")
+	}
+	separator := common.BytesToHash(request.Rawdata[2:34])
+	found := false
+	for _, s := range rule.separators {
+		if s == separator {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return fmt.Errorf("domain %v not allowed", separator)
+	}
+	return rule.matchCallinfo(request.Callinfo)
+}
+
+// evaluate matches the request against the rules in order, returning the
+// first rule matching it or nil along with the reasons no rule matched.
+func (p *policyUI) evaluate(match func(rule *PolicyRule) error) (*PolicyRule, string) {
+	var reasons []string
+	for i := range p.policy.Rules {
+		rule := &p.policy.Rules[i]
+		err := match(rule)
+		if err == nil {
+			return rule, ""
+		}
+		reasons = append(reasons, fmt.Sprintf("%s: %v", rule.Name, err))
+	}
+	if len(reasons) == 0 {
+		return nil, "no rules"
+	}
+	return nil, strings.Join(reasons, "; ")
+}
+
+// report logs the evaluation result and reports it to the user in dry-run mode.
+func (p *policyUI) report(kind string, rule *PolicyRule, reasons string) {
+	var msg string
+	if rule != nil {
+		msg = fmt.Sprintf("Policy: %s matched rule %q", kind, rule.Name)
+	} else {
+		msg = fmt.Sprintf("Policy: %s matched no rule (%s)", kind, reasons)
+	}
+	if p.policy.DryRun {
+		log.Info("Policy dry run", "request", kind, "result", msg)
+		p.next.ShowInfo("Dry run: " + msg)
+		return
+	}
+	log.Info(msg)
+}
+
+// decide returns whether the policy approves or rejects a request, or whether
+// it is to be forwarded to the next UI.
+func (p *policyUI) decide(rule *PolicyRule) (approved bool, handled bool) {
+	switch {
+	case p.policy.DryRun:
+		return false, false
+	case rule != nil:
+		return true, true
+	case p.policy.Unmatched == UnmatchedReject:
+		return false, true
+	}
+	return false, false
+}
+
+func (p *policyUI) ApproveTx(request *core.SignTxRequest) (core.SignTxResponse, error) {
+	p.lock.Lock()
+	rule, reasons := p.evaluate(func(rule *PolicyRule) error {
+		return p.matchTx(rule, &request.Transaction, request.Callinfo)
+	})
+	p.report("transaction", rule, reasons)
+	approved, handled := p.decide(rule)
+	if approved && rule.RateLimit != nil {
+		p.recordRate(rule, request.Transaction.From.Address(), request.Transaction.Value.ToInt())
+	}
+	p.lock.Unlock()
+
+	if !handled {
+		return p.next.ApproveTx(request)
+	}
+	if approved {
+		return core.SignTxResponse{Transaction: request.Transaction, Approved: true}, nil
+	}
+	return core.SignTxResponse{Approved: false}, nil
+}
+
+func (p *policyUI) ApproveSignData(request *core.SignDataRequest) (core.SignDataResponse, error) {
+	// Only typed data can be restricted to domains, other content types are
+	// never approved by the policy.
+	var rule *PolicyRule
+	if request.ContentType == apitypes.DataTyped.Mime {
+		var reasons string
+		rule, reasons = p.evaluate(func(rule *PolicyRule) error {
+			return p.matchTypedData(rule, request)
+		})
+		p.report("typed data", rule, reasons)
+	}
+	approved, handled := p.decide(rule)
+	if !handled {
+		return p.next.ApproveSignData(request)
+	}
+	return core.SignDataResponse{Approved: approved}, nil
+}
+
+// ApproveListing is not handled by the policy.
+func (p *policyUI) ApproveListing(request *core.ListRequest) (core.ListResponse, error) {
+	return p.next.ApproveListing(request)
+}
+
+// ApproveNewAccount is not handled by the policy.
+func (p *policyUI) ApproveNewAccount(request *core.NewAccountRequest) (core.NewAccountResponse, error) {
+	return p.next.ApproveNewAccount(request)
+}
+
+// OnInputRequired is not handled by the policy.
+func (p *policyUI) OnInputRequired(info core.UserInputRequest) (core.UserInputResponse, error) {
+	return p.next.OnInputRequired(info)
+}
+
+func (p *policyUI) RegisterUIServer(api *core.UIServerAPI) {
+	p.next.RegisterUIServer(api)
+}
+
+func (p *policyUI) ShowError(message string) {
+	log.Error(message)
+	p.next.ShowError(message)
+}
+
+func (p *policyUI) ShowInfo(message string) {
+	log.Info(message)
+	p.next.ShowInfo(message)
+}
+
+func (p *policyUI) OnSignerStartup(info core.StartupInfo) {
+	p.next.OnSignerStartup(info)
+}
+
+func (p *policyUI) OnApprovedTx(tx ethapi.SignTransactionResult) {
+	p.next.OnApprovedTx(tx)
+}
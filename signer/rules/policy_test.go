@@ -0,0 +1,318 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rules
+
+import (
+	"math/big"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/eip712"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/signer/core"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+	"github.com/ethereum/go-ethereum/signer/storage"
+)
+
+var (
+	policyAccount = common.HexToAddress("0x000000000000000000000000000000000000aaaa")
+	policyToken   = common.HexToAddress("0x000000000000000000000000000000000000bbbb")
+	policyFriend  = common.HexToAddress("0x000000000000000000000000000000000000cccc")
+)
+
+const yamlPolicy = `
+unmatched: reject
+rules:
+  - name: token-transfers
+    accounts: [0x000000000000000000000000000000000000aaaa]
+    recipients: [0x000000000000000000000000000000000000bbbb]
+    selectors: ["transfer(address, uint256)", "0x095ea7b3"]
+    maxGas: 100000
+    maxFeePerGas: 0x174876e800
+  - name: payments
+    accounts: [0x000000000000000000000000000000000000aaaa]
+    recipients: [0x000000000000000000000000000000000000cccc]
+    maxValue: 1000000000000000000
+    rateLimit:
+      window: 1h
+      maxTxs: 3
+      maxValue: 2000000000000000000
+  - name: permits
+    domains:
+      - name: Token
+        version: "1"
+        chainId: 1
+        verifyingContract: "0x000000000000000000000000000000000000bbbb"
+`
+
+const jsonPolicy = `{
+  "unmatched": "reject",
+  "rules": [
+    {
+      "name": "token-transfers",
+      "accounts": ["0x000000000000000000000000000000000000aaaa"],
+      "recipients": ["0x000000000000000000000000000000000000bbbb"],
+      "selectors": ["transfer(address, uint256)", "0x095ea7b3"],
+      "maxGas": "0x186a0",
+      "maxFeePerGas": "100000000000"
+    },
+    {
+      "name": "payments",
+      "accounts": ["0x000000000000000000000000000000000000aaaa"],
+      "recipients": ["0x000000000000000000000000000000000000cccc"],
+      "maxValue": "1000000000000000000",
+      "rateLimit": {"window": "1h", "maxTxs": 3, "maxValue": "0x1bc16d674ec80000"}
+    },
+    {
+      "name": "permits",
+      "domains": [
+        {"name": "Token", "version": "1", "chainId": 1, "verifyingContract": "0x000000000000000000000000000000000000bbbb"}
+      ]
+    }
+  ]
+}`
+
+const gwei = 1000000000
+
+func newPolicyUI(t *testing.T, policy string, next core.UIClientAPI) *policyUI {
+	t.Helper()
+	p, err := ParsePolicy([]byte(policy))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ui, err := NewPolicyEvaluator(next, p, storage.NewEphemeralStorage())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ui
+}
+
+func policyTx(to *common.Address, value int64, data []byte) *core.SignTxRequest {
+	var mto *common.MixedcaseAddress
+	if to != nil {
+		m := common.NewMixedcaseAddress(*to)
+		mto = &m
+	}
+	input := hexutil.Bytes(data)
+	return &core.SignTxRequest{
+		Transaction: apitypes.SendTxArgs{
+			From:         common.NewMixedcaseAddress(policyAccount),
+			To:           mto,
+			Value:        hexutil.Big(*big.NewInt(value)),
+			Gas:          50000,
+			MaxFeePerGas: (*hexutil.Big)(big.NewInt(gwei)),
+			Input:        &input,
+		},
+	}
+}
+
+func TestParsePolicy(t *testing.T) {
+	t.Parallel()
+	fromYAML, err := ParsePolicy([]byte(yamlPolicy))
+	if err != nil {
+		t.Fatalf("failed to parse YAML policy: %v", err)
+	}
+	fromJSON, err := ParsePolicy([]byte(jsonPolicy))
+	if err != nil {
+		t.Fatalf("failed to parse JSON policy: %v", err)
+	}
+	if !reflect.DeepEqual(fromYAML, fromJSON) {
+		t.Fatalf("YAML and JSON policies differ:\n%+v\n%+v", fromYAML, fromJSON)
+	}
+	if sel := fromYAML.Rules[0].selectors[0]; !reflect.DeepEqual(sel, common.FromHex("0xa9059cbb")) {
+		t.Fatalf("wrong selector %x", sel)
+	}
+	for _, invalid := range []string{
+		"unmatched: approve",
+		"unknownField: true",
+		"rules: [{selectors: [0x1234]}]",
+		"rules: [{selectors: [transfer]}]",
+		"rules: [{name: a}, {name: a}]",
+		"rules: [{rateLimit: {window: forever}}]",
+		"rules: [{maxValue: 1, domains: [{name: Token}]}]",
+		"rules: [{maxGas: -1}]",
+	} {
+		if _, err := ParsePolicy([]byte(invalid)); err == nil {
+			t.Errorf("expected error for policy %q", invalid)
+		}
+	}
+}
+
+func TestPolicyTransactions(t *testing.T) {
+	t.Parallel()
+	ui := newPolicyUI(t, yamlPolicy, &dontCallMe{t})
+
+	transfer := append(common.FromHex("0xa9059cbb"), make([]byte, 64)...)
+	for i, tt := range []struct {
+		req  *core.SignTxRequest
+		want bool
+	}{
+		{policyTx(&policyToken, 0, transfer), true},
+		{policyTx(&policyToken, 0, common.FromHex("0x095ea7b3")), true},
+		{policyTx(&policyToken, 0, common.FromHex("0x23b872dd")), false}, // transferFrom
+		{policyTx(&policyToken, 0, nil), false},                          // no selector
+		{policyTx(&policyFriend, 1, nil), true},
+		{policyTx(&policyFriend, 2e18, nil), false}, // value too high
+		{policyTx(&policyAccount, 1, nil), false},   // unknown recipient
+		{policyTx(nil, 0, nil), false},              // creation
+		{func() *core.SignTxRequest { // gas too high
+			req := policyTx(&policyToken, 0, transfer)
+			req.Transaction.Gas = 200000
+			return req
+		}(), false},
+		{func() *core.SignTxRequest { // fee too high
+			req := policyTx(&policyToken, 0, transfer)
+			req.Transaction.MaxFeePerGas = (*hexutil.Big)(big.NewInt(1000 * gwei))
+			return req
+		}(), false},
+		{func() *core.SignTxRequest { // different sender
+			req := policyTx(&policyToken, 0, transfer)
+			req.Transaction.From = common.NewMixedcaseAddress(policyFriend)
+			return req
+		}(), false},
+		{func() *core.SignTxRequest { // delegation
+			req := policyTx(&policyToken, 0, transfer)
+			req.Transaction.AuthorizationList = []types.SetCodeAuthorization{{Address: policyToken}}
+			return req
+		}(), false},
+		{func() *core.SignTxRequest { // validation warning
+			req := policyTx(&policyToken, 0, transfer)
+			req.Callinfo = []apitypes.ValidationInfo{{Typ: apitypes.WARN, Message: "warning"}}
+			return req
+		}(), false},
+	} {
+		resp, err := ui.ApproveTx(tt.req)
+		if err != nil {
+			t.Fatalf("test %d: unexpected error: %v", i, err)
+		}
+		if resp.Approved != tt.want {
+			t.Errorf("test %d: approved %v, want %v", i, resp.Approved, tt.want)
+		}
+	}
+}
+
+func TestPolicyRateLimit(t *testing.T) {
+	t.Parallel()
+	ui := newPolicyUI(t, yamlPolicy, &dontCallMe{t})
+	now := time.Unix(1700000000, 0)
+	ui.now = func() time.Time { return now }
+
+	check := func(value int64, want bool) {
+		t.Helper()
+		resp, _ := ui.ApproveTx(policyTx(&policyFriend, value, nil))
+		if resp.Approved != want {
+			t.Fatalf("approved %v, want %v", resp.Approved, want)
+		}
+	}
+	// The total value within the window is limited to 2 ether.
+	check(1e18, true)
+	check(8e17, true)
+	check(3e17, false)
+	// The number of transactions within the window is limited to 3.
+	check(1e17, true)
+	check(1, false)
+
+	// Once the first ones leave the window, more can be approved.
+	now = now.Add(time.Hour - time.Second)
+	check(1, false)
+	now = now.Add(time.Second)
+	check(1e18, true)
+}
+
+func TestPolicyTypedData(t *testing.T) {
+	t.Parallel()
+	ui := newPolicyUI(t, yamlPolicy, &dontCallMe{t})
+
+	request := func(domain eip712.TypedDataDomain) *core.SignDataRequest {
+		separator, err := domain.Separator()
+		if err != nil {
+			t.Fatal(err)
+		}
+		rawdata := append([]byte{0x19, 0x01}, separator.Bytes()...)
+		rawdata = append(rawdata, make([]byte, 32)...)
+		return &core.SignDataRequest{
+			ContentType: apitypes.DataTyped.Mime,
+			Address:     common.NewMixedcaseAddress(policyAccount),
+			Rawdata:     rawdata,
+		}
+	}
+	domain := eip712.TypedDataDomain{
+		Name:              "Token",
+		Version:           "1",
+		ChainId:           math.NewHexOrDecimal256(1),
+		VerifyingContract: policyToken.Hex(),
+	}
+	if resp, _ := ui.ApproveSignData(request(domain)); !resp.Approved {
+		t.Fatal("typed data for allowed domain not approved")
+	}
+	domain.ChainId = math.NewHexOrDecimal256(5)
+	if resp, _ := ui.ApproveSignData(request(domain)); resp.Approved {
+		t.Fatal("typed data for other domain approved")
+	}
+	text := &core.SignDataRequest{ContentType: apitypes.TextPlain.Mime, Rawdata: []byte("hello")}
+	if resp, _ := ui.ApproveSignData(text); resp.Approved {
+		t.Fatal("plain text approved")
+	}
+}
+
+func TestPolicyDryRun(t *testing.T) {
+	t.Parallel()
+	var (
+		next = &infoUI{}
+		ui   = newPolicyUI(t, "dryRun: true\n"+yamlPolicy, next)
+	)
+	// A matching request is reported, but still goes to manual.
+	resp, err := ui.ApproveTx(policyTx(&policyFriend, 1, nil))
+	if err == nil || resp.Approved {
+		t.Fatal("expected request to be forwarded to next UI")
+	}
+	if want := []string{"ShowInfo", "ApproveTx"}; !reflect.DeepEqual(next.calls, want) {
+		t.Fatalf("wrong calls %v, want %v", next.calls, want)
+	}
+	if !strings.Contains(next.infos[0], `"payments"`) {
+		t.Fatalf("matched rule not reported: %q", next.infos[0])
+	}
+	// An unmatched one is reported with the reasons, and goes to manual too
+	// even though unmatched requests are rejected.
+	ui.ApproveTx(policyTx(&policyAccount, 1, nil))
+	if !strings.Contains(next.infos[1], "no rule") || !strings.Contains(next.infos[1], "not allowed") {
+		t.Fatalf("mismatch not reported: %q", next.infos[1])
+	}
+	if len(next.calls) != 4 || next.calls[3] != "ApproveTx" {
+		t.Fatalf("unmatched request not forwarded: %v", next.calls)
+	}
+	// Dry runs don't count towards rate limits.
+	if entries := ui.rateEntries(&ui.policy.Rules[1], policyAccount); len(entries) != 0 {
+		t.Fatalf("dry run recorded %d rate limit entries", len(entries))
+	}
+}
+
+// infoUI is a dummyUI which also records the shown info messages.
+type infoUI struct {
+	dummyUI
+	infos []string
+}
+
+func (i *infoUI) ShowInfo(message string) {
+	i.dummyUI.ShowInfo(message)
+	i.infos = append(i.infos, message)
+}
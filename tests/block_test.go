@@ -18,10 +18,12 @@ package tests
 
 import (
 	"math/rand"
+	"path/filepath"
 	"testing"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/tracing"
 )
 
 func TestBlockchain(t *testing.T) {
@@ -80,6 +82,37 @@ func TestExecutionSpecBlocktests(t *testing.T) {
 	})
 }
 
+// TestBlockchainBlockAnalysis runs the blockchain tests with tracing enabled,
+// which disables the basic block analysis of the interpreter. Together with the
+// untraced runs of TestBlockchain, this checks both execution paths against the
+// expected results.
+func TestBlockchainBlockAnalysis(t *testing.T) {
+	for _, dir := range []string{blockTestDir, executionSpecBlockchainTestDir} {
+		t.Run(filepath.Base(filepath.Dir(dir))+"/"+filepath.Base(dir), func(t *testing.T) {
+			bt := new(testMatcher)
+			bt.skipLoad(`^GeneralStateTests/VMTests/vmPerformance`)
+			bt.skipLoad(`.*bcForgedTest/bcForkUncle\.json`)
+			bt.skipLoad(`.*/stTimeConsuming/.*`)
+			bt.skipLoad(`.*randomStatetest94.json.*`)
+			bt.skipLoad(`.*bcMultiChainTest/ChainAtoChainB_difficultyB.json`)
+			bt.skipLoad(`.*bcMultiChainTest/CallContractFromNotBestBlock.json`)
+			bt.skipLoad(`.*bcTotalDifficultyTest/uncleBlockAtBlock3afterBlock4.json`)
+			bt.skipLoad(`.*bcTotalDifficultyTest/lotsOfBranchesOverrideAtTheMiddle.json`)
+			bt.skipLoad(`.*bcTotalDifficultyTest/sideChainWithMoreTransactions.json`)
+			bt.skipLoad(`.*bcForkStressTest/ForkStressTest.json`)
+			bt.skipLoad(`.*bcMultiChainTest/lotsOfLeafs.json`)
+			bt.skipLoad(`.*bcFrontierToHomestead/blockChainFrontierWithLargerTDvsHomesteadBlockchain.json`)
+			bt.skipLoad(`.*bcFrontierToHomestead/blockChainFrontierWithLargerTDvsHomesteadBlockchain2.json`)
+
+			bt.walk(t, dir, func(t *testing.T, name string, test *BlockTest) {
+				if err := bt.checkFailure(t, test.Run(false, rawdb.HashScheme, false, &tracing.Hooks{}, nil)); err != nil {
+					t.Error(err)
+				}
+			})
+		})
+	}
+}
+
 func execBlockTest(t *testing.T, bt *testMatcher, test *BlockTest) {
 	// Define all the different flag combinations we should run the tests with,
 	// picking only one for short tests.
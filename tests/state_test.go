@@ -32,6 +32,7 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/tracing"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/core/vm"
 	"github.com/ethereum/go-ethereum/eth/tracers/logger"
@@ -102,6 +103,51 @@ func TestExecutionSpecState(t *testing.T) {
 	})
 }
 
+// TestStateBlockAnalysis runs the state tests with and without the basic block
+// analysis of the interpreter, which is disabled when tracing, and ensures both
+// produce the same post state and logs.
+func TestStateBlockAnalysis(t *testing.T) {
+	t.Parallel()
+
+	for _, dir := range []string{stateTestDir, legacyStateTestDir, executionSpecStateTestDir} {
+		t.Run(filepath.Base(filepath.Dir(dir))+"/"+filepath.Base(dir), func(t *testing.T) {
+			st := new(testMatcher)
+			if dir != executionSpecStateTestDir {
+				initMatcher(st)
+			}
+			st.walk(t, dir, func(t *testing.T, name string, test *StateTest) {
+				execStateTestBlockAnalysis(t, test)
+			})
+		})
+	}
+}
+
+func execStateTestBlockAnalysis(t *testing.T, test *StateTest) {
+	for _, subtest := range test.Subtests() {
+		key := fmt.Sprintf("%s/%d", subtest.Fork, subtest.Index)
+		t.Run(key, func(t *testing.T) {
+			run := func(vmconfig vm.Config) (common.Hash, common.Hash, error) {
+				state, root, err := test.RunNoVerify(subtest, vmconfig, false, rawdb.HashScheme)
+				defer state.Close()
+
+				var logs common.Hash
+				if state.StateDB != nil {
+					logs = rlpHash(state.StateDB.Logs())
+				}
+				return root, logs, err
+			}
+			root, logs, err := run(vm.Config{})
+			if _, ok := err.(UnsupportedForkError); ok {
+				t.Skip(err)
+			}
+			wantRoot, wantLogs, wantErr := run(vm.Config{Tracer: &tracing.Hooks{}})
+			if root != wantRoot || logs != wantLogs || fmt.Sprint(err) != fmt.Sprint(wantErr) {
+				t.Fatalf("block analysis mismatch: root %x/%x, logs %x/%x, err %v/%v", root, wantRoot, logs, wantLogs, err, wantErr)
+			}
+		})
+	}
+}
+
 func execStateTest(t *testing.T, st *testMatcher, test *StateTest) {
 	for _, subtest := range test.Subtests() {
 		key := fmt.Sprintf("%s/%d", subtest.Fork, subtest.Index)